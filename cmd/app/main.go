@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
@@ -25,6 +29,42 @@ import (
 	testimonialsRepository "sea-catering-backend/internal/api/testimonials/repository"
 	testimonialsService "sea-catering-backend/internal/api/testimonials/service"
 
+	deliveriesHandler "sea-catering-backend/internal/api/deliveries/handler"
+	deliveriesRepository "sea-catering-backend/internal/api/deliveries/repository"
+	deliveriesService "sea-catering-backend/internal/api/deliveries/service"
+	dispatchHandler "sea-catering-backend/internal/api/dispatch/handler"
+	dispatchService "sea-catering-backend/internal/api/dispatch/service"
+	exportsHandler "sea-catering-backend/internal/api/exports/handler"
+	exportsRepository "sea-catering-backend/internal/api/exports/repository"
+	exportsService "sea-catering-backend/internal/api/exports/service"
+	giftsHandler "sea-catering-backend/internal/api/gifts/handler"
+	giftsRepository "sea-catering-backend/internal/api/gifts/repository"
+	giftsService "sea-catering-backend/internal/api/gifts/service"
+	importsHandler "sea-catering-backend/internal/api/imports/handler"
+	importsRepository "sea-catering-backend/internal/api/imports/repository"
+	importsService "sea-catering-backend/internal/api/imports/service"
+	kitchenHandler "sea-catering-backend/internal/api/kitchen/handler"
+	kitchenRepository "sea-catering-backend/internal/api/kitchen/repository"
+	kitchenService "sea-catering-backend/internal/api/kitchen/service"
+	metricsHandler "sea-catering-backend/internal/api/metrics/handler"
+	metricsRepository "sea-catering-backend/internal/api/metrics/repository"
+	metricsService "sea-catering-backend/internal/api/metrics/service"
+	notificationsHandler "sea-catering-backend/internal/api/notifications/handler"
+	notificationsRepository "sea-catering-backend/internal/api/notifications/repository"
+	notificationsService "sea-catering-backend/internal/api/notifications/service"
+	organizationsHandler "sea-catering-backend/internal/api/organizations/handler"
+	organizationsRepository "sea-catering-backend/internal/api/organizations/repository"
+	organizationsService "sea-catering-backend/internal/api/organizations/service"
+	outboxHandler "sea-catering-backend/internal/api/outbox/handler"
+	outboxRepository "sea-catering-backend/internal/api/outbox/repository"
+	outboxService "sea-catering-backend/internal/api/outbox/service"
+	privacyHandler "sea-catering-backend/internal/api/privacy/handler"
+	privacyRepository "sea-catering-backend/internal/api/privacy/repository"
+	privacyService "sea-catering-backend/internal/api/privacy/service"
+	webhooksHandler "sea-catering-backend/internal/api/webhooks/handler"
+	webhooksRepository "sea-catering-backend/internal/api/webhooks/repository"
+	webhooksService "sea-catering-backend/internal/api/webhooks/service"
+
 	adminHandler "sea-catering-backend/internal/api/admin/handler"
 	adminRepository "sea-catering-backend/internal/api/admin/repository"
 	adminService "sea-catering-backend/internal/api/admin/service"
@@ -32,11 +72,15 @@ import (
 	"sea-catering-backend/internal/config"
 	"sea-catering-backend/internal/middleware"
 	"sea-catering-backend/pkg/bcrypt"
+	"sea-catering-backend/pkg/database"
 	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/events"
 	"sea-catering-backend/pkg/jwt"
 	"sea-catering-backend/pkg/logger"
 	"sea-catering-backend/pkg/redis"
 	"sea-catering-backend/pkg/s3"
+	"sea-catering-backend/pkg/screening"
+	"sea-catering-backend/pkg/sms"
 	"sea-catering-backend/pkg/utils"
 )
 
@@ -75,7 +119,7 @@ func main() {
 	appLogger.Info("Initializing services...")
 
 	validator := config.NewValidator()
-	jwtService := jwt.New()
+	jwtService := jwt.NewWithConfig(jwt.LoadConfig(), redisClient.GetClient())
 	bcryptService := bcrypt.New()
 	utilsService := utils.New()
 
@@ -87,6 +131,13 @@ func main() {
 		})
 	}
 
+	smsService, err := sms.New(appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize SMS service", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
 	fiberApp := config.NewFiber(appLogger)
 
 	middlewareService := middleware.New(appLogger, jwtService)
@@ -103,6 +154,90 @@ func main() {
 	subscriptionRepo := subscriptionsRepository.NewSubscriptionRepository(db, appLogger, utilsService)
 	testimonialRepo := testimonialsRepository.NewTestimonialRepository(db)
 	adminRepo := adminRepository.NewAdminRepository(db)
+	giftRepo := giftsRepository.NewGiftRepository(db, appLogger)
+	organizationRepo := organizationsRepository.NewOrganizationRepository(db)
+	deliveryRepo := deliveriesRepository.NewDeliveryRepository(db, appLogger)
+	kitchenRepo := kitchenRepository.NewKitchenRepository(db)
+	outboxRepo := outboxRepository.NewOutboxRepository(db)
+	preferenceRepo := notificationsRepository.NewPreferenceRepository(db)
+	inboxRepo := notificationsRepository.NewInboxRepository(db)
+	webhookRepo := webhooksRepository.NewWebhookRepository(db)
+	metricsRepo := metricsRepository.NewMetricsRepository(db)
+	exportRepo := exportsRepository.NewExportRepository(db)
+	importRepo := importsRepository.NewImportRepository(db)
+	privacyRepo := privacyRepository.NewPrivacyRepository(db)
+
+	txManager := database.NewTxManager(db)
+
+	outboxSvc := outboxService.NewOutboxService(outboxRepo, emailService, utilsService, outboxService.LoadConfig(), appLogger)
+	emailService.SetQueue(outboxSvc)
+	outboxSvc.Start(context.Background())
+	defer outboxSvc.Stop()
+
+	preferenceSvc := notificationsService.NewPreferenceService(preferenceRepo, userRepo, notificationsService.LoadPreferenceConfig(), appLogger)
+	emailService.SetConsentChecker(preferenceSvc)
+	emailService.SetLocaleResolver(preferenceSvc)
+
+	inboxSvc := notificationsService.NewInboxService(inboxRepo, preferenceSvc, redisClient, utilsService, appLogger)
+
+	eventBus := events.New(appLogger)
+	defer eventBus.Close()
+
+	notificationSvc := notificationsService.NewNotificationService(
+		userRepo,
+		subscriptionRepo,
+		preferenceSvc,
+		inboxSvc,
+		emailService,
+		smsService,
+		appLogger,
+	)
+	notificationSvc.RegisterHandlers(eventBus)
+
+	webhookSvc := webhooksService.NewWebhookService(webhookRepo, utilsService, webhooksService.LoadConfig(), appLogger)
+	webhookSvc.RegisterHandlers(eventBus)
+	webhookSvc.Start(context.Background())
+	defer webhookSvc.Stop()
+
+	metricsSvc := metricsService.NewMetricsService(metricsRepo, metricsService.LoadConfig(), appLogger)
+	metricsSvc.Start(context.Background())
+	defer metricsSvc.Stop()
+
+	exportSvc := exportsService.NewExportService(
+		exportRepo,
+		adminRepo,
+		testimonialRepo,
+		metricsRepo,
+		s3Service,
+		utilsService,
+		exportsService.LoadConfig(),
+		appLogger,
+	)
+	exportSvc.Start(context.Background())
+	defer exportSvc.Stop()
+
+	privacySvc := privacyService.NewPrivacyService(
+		privacyRepo,
+		userRepo,
+		s3Service,
+		emailService,
+		jwtService,
+		bcryptService,
+		utilsService,
+		privacyService.LoadConfig(),
+		appLogger,
+	)
+	privacySvc.Start(context.Background())
+	defer privacySvc.Stop()
+
+	importSvc := importsService.NewImportService(
+		importRepo,
+		validator,
+		bcryptService,
+		utilsService,
+		importsService.LoadConfig(),
+		appLogger,
+	)
 
 	authSvc := authService.NewAuthService(
 		userRepo,
@@ -110,6 +245,7 @@ func main() {
 		bcryptService,
 		redisClient,
 		emailService,
+		smsService,
 		s3Service,
 		utilsService,
 		appLogger,
@@ -124,30 +260,96 @@ func main() {
 	subscriptionSvc := subscriptionsService.NewSubscriptionService(
 		subscriptionRepo,
 		mealPlanRepo,
+		organizationRepo,
+		txManager,
+		eventBus,
 		utilsService,
 		appLogger,
 	)
 
+	testimonialScreener, err := screening.New(screening.LoadConfig(), testimonialRepo, redisClient, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize content screening", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
 	testimonialSvc := testimonialsService.NewTestimonialService(
 		testimonialRepo,
+		userRepo,
+		mealPlanRepo,
+		subscriptionRepo,
+		eventBus,
+		testimonialScreener,
+		utilsService,
+		appLogger,
+	)
+
+	giftSvc := giftsService.NewGiftService(
+		giftRepo,
+		subscriptionRepo,
+		mealPlanRepo,
+		userRepo,
+		txManager,
+		emailService,
+		utilsService,
+		appLogger,
+	)
+
+	organizationSvc := organizationsService.NewOrganizationService(
+		organizationRepo,
+		userRepo,
+		emailService,
+		utilsService,
+		appLogger,
+	)
+
+	deliverySvc := deliveriesService.NewDeliveryService(
+		deliveryRepo,
+		userRepo,
+		s3Service,
+		bcryptService,
+		eventBus,
 		utilsService,
 		appLogger,
 	)
 
+	dispatchSvc := dispatchService.NewDispatchService(deliveryRepo, dispatchService.LoadConfig(), appLogger)
+
+	kitchenSvc := kitchenService.NewKitchenService(kitchenRepo, appLogger)
+
 	adminSvc := adminService.NewAdminService(
 		adminRepo,
+		metricsRepo,
 		subscriptionRepo,
 		testimonialRepo,
 		userRepo,
+		txManager,
 		jwtService,
 		bcryptService,
+		eventBus,
+		adminService.LoadConfig(),
 		appLogger,
 	)
+	adminSvc.Start(context.Background())
+	defer adminSvc.Stop()
 
 	authHdlr := authHandler.NewAuthHandler(authSvc, validator, appLogger)
 	mealPlanHdlr := mealPlansHandler.NewMealPlanHandler(mealPlanSvc, validator, middlewareService, appLogger)
 	subscriptionHdlr := subscriptionsHandler.NewSubscriptionHandler(subscriptionSvc, validator, middlewareService, appLogger)
 	testimonialHdlr := testimonialsHandler.NewTestimonialHandler(testimonialSvc, validator, middlewareService, appLogger)
+	giftHdlr := giftsHandler.NewGiftHandler(giftSvc, validator, middlewareService, appLogger)
+	organizationHdlr := organizationsHandler.NewOrganizationHandler(organizationSvc, validator, middlewareService, appLogger)
+	deliveryHdlr := deliveriesHandler.NewDeliveryHandler(deliverySvc, validator, middlewareService, appLogger)
+	dispatchHdlr := dispatchHandler.NewDispatchHandler(dispatchSvc, validator, middlewareService, appLogger)
+	kitchenHdlr := kitchenHandler.NewKitchenHandler(kitchenSvc, validator, middlewareService, appLogger)
+	outboxHdlr := outboxHandler.NewOutboxHandler(outboxSvc, validator, middlewareService, appLogger)
+	notificationHdlr := notificationsHandler.NewNotificationHandler(preferenceSvc, inboxSvc, validator, middlewareService, appLogger)
+	webhookHdlr := webhooksHandler.NewWebhookHandler(webhookSvc, validator, middlewareService, appLogger)
+	metricsHdlr := metricsHandler.NewMetricsHandler(metricsSvc, validator, middlewareService, appLogger)
+	exportHdlr := exportsHandler.NewExportHandler(exportSvc, validator, middlewareService, appLogger)
+	importHdlr := importsHandler.NewImportHandler(importSvc, validator, middlewareService, appLogger)
+	privacyHdlr := privacyHandler.NewPrivacyHandler(privacySvc, validator, middlewareService, appLogger)
 	adminHdlr := adminHandler.NewAdminHandler(adminSvc, validator, middlewareService, appLogger)
 
 	api := fiberApp.Group("/api/v1")
@@ -160,6 +362,30 @@ func main() {
 
 	testimonialHdlr.RegisterRoutes(api)
 
+	giftHdlr.RegisterRoutes(api)
+
+	organizationHdlr.RegisterRoutes(api)
+
+	deliveryHdlr.RegisterRoutes(api)
+
+	dispatchHdlr.RegisterRoutes(api)
+
+	kitchenHdlr.RegisterRoutes(api)
+
+	outboxHdlr.RegisterRoutes(api)
+
+	notificationHdlr.RegisterRoutes(api)
+
+	webhookHdlr.RegisterRoutes(api)
+
+	metricsHdlr.RegisterRoutes(api)
+
+	exportHdlr.RegisterRoutes(api)
+
+	importHdlr.RegisterRoutes(api)
+
+	privacyHdlr.RegisterRoutes(api)
+
 	adminHdlr.RegisterRoutes(api)
 
 	fiberApp.Get("/health", func(c *fiber.Ctx) error {
@@ -185,6 +411,8 @@ func main() {
 					"update_profile":  "PUT /api/v1/user/profile",
 					"change_password": "POST /api/v1/user/change-password",
 					"upload_image":    "POST /api/v1/user/profile/image",
+					"send_phone_otp":  "POST /api/v1/user/phone/send-otp",
+					"verify_phone":    "POST /api/v1/user/phone/verify-otp",
 				},
 				"meal_plans": fiber.Map{
 					"list":        "GET /api/v1/meal-plans",
@@ -201,30 +429,129 @@ func main() {
 					"stats":       "GET /api/v1/meal-plans/admin/stats (Admin only)",
 				},
 				"subscriptions": fiber.Map{
-					"create":     "POST /api/v1/subscriptions (Auth required)",
-					"my":         "GET /api/v1/subscriptions/my (Auth required)",
-					"get_by_id":  "GET /api/v1/subscriptions/{id} (Auth required)",
-					"update":     "PUT /api/v1/subscriptions/{id} (Auth required)",
-					"pause":      "PUT /api/v1/subscriptions/{id}/pause (Auth required)",
-					"resume":     "PUT /api/v1/subscriptions/{id}/resume (Auth required)",
-					"reactivate": "PUT /api/v1/subscriptions/{id}/reactivate (Auth required)",
-					"cancel":     "DELETE /api/v1/subscriptions/{id} (Auth required)",
-					"stats":      "GET /api/v1/subscriptions/admin/stats (Admin only)",
+					"create":               "POST /api/v1/subscriptions (Auth required)",
+					"my":                   "GET /api/v1/subscriptions/my (Auth required)",
+					"get_by_id":            "GET /api/v1/subscriptions/{id} (Auth required)",
+					"update":               "PUT /api/v1/subscriptions/{id} (Auth required)",
+					"pause":                "PUT /api/v1/subscriptions/{id}/pause (Auth required)",
+					"resume":               "PUT /api/v1/subscriptions/{id}/resume (Auth required)",
+					"reactivate":           "PUT /api/v1/subscriptions/{id}/reactivate (Auth required)",
+					"cancel":               "DELETE /api/v1/subscriptions/{id} (Auth required)",
+					"stats":                "GET /api/v1/subscriptions/admin/stats (Admin only)",
+					"cancellation_reasons": "GET /api/v1/subscriptions/admin/cancellation-reasons?start_date={YYYY-MM-DD}&end_date={YYYY-MM-DD}&meal_plan_id={id} (Admin only)",
+					"reminders":            "POST /api/v1/subscriptions/admin/process-pause-reminders (Admin only)",
 				},
 				"testimonials": fiber.Map{
-					"create":        "POST /api/v1/testimonials",
-					"get_approved":  "GET /api/v1/testimonials",
+					"create":        "POST /api/v1/testimonials (Auth required)",
+					"mine":          "GET /api/v1/testimonials/mine (Auth required)",
+					"update":        "PUT /api/v1/testimonials/{id} (Auth required)",
+					"get_approved":  "GET /api/v1/testimonials?meal_plan_id={id}",
 					"admin_get_all": "GET /api/v1/testimonials/admin/all (Admin only)",
+					"admin_queue":   "GET /api/v1/testimonials/admin/queue?status={queue|all|pending|approved|rejected|hidden|flagged}&rating=&verified=&min_score=&meal_plan_id=&search=&sort={oldest|newest|score} (Admin only)",
+					"admin_detail":  "GET /api/v1/testimonials/admin/{id} (Admin only, includes moderation history)",
 					"admin_approve": "PUT /api/v1/testimonials/admin/{id}/approve (Admin only)",
-					"admin_reject":  "PUT /api/v1/testimonials/admin/{id}/reject (Admin only)",
+					"admin_reject":  "PUT /api/v1/testimonials/admin/{id}/reject (Admin only, reason required)",
+					"admin_hide":    "PUT /api/v1/testimonials/admin/{id}/hide (Admin only, reason required)",
+					"admin_flag":    "PUT /api/v1/testimonials/admin/{id}/flag (Admin only, note required)",
 					"admin_delete":  "DELETE /api/v1/testimonials/admin/{id} (Admin only)",
 				},
+				"gifts": fiber.Map{
+					"purchase":          "POST /api/v1/gifts (Auth required)",
+					"my":                "GET /api/v1/gifts/my (Auth required)",
+					"redeem":            "POST /api/v1/gifts/redeem (Auth required)",
+					"resend":            "POST /api/v1/gifts/{id}/resend (Auth required)",
+					"admin_outstanding": "GET /api/v1/gifts/admin/outstanding (Admin only)",
+					"admin_resend":      "POST /api/v1/gifts/admin/{id}/resend (Admin only)",
+					"process_expired":   "POST /api/v1/gifts/admin/process-expired (Admin only)",
+				},
+				"organizations": fiber.Map{
+					"create":            "POST /api/v1/organizations (Auth required)",
+					"my":                "GET /api/v1/organizations/my (Auth required)",
+					"accept_invitation": "POST /api/v1/organizations/invitations/accept (Auth required)",
+					"get":               "GET /api/v1/organizations/{orgId} (Org admin only)",
+					"update":            "PUT /api/v1/organizations/{orgId} (Org admin only)",
+					"dashboard":         "GET /api/v1/organizations/{orgId}/dashboard (Org admin only)",
+					"members":           "GET /api/v1/organizations/{orgId}/members (Org admin only)",
+					"invite":            "POST /api/v1/organizations/{orgId}/members/invite (Org admin only)",
+					"update_allowance":  "PUT /api/v1/organizations/{orgId}/members/{memberId}/allowance (Org admin only)",
+					"remove_member":     "DELETE /api/v1/organizations/{orgId}/members/{memberId} (Org admin only)",
+					"subscriptions":     "GET /api/v1/organizations/{orgId}/subscriptions (Org admin only)",
+					"generate_invoice":  "POST /api/v1/organizations/{orgId}/invoices (Org admin only)",
+					"invoices":          "GET /api/v1/organizations/{orgId}/invoices (Org admin only)",
+					"invoice":           "GET /api/v1/organizations/{orgId}/invoices/{invoiceId} (Org admin only)",
+				},
+				"deliveries": fiber.Map{
+					"today":             "GET /api/v1/deliveries/today (Auth required)",
+					"courier_list":      "GET /api/v1/deliveries/courier?date={YYYY-MM-DD} (Courier only)",
+					"out_for_delivery":  "PUT /api/v1/deliveries/courier/{id}/out-for-delivery (Courier only)",
+					"delivered":         "POST /api/v1/deliveries/courier/{id}/delivered (Courier only, multipart photo)",
+					"failed":            "PUT /api/v1/deliveries/courier/{id}/failed (Courier only)",
+					"admin_list":        "GET /api/v1/deliveries/admin (Admin only)",
+					"admin_generate":    "POST /api/v1/deliveries/admin/generate (Admin only)",
+					"admin_assign":      "PUT /api/v1/deliveries/admin/assign (Admin only)",
+					"admin_couriers":    "GET /api/v1/deliveries/admin/couriers (Admin only)",
+					"admin_new_courier": "POST /api/v1/deliveries/admin/couriers (Admin only)",
+				},
+				"dispatch": fiber.Map{
+					"routes":        "GET /api/v1/dispatch/admin/routes?date={YYYY-MM-DD}&zone={zone}&courier_id={id} (Admin only)",
+					"route_sheet":   "GET /api/v1/dispatch/admin/routes/sheet?date={YYYY-MM-DD}&courier_id={id} (Admin only, CSV)",
+					"courier_route": "GET /api/v1/dispatch/courier/route?date={YYYY-MM-DD} (Courier only)",
+					"courier_sheet": "GET /api/v1/dispatch/courier/route/sheet?date={YYYY-MM-DD} (Courier only, CSV)",
+				},
+				"kitchen": fiber.Map{
+					"production":       "GET /api/v1/kitchen/admin/production?date={YYYY-MM-DD} (Admin only)",
+					"production_csv":   "GET /api/v1/kitchen/admin/production/csv?date={YYYY-MM-DD} (Admin only)",
+					"production_print": "GET /api/v1/kitchen/admin/production/print?date={YYYY-MM-DD} (Admin only, printable HTML)",
+				},
+				"outbox": fiber.Map{
+					"emails":    "GET /api/v1/outbox/admin/emails?status={pending|sending|sent|dead} (Admin only)",
+					"stats":     "GET /api/v1/outbox/admin/emails/stats (Admin only)",
+					"email":     "GET /api/v1/outbox/admin/emails/{id} (Admin only)",
+					"resend":    "POST /api/v1/outbox/admin/emails/{id}/resend (Admin only)",
+					"templates": "GET /api/v1/outbox/admin/templates (Admin only)",
+					"preview":   "GET /api/v1/outbox/admin/templates/{name}/preview?locale={id|en}&format={json|html|text} (Admin only)",
+				},
+				"notifications": fiber.Map{
+					"inbox":              "GET /api/v1/notifications (Auth required)",
+					"unread_count":       "GET /api/v1/notifications/unread-count (Auth required)",
+					"stream":             "GET /api/v1/notifications/stream (Auth required, Server-Sent Events)",
+					"mark_read":          "PATCH /api/v1/notifications/{id}/read (Auth required)",
+					"mark_all_read":      "POST /api/v1/notifications/read-all (Auth required)",
+					"preferences":        "GET /api/v1/notifications/preferences",
+					"update_preferences": "PUT /api/v1/notifications/preferences",
+					"unsubscribe":        "GET|POST /api/v1/notifications/unsubscribe?token={token}",
+				},
+				"webhooks": fiber.Map{
+					"event_types": "GET /api/v1/webhooks/admin/event-types (Admin only)",
+					"endpoints":   "GET|POST /api/v1/webhooks/admin/endpoints (Admin only)",
+					"endpoint":    "GET|PUT|DELETE /api/v1/webhooks/admin/endpoints/{id} (Admin only)",
+					"test":        "POST /api/v1/webhooks/admin/endpoints/{id}/test (Admin only)",
+					"deliveries":  "GET /api/v1/webhooks/admin/endpoints/{id}/deliveries?status={pending|sending|delivered|dead} (Admin only)",
+					"delivery":    "GET /api/v1/webhooks/admin/deliveries/{id} (Admin only)",
+					"replay":      "POST /api/v1/webhooks/admin/deliveries/{id}/replay (Admin only)",
+				},
+				"metrics": fiber.Map{
+					"daily":    "GET /api/v1/metrics/admin/daily?start_date={YYYY-MM-DD}&end_date={YYYY-MM-DD} (Admin only)",
+					"cohorts":  "GET /api/v1/metrics/admin/cohorts?start_month={YYYY-MM}&end_month={YYYY-MM}&months={1-24}&meal_plan_id={id} (Admin only)",
+					"snapshot": "POST /api/v1/metrics/admin/snapshot (Admin only)",
+					"backfill": "POST /api/v1/metrics/admin/backfill (Admin only)",
+				},
+				"exports": fiber.Map{
+					"users":         "GET /api/v1/exports/admin/users?format={csv|xlsx}&async={true|false}&{user list filters} (Admin only)",
+					"subscriptions": "GET /api/v1/exports/admin/subscriptions?format={csv|xlsx}&async={true|false}&{subscription search filters} (Admin only)",
+					"testimonials":  "GET /api/v1/exports/admin/testimonials?format={csv|xlsx}&async={true|false}&{moderation queue filters} (Admin only)",
+					"revenue":       "GET /api/v1/exports/admin/revenue?start_date={YYYY-MM-DD}&end_date={YYYY-MM-DD}&format={csv|xlsx} (Admin only)",
+					"jobs":          "GET /api/v1/exports/admin/jobs (Admin only)",
+					"job":           "GET /api/v1/exports/admin/jobs/{id} (Admin only)",
+				},
+				"imports": fiber.Map{
+					"meal_plans":  "POST /api/v1/imports/admin/meal-plans?commit={true|false} (multipart file, Admin only)",
+					"subscribers": "POST /api/v1/imports/admin/subscribers?commit={true|false} (multipart file, Admin only)",
+				},
 				"admin": fiber.Map{
-					"login":               "POST /api/v1/admin/login",
-					"dashboard":           "GET /api/v1/admin/dashboard (Admin only)",
-					"dashboard_filter":    "POST /api/v1/admin/dashboard/filter (Admin only)",
-					"approve_testimonial": "PUT /api/v1/admin/testimonials/{id}/approve (Admin only)",
-					"reject_testimonial":  "PUT /api/v1/admin/testimonials/{id}/reject (Admin only)",
+					"login":            "POST /api/v1/admin/login",
+					"dashboard":        "GET /api/v1/admin/dashboard (Admin only)",
+					"dashboard_filter": "POST /api/v1/admin/dashboard/filter (Admin only)",
 				},
 			},
 			"business_info": fiber.Map{
@@ -248,13 +575,31 @@ func main() {
 		port = "8080"
 	}
 
-	appLogger.Info("Server starting...", logger.Fields{
-		"port": port,
-	})
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	if err := fiberApp.Listen(":" + port); err != nil {
-		appLogger.Fatal("Server failed to start", logger.Fields{
+	go func() {
+		appLogger.Info("Server starting...", logger.Fields{
+			"port": port,
+		})
+
+		if err := fiberApp.Listen(":" + port); err != nil {
+			appLogger.Fatal("Server failed to start", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	<-quit
+	appLogger.Info("Shutting down server...")
+
+	if err := fiberApp.ShutdownWithTimeout(30 * time.Second); err != nil {
+		appLogger.Error("Error during server shutdown", logger.Fields{
 			"error": err.Error(),
 		})
 	}
+
+	// Returning runs the deferred Stop calls, newest first, so each worker
+	// pool drains before the outbox and connections it relies on close.
+	appLogger.Info("Stopping background workers...")
 }
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_gift_subscriptions_updated_at ON gift_subscriptions;
+DROP INDEX IF EXISTS idx_subscriptions_gift_id;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS fk_subscriptions_gift;
+DROP TABLE IF EXISTS gift_subscriptions;
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS ends_at,
+    DROP COLUMN IF EXISTS gift_id,
+    DROP COLUMN IF EXISTS delivery_address;
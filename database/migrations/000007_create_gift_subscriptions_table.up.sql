@@ -0,0 +1,56 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS delivery_address TEXT,
+    ADD COLUMN IF NOT EXISTS gift_id VARCHAR(36),
+    ADD COLUMN IF NOT EXISTS ends_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS gift_subscriptions (
+                                                  id VARCHAR(36) PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    buyer_user_id VARCHAR(36) NOT NULL,
+    recipient_name VARCHAR(255) NOT NULL,
+    recipient_email VARCHAR(255) NOT NULL,
+    message TEXT,
+    meal_plan_id VARCHAR(36) NOT NULL,
+    meal_types meal_type[] NOT NULL,
+    delivery_days delivery_day[] NOT NULL,
+    duration_months INTEGER NOT NULL CHECK (duration_months > 0),
+    total_price DECIMAL(10, 2) NOT NULL CHECK (total_price > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',
+    expires_at TIMESTAMP NOT NULL,
+    redeemed_by_user_id VARCHAR(36),
+    subscription_id VARCHAR(36),
+    redeemed_at TIMESTAMP,
+    last_sent_at TIMESTAMP,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_gift_subscriptions_buyer FOREIGN KEY (buyer_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_gift_subscriptions_meal_plan FOREIGN KEY (meal_plan_id) REFERENCES meal_plans(id),
+    CONSTRAINT fk_gift_subscriptions_redeemed_by FOREIGN KEY (redeemed_by_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_gift_subscriptions_subscription FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE SET NULL,
+    CONSTRAINT chk_gift_subscriptions_status CHECK (
+                                                       status IN ('issued', 'redeemed', 'expired', 'cancelled')
+    )
+    );
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT fk_subscriptions_gift FOREIGN KEY (gift_id) REFERENCES gift_subscriptions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_gift_subscriptions_code ON gift_subscriptions(code);
+CREATE INDEX idx_gift_subscriptions_buyer ON gift_subscriptions(buyer_user_id);
+CREATE INDEX idx_gift_subscriptions_status ON gift_subscriptions(status);
+CREATE INDEX idx_gift_subscriptions_expires_at ON gift_subscriptions(expires_at);
+CREATE INDEX idx_subscriptions_gift_id ON subscriptions(gift_id) WHERE gift_id IS NOT NULL;
+
+CREATE TRIGGER update_gift_subscriptions_updated_at
+    BEFORE UPDATE ON gift_subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE gift_subscriptions IS 'Prepaid fixed-duration subscriptions bought for another person';
+COMMENT ON COLUMN gift_subscriptions.code IS 'Redeemable gift code emailed to the recipient';
+COMMENT ON COLUMN gift_subscriptions.duration_months IS 'Number of months the gifted subscription runs after redemption';
+COMMENT ON COLUMN gift_subscriptions.expires_at IS 'Deadline for redeeming the gift code';
+COMMENT ON COLUMN subscriptions.delivery_address IS 'Delivery address for this subscription';
+COMMENT ON COLUMN subscriptions.gift_id IS 'Gift the subscription was redeemed from, if any';
+COMMENT ON COLUMN subscriptions.ends_at IS 'End of a fixed-duration (gifted) subscription';
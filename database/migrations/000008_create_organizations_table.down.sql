@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_subscriptions_organization_id;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS fk_subscriptions_organization;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS organization_id;
+DROP TRIGGER IF EXISTS update_organization_invoices_updated_at ON organization_invoices;
+DROP TRIGGER IF EXISTS update_organization_members_updated_at ON organization_members;
+DROP TRIGGER IF EXISTS update_organizations_updated_at ON organizations;
+DROP TABLE IF EXISTS organization_invoices;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,83 @@
+CREATE TABLE IF NOT EXISTS organizations (
+                                             id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    billing_email VARCHAR(255) NOT NULL,
+    owner_user_id VARCHAR(36) NOT NULL,
+    default_monthly_allowance DECIMAL(10, 2) CHECK (default_monthly_allowance IS NULL OR default_monthly_allowance >= 0),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_organizations_owner FOREIGN KEY (owner_user_id) REFERENCES users(id)
+    );
+
+CREATE TABLE IF NOT EXISTS organization_members (
+                                                    id VARCHAR(36) PRIMARY KEY,
+    organization_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    status VARCHAR(20) NOT NULL DEFAULT 'invited',
+    monthly_allowance DECIMAL(10, 2) CHECK (monthly_allowance IS NULL OR monthly_allowance >= 0),
+    invite_token_hash VARCHAR(128),
+    invite_expires_at TIMESTAMP,
+    invited_by VARCHAR(36),
+    joined_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_organization_members_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_organization_members_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_organization_members_invited_by FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT uq_organization_members_email UNIQUE (organization_id, email),
+    CONSTRAINT chk_organization_members_role CHECK (role IN ('org_admin', 'member')),
+    CONSTRAINT chk_organization_members_status CHECK (status IN ('invited', 'active', 'removed'))
+    );
+
+CREATE TABLE IF NOT EXISTS organization_invoices (
+                                                     id VARCHAR(36) PRIMARY KEY,
+    organization_id VARCHAR(36) NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    subscription_count INTEGER NOT NULL DEFAULT 0,
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    line_items JSONB NOT NULL DEFAULT '[]',
+    issued_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_organization_invoices_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT uq_organization_invoices_period UNIQUE (organization_id, period_start),
+    CONSTRAINT chk_organization_invoices_status CHECK (status IN ('draft', 'issued', 'paid', 'void'))
+    );
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS organization_id VARCHAR(36),
+    ADD CONSTRAINT fk_subscriptions_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_organizations_owner ON organizations(owner_user_id);
+CREATE INDEX idx_organization_members_organization ON organization_members(organization_id);
+CREATE INDEX idx_organization_members_user ON organization_members(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_organization_members_invite_token ON organization_members(invite_token_hash) WHERE invite_token_hash IS NOT NULL;
+CREATE INDEX idx_organization_invoices_organization ON organization_invoices(organization_id);
+CREATE INDEX idx_subscriptions_organization_id ON subscriptions(organization_id) WHERE organization_id IS NOT NULL;
+
+CREATE TRIGGER update_organizations_updated_at
+    BEFORE UPDATE ON organizations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_organization_members_updated_at
+    BEFORE UPDATE ON organization_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_organization_invoices_updated_at
+    BEFORE UPDATE ON organization_invoices
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE organizations IS 'Corporate accounts that pay for their members subscriptions';
+COMMENT ON COLUMN organizations.default_monthly_allowance IS 'Monthly budget per member; NULL means unlimited';
+COMMENT ON TABLE organization_members IS 'Organization membership and pending invitations';
+COMMENT ON COLUMN organization_members.monthly_allowance IS 'Per-member override of the organization default allowance';
+COMMENT ON TABLE organization_invoices IS 'Consolidated monthly invoices for organization-billed subscriptions';
+COMMENT ON COLUMN subscriptions.organization_id IS 'Organization billed for this subscription, if any';
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS update_deliveries_updated_at ON deliveries;
+DROP TABLE IF EXISTS deliveries;
+UPDATE users SET role = 'user' WHERE role = 'courier';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
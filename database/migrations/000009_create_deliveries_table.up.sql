@@ -0,0 +1,46 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin', 'courier'));
+
+CREATE TABLE IF NOT EXISTS deliveries (
+                                          id VARCHAR(36) PRIMARY KEY,
+    subscription_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    delivery_date DATE NOT NULL,
+    meal_type meal_type NOT NULL,
+    delivery_address TEXT,
+    courier_id VARCHAR(36),
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    proof_photo_url VARCHAR(500),
+    failure_reason TEXT,
+    assigned_at TIMESTAMP,
+    out_for_delivery_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    failed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_deliveries_subscription FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_deliveries_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_deliveries_courier FOREIGN KEY (courier_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT uq_deliveries_subscription_slot UNIQUE (subscription_id, delivery_date, meal_type),
+    CONSTRAINT chk_deliveries_status CHECK (
+                                               status IN ('scheduled', 'out_for_delivery', 'delivered', 'failed')
+    ),
+    CONSTRAINT chk_deliveries_proof CHECK (status != 'delivered' OR proof_photo_url IS NOT NULL),
+    CONSTRAINT chk_deliveries_failure_reason CHECK (status != 'failed' OR failure_reason IS NOT NULL)
+    );
+
+CREATE INDEX idx_deliveries_date ON deliveries(delivery_date);
+CREATE INDEX idx_deliveries_courier_date ON deliveries(courier_id, delivery_date) WHERE courier_id IS NOT NULL;
+CREATE INDEX idx_deliveries_user_date ON deliveries(user_id, delivery_date);
+CREATE INDEX idx_deliveries_status ON deliveries(status);
+
+CREATE TRIGGER update_deliveries_updated_at
+    BEFORE UPDATE ON deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE deliveries IS 'Individual meal drop-offs generated from active subscriptions';
+COMMENT ON COLUMN deliveries.courier_id IS 'Courier the delivery is assigned to';
+COMMENT ON COLUMN deliveries.proof_photo_url IS 'Proof-of-delivery photo uploaded by the courier';
+COMMENT ON COLUMN deliveries.failure_reason IS 'Why the courier could not complete the delivery';
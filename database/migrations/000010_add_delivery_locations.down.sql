@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_deliveries_date_zone;
+ALTER TABLE deliveries
+    DROP COLUMN IF EXISTS zone,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS chk_subscriptions_delivery_coordinates,
+    DROP COLUMN IF EXISTS delivery_zone,
+    DROP COLUMN IF EXISTS delivery_longitude,
+    DROP COLUMN IF EXISTS delivery_latitude;
@@ -0,0 +1,20 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS delivery_latitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS delivery_longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS delivery_zone VARCHAR(50),
+    ADD CONSTRAINT chk_subscriptions_delivery_coordinates CHECK (
+        (delivery_latitude IS NULL AND delivery_longitude IS NULL) OR
+        (delivery_latitude BETWEEN -90 AND 90 AND delivery_longitude BETWEEN -180 AND 180)
+    );
+
+ALTER TABLE deliveries
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS zone VARCHAR(50);
+
+CREATE INDEX idx_deliveries_date_zone ON deliveries(delivery_date, zone);
+
+COMMENT ON COLUMN subscriptions.delivery_latitude IS 'Latitude of the delivery address, used for route planning';
+COMMENT ON COLUMN subscriptions.delivery_longitude IS 'Longitude of the delivery address, used for route planning';
+COMMENT ON COLUMN subscriptions.delivery_zone IS 'Dispatch zone the delivery address belongs to';
+COMMENT ON COLUMN deliveries.zone IS 'Dispatch zone copied from the subscription when the delivery was generated';
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+                                            id VARCHAR(36) PRIMARY KEY,
+    recipients TEXT[] NOT NULL,
+    subject VARCHAR(500) NOT NULL,
+    body TEXT NOT NULL,
+    is_html BOOLEAN NOT NULL DEFAULT true,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 6,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_email_outbox_status CHECK (
+                                                 status IN ('pending', 'sending', 'sent', 'dead')
+    ),
+    CONSTRAINT chk_email_outbox_recipients CHECK (cardinality(recipients) > 0)
+    );
+
+CREATE INDEX idx_email_outbox_due ON email_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_email_outbox_locked ON email_outbox(locked_until) WHERE status = 'sending';
+CREATE INDEX idx_email_outbox_status ON email_outbox(status, created_at DESC);
+
+CREATE TRIGGER update_email_outbox_updated_at
+    BEFORE UPDATE ON email_outbox
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE email_outbox IS 'Outbound emails waiting for, or finished with, SMTP delivery';
+COMMENT ON COLUMN email_outbox.status IS 'pending: waiting for a worker, sending: claimed by a worker, sent: delivered, dead: gave up after max_attempts';
+COMMENT ON COLUMN email_outbox.next_attempt_at IS 'Earliest time a worker may try the email again (exponential backoff)';
+COMMENT ON COLUMN email_outbox.locked_until IS 'Claim expiry so emails held by a crashed worker are retried';
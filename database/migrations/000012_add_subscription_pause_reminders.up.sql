@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS pause_reminder_sent_for DATE;
+
+COMMENT ON COLUMN subscriptions.pause_reminder_sent_for IS 'Pause end date the customer was last reminded about, so each pause is announced once';
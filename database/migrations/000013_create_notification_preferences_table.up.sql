@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+                                                        user_id VARCHAR(36) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, channel, category),
+    CONSTRAINT fk_notification_preferences_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_notification_preferences_channel CHECK (channel IN ('email', 'sms', 'in_app')),
+    CONSTRAINT chk_notification_preferences_category CHECK (
+                                                               category IN ('transactional', 'delivery_updates', 'marketing', 'menu_of_the_week')
+    ),
+    CONSTRAINT chk_notification_preferences_transactional CHECK (category != 'transactional' OR enabled)
+    );
+
+CREATE TRIGGER update_notification_preferences_updated_at
+    BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE notification_preferences IS 'Per-user consent for each notification channel and category; missing rows use the category default';
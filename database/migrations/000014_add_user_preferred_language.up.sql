@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(2) NOT NULL DEFAULT 'id';
+
+ALTER TABLE users
+    ADD CONSTRAINT chk_users_preferred_language CHECK (preferred_language IN ('id', 'en'));
+
+COMMENT ON COLUMN users.preferred_language IS 'Language for emails and other customer messages: id (Indonesian) or en (English)';
@@ -0,0 +1,4 @@
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS text_body TEXT;
+
+COMMENT ON COLUMN email_outbox.text_body IS 'Plain-text alternative sent alongside an HTML body';
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS notifications (
+                                             id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    reference_type VARCHAR(30),
+    reference_id VARCHAR(36),
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_notifications_category CHECK (
+                                                    category IN ('transactional', 'delivery_updates', 'marketing', 'menu_of_the_week')
+    )
+    );
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+COMMENT ON TABLE notifications IS 'In-app notification inbox, one row per message shown to a user';
+COMMENT ON COLUMN notifications.type IS 'Event that produced the notification, e.g. subscription.paused';
+COMMENT ON COLUMN notifications.reference_type IS 'Kind of record the notification links to (subscription, delivery)';
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+                                                 id VARCHAR(36) PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_webhook_endpoints_event_types CHECK (cardinality(event_types) > 0)
+    );
+
+CREATE TRIGGER update_webhook_endpoints_updated_at
+    BEFORE UPDATE ON webhook_endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  id VARCHAR(36) PRIMARY KEY,
+    endpoint_id VARCHAR(36) NOT NULL,
+    event_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 8,
+    response_status INTEGER,
+    response_body TEXT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_webhook_deliveries_endpoint FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    CONSTRAINT chk_webhook_deliveries_status CHECK (
+                                                       status IN ('pending', 'sending', 'delivered', 'dead')
+    )
+    );
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_locked ON webhook_deliveries(locked_until) WHERE status = 'sending';
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at DESC);
+
+CREATE TRIGGER update_webhook_deliveries_updated_at
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE webhook_endpoints IS 'Partner URLs that receive signed event notifications';
+COMMENT ON COLUMN webhook_endpoints.event_types IS 'Event types the endpoint receives; * subscribes to all';
+COMMENT ON TABLE webhook_deliveries IS 'Per-endpoint delivery log of webhook events, with retry state';
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'Shared by every endpoint the event went to, and kept on replay so receivers can de-duplicate';
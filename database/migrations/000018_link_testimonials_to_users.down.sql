@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_testimonials_meal_plan_id;
+DROP INDEX IF EXISTS idx_testimonials_user_plan;
+
+ALTER TABLE testimonials
+    DROP COLUMN IF EXISTS is_verified,
+    DROP COLUMN IF EXISTS subscription_id,
+    DROP COLUMN IF EXISTS meal_plan_id,
+    DROP COLUMN IF EXISTS user_id;
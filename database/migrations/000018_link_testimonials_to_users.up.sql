@@ -0,0 +1,15 @@
+ALTER TABLE testimonials
+    ADD COLUMN IF NOT EXISTS user_id VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS meal_plan_id VARCHAR(36) REFERENCES meal_plans(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS subscription_id VARCHAR(36) REFERENCES subscriptions(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS is_verified BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_testimonials_user_plan
+    ON testimonials(user_id, COALESCE(meal_plan_id, ''))
+    WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_testimonials_meal_plan_id ON testimonials(meal_plan_id);
+
+COMMENT ON COLUMN testimonials.user_id IS 'Author; NULL for reviews submitted before accounts were required';
+COMMENT ON COLUMN testimonials.meal_plan_id IS 'Meal plan being reviewed; NULL for a general review';
+COMMENT ON COLUMN testimonials.subscription_id IS 'Subscription that verifies the author as a customer';
+COMMENT ON COLUMN testimonials.is_verified IS 'Whether the author has or had a subscription when the review was last written';
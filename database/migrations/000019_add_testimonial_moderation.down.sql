@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS testimonial_moderation_log;
+
+ALTER TABLE testimonials ADD COLUMN IF NOT EXISTS is_approved BOOLEAN DEFAULT false;
+UPDATE testimonials SET is_approved = (status = 'approved');
+CREATE INDEX IF NOT EXISTS idx_testimonials_approved ON testimonials(is_approved);
+
+DROP INDEX IF EXISTS idx_testimonials_status_created;
+
+ALTER TABLE testimonials
+    DROP CONSTRAINT IF EXISTS chk_testimonials_status,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderation_note,
+    DROP COLUMN IF EXISTS rejection_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,38 @@
+ALTER TABLE testimonials
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS rejection_reason VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS moderation_note VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS moderated_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP;
+
+UPDATE testimonials SET status = 'approved', moderated_at = updated_at WHERE is_approved;
+
+ALTER TABLE testimonials
+    ADD CONSTRAINT chk_testimonials_status CHECK (
+        status IN ('pending', 'approved', 'rejected', 'hidden', 'flagged')
+    );
+
+DROP INDEX IF EXISTS idx_testimonials_approved;
+ALTER TABLE testimonials DROP COLUMN IF EXISTS is_approved;
+
+CREATE INDEX IF NOT EXISTS idx_testimonials_status_created ON testimonials(status, created_at);
+
+CREATE TABLE IF NOT EXISTS testimonial_moderation_log (
+    id VARCHAR(36) PRIMARY KEY,
+    testimonial_id VARCHAR(36) NOT NULL REFERENCES testimonials(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason VARCHAR(50),
+    note VARCHAR(500),
+    moderator_id VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_testimonial_moderation_log_testimonial ON testimonial_moderation_log(testimonial_id, created_at);
+
+COMMENT ON COLUMN testimonials.status IS 'Moderation state: pending, approved, rejected, hidden or flagged';
+COMMENT ON COLUMN testimonials.rejection_reason IS 'Reason code for the latest rejection or hiding';
+COMMENT ON COLUMN testimonials.moderation_note IS 'Moderator note for the latest decision';
+COMMENT ON COLUMN testimonials.moderated_by IS 'Admin who made the latest decision';
+COMMENT ON TABLE testimonial_moderation_log IS 'Every status change of a testimonial, by moderators and by author edits';
+COMMENT ON COLUMN testimonial_moderation_log.moderator_id IS 'Admin who made the change; NULL when the author edited the review';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_testimonials_content_hash;
+
+ALTER TABLE testimonials DROP CONSTRAINT IF EXISTS chk_testimonials_spam_score;
+
+ALTER TABLE testimonials
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS screening_findings,
+    DROP COLUMN IF EXISTS spam_score;
@@ -0,0 +1,13 @@
+ALTER TABLE testimonials
+    ADD COLUMN IF NOT EXISTS spam_score INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS screening_findings JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+ALTER TABLE testimonials
+    ADD CONSTRAINT chk_testimonials_spam_score CHECK (spam_score BETWEEN 0 AND 100);
+
+CREATE INDEX IF NOT EXISTS idx_testimonials_content_hash ON testimonials(content_hash);
+
+COMMENT ON COLUMN testimonials.spam_score IS 'Automatic screening score from 0 to 100; higher is more likely spam';
+COMMENT ON COLUMN testimonials.screening_findings IS 'Checks that contributed to spam_score, with details for moderators';
+COMMENT ON COLUMN testimonials.content_hash IS 'SHA-256 of the normalised message, used to detect duplicates';
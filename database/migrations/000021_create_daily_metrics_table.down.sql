@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_subscription_audit_action_created;
+DROP INDEX IF EXISTS idx_subscription_audit_subscription_created;
+
+DROP TABLE IF EXISTS daily_metrics;
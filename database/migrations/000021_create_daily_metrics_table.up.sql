@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS daily_metrics (
+    metric_date DATE PRIMARY KEY,
+    mrr DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    active_subscriptions INTEGER NOT NULL DEFAULT 0,
+    paused_subscriptions INTEGER NOT NULL DEFAULT 0,
+    cancelled_subscriptions INTEGER NOT NULL DEFAULT 0,
+    new_subscriptions INTEGER NOT NULL DEFAULT 0,
+    churned_subscriptions INTEGER NOT NULL DEFAULT 0,
+    reactivations INTEGER NOT NULL DEFAULT 0,
+    revenue_by_plan JSONB NOT NULL DEFAULT '[]',
+    source VARCHAR(20) NOT NULL DEFAULT 'snapshot',
+    computed_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_daily_metrics_source CHECK (source IN ('snapshot', 'backfill'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_audit_subscription_created ON subscription_audit(subscription_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_subscription_audit_action_created ON subscription_audit(action, created_at);
+
+COMMENT ON TABLE daily_metrics IS 'End-of-day subscription and revenue figures, one row per day';
+COMMENT ON COLUMN daily_metrics.mrr IS 'Monthly recurring revenue: sum of total_price over subscriptions active at the end of the day';
+COMMENT ON COLUMN daily_metrics.churned_subscriptions IS 'Subscriptions cancelled during the day';
+COMMENT ON COLUMN daily_metrics.revenue_by_plan IS 'Active subscriptions and MRR per meal plan at the end of the day';
+COMMENT ON COLUMN daily_metrics.source IS 'snapshot: recorded from live data; backfill: reconstructed from subscription_audit';
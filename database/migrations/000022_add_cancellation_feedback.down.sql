@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_subscription_audit_cancellation_reason;
+
+ALTER TABLE subscription_audit
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_pause_offer,
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_cancellation_reason;
+
+ALTER TABLE subscription_audit
+    DROP COLUMN IF EXISTS pause_offer,
+    DROP COLUMN IF EXISTS feedback,
+    DROP COLUMN IF EXISTS cancellation_reason;
@@ -0,0 +1,22 @@
+ALTER TABLE subscription_audit
+    ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS feedback VARCHAR(1000),
+    ADD COLUMN IF NOT EXISTS pause_offer VARCHAR(20);
+
+ALTER TABLE subscription_audit
+    ADD CONSTRAINT chk_subscription_audit_cancellation_reason CHECK (
+        cancellation_reason IS NULL OR cancellation_reason IN (
+            'price', 'taste', 'delivery', 'moving', 'health', 'variety', 'schedule', 'other'
+        )
+    ),
+    ADD CONSTRAINT chk_subscription_audit_pause_offer CHECK (
+        pause_offer IS NULL OR pause_offer IN ('accepted', 'declined')
+    );
+
+CREATE INDEX IF NOT EXISTS idx_subscription_audit_cancellation_reason
+    ON subscription_audit(cancellation_reason, created_at)
+    WHERE cancellation_reason IS NOT NULL;
+
+COMMENT ON COLUMN subscription_audit.cancellation_reason IS 'Reason code the customer gave when cancelling, also kept when they paused instead';
+COMMENT ON COLUMN subscription_audit.feedback IS 'Free-text exit survey answer';
+COMMENT ON COLUMN subscription_audit.pause_offer IS 'Answer to the pause-instead offer: accepted or declined; NULL when it was not shown';
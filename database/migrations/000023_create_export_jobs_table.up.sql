@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    dataset VARCHAR(30) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    row_count INT,
+    file_key VARCHAR(500),
+    last_error TEXT,
+    locked_until TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_export_jobs_dataset CHECK (dataset IN ('users', 'subscriptions', 'testimonials', 'revenue')),
+    CONSTRAINT chk_export_jobs_format CHECK (format IN ('csv', 'xlsx')),
+    CONSTRAINT chk_export_jobs_status CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_status_created ON export_jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_expires_at ON export_jobs(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON TABLE export_jobs IS 'Admin exports too large to stream, built in the background and stored in S3';
+COMMENT ON COLUMN export_jobs.filters IS 'The list filters the export was requested with';
+COMMENT ON COLUMN export_jobs.file_key IS 'S3 key of the finished file';
+COMMENT ON COLUMN export_jobs.expires_at IS 'When the file and the job are deleted';
@@ -0,0 +1,11 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS uq_subscriptions_external_id;
+
+ALTER TABLE meal_plans
+    DROP CONSTRAINT IF EXISTS uq_meal_plans_external_id;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS external_id;
+
+ALTER TABLE meal_plans
+    DROP COLUMN IF EXISTS external_id;
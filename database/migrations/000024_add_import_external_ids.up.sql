@@ -0,0 +1,14 @@
+ALTER TABLE meal_plans
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(100);
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(100);
+
+ALTER TABLE meal_plans
+    ADD CONSTRAINT uq_meal_plans_external_id UNIQUE (external_id);
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT uq_subscriptions_external_id UNIQUE (external_id);
+
+COMMENT ON COLUMN meal_plans.external_id IS 'ID from the spreadsheet the plan was imported from; re-importing the same ID updates the plan';
+COMMENT ON COLUMN subscriptions.external_id IS 'ID of the legacy subscriber the subscription was imported from; re-importing the same ID is skipped';
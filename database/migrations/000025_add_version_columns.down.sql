@@ -0,0 +1,5 @@
+ALTER TABLE meal_plans
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,8 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE meal_plans
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN subscriptions.version IS 'Incremented on every change; clients send the version they read in If-Match so concurrent edits are rejected instead of overwritten';
+COMMENT ON COLUMN meal_plans.version IS 'Incremented on every change; clients send the version they read in If-Match so concurrent edits are rejected instead of overwritten';
@@ -0,0 +1,31 @@
+-- Enum values cannot be dropped, so the type is rebuilt. Subscriptions in the
+-- new statuses are mapped to the nearest old one first.
+UPDATE subscriptions SET status = 'active' WHERE status IN ('pending_payment', 'past_due');
+UPDATE subscriptions SET status = 'cancelled' WHERE status = 'expired';
+
+UPDATE subscription_audit SET new_status = 'active' WHERE new_status IN ('pending_payment', 'past_due');
+UPDATE subscription_audit SET new_status = 'cancelled' WHERE new_status = 'expired';
+UPDATE subscription_audit SET old_status = 'active' WHERE old_status IN ('pending_payment', 'past_due');
+UPDATE subscription_audit SET old_status = 'cancelled' WHERE old_status = 'expired';
+UPDATE subscription_audit SET action = 'cancelled' WHERE action = 'expired';
+UPDATE subscription_audit SET action = 'updated' WHERE action IN ('payment_failed', 'payment_received');
+
+ALTER TABLE subscription_audit
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_status,
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_action;
+
+ALTER TABLE subscription_audit
+    ADD CONSTRAINT chk_subscription_audit_status CHECK (
+        new_status IN ('active', 'paused', 'cancelled')
+    ),
+    ADD CONSTRAINT chk_subscription_audit_action CHECK (
+        action IN ('created', 'paused', 'resumed', 'cancelled', 'reactivated', 'updated')
+    );
+
+ALTER TABLE subscriptions ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE subscription_status RENAME TO subscription_status_old;
+CREATE TYPE subscription_status AS ENUM ('active', 'paused', 'cancelled');
+ALTER TABLE subscriptions
+    ALTER COLUMN status TYPE subscription_status USING status::text::subscription_status;
+ALTER TABLE subscriptions ALTER COLUMN status SET DEFAULT 'active';
+DROP TYPE subscription_status_old;
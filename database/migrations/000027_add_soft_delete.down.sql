@@ -0,0 +1,37 @@
+-- Deleted rows cannot be told apart once the column is gone, so they are
+-- removed, except users, who go back to being deactivated with their email
+-- suffixed.
+DELETE FROM testimonials WHERE deleted_at IS NOT NULL;
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+DELETE FROM meal_plans m
+WHERE m.deleted_at IS NOT NULL
+AND NOT EXISTS (SELECT 1 FROM subscriptions s WHERE s.meal_plan_id = m.id)
+AND NOT EXISTS (SELECT 1 FROM gift_subscriptions g WHERE g.meal_plan_id = m.id);
+UPDATE meal_plans SET is_active = false WHERE deleted_at IS NOT NULL;
+
+UPDATE users
+SET is_active = false,
+    email = email || '_deleted_' || extract(epoch from deleted_at),
+    phone = NULL
+WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_meal_plans_deleted_at;
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+DROP INDEX IF EXISTS idx_testimonials_deleted_at;
+
+DROP INDEX IF EXISTS idx_testimonials_user_plan;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_testimonials_user_plan
+    ON testimonials(user_id, COALESCE(meal_plan_id, ''))
+    WHERE user_id IS NOT NULL;
+
+DROP INDEX IF EXISTS users_email_key;
+DROP INDEX IF EXISTS users_phone_key;
+ALTER TABLE users
+    ADD CONSTRAINT users_email_key UNIQUE (email),
+    ADD CONSTRAINT users_phone_key UNIQUE (phone);
+
+ALTER TABLE testimonials DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE meal_plans DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,36 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE meal_plans ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE testimonials ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- A deleted account no longer holds its email or phone number, so the
+-- person can sign up again.
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_email_key,
+    DROP CONSTRAINT IF EXISTS users_phone_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_key ON users(phone) WHERE deleted_at IS NULL;
+
+-- Users deleted before this migration had their email suffixed to free it
+-- for a new account; mark them deleted and give the address back.
+-- This runs after the indexes above so that an address since taken by a new
+-- account does not conflict.
+UPDATE users
+SET deleted_at = updated_at,
+    email = regexp_replace(email, '_deleted_[0-9.]+$', '')
+WHERE is_active = false AND email ~ '_deleted_[0-9.]+$';
+
+DROP INDEX IF EXISTS idx_testimonials_user_plan;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_testimonials_user_plan
+    ON testimonials(user_id, COALESCE(meal_plan_id, ''))
+    WHERE user_id IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_meal_plans_deleted_at ON meal_plans(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at ON subscriptions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_testimonials_deleted_at ON testimonials(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN users.deleted_at IS 'Set when an admin deletes the account; reads skip the row until it is restored or purged';
+COMMENT ON COLUMN meal_plans.deleted_at IS 'Set when an admin deletes the plan; reads skip the row until it is restored or purged';
+COMMENT ON COLUMN subscriptions.deleted_at IS 'Set when the subscription is deleted; reads skip the row until it is restored. Deleted subscriptions are kept for financial history';
+COMMENT ON COLUMN testimonials.deleted_at IS 'Set when the review is deleted; reads skip the row until it is restored or purged';
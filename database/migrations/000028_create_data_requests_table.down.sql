@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS anonymised_at;
+
+DROP TABLE IF EXISTS data_requests;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS data_requests (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason VARCHAR(500),
+    user_was_active BOOLEAN,
+    attempts INT NOT NULL DEFAULT 0,
+    file_key VARCHAR(500),
+    last_error TEXT,
+    scheduled_for TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    cancelled_by VARCHAR(36) REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT chk_data_requests_type CHECK (type IN ('export', 'erasure')),
+    CONSTRAINT chk_data_requests_status CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+-- A user has at most one export and one erasure in progress.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_requests_open
+    ON data_requests(user_id, type)
+    WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_data_requests_status_scheduled ON data_requests(status, scheduled_for);
+CREATE INDEX IF NOT EXISTS idx_data_requests_expires_at ON data_requests(expires_at) WHERE expires_at IS NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymised_at TIMESTAMP;
+
+COMMENT ON TABLE data_requests IS 'Personal data exports and account erasures requested by users';
+COMMENT ON COLUMN data_requests.user_id IS 'NULL once an erased account has been purged; the request is kept as a record';
+COMMENT ON COLUMN data_requests.user_was_active IS 'Whether the account was active before an erasure deactivated it; restored if the erasure is cancelled';
+COMMENT ON COLUMN data_requests.scheduled_for IS 'Earliest time the request is processed; erasures wait out a grace period';
+COMMENT ON COLUMN data_requests.file_key IS 'S3 key of the export archive, cleared once the file is deleted';
+COMMENT ON COLUMN data_requests.expires_at IS 'When the export archive is deleted';
+COMMENT ON COLUMN users.anonymised_at IS 'Set when the account was erased at the user''s request; such accounts cannot be restored';
@@ -0,0 +1,4 @@
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS unsubscribe_url TEXT;
+
+COMMENT ON COLUMN email_outbox.unsubscribe_url IS 'Signed one-click unsubscribe link sent in the List-Unsubscribe header';
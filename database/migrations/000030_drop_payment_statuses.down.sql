@@ -0,0 +1,17 @@
+ALTER TYPE subscription_status ADD VALUE IF NOT EXISTS 'pending_payment';
+ALTER TYPE subscription_status ADD VALUE IF NOT EXISTS 'past_due';
+
+ALTER TABLE subscription_audit
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_status,
+    DROP CONSTRAINT IF EXISTS chk_subscription_audit_action;
+
+ALTER TABLE subscription_audit
+    ADD CONSTRAINT chk_subscription_audit_status CHECK (
+        new_status IN ('pending_payment', 'active', 'paused', 'past_due', 'cancelled', 'expired')
+    ),
+    ADD CONSTRAINT chk_subscription_audit_action CHECK (
+        action IN (
+            'created', 'paused', 'resumed', 'cancelled', 'reactivated', 'updated',
+            'expired', 'payment_failed', 'payment_received'
+        )
+    );
@@ -0,0 +1,9 @@
+UPDATE gift_subscriptions SET status = 'cancelled' WHERE status = 'pending_payment';
+
+ALTER TABLE gift_subscriptions
+    DROP CONSTRAINT IF EXISTS chk_gift_subscriptions_status;
+
+ALTER TABLE gift_subscriptions
+    ADD CONSTRAINT chk_gift_subscriptions_status CHECK (
+        status IN ('issued', 'redeemed', 'expired', 'cancelled')
+    );
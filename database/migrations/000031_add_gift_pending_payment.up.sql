@@ -0,0 +1,9 @@
+ALTER TABLE gift_subscriptions
+    DROP CONSTRAINT IF EXISTS chk_gift_subscriptions_status;
+
+ALTER TABLE gift_subscriptions
+    ADD CONSTRAINT chk_gift_subscriptions_status CHECK (
+        status IN ('pending_payment', 'issued', 'redeemed', 'expired', 'cancelled')
+    );
+
+COMMENT ON COLUMN gift_subscriptions.status IS 'pending_payment until the payment is confirmed; only issued codes can be redeemed';
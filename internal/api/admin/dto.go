@@ -3,6 +3,8 @@ package admin
 import (
 	"github.com/google/uuid"
 	"time"
+
+	"sea-catering-backend/internal/entity"
 )
 
 type AdminLoginRequest struct {
@@ -26,16 +28,22 @@ type AdminResponse struct {
 }
 
 type DashboardStatsResponse struct {
-	TotalSubscriptions     int     `json:"total_subscriptions"`
-	ActiveSubscriptions    int     `json:"active_subscriptions"`
-	NewSubscriptions       int     `json:"new_subscriptions"`
-	MonthlyRevenue         float64 `json:"monthly_revenue"`
-	Reactivations          int     `json:"reactivations"`
-	SubscriptionGrowth     float64 `json:"subscription_growth_percentage"`
-	RevenueGrowth          float64 `json:"revenue_growth_percentage"`
-	PendingTestimonials    int     `json:"pending_testimonials"`
-	TotalUsers             int     `json:"total_users"`
-	CancelledSubscriptions int     `json:"cancelled_subscriptions"`
+	// AsOf is the day the subscription counts and revenue describe: the
+	// last recorded day in the period, or nil when there is none yet.
+	AsOf                   *string              `json:"as_of"`
+	TotalSubscriptions     int                  `json:"total_subscriptions"`
+	ActiveSubscriptions    int                  `json:"active_subscriptions"`
+	PausedSubscriptions    int                  `json:"paused_subscriptions"`
+	NewSubscriptions       int                  `json:"new_subscriptions"`
+	MonthlyRevenue         float64              `json:"monthly_revenue"`
+	Reactivations          int                  `json:"reactivations"`
+	SubscriptionGrowth     float64              `json:"subscription_growth_percentage"`
+	RevenueGrowth          float64              `json:"revenue_growth_percentage"`
+	PendingTestimonials    int                  `json:"pending_testimonials"`
+	TotalUsers             int                  `json:"total_users"`
+	CancelledSubscriptions int                  `json:"cancelled_subscriptions"`
+	ChurnedSubscriptions   int                  `json:"churned_subscriptions"`
+	RevenueByPlan          []entity.PlanRevenue `json:"revenue_by_plan"`
 }
 
 type DateRangeFilter struct {
@@ -48,7 +56,7 @@ type UserListRequest struct {
 	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
 	Search  string `query:"search" validate:"omitempty,max=100"`
 	Status  string `query:"status" validate:"omitempty,oneof=active inactive all"`
-	Role    string `query:"role" validate:"omitempty,oneof=user admin all"`
+	Role    string `query:"role" validate:"omitempty,oneof=user admin courier all"`
 	SortBy  string `query:"sort_by" validate:"omitempty,oneof=name email created_at last_login_at"`
 	SortDir string `query:"sort_dir" validate:"omitempty,oneof=asc desc"`
 }
@@ -97,7 +105,7 @@ type SubscriptionSearchRequest struct {
 	Page       int     `query:"page" validate:"omitempty,min=1"`
 	Limit      int     `query:"limit" validate:"omitempty,min=1,max=100"`
 	Search     string  `query:"search" validate:"omitempty,max=100"`
-	Status     string  `query:"status" validate:"omitempty,oneof=active paused cancelled all"`
+	Status     string  `query:"status" validate:"omitempty,oneof=active paused cancelled expired all"`
 	MealPlanID string  `query:"meal_plan_id" validate:"omitempty"`
 	UserID     string  `query:"user_id" validate:"omitempty"`
 	MinPrice   float64 `query:"min_price" validate:"omitempty,min=0"`
@@ -122,6 +130,7 @@ type SubscriptionSearchResponse struct {
 	Status       string     `json:"status"`
 	PauseStart   *time.Time `json:"pause_start_date,omitempty"`
 	PauseEnd     *time.Time `json:"pause_end_date,omitempty"`
+	Version      int        `json:"version"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -136,6 +145,8 @@ type ForceCancelSubscriptionRequest struct {
 	RefundAmount  *float64 `json:"refund_amount,omitempty" validate:"omitempty,min=0"`
 	NotifyUser    bool     `json:"notify_user"`
 	AdminComments string   `json:"admin_comments,omitempty" validate:"omitempty,max=1000"`
+	// Version may be sent here instead of in an If-Match header.
+	Version *int `json:"version,omitempty"`
 }
 
 type ForceCancelSubscriptionResponse struct {
@@ -146,3 +157,10 @@ type ForceCancelSubscriptionResponse struct {
 	RefundID       *string   `json:"refund_id,omitempty"`
 	Message        string    `json:"message"`
 }
+
+// PurgeResult counts the soft-deleted rows removed for good.
+type PurgeResult struct {
+	Users        int64 `json:"users"`
+	MealPlans    int64 `json:"meal_plans"`
+	Testimonials int64 `json:"testimonials"`
+}
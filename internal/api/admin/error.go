@@ -9,4 +9,5 @@ var (
 	ErrUnauthorizedAccess = errors.New("unauthorized access")
 	ErrInvalidRole        = errors.New("invalid admin role")
 	ErrInsufficientRights = errors.New("insufficient admin rights")
+	ErrUserIdentityTaken  = errors.New("email or phone is in use by another account")
 )
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -8,10 +9,14 @@ import (
 
 	"sea-catering-backend/internal/api/admin"
 	"sea-catering-backend/internal/api/admin/service"
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/api/subscriptions"
 	"sea-catering-backend/internal/middleware"
 	"sea-catering-backend/pkg/context"
 	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
 	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
 )
 
 type AdminHandler struct {
@@ -44,19 +49,19 @@ func (h *AdminHandler) RegisterRoutes(router fiber.Router) {
 	protected.Get("/dashboard", h.GetDashboardStats)
 	protected.Post("/dashboard/filter", h.GetDashboardStatsWithFilter)
 
-	protected.Put("/testimonials/:id/approve", h.ApproveTestimonial)
-	protected.Put("/testimonials/:id/reject", h.RejectTestimonial)
-
 	protected.Get("/users", h.GetAllUsers)
 	protected.Get("/users/:id", h.GetUserByID)
 	protected.Put("/users/:id/status", h.UpdateUserStatus)
 	protected.Delete("/users/:id", h.DeleteUser)
+	protected.Post("/users/:id/restore", h.RestoreUser)
 
 	subscriptionGroup := router.Group("/subscriptions/admin")
 	subscriptionProtected := subscriptionGroup.Use(h.middleware.AdminMiddleware())
 
 	subscriptionProtected.Get("/search", h.SearchSubscriptions)
 	subscriptionProtected.Put("/:id/force-cancel", h.ForceCancelSubscription)
+	subscriptionProtected.Delete("/:id", h.DeleteSubscription)
+	subscriptionProtected.Post("/:id/restore", h.RestoreSubscription)
 }
 
 func (h *AdminHandler) AdminLogin(c *fiber.Ctx) error {
@@ -116,56 +121,12 @@ func (h *AdminHandler) GetDashboardStatsWithFilter(c *fiber.Ctx) error {
 
 	stats, err := h.adminService.GetDashboardStatsWithFilter(ctx, filter.StartDate, filter.EndDate)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "get_dashboard_stats_with_filter")
+		return h.handleAdminError(c, errHandler, requestID, err, c.Path(), "get_dashboard_stats_with_filter")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, stats)
 }
 
-func (h *AdminHandler) ApproveTestimonial(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
-	defer cancel()
-
-	errHandler := handlerutil.New(h.logger)
-	requestID := h.getRequestID(c)
-
-	testimonialID := c.Params("id")
-	if testimonialID == "" {
-		return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
-	}
-
-	err := h.adminService.ApproveTestimonial(ctx, testimonialID)
-	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "approve_testimonial")
-	}
-
-	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
-		"message": "Testimonial approved successfully",
-	})
-}
-
-func (h *AdminHandler) RejectTestimonial(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
-	defer cancel()
-
-	errHandler := handlerutil.New(h.logger)
-	requestID := h.getRequestID(c)
-
-	testimonialID := c.Params("id")
-	if testimonialID == "" {
-		return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
-	}
-
-	err := h.adminService.RejectTestimonial(ctx, testimonialID)
-	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "reject_testimonial")
-	}
-
-	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
-		"message": "Testimonial rejected successfully",
-	})
-}
-
 func (h *AdminHandler) getRequestID(c *fiber.Ctx) string {
 	if requestID := c.Locals("request_id"); requestID != nil {
 		if id, ok := requestID.(string); ok {
@@ -177,6 +138,8 @@ func (h *AdminHandler) getRequestID(c *fiber.Ctx) string {
 
 func (h *AdminHandler) handleAdminError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
 	switch err {
+	case metrics.ErrInvalidDateRange:
+		return errHandler.HandleBadRequest(c, requestID, err.Error())
 	case admin.ErrAdminNotFound:
 		return errHandler.HandleNotFound(c, requestID, "Admin")
 	case admin.ErrInvalidCredentials:
@@ -189,6 +152,8 @@ func (h *AdminHandler) handleAdminError(c *fiber.Ctx, errHandler *handlerutil.Er
 		return errHandler.HandleBadRequest(c, requestID, "Invalid admin role")
 	case admin.ErrInsufficientRights:
 		return errHandler.HandleForbidden(c, requestID, "Insufficient admin rights")
+	case admin.ErrUserIdentityTaken:
+		return response.Conflict(c, "Another account now uses this user's email or phone")
 	default:
 		return errHandler.Handle(c, requestID, err, path, operation)
 	}
@@ -309,6 +274,31 @@ func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
 	})
 }
 
+func (h *AdminHandler) RestoreUser(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID := c.Params("id")
+	if userID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "User ID is required")
+	}
+
+	err := h.adminService.RestoreUser(ctx, userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return errHandler.HandleNotFound(c, requestID, "User")
+		}
+		return h.handleAdminError(c, errHandler, requestID, err, c.Path(), "restore_user")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "User restored successfully",
+	})
+}
+
 func (h *AdminHandler) SearchSubscriptions(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
 	defer cancel()
@@ -354,16 +344,85 @@ func (h *AdminHandler) ForceCancelSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleValidationError(c, requestID, err, c.Path())
 	}
 
-	result, err := h.adminService.ForceCancelSubscription(ctx, subscriptionID, req)
+	version, ok := handlerutil.ExpectedVersion(c, req.Version)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	adminID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	result, err := h.adminService.ForceCancelSubscription(ctx, subscriptionID, adminID, version, req)
 	if err != nil {
+		if err == subscriptions.ErrVersionConflict {
+			return response.Conflict(c, subscriptions.GetErrorMessage(err))
+		}
 		if err.Error() == "subscription not found" {
 			return errHandler.HandleNotFound(c, requestID, "Subscription")
 		}
-		if err.Error() == "subscription is already cancelled" {
-			return errHandler.HandleBadRequest(c, requestID, err.Error())
+		var transitionErr *subscriptions.TransitionError
+		if errors.As(err, &transitionErr) {
+			return errHandler.HandleBadRequest(c, requestID, transitionErr.Message())
 		}
 		return errHandler.Handle(c, requestID, err, c.Path(), "force_cancel_subscription")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
 }
+
+func (h *AdminHandler) DeleteSubscription(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	subscriptionID := c.Params("id")
+	if subscriptionID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Subscription ID is required")
+	}
+
+	err := h.adminService.DeleteSubscription(ctx, subscriptionID)
+	if err != nil {
+		return h.handleSubscriptionError(c, errHandler, requestID, err, "delete_subscription")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Subscription deleted successfully",
+	})
+}
+
+func (h *AdminHandler) RestoreSubscription(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	subscriptionID := c.Params("id")
+	if subscriptionID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Subscription ID is required")
+	}
+
+	err := h.adminService.RestoreSubscription(ctx, subscriptionID)
+	if err != nil {
+		return h.handleSubscriptionError(c, errHandler, requestID, err, "restore_subscription")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Subscription restored successfully",
+	})
+}
+
+func (h *AdminHandler) handleSubscriptionError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, operation string) error {
+	switch err {
+	case subscriptions.ErrSubscriptionNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Subscription")
+	case subscriptions.ErrSubscriptionNotClosed:
+		return response.Conflict(c, subscriptions.GetErrorMessage(err))
+	default:
+		return errHandler.Handle(c, requestID, err, c.Path(), operation)
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"sea-catering-backend/internal/api/admin"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
 )
 
 type AdminRepository interface {
@@ -26,20 +28,31 @@ type AdminRepository interface {
 	GetUserByID(ctx context.Context, userID string) (*admin.UserResponse, error)
 	UpdateUserStatus(ctx context.Context, userID string, isActive bool, reason string) error
 	DeleteUser(ctx context.Context, userID string) error
+	// RestoreUser fails with admin.ErrUserIdentityTaken when another account
+	// has since registered the same email or phone. Erased accounts cannot
+	// be restored.
+	RestoreUser(ctx context.Context, userID string) error
 	GetUserStats(ctx context.Context, userID string) (subscriptionCount int, totalSpent float64, error error)
 
 	SearchSubscriptions(ctx context.Context, req admin.SubscriptionSearchRequest) ([]admin.SubscriptionSearchResponse, *admin.PaginationMeta, error)
-	ForceCancelSubscription(ctx context.Context, subscriptionID, reason, adminComments string) error
-	GetSubscriptionForCancel(ctx context.Context, subscriptionID string) (*admin.SubscriptionSearchResponse, error)
+	// EachUser and EachSubscription apply the list filters without paging
+	// and pass rows to fn as they are read. They stop at fn's first error.
+	EachUser(ctx context.Context, req admin.UserListRequest, fn func(*admin.UserResponse) error) error
+	EachSubscription(ctx context.Context, req admin.SubscriptionSearchRequest, fn func(*admin.SubscriptionSearchResponse) error) error
+
+	// PurgeDeleted permanently removes rows soft-deleted before the cutoff.
+	// Subscriptions are never purged, and users and meal plans are kept
+	// while any subscription or gift still refers to them.
+	PurgeDeleted(ctx context.Context, before time.Time) (*admin.PurgeResult, error)
 }
 
 type adminRepository struct {
-	db *sqlx.DB
+	db *database.DB
 }
 
 func NewAdminRepository(db *sqlx.DB) AdminRepository {
 	return &adminRepository{
-		db: db,
+		db: database.New(db),
 	}
 }
 
@@ -222,37 +235,7 @@ func (r *adminRepository) GetAllUsers(ctx context.Context, req admin.UserListReq
 		req.SortDir = "desc"
 	}
 
-	whereConditions := []string{}
-	args := []interface{}{}
-	argIndex := 1
-
-	if req.Status == "active" {
-		whereConditions = append(whereConditions, fmt.Sprintf("u.is_active = $%d", argIndex))
-		args = append(args, true)
-		argIndex++
-	} else if req.Status == "inactive" {
-		whereConditions = append(whereConditions, fmt.Sprintf("u.is_active = $%d", argIndex))
-		args = append(args, false)
-		argIndex++
-	}
-
-	if req.Role != "" && req.Role != "all" {
-		whereConditions = append(whereConditions, fmt.Sprintf("u.role = $%d", argIndex))
-		args = append(args, req.Role)
-		argIndex++
-	}
-
-	if req.Search != "" {
-		searchCondition := fmt.Sprintf("(LOWER(u.name) LIKE LOWER($%d) OR LOWER(u.email) LIKE LOWER($%d) OR u.phone LIKE $%d)", argIndex, argIndex, argIndex)
-		whereConditions = append(whereConditions, searchCondition)
-		args = append(args, "%"+req.Search+"%")
-		argIndex++
-	}
-
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
-	}
+	whereClause, args, argIndex := userFilter(req)
 
 	countQuery := fmt.Sprintf(`
 		SELECT COUNT(*) 
@@ -269,38 +252,12 @@ func (r *adminRepository) GetAllUsers(ctx context.Context, req admin.UserListReq
 	offset := (req.Page - 1) * req.Limit
 	totalPages := (total + req.Limit - 1) / req.Limit
 
-	validSortFields := map[string]bool{
-		"name":          true,
-		"email":         true,
-		"created_at":    true,
-		"last_login_at": true,
-	}
-
-	if !validSortFields[req.SortBy] {
-		req.SortBy = "created_at"
-	}
-
 	query := fmt.Sprintf(`
-		SELECT 
-			u.id, u.email, u.name, u.phone, u.is_verified, 
-			u.email_verified_at, u.phone_verified_at, u.profile_image_url,
-			u.role, u.is_active, u.last_login_at, u.created_at, u.updated_at,
-			COALESCE(s.subscription_count, 0) as subscription_count,
-			COALESCE(s.total_spent, 0) as total_spent
-		FROM users u
-		LEFT JOIN (
-			SELECT 
-				user_id,
-				COUNT(*) as subscription_count,
-				SUM(total_price) as total_spent
-			FROM subscriptions 
-			WHERE status != 'cancelled'
-			GROUP BY user_id
-		) s ON u.id = s.user_id
 		%s
-		ORDER BY u.%s %s
+		%s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, req.SortBy, strings.ToUpper(req.SortDir), argIndex, argIndex+1)
+	`, userSelect, whereClause, userOrder(req), argIndex, argIndex+1)
 
 	args = append(args, req.Limit, offset)
 
@@ -312,17 +269,11 @@ func (r *adminRepository) GetAllUsers(ctx context.Context, req admin.UserListReq
 
 	var users []admin.UserResponse
 	for rows.Next() {
-		var user admin.UserResponse
-		err := rows.Scan(
-			&user.ID, &user.Email, &user.Name, &user.Phone, &user.IsVerified,
-			&user.EmailVerifiedAt, &user.PhoneVerifiedAt, &user.ProfileImageURL,
-			&user.Role, &user.IsActive, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
-			&user.SubscriptionCount, &user.TotalSpent,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, user)
+		users = append(users, *user)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -356,10 +307,10 @@ func (r *adminRepository) GetUserByID(ctx context.Context, userID string) (*admi
 				COUNT(*) as subscription_count,
 				SUM(total_price) as total_spent
 			FROM subscriptions 
-			WHERE status != 'cancelled'
+			WHERE status NOT IN ('cancelled', 'expired') AND deleted_at IS NULL
 			GROUP BY user_id
 		) s ON u.id = s.user_id
-		WHERE u.id = $1
+		WHERE u.id = $1 AND u.deleted_at IS NULL
 	`
 
 	var user admin.UserResponse
@@ -384,7 +335,7 @@ func (r *adminRepository) UpdateUserStatus(ctx context.Context, userID string, i
 	query := `
 		UPDATE users 
 		SET is_active = $2, updated_at = $3
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query, userID, isActive, time.Now())
@@ -404,29 +355,55 @@ func (r *adminRepository) UpdateUserStatus(ctx context.Context, userID string, i
 	return nil
 }
 
+// DeleteUser marks the user deleted, keeping their subscriptions and audit
+// history. It locks the user before counting live subscriptions. Inserting a
+// subscription holds a share lock on its user until it commits, so one still
+// being created is waited for and counted rather than missed.
 func (r *adminRepository) DeleteUser(ctx context.Context, userID string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var id string
+		err := r.db.GetContext(ctx, &id, "SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("user not found")
+			}
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
 
-	var activeSubscriptions int
-	err := r.db.QueryRowContext(ctx,
-		"SELECT COUNT(*) FROM subscriptions WHERE user_id = $1 AND status IN ('active', 'paused')",
-		userID).Scan(&activeSubscriptions)
-	if err != nil {
-		return fmt.Errorf("failed to check user subscriptions: %w", err)
-	}
+		var activeSubscriptions int
+		err = r.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM subscriptions WHERE user_id = $1 AND status NOT IN ('cancelled', 'expired') AND deleted_at IS NULL",
+			userID).Scan(&activeSubscriptions)
+		if err != nil {
+			return fmt.Errorf("failed to check user subscriptions: %w", err)
+		}
 
-	if activeSubscriptions > 0 {
-		return fmt.Errorf("cannot delete user with active subscriptions")
-	}
+		if activeSubscriptions > 0 {
+			return fmt.Errorf("cannot delete user with active subscriptions")
+		}
+
+		query := `UPDATE users SET deleted_at = $2, updated_at = $2 WHERE id = $1`
+
+		if _, err := r.db.ExecContext(ctx, query, userID, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+}
 
+func (r *adminRepository) RestoreUser(ctx context.Context, userID string) error {
 	query := `
-		UPDATE users 
-		SET is_active = false, updated_at = $2, email = email || '_deleted_' || extract(epoch from now())
-		WHERE id = $1
+		UPDATE users
+		SET deleted_at = NULL, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NOT NULL AND anonymised_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query, userID, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return admin.ErrUserIdentityTaken
+		}
+		return fmt.Errorf("failed to restore user: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -447,7 +424,7 @@ func (r *adminRepository) GetUserStats(ctx context.Context, userID string) (subs
 			COUNT(*) as subscription_count,
 			COALESCE(SUM(total_price), 0) as total_spent
 		FROM subscriptions 
-		WHERE user_id = $1 AND status != 'cancelled'
+		WHERE user_id = $1 AND status NOT IN ('cancelled', 'expired') AND deleted_at IS NULL
 	`
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(&subscriptionCount, &totalSpent)
@@ -476,7 +453,153 @@ func (r *adminRepository) SearchSubscriptions(ctx context.Context, req admin.Sub
 		req.SortDir = "desc"
 	}
 
-	whereConditions := []string{}
+	whereClause, args, argIndex := subscriptionFilter(req)
+
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*) 
+		FROM subscriptions s
+		JOIN users u ON s.user_id = u.id
+		JOIN meal_plans mp ON s.meal_plan_id = mp.id
+		%s
+	`, whereClause)
+
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		%s
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, subscriptionSelect, whereClause, subscriptionOrder(req), argIndex, argIndex+1)
+
+	args = append(args, req.Limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []admin.SubscriptionSearchResponse
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, *sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	meta := &admin.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return subscriptions, meta, nil
+}
+
+const userSelect = `
+	SELECT 
+		u.id, u.email, u.name, u.phone, u.is_verified, 
+		u.email_verified_at, u.phone_verified_at, u.profile_image_url,
+		u.role, u.is_active, u.last_login_at, u.created_at, u.updated_at,
+		COALESCE(s.subscription_count, 0) as subscription_count,
+		COALESCE(s.total_spent, 0) as total_spent
+	FROM users u
+	LEFT JOIN (
+		SELECT 
+			user_id,
+			COUNT(*) as subscription_count,
+			SUM(total_price) as total_spent
+		FROM subscriptions 
+		WHERE status NOT IN ('cancelled', 'expired') AND deleted_at IS NULL
+		GROUP BY user_id
+	) s ON u.id = s.user_id
+`
+
+const subscriptionSelect = `
+	SELECT 
+		s.id, s.user_id, u.name, u.email, u.phone,
+		s.meal_plan_id, mp.name, s.meal_types, s.delivery_days,
+		s.total_price, s.status, s.pause_start_date, s.pause_end_date,
+		s.version, s.created_at, s.updated_at
+	FROM subscriptions s
+	JOIN users u ON s.user_id = u.id
+	JOIN meal_plans mp ON s.meal_plan_id = mp.id
+`
+
+// userFilter builds the WHERE clause shared by the user list and export. It
+// returns the next free placeholder index.
+func userFilter(req admin.UserListRequest) (string, []interface{}, int) {
+	whereConditions := []string{"u.deleted_at IS NULL"}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Status == "active" {
+		whereConditions = append(whereConditions, fmt.Sprintf("u.is_active = $%d", argIndex))
+		args = append(args, true)
+		argIndex++
+	} else if req.Status == "inactive" {
+		whereConditions = append(whereConditions, fmt.Sprintf("u.is_active = $%d", argIndex))
+		args = append(args, false)
+		argIndex++
+	}
+
+	if req.Role != "" && req.Role != "all" {
+		whereConditions = append(whereConditions, fmt.Sprintf("u.role = $%d", argIndex))
+		args = append(args, req.Role)
+		argIndex++
+	}
+
+	if req.Search != "" {
+		searchCondition := fmt.Sprintf("(LOWER(u.name) LIKE LOWER($%d) OR LOWER(u.email) LIKE LOWER($%d) OR u.phone LIKE $%d)", argIndex, argIndex, argIndex)
+		whereConditions = append(whereConditions, searchCondition)
+		args = append(args, "%"+req.Search+"%")
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(whereConditions, " AND ")
+
+	return whereClause, args, argIndex
+}
+
+func userOrder(req admin.UserListRequest) string {
+	validSortFields := map[string]bool{
+		"name":          true,
+		"email":         true,
+		"created_at":    true,
+		"last_login_at": true,
+	}
+
+	sortBy := req.SortBy
+	if !validSortFields[sortBy] {
+		sortBy = "created_at"
+	}
+
+	sortDir := "DESC"
+	if req.SortDir == "asc" {
+		sortDir = "ASC"
+	}
+
+	return fmt.Sprintf("u.%s %s, u.id", sortBy, sortDir)
+}
+
+func subscriptionFilter(req admin.SubscriptionSearchRequest) (string, []interface{}, int) {
+	whereConditions := []string{"s.deleted_at IS NULL"}
 	args := []interface{}{}
 	argIndex := 1
 
@@ -534,155 +657,170 @@ func (r *adminRepository) SearchSubscriptions(ctx context.Context, req admin.Sub
 		argIndex++
 	}
 
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
-	}
-
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*) 
-		FROM subscriptions s
-		JOIN users u ON s.user_id = u.id
-		JOIN meal_plans mp ON s.meal_plan_id = mp.id
-		%s
-	`, whereClause)
-
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to count subscriptions: %w", err)
-	}
+	whereClause := "WHERE " + strings.Join(whereConditions, " AND ")
 
-	offset := (req.Page - 1) * req.Limit
-	totalPages := (total + req.Limit - 1) / req.Limit
+	return whereClause, args, argIndex
+}
 
+func subscriptionOrder(req admin.SubscriptionSearchRequest) string {
 	validSortFields := map[string]bool{
 		"created_at":  true,
 		"updated_at":  true,
 		"total_price": true,
 	}
 
-	if !validSortFields[req.SortBy] {
-		req.SortBy = "created_at"
+	sortBy := req.SortBy
+	if !validSortFields[sortBy] {
+		sortBy = "created_at"
 	}
 
-	query := fmt.Sprintf(`
-		SELECT 
-			s.id, s.user_id, u.name, u.email, u.phone,
-			s.meal_plan_id, mp.name, s.meal_types, s.delivery_days,
-			s.total_price, s.status, s.pause_start_date, s.pause_end_date,
-			s.created_at, s.updated_at
-		FROM subscriptions s
-		JOIN users u ON s.user_id = u.id
-		JOIN meal_plans mp ON s.meal_plan_id = mp.id
-		%s
-		ORDER BY s.%s %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, req.SortBy, strings.ToUpper(req.SortDir), argIndex, argIndex+1)
+	sortDir := "DESC"
+	if req.SortDir == "asc" {
+		sortDir = "ASC"
+	}
 
-	args = append(args, req.Limit, offset)
+	return fmt.Sprintf("s.%s %s, s.id", sortBy, sortDir)
+}
+
+func (r *adminRepository) EachUser(ctx context.Context, req admin.UserListRequest, fn func(*admin.UserResponse) error) error {
+	whereClause, args, _ := userFilter(req)
+	query := fmt.Sprintf(`%s %s ORDER BY %s`, userSelect, whereClause, userOrder(req))
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to query subscriptions: %w", err)
+		return fmt.Errorf("failed to query users: %w", err)
 	}
 	defer rows.Close()
 
-	var subscriptions []admin.SubscriptionSearchResponse
 	for rows.Next() {
-		var sub admin.SubscriptionSearchResponse
-		var mealTypes, deliveryDays []string
-
-		err := rows.Scan(
-			&sub.ID, &sub.UserID, &sub.UserName, &sub.UserEmail, &sub.UserPhone,
-			&sub.MealPlanID, &sub.MealPlanName, &mealTypes, &deliveryDays,
-			&sub.TotalPrice, &sub.Status, &sub.PauseStart, &sub.PauseEnd,
-			&sub.CreatedAt, &sub.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to scan subscription: %w", err)
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := fn(user); err != nil {
+			return err
 		}
-
-		sub.MealTypes = mealTypes
-		sub.DeliveryDays = deliveryDays
-		subscriptions = append(subscriptions, sub)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, nil, fmt.Errorf("row iteration error: %w", err)
-	}
-
-	meta := &admin.PaginationMeta{
-		Page:       req.Page,
-		Limit:      req.Limit,
-		Total:      total,
-		TotalPages: totalPages,
-		HasNext:    req.Page < totalPages,
-		HasPrev:    req.Page > 1,
+		return fmt.Errorf("row iteration error: %w", err)
 	}
 
-	return subscriptions, meta, nil
+	return nil
 }
 
-func (r *adminRepository) ForceCancelSubscription(ctx context.Context, subscriptionID, reason, adminComments string) error {
-	query := `
-		UPDATE subscriptions 
-		SET status = 'cancelled', 
-		    pause_start_date = NULL, 
-		    pause_end_date = NULL,
-		    updated_at = $2
-		WHERE id = $1 AND status != 'cancelled'
-	`
+func (r *adminRepository) EachSubscription(ctx context.Context, req admin.SubscriptionSearchRequest, fn func(*admin.SubscriptionSearchResponse) error) error {
+	whereClause, args, _ := subscriptionFilter(req)
+	query := fmt.Sprintf(`%s %s ORDER BY %s`, subscriptionSelect, whereClause, subscriptionOrder(req))
 
-	result, err := r.db.ExecContext(ctx, query, subscriptionID, time.Now())
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to cancel subscription: %w", err)
+		return fmt.Errorf("failed to query subscriptions: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("subscription not found or already cancelled")
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
 	}
 
 	return nil
 }
 
-func (r *adminRepository) GetSubscriptionForCancel(ctx context.Context, subscriptionID string) (*admin.SubscriptionSearchResponse, error) {
-	query := `
-		SELECT 
-			s.id, s.user_id, u.name, u.email, u.phone,
-			s.meal_plan_id, mp.name, s.meal_types, s.delivery_days,
-			s.total_price, s.status, s.pause_start_date, s.pause_end_date,
-			s.created_at, s.updated_at
-		FROM subscriptions s
-		JOIN users u ON s.user_id = u.id
-		JOIN meal_plans mp ON s.meal_plan_id = mp.id
-		WHERE s.id = $1
-	`
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanUser(row rowScanner) (*admin.UserResponse, error) {
+	var user admin.UserResponse
+	err := row.Scan(
+		&user.ID, &user.Email, &user.Name, &user.Phone, &user.IsVerified,
+		&user.EmailVerifiedAt, &user.PhoneVerifiedAt, &user.ProfileImageURL,
+		&user.Role, &user.IsActive, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
+		&user.SubscriptionCount, &user.TotalSpent,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func scanSubscription(row rowScanner) (*admin.SubscriptionSearchResponse, error) {
 	var sub admin.SubscriptionSearchResponse
-	var mealTypes, deliveryDays []string
+	var mealTypes, deliveryDays pq.StringArray
 
-	err := r.db.QueryRowContext(ctx, query, subscriptionID).Scan(
+	err := row.Scan(
 		&sub.ID, &sub.UserID, &sub.UserName, &sub.UserEmail, &sub.UserPhone,
 		&sub.MealPlanID, &sub.MealPlanName, &mealTypes, &deliveryDays,
 		&sub.TotalPrice, &sub.Status, &sub.PauseStart, &sub.PauseEnd,
-		&sub.CreatedAt, &sub.UpdatedAt,
+		&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
-		}
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
+		return nil, err
 	}
 
 	sub.MealTypes = mealTypes
 	sub.DeliveryDays = deliveryDays
-
 	return &sub, nil
 }
+
+func (r *adminRepository) PurgeDeleted(ctx context.Context, before time.Time) (*admin.PurgeResult, error) {
+	var result admin.PurgeResult
+
+	// Testimonials go first so that plans and users are not held back by
+	// reviews that are themselves due for purging. A plan that still has
+	// reviews is kept, since deleting it would cascade to them.
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		purge := func(query string, count *int64) error {
+			res, err := r.db.ExecContext(ctx, query, before)
+			if err != nil {
+				return err
+			}
+			*count, err = res.RowsAffected()
+			return err
+		}
+
+		if err := purge(`DELETE FROM testimonials WHERE deleted_at < $1`, &result.Testimonials); err != nil {
+			return fmt.Errorf("failed to purge testimonials: %w", err)
+		}
+
+		mealPlanQuery := `
+			DELETE FROM meal_plans mp
+			WHERE mp.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM subscriptions s WHERE s.meal_plan_id = mp.id)
+				AND NOT EXISTS (SELECT 1 FROM gift_subscriptions g WHERE g.meal_plan_id = mp.id)
+				AND NOT EXISTS (SELECT 1 FROM testimonials t WHERE t.meal_plan_id = mp.id)
+		`
+		if err := purge(mealPlanQuery, &result.MealPlans); err != nil {
+			return fmt.Errorf("failed to purge meal plans: %w", err)
+		}
+
+		userQuery := `
+			DELETE FROM users u
+			WHERE u.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM subscriptions s WHERE s.user_id = u.id)
+				AND NOT EXISTS (SELECT 1 FROM subscription_audit a WHERE a.user_id = u.id)
+				AND NOT EXISTS (SELECT 1 FROM gift_subscriptions g WHERE g.buyer_user_id = u.id)
+				AND NOT EXISTS (SELECT 1 FROM organizations o WHERE o.owner_user_id = u.id)
+		`
+		if err := purge(userQuery, &result.Users); err != nil {
+			return fmt.Errorf("failed to purge users: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -3,64 +3,187 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"sea-catering-backend/internal/api/admin"
 	"sea-catering-backend/internal/api/admin/repository"
 	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/metrics"
+	metricsRepo "sea-catering-backend/internal/api/metrics/repository"
+	"sea-catering-backend/internal/api/subscriptions"
 	subscriptionRepo "sea-catering-backend/internal/api/subscriptions/repository"
 	testimonialRepo "sea-catering-backend/internal/api/testimonials/repository"
+	"sea-catering-backend/internal/entity"
 	"sea-catering-backend/pkg/bcrypt"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/events"
 	"sea-catering-backend/pkg/jwt"
 	"sea-catering-backend/pkg/logger"
 )
 
 type AdminService interface {
+	Start(ctx context.Context)
+	Stop()
+
 	AdminLogin(ctx context.Context, req admin.AdminLoginRequest) (*admin.AdminLoginResponse, error)
 	GetDashboardStats(ctx context.Context) (*admin.DashboardStatsResponse, error)
 	GetDashboardStatsWithFilter(ctx context.Context, startDate, endDate time.Time) (*admin.DashboardStatsResponse, error)
-	ApproveTestimonial(ctx context.Context, testimonialID string) error
-	RejectTestimonial(ctx context.Context, testimonialID string) error
 
 	GetAllUsers(ctx context.Context, req admin.UserListRequest) (*admin.UserListResponse, error)
 	GetUserByID(ctx context.Context, userID string) (*admin.UserResponse, error)
 	UpdateUserStatus(ctx context.Context, userID string, req admin.UpdateUserStatusRequest) error
 	DeleteUser(ctx context.Context, userID string) error
+	RestoreUser(ctx context.Context, userID string) error
 
 	SearchSubscriptions(ctx context.Context, req admin.SubscriptionSearchRequest) (*admin.SubscriptionSearchListResponse, error)
-	ForceCancelSubscription(ctx context.Context, subscriptionID string, req admin.ForceCancelSubscriptionRequest) (*admin.ForceCancelSubscriptionResponse, error)
+	// ForceCancelSubscription fails with subscriptions.ErrVersionConflict
+	// unless the subscription is still at version.
+	ForceCancelSubscription(ctx context.Context, subscriptionID, adminID string, version int, req admin.ForceCancelSubscriptionRequest) (*admin.ForceCancelSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	RestoreSubscription(ctx context.Context, subscriptionID string) error
+
+	// PurgeDeleted removes rows that have been soft-deleted for longer than
+	// the retention period.
+	PurgeDeleted(ctx context.Context) (*admin.PurgeResult, error)
+}
+
+type Config struct {
+	// DeletedRetention is how long soft-deleted rows can be restored before
+	// the purge job removes them.
+	DeletedRetention time.Duration
+	// PurgeInterval is how often the purge job runs.
+	PurgeInterval time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		DeletedRetention: 90 * 24 * time.Hour,
+		PurgeInterval:    time.Hour,
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv("SOFT_DELETE_RETENTION")); err == nil && retention > 0 {
+		config.DeletedRetention = retention
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("SOFT_DELETE_PURGE_INTERVAL")); err == nil && interval > 0 {
+		config.PurgeInterval = interval
+	}
+
+	return config
 }
 
 type adminService struct {
 	adminRepo        repository.AdminRepository
+	metricsRepo      metricsRepo.MetricsRepository
 	subscriptionRepo subscriptionRepo.SubscriptionRepository
 	testimonialRepo  testimonialRepo.TestimonialRepository
 	userRepo         authRepo.UserRepository
+	txManager        database.TxManager
 	jwtService       jwt.Interface
 	bcryptService    bcrypt.Interface
+	eventBus         events.Interface
+	config           *Config
 	logger           *logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 func NewAdminService(
 	adminRepo repository.AdminRepository,
+	metricsRepo metricsRepo.MetricsRepository,
 	subscriptionRepo subscriptionRepo.SubscriptionRepository,
 	testimonialRepo testimonialRepo.TestimonialRepository,
 	userRepo authRepo.UserRepository,
+	txManager database.TxManager,
 	jwtService jwt.Interface,
 	bcryptService bcrypt.Interface,
+	eventBus events.Interface,
+	config *Config,
 	logger *logger.Logger,
 ) AdminService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
 	return &adminService{
 		adminRepo:        adminRepo,
+		metricsRepo:      metricsRepo,
 		subscriptionRepo: subscriptionRepo,
 		testimonialRepo:  testimonialRepo,
 		userRepo:         userRepo,
+		txManager:        txManager,
 		jwtService:       jwtService,
 		bcryptService:    bcryptService,
+		eventBus:         eventBus,
+		config:           config,
 		logger:           logger,
 	}
 }
 
+// Start runs the purge job. Each run deletes in one transaction, so replicas
+// running it at the same time only repeat work.
+func (s *adminService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go s.runPurge(ctx)
+
+	s.logger.Info("Soft delete purge job started", logger.Fields{
+		"retention": s.config.DeletedRetention.String(),
+		"interval":  s.config.PurgeInterval.String(),
+	})
+}
+
+func (s *adminService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Soft delete purge job stopped")
+}
+
+func (s *adminService) runPurge(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeDeleted(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to purge deleted rows", logger.Fields{
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+}
+
+func (s *adminService) PurgeDeleted(ctx context.Context) (*admin.PurgeResult, error) {
+	result, err := s.adminRepo.PurgeDeleted(ctx, time.Now().Add(-s.config.DeletedRetention))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Users > 0 || result.MealPlans > 0 || result.Testimonials > 0 {
+		s.logger.Info("Purged deleted rows", logger.Fields{
+			"users":        result.Users,
+			"meal_plans":   result.MealPlans,
+			"testimonials": result.Testimonials,
+		})
+	}
+
+	return result, nil
+}
+
 func (s *adminService) AdminLogin(ctx context.Context, req admin.AdminLoginRequest) (*admin.AdminLoginResponse, error) {
 
 	adminUser, err := s.adminRepo.GetByEmail(ctx, req.Email)
@@ -117,70 +240,73 @@ func (s *adminService) GetDashboardStats(ctx context.Context) (*admin.DashboardS
 	return s.GetDashboardStatsWithFilter(ctx, startDate, endDate)
 }
 
+// GetDashboardStatsWithFilter reports from the daily_metrics snapshots.
+// Subscription counts and revenue are as of the last recorded day in the
+// period; new, churned and reactivated subscriptions are totals over it.
+// Growth compares the end of the period with the day before it started.
 func (s *adminService) GetDashboardStatsWithFilter(ctx context.Context, startDate, endDate time.Time) (*admin.DashboardStatsResponse, error) {
 	s.logger.Info("Getting dashboard stats with filter", logger.Fields{
 		"start_date": startDate.Format("2006-01-02"),
 		"end_date":   endDate.Format("2006-01-02"),
 	})
 
-	stats := &admin.DashboardStatsResponse{}
-
-	subscriptionStats, err := s.subscriptionRepo.GetSubscriptionStats(ctx, startDate, endDate)
-	if err != nil {
-		s.logger.Error("Failed to get subscription stats", logger.Fields{"error": err.Error()})
-		return nil, err
+	if startDate.After(endDate) {
+		return nil, metrics.ErrInvalidDateRange
 	}
 
-	stats.TotalSubscriptions = subscriptionStats.TotalSubscriptions
-	stats.ActiveSubscriptions = subscriptionStats.ActiveSubscriptions
-	stats.NewSubscriptions = subscriptionStats.NewSubscriptions
-	stats.MonthlyRevenue = subscriptionStats.MonthlyRevenue
-	stats.CancelledSubscriptions = subscriptionStats.CancelledSubscriptions
-	stats.Reactivations = subscriptionStats.Reactivations
-
-	if stats.ActiveSubscriptions > 0 {
-		growthBase := float64(stats.NewSubscriptions + stats.Reactivations)
-		stats.SubscriptionGrowth = (growthBase / float64(stats.ActiveSubscriptions)) * 100
-
-		stats.SubscriptionGrowth = float64(int(stats.SubscriptionGrowth*100)) / 100
-	} else {
-		stats.SubscriptionGrowth = 0.0
+	stats := &admin.DashboardStatsResponse{
+		RevenueByPlan: []entity.PlanRevenue{},
 	}
 
-	s.logger.Debug("Subscription growth calculation", logger.Fields{
-		"new_subscriptions":    stats.NewSubscriptions,
-		"reactivations":        stats.Reactivations,
-		"active_subscriptions": stats.ActiveSubscriptions,
-		"growth_percentage":    stats.SubscriptionGrowth,
-	})
+	current, err := s.metricsRepo.GetLatestOnOrBefore(ctx, endDate)
+	if err != nil && err != metrics.ErrMetricsNotFound {
+		s.logger.Error("Failed to get daily metrics", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
 
-	prevStartDate := startDate.AddDate(0, -1, 0)
-	prevEndDate := startDate.Add(-time.Nanosecond)
+	if current != nil {
+		asOf := current.Date.Format("2006-01-02")
+		stats.AsOf = &asOf
+		stats.ActiveSubscriptions = current.ActiveSubscriptions
+		stats.PausedSubscriptions = current.PausedSubscriptions
+		stats.CancelledSubscriptions = current.CancelledSubscriptions
+		stats.TotalSubscriptions = current.ActiveSubscriptions + current.PausedSubscriptions + current.CancelledSubscriptions
+		stats.MonthlyRevenue = current.MRR
+		stats.RevenueByPlan = current.RevenueByPlan
+	}
 
-	prevStats, err := s.subscriptionRepo.GetSubscriptionStats(ctx, prevStartDate, prevEndDate)
+	activity, err := s.metricsRepo.SumActivity(ctx, startDate, endDate)
 	if err != nil {
-		s.logger.Warn("Failed to get previous period stats for revenue growth", logger.Fields{
+		s.logger.Error("Failed to sum daily metrics", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+	stats.NewSubscriptions = activity.NewSubscriptions
+	stats.ChurnedSubscriptions = activity.ChurnedSubscriptions
+	stats.Reactivations = activity.Reactivations
+
+	prevEndDate := startDate.AddDate(0, 0, -1)
+	previous, err := s.metricsRepo.GetLatestOnOrBefore(ctx, prevEndDate)
+	if err != nil && err != metrics.ErrMetricsNotFound {
+		s.logger.Warn("Failed to get previous period metrics for growth", logger.Fields{
 			"error": err.Error(),
 		})
-		stats.RevenueGrowth = 0.0
-	} else {
-		if prevStats.MonthlyRevenue > 0 {
-			revenueChange := stats.MonthlyRevenue - prevStats.MonthlyRevenue
-			stats.RevenueGrowth = (revenueChange / prevStats.MonthlyRevenue) * 100
-
-			stats.RevenueGrowth = float64(int(stats.RevenueGrowth*100)) / 100
-		} else if stats.MonthlyRevenue > 0 {
-
-			stats.RevenueGrowth = 100.0
-		} else {
-			stats.RevenueGrowth = 0.0
-		}
 	}
 
-	s.logger.Debug("Revenue growth calculation", logger.Fields{
-		"current_revenue":  stats.MonthlyRevenue,
-		"previous_revenue": prevStats.MonthlyRevenue,
-		"revenue_growth":   stats.RevenueGrowth,
+	var prevActive int
+	var prevRevenue float64
+	if previous != nil {
+		prevActive = previous.ActiveSubscriptions
+		prevRevenue = previous.MRR
+	}
+	stats.SubscriptionGrowth = growth(float64(prevActive), float64(stats.ActiveSubscriptions))
+	stats.RevenueGrowth = growth(prevRevenue, stats.MonthlyRevenue)
+
+	s.logger.Debug("Growth calculation", logger.Fields{
+		"previous_period_end":  prevEndDate.Format("2006-01-02"),
+		"previous_active":      prevActive,
+		"active_subscriptions": stats.ActiveSubscriptions,
+		"previous_revenue":     prevRevenue,
+		"current_revenue":      stats.MonthlyRevenue,
 	})
 
 	pendingTestimonials, err := s.testimonialRepo.CountPending(ctx)
@@ -209,70 +335,24 @@ func (s *adminService) GetDashboardStatsWithFilter(ctx context.Context, startDat
 		"revenue_growth":          stats.RevenueGrowth,
 		"total_users":             stats.TotalUsers,
 		"cancelled_subscriptions": stats.CancelledSubscriptions,
+		"churned_subscriptions":   stats.ChurnedSubscriptions,
 		"pending_testimonials":    stats.PendingTestimonials,
 	})
 
 	return stats, nil
 }
 
-func (s *adminService) ApproveTestimonial(ctx context.Context, testimonialID string) error {
-
-	testimonial, err := s.testimonialRepo.GetByID(ctx, testimonialID)
-	if err != nil {
-		return err
-	}
-
-	if testimonial.IsApproved {
-		return nil
-	}
-
-	testimonial.IsApproved = true
-	testimonial.UpdatedAt = time.Now()
-
-	if err := s.testimonialRepo.Update(ctx, testimonial); err != nil {
-		s.logger.Error("Failed to approve testimonial", logger.Fields{
-			"error":          err.Error(),
-			"testimonial_id": testimonialID,
-		})
-		return err
-	}
-
-	s.logger.Info("Testimonial approved successfully", logger.Fields{
-		"testimonial_id": testimonialID,
-		"customer_name":  testimonial.CustomerName,
-	})
-
-	return nil
-}
-
-func (s *adminService) RejectTestimonial(ctx context.Context, testimonialID string) error {
-
-	testimonial, err := s.testimonialRepo.GetByID(ctx, testimonialID)
-	if err != nil {
-		return err
+// growth is the percentage change from previous to current, truncated to
+// two decimals. Growth from nothing counts as 100%.
+func growth(previous, current float64) float64 {
+	if previous > 0 {
+		change := (current - previous) / previous * 100
+		return float64(int(change*100)) / 100
 	}
-
-	if !testimonial.IsApproved {
-		return nil
-	}
-
-	testimonial.IsApproved = false
-	testimonial.UpdatedAt = time.Now()
-
-	if err := s.testimonialRepo.Update(ctx, testimonial); err != nil {
-		s.logger.Error("Failed to reject testimonial", logger.Fields{
-			"error":          err.Error(),
-			"testimonial_id": testimonialID,
-		})
-		return err
+	if current > 0 {
+		return 100.0
 	}
-
-	s.logger.Info("Testimonial rejected successfully", logger.Fields{
-		"testimonial_id": testimonialID,
-		"customer_name":  testimonial.CustomerName,
-	})
-
-	return nil
+	return 0.0
 }
 
 func (s *adminService) GetAllUsers(ctx context.Context, req admin.UserListRequest) (*admin.UserListResponse, error) {
@@ -360,16 +440,23 @@ func (s *adminService) DeleteUser(ctx context.Context, userID string) error {
 		return fmt.Errorf("user ID is required")
 	}
 
-	user, err := s.adminRepo.GetUserByID(ctx, userID)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
+	// The check and the delete share a transaction, and DeleteUser counts
+	// again under a lock, so a subscription created in between cannot be
+	// left on a deleted account.
+	var user *admin.UserResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.adminRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
 
-	if user.SubscriptionCount > 0 {
-		return fmt.Errorf("cannot delete user with %d active subscriptions", user.SubscriptionCount)
-	}
+		if user.SubscriptionCount > 0 {
+			return fmt.Errorf("cannot delete user with %d active subscriptions", user.SubscriptionCount)
+		}
 
-	err = s.adminRepo.DeleteUser(ctx, userID)
+		return s.adminRepo.DeleteUser(ctx, userID)
+	})
 	if err != nil {
 		s.logger.Error("Failed to delete user", logger.Fields{
 			"error":   err.Error(),
@@ -392,6 +479,26 @@ func (s *adminService) DeleteUser(ctx context.Context, userID string) error {
 	return nil
 }
 
+func (s *adminService) RestoreUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID is required")
+	}
+
+	if err := s.adminRepo.RestoreUser(ctx, userID); err != nil {
+		s.logger.Error("Failed to restore user", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return err
+	}
+
+	s.logger.Info("User restored", logger.Fields{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
 func (s *adminService) SearchSubscriptions(ctx context.Context, req admin.SubscriptionSearchRequest) (*admin.SubscriptionSearchListResponse, error) {
 
 	if req.DateFrom != "" && req.DateTo != "" {
@@ -437,21 +544,22 @@ func (s *adminService) SearchSubscriptions(ctx context.Context, req admin.Subscr
 	}, nil
 }
 
-func (s *adminService) ForceCancelSubscription(ctx context.Context, subscriptionID string, req admin.ForceCancelSubscriptionRequest) (*admin.ForceCancelSubscriptionResponse, error) {
+func (s *adminService) ForceCancelSubscription(ctx context.Context, subscriptionID, adminID string, version int, req admin.ForceCancelSubscriptionRequest) (*admin.ForceCancelSubscriptionResponse, error) {
 	if subscriptionID == "" {
 		return nil, fmt.Errorf("subscription ID is required")
 	}
 
-	subscription, err := s.adminRepo.GetSubscriptionForCancel(ctx, subscriptionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
-	}
-
-	if subscription.Status == "cancelled" {
-		return nil, fmt.Errorf("subscription is already cancelled")
+	audit := subscriptionRepo.AuditEntry{
+		AdminID: &adminID,
+		Reason:  req.Reason,
 	}
 
-	err = s.adminRepo.ForceCancelSubscription(ctx, subscriptionID, req.Reason, req.AdminComments)
+	subscription, err := s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionCancel, audit, func(subscription *entity.Subscription) error {
+		if subscription.Version != version {
+			return subscriptions.ErrVersionConflict
+		}
+		return nil
+	})
 	if err != nil {
 		s.logger.Error("Failed to force cancel subscription", logger.Fields{
 			"error":           err.Error(),
@@ -473,18 +581,17 @@ func (s *adminService) ForceCancelSubscription(ctx context.Context, subscription
 		})
 	}
 
-	if req.NotifyUser {
-		s.logger.Info("User notification sent for subscription cancellation", logger.Fields{
-			"subscription_id": subscriptionID,
-			"user_id":         subscription.UserID,
-			"user_name":       subscription.UserName,
-		})
-	}
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionForceCancelled, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         subscription.UserID,
+		Reason:         req.Reason,
+		NotifyUser:     req.NotifyUser,
+	})
 
 	s.logger.Info("Subscription force cancelled by admin", logger.Fields{
 		"subscription_id": subscriptionID,
 		"user_id":         subscription.UserID,
-		"user_name":       subscription.UserName,
+		"admin_id":        adminID,
 		"reason":          req.Reason,
 		"refund_amount":   req.RefundAmount,
 		"admin_comments":  req.AdminComments,
@@ -501,3 +608,27 @@ func (s *adminService) ForceCancelSubscription(ctx context.Context, subscription
 
 	return response, nil
 }
+
+func (s *adminService) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	if err := s.subscriptionRepo.Delete(ctx, subscriptionID); err != nil {
+		s.logger.Error("Failed to delete subscription", logger.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (s *adminService) RestoreSubscription(ctx context.Context, subscriptionID string) error {
+	if err := s.subscriptionRepo.Restore(ctx, subscriptionID); err != nil {
+		s.logger.Error("Failed to restore subscription", logger.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+		})
+		return err
+	}
+
+	return nil
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type RegisterRequest struct {
-	Name     string `json:"name" validate:"required,min=2,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,strong_password"`
-	Phone    string `json:"phone,omitempty" validate:"omitempty,phone_id"`
+	Name              string `json:"name" validate:"required,min=2,max=100"`
+	Email             string `json:"email" validate:"required,email"`
+	Password          string `json:"password" validate:"required,strong_password"`
+	Phone             string `json:"phone,omitempty" validate:"omitempty,phone_id"`
+	PreferredLanguage string `json:"preferred_language,omitempty" validate:"omitempty,oneof=id en"`
 }
 
 type LoginRequest struct {
@@ -26,18 +27,20 @@ type LoginResponse struct {
 }
 
 type UserInfo struct {
-	ID              uuid.UUID `json:"id"`
-	Name            string    `json:"name"`
-	Email           string    `json:"email"`
-	Phone           *string   `json:"phone"`
-	IsVerified      bool      `json:"is_verified"`
-	ProfileImageURL *string   `json:"profile_image_url"`
-	Role            string    `json:"role"`
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	Phone             *string   `json:"phone"`
+	IsVerified        bool      `json:"is_verified"`
+	ProfileImageURL   *string   `json:"profile_image_url"`
+	Role              string    `json:"role"`
+	PreferredLanguage string    `json:"preferred_language"`
 }
 
 type UpdateProfileRequest struct {
-	Name  string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Phone *string `json:"phone,omitempty" validate:"omitempty,phone_id"`
+	Name              string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Phone             *string `json:"phone,omitempty" validate:"omitempty,phone_id"`
+	PreferredLanguage string  `json:"preferred_language,omitempty" validate:"omitempty,oneof=id en"`
 }
 
 type ChangePasswordRequest struct {
@@ -66,6 +69,10 @@ type SendOTPRequest struct {
 	Type  string `json:"type" validate:"required,oneof=email_verification password_reset"`
 }
 
+type VerifyPhoneOTPRequest struct {
+	OTP string `json:"otp" validate:"required,len=6"`
+}
+
 type UploadProfileImageResponse struct {
 	ImageURL string `json:"image_url"`
 	Message  string `json:"message"`
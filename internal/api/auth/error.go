@@ -24,4 +24,7 @@ var (
 	ErrForbidden           = errors.New("forbidden access")
 	ErrInvalidImageFormat  = errors.New("invalid image format")
 	ErrImageTooLarge       = errors.New("image file too large")
+	ErrPhoneNotSet         = errors.New("no phone number on the account")
+	ErrPhoneVerified       = errors.New("phone number already verified")
+	ErrTooManyOTPRequests  = errors.New("too many OTP requests")
 )
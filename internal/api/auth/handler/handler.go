@@ -48,6 +48,8 @@ func (h *AuthHandler) RegisterRoutes(router fiber.Router) {
 	userGroup.Put("/profile", h.UpdateProfile)
 	userGroup.Post("/change-password", h.ChangePassword)
 	userGroup.Post("/profile/image", h.UploadProfileImage)
+	userGroup.Post("/phone/send-otp", h.SendPhoneOTP)
+	userGroup.Post("/phone/verify-otp", h.VerifyPhoneOTP)
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
@@ -272,6 +274,43 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 	return response.Success(c, nil, "OTP verified successfully")
 }
 
+func (h *AuthHandler) SendPhoneOTP(c *fiber.Ctx) error {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		return response.Unauthorized(c)
+	}
+
+	err = h.authService.SendPhoneOTP(c.Context(), userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, nil, "OTP sent successfully")
+}
+
+func (h *AuthHandler) VerifyPhoneOTP(c *fiber.Ctx) error {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		return response.Unauthorized(c)
+	}
+
+	var req auth.VerifyPhoneOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return h.handleValidationError(c, err)
+	}
+
+	err = h.authService.VerifyPhoneOTP(c.Context(), userID, req)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return response.Success(c, nil, "Phone number verified successfully")
+}
+
 func (h *AuthHandler) UploadProfileImage(c *fiber.Ctx) error {
 	userID, err := h.getUserIDFromContext(c)
 	if err != nil {
@@ -334,6 +373,12 @@ func (h *AuthHandler) handleError(c *fiber.Ctx, err error) error {
 		return response.BadRequest(c, "Invalid image format")
 	case auth.ErrImageTooLarge:
 		return response.BadRequest(c, "Image file too large")
+	case auth.ErrPhoneNotSet:
+		return response.BadRequest(c, "Add a phone number to your profile first")
+	case auth.ErrPhoneVerified:
+		return response.Conflict(c, "Phone number already verified")
+	case auth.ErrTooManyOTPRequests:
+		return response.TooManyRequests(c, "Too many OTP requests, please try again later")
 	default:
 		h.logger.Error("Unhandled auth error", logger.Fields{"error": err.Error()})
 		return response.InternalServerError(c, "An unexpected error occurred")
@@ -12,6 +12,7 @@ import (
 
 	"sea-catering-backend/internal/api/auth"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
 )
 
 type UserRepository interface {
@@ -32,22 +33,22 @@ type UserRepository interface {
 }
 
 type userRepository struct {
-	db *sqlx.DB
+	db *database.DB
 }
 
 func NewUserRepository(db *sqlx.DB) UserRepository {
-	return &userRepository{db: db}
+	return &userRepository{db: database.New(db)}
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	query := `
-		INSERT INTO users (id, name, email, phone, password, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, name, email, phone, password, role, preferred_language, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		user.ID, user.Name, user.Email, user.Phone, user.Password,
-		user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt,
+		user.Role, user.PreferredLanguage, user.IsActive, user.CreatedAt, user.UpdatedAt,
 	)
 
 	if err != nil {
@@ -71,10 +72,10 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	query := `
 		SELECT id, email, name, phone, password, is_verified, email_verified_at,
-		       phone_verified_at, profile_image_url, role, is_active, last_login_at,
-		       created_at, updated_at
+		       phone_verified_at, profile_image_url, role, preferred_language, is_active,
+		       last_login_at, created_at, updated_at
 		FROM users
-		WHERE id = $1 AND is_active = true
+		WHERE id = $1 AND is_active = true AND deleted_at IS NULL
 	`
 
 	var user entity.User
@@ -92,10 +93,10 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
 		SELECT id, email, name, phone, password, is_verified, email_verified_at,
-		       phone_verified_at, profile_image_url, role, is_active, last_login_at,
-		       created_at, updated_at
+		       phone_verified_at, profile_image_url, role, preferred_language, is_active,
+		       last_login_at, created_at, updated_at
 		FROM users
-		WHERE email = $1 AND is_active = true
+		WHERE email = $1 AND is_active = true AND deleted_at IS NULL
 	`
 
 	var user entity.User
@@ -113,10 +114,10 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
 	query := `
 		SELECT id, email, name, phone, password, is_verified, email_verified_at,
-		       phone_verified_at, profile_image_url, role, is_active, last_login_at,
-		       created_at, updated_at
+		       phone_verified_at, profile_image_url, role, preferred_language, is_active,
+		       last_login_at, created_at, updated_at
 		FROM users
-		WHERE phone = $1 AND is_active = true
+		WHERE phone = $1 AND is_active = true AND deleted_at IS NULL
 	`
 
 	var user entity.User
@@ -134,12 +135,12 @@ func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entity.
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 	query := `
 		UPDATE users
-		SET name = $2, phone = $3, updated_at = $4
-		WHERE id = $1
+		SET name = $2, phone = $3, phone_verified_at = $4, preferred_language = $5, updated_at = $6
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
-		user.ID, user.Name, user.Phone, time.Now(),
+		user.ID, user.Name, user.Phone, user.PhoneVerifiedAt, user.PreferredLanguage, time.Now(),
 	)
 
 	if err != nil {
@@ -161,7 +162,7 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, h
 	query := `
 		UPDATE users
 		SET password = $2, updated_at = $3
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query, userID, hashedPassword, time.Now())
@@ -172,7 +173,7 @@ func (r *userRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID)
 	query := `
 		UPDATE users
 		SET last_login_at = $2, updated_at = $2
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	now := time.Now()
@@ -184,7 +185,7 @@ func (r *userRepository) UpdateProfileImage(ctx context.Context, userID uuid.UUI
 	query := `
 		UPDATE users
 		SET profile_image_url = $2, updated_at = $3
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query, userID, imageURL, time.Now())
@@ -195,7 +196,7 @@ func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID
 	query := `
 		UPDATE users
 		SET is_verified = true, email_verified_at = $2, updated_at = $2
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	now := time.Now()
@@ -207,7 +208,7 @@ func (r *userRepository) MarkPhoneVerified(ctx context.Context, userID uuid.UUID
 	query := `
 		UPDATE users
 		SET phone_verified_at = $2, updated_at = $2
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	now := time.Now()
@@ -216,7 +217,7 @@ func (r *userRepository) MarkPhoneVerified(ctx context.Context, userID uuid.UUID
 }
 
 func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)`
 
 	var exists bool
 	err := r.db.GetContext(ctx, &exists, query, email)
@@ -224,7 +225,7 @@ func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool,
 }
 
 func (r *userRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1 AND deleted_at IS NULL)`
 
 	var exists bool
 	err := r.db.GetContext(ctx, &exists, query, phone)
@@ -232,7 +233,7 @@ func (r *userRepository) ExistsByPhone(ctx context.Context, phone string) (bool,
 }
 
 func (r *userRepository) CountActiveUsers(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM users WHERE is_active = true`
+	query := `SELECT COUNT(*) FROM users WHERE is_active = true AND deleted_at IS NULL`
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
@@ -244,7 +245,7 @@ func (r *userRepository) CountActiveUsers(ctx context.Context) (int, error) {
 }
 
 func (r *userRepository) CountTotalUsers(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM users`
+	query := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
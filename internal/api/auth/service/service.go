@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"sea-catering-backend/pkg/logger"
 	"sea-catering-backend/pkg/redis"
 	"sea-catering-backend/pkg/s3"
+	"sea-catering-backend/pkg/sms"
 	"sea-catering-backend/pkg/utils"
 )
 
@@ -30,6 +32,8 @@ type AuthService interface {
 	ResetPassword(ctx context.Context, req auth.ResetPasswordRequest) error
 	SendOTP(ctx context.Context, req auth.SendOTPRequest) error
 	VerifyOTP(ctx context.Context, req auth.VerifyOTPRequest) error
+	SendPhoneOTP(ctx context.Context, userID uuid.UUID) error
+	VerifyPhoneOTP(ctx context.Context, userID uuid.UUID, req auth.VerifyPhoneOTPRequest) error
 	UploadProfileImage(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (*auth.UploadProfileImageResponse, error)
 }
 
@@ -39,6 +43,7 @@ type authService struct {
 	bcryptService bcrypt.Interface
 	redisService  redis.Interface
 	emailService  email.Interface
+	smsService    sms.Interface
 	s3Service     s3.Interface
 	utilsService  utils.Interface
 	logger        *logger.Logger
@@ -50,6 +55,7 @@ func NewAuthService(
 	bcryptService bcrypt.Interface,
 	redisService redis.Interface,
 	emailService email.Interface,
+	smsService sms.Interface,
 	s3Service s3.Interface,
 	utilsService utils.Interface,
 	logger *logger.Logger,
@@ -60,6 +66,7 @@ func NewAuthService(
 		bcryptService: bcryptService,
 		redisService:  redisService,
 		emailService:  emailService,
+		smsService:    smsService,
 		s3Service:     s3Service,
 		utilsService:  utilsService,
 		logger:        logger,
@@ -109,6 +116,11 @@ func (s *authService) Register(ctx context.Context, req auth.RegisterRequest) (*
 		user.Phone = &req.Phone
 	}
 
+	user.PreferredLanguage = entity.DefaultLanguage
+	if req.PreferredLanguage != "" {
+		user.PreferredLanguage = req.PreferredLanguage
+	}
+
 	err = s.userRepo.Create(ctx, user)
 	if err != nil {
 		s.logger.Error("Failed to create user", logger.Fields{"error": err.Error()})
@@ -117,7 +129,7 @@ func (s *authService) Register(ctx context.Context, req auth.RegisterRequest) (*
 
 	err = s.emailService.SendWelcomeEmail(user.Email, user.Name)
 	if err != nil {
-		s.logger.Warn("Failed to send welcome email", logger.Fields{
+		s.logger.Warn("Failed to queue welcome email", logger.Fields{
 			"error":   err.Error(),
 			"email":   user.Email,
 			"user_id": user.ID.String(),
@@ -152,13 +164,14 @@ func (s *authService) Register(ctx context.Context, req auth.RegisterRequest) (*
 		AccessToken: tokenPair.AccessToken,
 		ExpiresAt:   tokenPair.ExpiresAt,
 		User: auth.UserInfo{
-			ID:              user.ID,
-			Name:            user.Name,
-			Email:           user.Email,
-			Phone:           user.Phone,
-			IsVerified:      user.IsVerified,
-			ProfileImageURL: user.ProfileImageURL,
-			Role:            user.Role,
+			ID:                user.ID,
+			Name:              user.Name,
+			Email:             user.Email,
+			Phone:             user.Phone,
+			IsVerified:        user.IsVerified,
+			ProfileImageURL:   user.ProfileImageURL,
+			Role:              user.Role,
+			PreferredLanguage: user.PreferredLanguage,
 		},
 	}, nil
 }
@@ -204,13 +217,14 @@ func (s *authService) Login(ctx context.Context, req auth.LoginRequest) (*auth.L
 		AccessToken: tokenPair.AccessToken,
 		ExpiresAt:   tokenPair.ExpiresAt,
 		User: auth.UserInfo{
-			ID:              user.ID,
-			Name:            user.Name,
-			Email:           user.Email,
-			Phone:           user.Phone,
-			IsVerified:      user.IsVerified,
-			ProfileImageURL: user.ProfileImageURL,
-			Role:            user.Role,
+			ID:                user.ID,
+			Name:              user.Name,
+			Email:             user.Email,
+			Phone:             user.Phone,
+			IsVerified:        user.IsVerified,
+			ProfileImageURL:   user.ProfileImageURL,
+			Role:              user.Role,
+			PreferredLanguage: user.PreferredLanguage,
 		},
 	}, nil
 }
@@ -251,6 +265,10 @@ func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, req a
 		user.PhoneVerifiedAt = nil
 	}
 
+	if req.PreferredLanguage != "" {
+		user.PreferredLanguage = req.PreferredLanguage
+	}
+
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
@@ -375,7 +393,7 @@ func (s *authService) SendOTP(ctx context.Context, req auth.SendOTPRequest) erro
 
 	err = s.emailService.SendOTPEmail(req.Email, userName, otp)
 	if err != nil {
-		s.logger.Error("Failed to send OTP email", logger.Fields{
+		s.logger.Error("Failed to queue OTP email", logger.Fields{
 			"error": err.Error(),
 			"email": req.Email,
 			"type":  req.Type,
@@ -383,7 +401,7 @@ func (s *authService) SendOTP(ctx context.Context, req auth.SendOTPRequest) erro
 		return fmt.Errorf("failed to send OTP email: %w", err)
 	}
 
-	s.logger.Info("OTP sent successfully via email", logger.Fields{
+	s.logger.Info("OTP email queued", logger.Fields{
 		"email": req.Email,
 		"type":  req.Type,
 	})
@@ -430,6 +448,117 @@ func (s *authService) VerifyOTP(ctx context.Context, req auth.VerifyOTPRequest)
 	return nil
 }
 
+// Phone OTPs are sent by SMS, which costs money per message, so sends and
+// verification attempts are rate limited per number.
+const (
+	phoneOTPSendLimit   = 3
+	phoneOTPVerifyLimit = 5
+	phoneOTPWindow      = 10 * time.Minute
+)
+
+func (s *authService) SendPhoneOTP(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Phone == nil || *user.Phone == "" {
+		return auth.ErrPhoneNotSet
+	}
+	if user.PhoneVerifiedAt != nil {
+		return auth.ErrPhoneVerified
+	}
+
+	limited, err := s.redisService.IsRateLimited(ctx, fmt.Sprintf("phone_otp:send:%s", *user.Phone), phoneOTPSendLimit, phoneOTPWindow)
+	if err != nil {
+		s.logger.Error("Failed to check phone OTP rate limit", logger.Fields{"error": err.Error()})
+		return err
+	}
+	if limited {
+		return auth.ErrTooManyOTPRequests
+	}
+
+	otp := s.utilsService.GenerateNumericOTP(6)
+
+	otpKey := fmt.Sprintf("otp:phone_verification:%s", *user.Phone)
+	err = s.redisService.SetOTP(ctx, otpKey, otp, phoneOTPWindow)
+	if err != nil {
+		s.logger.Error("Failed to store OTP", logger.Fields{"error": err.Error()})
+		return err
+	}
+
+	err = s.smsService.SendOTP(ctx, *user.Phone, otp, sms.Locale(user.PreferredLanguage))
+	if err != nil {
+		s.logger.Error("Failed to send OTP SMS", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		if errors.Is(err, sms.ErrInvalidPhone) {
+			return auth.ErrInvalidPhone
+		}
+		return fmt.Errorf("failed to send OTP SMS: %w", err)
+	}
+
+	s.logger.Info("Phone OTP sent", logger.Fields{
+		"user_id":  userID.String(),
+		"provider": s.smsService.ProviderName(),
+	})
+
+	return nil
+}
+
+// VerifyPhoneOTP checks the code against the phone number currently on the
+// account, so a code sent before the number changed cannot verify the new one.
+func (s *authService) VerifyPhoneOTP(ctx context.Context, userID uuid.UUID, req auth.VerifyPhoneOTPRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Phone == nil || *user.Phone == "" {
+		return auth.ErrPhoneNotSet
+	}
+	if user.PhoneVerifiedAt != nil {
+		return auth.ErrPhoneVerified
+	}
+
+	limited, err := s.redisService.IsRateLimited(ctx, fmt.Sprintf("phone_otp:verify:%s", *user.Phone), phoneOTPVerifyLimit, phoneOTPWindow)
+	if err != nil {
+		s.logger.Error("Failed to check phone OTP rate limit", logger.Fields{"error": err.Error()})
+		return err
+	}
+	if limited {
+		return auth.ErrTooManyOTPRequests
+	}
+
+	otpKey := fmt.Sprintf("otp:phone_verification:%s", *user.Phone)
+	storedOTP, err := s.redisService.GetOTP(ctx, otpKey)
+	if err != nil {
+		return auth.ErrOTPNotFound
+	}
+
+	if storedOTP != req.OTP {
+		return auth.ErrInvalidOTP
+	}
+
+	err = s.redisService.DeleteOTP(ctx, otpKey)
+	if err != nil {
+		s.logger.Error("Failed to delete OTP", logger.Fields{"error": err.Error()})
+	}
+
+	err = s.userRepo.MarkPhoneVerified(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to mark phone verified", logger.Fields{"error": err.Error()})
+		return err
+	}
+
+	s.logger.Info("Phone verified successfully", logger.Fields{
+		"user_id": user.ID.String(),
+	})
+
+	return nil
+}
+
 func (s *authService) UploadProfileImage(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (*auth.UploadProfileImageResponse, error) {
 
 	if !s.utilsService.IsImageFile(file.Filename) {
@@ -0,0 +1,77 @@
+package deliveries
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+type DateRequest struct {
+	Date string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type GenerateDeliveriesRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type DeliveryListRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Date      string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	CourierID string `query:"courier_id" validate:"omitempty,max=36"`
+	Status    string `query:"status" validate:"omitempty,oneof=scheduled out_for_delivery delivered failed"`
+	// Unassigned restricts the list to deliveries without a courier.
+	Unassigned bool `query:"unassigned"`
+}
+
+type AssignDeliveriesRequest struct {
+	DeliveryIDs []string `json:"delivery_ids" validate:"required,min=1,max=200,dive,required,max=36"`
+	CourierID   string   `json:"courier_id" validate:"required,max=36"`
+}
+
+type MarkFailedRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type CreateCourierRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,strong_password"`
+	Phone    string `json:"phone" validate:"required,phone_id"`
+}
+
+type GenerateDeliveriesResponse struct {
+	Date    string `json:"date"`
+	Created int    `json:"created"`
+}
+
+type AssignDeliveriesResponse struct {
+	CourierID string `json:"courier_id"`
+	Assigned  int    `json:"assigned"`
+	Skipped   int    `json:"skipped"`
+}
+
+type DeliveryListResponse struct {
+	Deliveries []entity.DeliveryWithDetails `json:"deliveries"`
+	Meta       *PaginationMeta              `json:"meta"`
+}
+
+type CourierResponse struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	Phone          *string   `json:"phone,omitempty"`
+	IsActive       bool      `json:"is_active"`
+	AssignedToday  int       `json:"assigned_today"`
+	CompletedToday int       `json:"completed_today"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
@@ -0,0 +1,14 @@
+package deliveries
+
+import "errors"
+
+var (
+	ErrDeliveryNotFound        = errors.New("delivery not found")
+	ErrCourierNotFound         = errors.New("courier not found")
+	ErrNotAssignedCourier      = errors.New("delivery is not assigned to this courier")
+	ErrInvalidStatusTransition = errors.New("invalid delivery status transition")
+	ErrInvalidDeliveryDate     = errors.New("invalid delivery date")
+	ErrProofPhotoRequired      = errors.New("proof-of-delivery photo is required")
+	ErrInvalidPhotoFormat      = errors.New("invalid proof photo format")
+	ErrPhotoTooLarge           = errors.New("proof photo is too large")
+)
@@ -0,0 +1,26 @@
+package deliveries
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+// Delivery-day events published on the app event bus when a courier updates
+// a drop.
+const (
+	EventDeliveryOutForDelivery = "delivery.out_for_delivery"
+	EventDeliveryDelivered      = "delivery.delivered"
+	EventDeliveryFailed         = "delivery.failed"
+)
+
+// DeliveryEvent is a snapshot taken when the status changed, since the
+// delivery may have moved on by the time a handler runs.
+type DeliveryEvent struct {
+	DeliveryID    string
+	UserID        string
+	Status        entity.DeliveryStatus
+	MealType      entity.MealType
+	DeliveryDate  time.Time
+	FailureReason string
+}
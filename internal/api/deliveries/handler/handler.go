@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/auth"
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/deliveries/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type DeliveryHandler struct {
+	deliveryService service.DeliveryService
+	validator       *validator.Validate
+	middleware      middleware.Interface
+	logger          *logger.Logger
+}
+
+func NewDeliveryHandler(
+	deliveryService service.DeliveryService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+		validator:       validator,
+		middleware:      middleware,
+		logger:          logger,
+	}
+}
+
+func (h *DeliveryHandler) RegisterRoutes(router fiber.Router) {
+	deliveriesGroup := router.Group("/deliveries")
+
+	admin := deliveriesGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/", h.ListDeliveries)
+	admin.Post("/generate", h.GenerateDeliveries)
+	admin.Put("/assign", h.AssignDeliveries)
+	admin.Get("/couriers", h.GetCouriers)
+	admin.Post("/couriers", h.CreateCourier)
+
+	courier := deliveriesGroup.Group("/courier", h.middleware.CourierMiddleware())
+	courier.Get("/", h.GetCourierDeliveries)
+	courier.Put("/:id/out-for-delivery", h.MarkOutForDelivery)
+	courier.Post("/:id/delivered", h.MarkDelivered)
+	courier.Put("/:id/failed", h.MarkFailed)
+
+	protected := deliveriesGroup.Use(h.middleware.AuthMiddleware())
+	protected.Get("/today", h.GetTodayDeliveries)
+}
+
+func (h *DeliveryHandler) GetCourierDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req deliveries.DateRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	deliveryList, err := h.deliveryService.GetCourierDeliveries(ctx, courierID, parseDateOrToday(req.Date))
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "get_courier_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, deliveryList)
+}
+
+func (h *DeliveryHandler) MarkOutForDelivery(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	deliveryID := c.Params("id")
+	if deliveryID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Delivery ID is required")
+	}
+
+	delivery, err := h.deliveryService.MarkOutForDelivery(ctx, deliveryID, courierID)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "mark_out_for_delivery")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, delivery)
+}
+
+func (h *DeliveryHandler) MarkDelivered(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	deliveryID := c.Params("id")
+	if deliveryID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Delivery ID is required")
+	}
+
+	photo, err := c.FormFile("photo")
+	if err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Proof-of-delivery photo is required")
+	}
+
+	delivery, err := h.deliveryService.MarkDelivered(ctx, deliveryID, courierID, photo)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "mark_delivered")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, delivery)
+}
+
+func (h *DeliveryHandler) MarkFailed(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	deliveryID := c.Params("id")
+	if deliveryID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Delivery ID is required")
+	}
+
+	var req deliveries.MarkFailedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	delivery, err := h.deliveryService.MarkFailed(ctx, deliveryID, courierID, req)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "mark_failed")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, delivery)
+}
+
+func (h *DeliveryHandler) GetTodayDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	deliveryList, err := h.deliveryService.GetCustomerDeliveries(ctx, userID, time.Now())
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "get_today_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, deliveryList)
+}
+
+func (h *DeliveryHandler) ListDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req deliveries.DeliveryListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.deliveryService.ListDeliveries(ctx, req)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "list_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *DeliveryHandler) GenerateDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 60*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req deliveries.GenerateDeliveriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.deliveryService.GenerateDeliveries(ctx, req)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "generate_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *DeliveryHandler) AssignDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req deliveries.AssignDeliveriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.deliveryService.AssignDeliveries(ctx, req)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "assign_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *DeliveryHandler) GetCouriers(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req deliveries.DateRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	couriers, err := h.deliveryService.GetCouriers(ctx, parseDateOrToday(req.Date))
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "get_couriers")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, couriers)
+}
+
+func (h *DeliveryHandler) CreateCourier(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req deliveries.CreateCourierRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	courier, err := h.deliveryService.CreateCourier(ctx, req)
+	if err != nil {
+		return h.handleDeliveryError(c, errHandler, requestID, err, c.Path(), "create_courier")
+	}
+
+	return response.Created(c, courier, "Courier account created successfully")
+}
+
+func (h *DeliveryHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+// parseDateOrToday expects a value already checked by the datetime validator.
+func parseDateOrToday(date string) time.Time {
+	if parsed, err := time.Parse("2006-01-02", date); err == nil {
+		return parsed
+	}
+	return time.Now()
+}
+
+func (h *DeliveryHandler) handleDeliveryError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case deliveries.ErrDeliveryNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Delivery")
+	case deliveries.ErrCourierNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Courier")
+	case deliveries.ErrNotAssignedCourier:
+		return errHandler.HandleForbidden(c, requestID, "This delivery is not assigned to you")
+	case deliveries.ErrInvalidStatusTransition:
+		return response.UnprocessableEntity(c, "This delivery cannot move to the requested status")
+	case deliveries.ErrInvalidDeliveryDate:
+		return errHandler.HandleBadRequest(c, requestID, "Invalid delivery date. Use YYYY-MM-DD")
+	case deliveries.ErrProofPhotoRequired:
+		return errHandler.HandleBadRequest(c, requestID, "Proof-of-delivery photo is required")
+	case deliveries.ErrInvalidPhotoFormat:
+		return errHandler.HandleBadRequest(c, requestID, "Proof photo must be an image")
+	case deliveries.ErrPhotoTooLarge:
+		return errHandler.HandleBadRequest(c, requestID, "Proof photo must be 5MB or smaller")
+	case auth.ErrEmailAlreadyExists:
+		return response.Conflict(c, "Email already registered")
+	case auth.ErrPhoneAlreadyExists:
+		return response.Conflict(c, "Phone number already registered")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/logger"
+)
+
+type DeliveryRepository interface {
+	GetDueSubscriptions(ctx context.Context, date time.Time) ([]entity.Subscription, error)
+	CreateBatch(ctx context.Context, deliveryList []entity.Delivery) (int, error)
+	GetByID(ctx context.Context, id string) (*entity.DeliveryWithDetails, error)
+	GetByCourierAndDate(ctx context.Context, courierID string, date time.Time) ([]entity.DeliveryWithDetails, error)
+	GetByUserAndDate(ctx context.Context, userID string, date time.Time) ([]entity.DeliveryWithDetails, error)
+	GetForDispatch(ctx context.Context, date time.Time, courierID, zone string) ([]entity.DeliveryWithDetails, error)
+	List(ctx context.Context, req deliveries.DeliveryListRequest) ([]entity.DeliveryWithDetails, *deliveries.PaginationMeta, error)
+	Assign(ctx context.Context, deliveryIDs []string, courierID string) (int, error)
+	UpdateStatus(ctx context.Context, delivery *entity.Delivery, fromStatus entity.DeliveryStatus) error
+	GetCouriers(ctx context.Context, date time.Time) ([]deliveries.CourierResponse, error)
+}
+
+type deliveryRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewDeliveryRepository(db *sqlx.DB, logger *logger.Logger) DeliveryRepository {
+	return &deliveryRepository{
+		db:     database.New(db),
+		logger: logger,
+	}
+}
+
+const deliveryDetailsQuery = `
+	SELECT d.id, d.subscription_id, d.user_id, d.delivery_date, d.meal_type,
+	       COALESCE(d.delivery_address, '') as delivery_address, d.latitude, d.longitude,
+	       COALESCE(d.zone, '') as zone, d.courier_id, d.status,
+	       d.proof_photo_url, d.failure_reason, d.assigned_at, d.out_for_delivery_at,
+	       d.delivered_at, d.failed_at, d.created_at, d.updated_at,
+	       u.name as customer_name, u.phone as customer_phone, mp.name as meal_plan_name,
+	       COALESCE(s.allergies, '') as allergies, c.name as courier_name
+	FROM deliveries d
+	JOIN users u ON d.user_id = u.id
+	JOIN subscriptions s ON d.subscription_id = s.id
+	JOIN meal_plans mp ON s.meal_plan_id = mp.id
+	LEFT JOIN users c ON d.courier_id = c.id
+`
+
+// mealTypeOrder sorts a day's deliveries in the order they are eaten rather
+// than alphabetically.
+const mealTypeOrder = `CASE d.meal_type WHEN 'breakfast' THEN 1 WHEN 'lunch' THEN 2 ELSE 3 END`
+
+func (r *deliveryRepository) GetDueSubscriptions(ctx context.Context, date time.Time) ([]entity.Subscription, error) {
+	query := `
+		SELECT id, user_id, meal_types, COALESCE(delivery_address, '') as delivery_address,
+		       delivery_latitude, delivery_longitude, COALESCE(delivery_zone, '') as delivery_zone
+		FROM subscriptions
+		WHERE status = 'active' AND deleted_at IS NULL
+		AND $2::delivery_day = ANY(delivery_days)
+		AND created_at::date <= $1
+		AND (ends_at IS NULL OR ends_at::date >= $1)
+		AND NOT (pause_start_date IS NOT NULL AND $1 BETWEEN pause_start_date AND pause_end_date)
+	`
+
+	weekday := strings.ToLower(date.Weekday().String())
+
+	rows, err := r.db.QueryContext(ctx, query, date.Format("2006-01-02"), weekday)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptionList []entity.Subscription
+	for rows.Next() {
+		var sub entity.Subscription
+		var mealTypes pq.StringArray
+
+		err := rows.Scan(
+			&sub.ID, &sub.UserID, &mealTypes, &sub.DeliveryAddress,
+			&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan due subscription: %w", err)
+		}
+
+		sub.MealTypes = make([]entity.MealType, len(mealTypes))
+		for i, mt := range mealTypes {
+			sub.MealTypes[i] = entity.MealType(mt)
+		}
+
+		subscriptionList = append(subscriptionList, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return subscriptionList, nil
+}
+
+// CreateBatch inserts the deliveries and skips any that already exist for the
+// same subscription, date and meal, so generating a day twice is harmless.
+func (r *deliveryRepository) CreateBatch(ctx context.Context, deliveryList []entity.Delivery) (int, error) {
+	query := `
+		INSERT INTO deliveries (
+			id, subscription_id, user_id, delivery_date, meal_type, delivery_address,
+			latitude, longitude, zone, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (subscription_id, delivery_date, meal_type) DO NOTHING
+	`
+
+	created := 0
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for _, d := range deliveryList {
+			result, err := r.db.ExecContext(ctx, query,
+				d.ID, d.SubscriptionID, d.UserID, d.DeliveryDate.Format("2006-01-02"), d.MealType, d.DeliveryAddress,
+				d.Latitude, d.Longitude, d.Zone, d.Status, d.CreatedAt, d.UpdatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create delivery: %w", err)
+			}
+
+			rowsAffected, _ := result.RowsAffected()
+			created += int(rowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+func (r *deliveryRepository) GetByID(ctx context.Context, id string) (*entity.DeliveryWithDetails, error) {
+	query := deliveryDetailsQuery + ` WHERE d.id = $1`
+
+	var delivery entity.DeliveryWithDetails
+	if err := r.db.GetContext(ctx, &delivery, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, deliveries.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *deliveryRepository) GetByCourierAndDate(ctx context.Context, courierID string, date time.Time) ([]entity.DeliveryWithDetails, error) {
+	query := deliveryDetailsQuery + `
+		WHERE d.courier_id = $1 AND d.delivery_date = $2
+		ORDER BY ` + mealTypeOrder + `, d.delivery_address
+	`
+
+	deliveryList := []entity.DeliveryWithDetails{}
+	if err := r.db.SelectContext(ctx, &deliveryList, query, courierID, date.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf("failed to get courier deliveries: %w", err)
+	}
+
+	return deliveryList, nil
+}
+
+func (r *deliveryRepository) GetByUserAndDate(ctx context.Context, userID string, date time.Time) ([]entity.DeliveryWithDetails, error) {
+	query := deliveryDetailsQuery + `
+		WHERE d.user_id = $1 AND d.delivery_date = $2
+		ORDER BY ` + mealTypeOrder
+
+	deliveryList := []entity.DeliveryWithDetails{}
+	if err := r.db.SelectContext(ctx, &deliveryList, query, userID, date.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf("failed to get user deliveries: %w", err)
+	}
+
+	return deliveryList, nil
+}
+
+// GetForDispatch returns the day's open deliveries for route planning,
+// optionally narrowed to one courier or zone.
+func (r *deliveryRepository) GetForDispatch(ctx context.Context, date time.Time, courierID, zone string) ([]entity.DeliveryWithDetails, error) {
+	whereConditions := []string{"d.delivery_date = $1", "d.status IN ('scheduled', 'out_for_delivery')"}
+	args := []interface{}{date.Format("2006-01-02")}
+	argIndex := 2
+
+	if courierID != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.courier_id = $%d", argIndex))
+		args = append(args, courierID)
+		argIndex++
+	}
+
+	if zone != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.zone = $%d", argIndex))
+		args = append(args, zone)
+	}
+
+	query := fmt.Sprintf(`%s
+		WHERE %s
+		ORDER BY d.zone, %s, d.delivery_address
+	`, deliveryDetailsQuery, strings.Join(whereConditions, " AND "), mealTypeOrder)
+
+	deliveryList := []entity.DeliveryWithDetails{}
+	if err := r.db.SelectContext(ctx, &deliveryList, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get deliveries for dispatch: %w", err)
+	}
+
+	return deliveryList, nil
+}
+
+func (r *deliveryRepository) List(ctx context.Context, req deliveries.DeliveryListRequest) ([]entity.DeliveryWithDetails, *deliveries.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Date != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.delivery_date = $%d", argIndex))
+		args = append(args, req.Date)
+		argIndex++
+	}
+
+	if req.CourierID != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.courier_id = $%d", argIndex))
+		args = append(args, req.CourierID)
+		argIndex++
+	}
+
+	if req.Unassigned {
+		whereConditions = append(whereConditions, "d.courier_id IS NULL")
+	}
+
+	if req.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("d.status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM deliveries d %s`, whereClause)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`%s
+		%s
+		ORDER BY d.delivery_date DESC, %s, d.delivery_address
+		LIMIT $%d OFFSET $%d
+	`, deliveryDetailsQuery, whereClause, mealTypeOrder, argIndex, argIndex+1)
+
+	args = append(args, req.Limit, offset)
+
+	deliveryList := []entity.DeliveryWithDetails{}
+	if err := r.db.SelectContext(ctx, &deliveryList, query, args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	meta := &deliveries.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return deliveryList, meta, nil
+}
+
+// Assign hands the deliveries to a courier. Deliveries that are already out
+// with another courier go back to scheduled so the new courier picks them up;
+// completed deliveries are left untouched.
+func (r *deliveryRepository) Assign(ctx context.Context, deliveryIDs []string, courierID string) (int, error) {
+	query := `
+		UPDATE deliveries
+		SET courier_id = $1,
+		    assigned_at = $3,
+		    status = 'scheduled',
+		    out_for_delivery_at = NULL,
+		    updated_at = $3
+		WHERE id = ANY($2) AND status IN ('scheduled', 'out_for_delivery')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, courierID, pq.Array(deliveryIDs), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign deliveries: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// UpdateStatus persists a courier's status change. The update only applies
+// while the delivery is still in fromStatus and assigned to the same courier,
+// so a concurrent reassignment wins over a stale client.
+func (r *deliveryRepository) UpdateStatus(ctx context.Context, delivery *entity.Delivery, fromStatus entity.DeliveryStatus) error {
+	query := `
+		UPDATE deliveries
+		SET status = $3, proof_photo_url = $4, failure_reason = $5,
+		    out_for_delivery_at = $6, delivered_at = $7, failed_at = $8, updated_at = $9
+		WHERE id = $1 AND courier_id = $2 AND status = $10
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.ID, delivery.CourierID, delivery.Status, delivery.ProofPhotoURL, delivery.FailureReason,
+		delivery.OutForDeliveryAt, delivery.DeliveredAt, delivery.FailedAt, time.Now(), fromStatus,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return deliveries.ErrInvalidStatusTransition
+	}
+
+	return nil
+}
+
+func (r *deliveryRepository) GetCouriers(ctx context.Context, date time.Time) ([]deliveries.CourierResponse, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.phone, u.is_active, u.created_at,
+		       COUNT(d.id),
+		       COUNT(d.id) FILTER (WHERE d.status IN ('delivered', 'failed'))
+		FROM users u
+		LEFT JOIN deliveries d ON d.courier_id = u.id AND d.delivery_date = $1
+		WHERE u.role = 'courier' AND u.deleted_at IS NULL
+		GROUP BY u.id
+		ORDER BY u.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, date.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get couriers: %w", err)
+	}
+	defer rows.Close()
+
+	couriers := []deliveries.CourierResponse{}
+	for rows.Next() {
+		var courier deliveries.CourierResponse
+		err := rows.Scan(
+			&courier.ID, &courier.Name, &courier.Email, &courier.Phone, &courier.IsActive, &courier.CreatedAt,
+			&courier.AssignedToday, &courier.CompletedToday,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan courier: %w", err)
+		}
+		couriers = append(couriers, courier)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return couriers, nil
+}
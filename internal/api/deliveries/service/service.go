@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/deliveries/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/bcrypt"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/s3"
+	"sea-catering-backend/pkg/utils"
+)
+
+const maxProofPhotoSize = 5 * 1024 * 1024
+
+type DeliveryService interface {
+	GetCourierDeliveries(ctx context.Context, courierID string, date time.Time) ([]entity.DeliveryWithDetails, error)
+	MarkOutForDelivery(ctx context.Context, deliveryID, courierID string) (*entity.DeliveryWithDetails, error)
+	MarkDelivered(ctx context.Context, deliveryID, courierID string, photo *multipart.FileHeader) (*entity.DeliveryWithDetails, error)
+	MarkFailed(ctx context.Context, deliveryID, courierID string, req deliveries.MarkFailedRequest) (*entity.DeliveryWithDetails, error)
+
+	GetCustomerDeliveries(ctx context.Context, userID string, date time.Time) ([]entity.DeliveryWithDetails, error)
+
+	GenerateDeliveries(ctx context.Context, req deliveries.GenerateDeliveriesRequest) (*deliveries.GenerateDeliveriesResponse, error)
+	ListDeliveries(ctx context.Context, req deliveries.DeliveryListRequest) (*deliveries.DeliveryListResponse, error)
+	AssignDeliveries(ctx context.Context, req deliveries.AssignDeliveriesRequest) (*deliveries.AssignDeliveriesResponse, error)
+	GetCouriers(ctx context.Context, date time.Time) ([]deliveries.CourierResponse, error)
+	CreateCourier(ctx context.Context, req deliveries.CreateCourierRequest) (*entity.UserResponse, error)
+}
+
+type deliveryService struct {
+	deliveryRepo  repository.DeliveryRepository
+	userRepo      authRepo.UserRepository
+	s3Service     s3.Interface
+	bcryptService bcrypt.Interface
+	eventBus      events.Interface
+	utils         utils.Interface
+	logger        *logger.Logger
+}
+
+func NewDeliveryService(
+	deliveryRepo repository.DeliveryRepository,
+	userRepo authRepo.UserRepository,
+	s3Service s3.Interface,
+	bcryptService bcrypt.Interface,
+	eventBus events.Interface,
+	utils utils.Interface,
+	logger *logger.Logger,
+) DeliveryService {
+	return &deliveryService{
+		deliveryRepo:  deliveryRepo,
+		userRepo:      userRepo,
+		s3Service:     s3Service,
+		bcryptService: bcryptService,
+		eventBus:      eventBus,
+		utils:         utils,
+		logger:        logger,
+	}
+}
+
+func (s *deliveryService) GetCourierDeliveries(ctx context.Context, courierID string, date time.Time) ([]entity.DeliveryWithDetails, error) {
+	return s.deliveryRepo.GetByCourierAndDate(ctx, courierID, date)
+}
+
+func (s *deliveryService) MarkOutForDelivery(ctx context.Context, deliveryID, courierID string) (*entity.DeliveryWithDetails, error) {
+	delivery, err := s.getAssignedDelivery(ctx, deliveryID, courierID, entity.DeliveryOutForDelivery)
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := delivery.Status
+	now := time.Now()
+	delivery.Status = entity.DeliveryOutForDelivery
+	delivery.OutForDeliveryAt = &now
+
+	return s.updateStatus(ctx, &delivery.Delivery, fromStatus)
+}
+
+func (s *deliveryService) MarkDelivered(ctx context.Context, deliveryID, courierID string, photo *multipart.FileHeader) (*entity.DeliveryWithDetails, error) {
+	if photo == nil {
+		return nil, deliveries.ErrProofPhotoRequired
+	}
+
+	if !s.utils.IsImageFile(photo.Filename) {
+		return nil, deliveries.ErrInvalidPhotoFormat
+	}
+
+	if photo.Size > maxProofPhotoSize {
+		return nil, deliveries.ErrPhotoTooLarge
+	}
+
+	delivery, err := s.getAssignedDelivery(ctx, deliveryID, courierID, entity.DeliveryDelivered)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("delivery-proofs/%s/%s",
+		delivery.DeliveryDate.Format("2006-01-02"), s.utils.GenerateUniqueFilename(photo.Filename))
+
+	result, err := s.s3Service.UploadFile(photo, key)
+	if err != nil {
+		s.logger.Error("Failed to upload proof of delivery", logger.Fields{
+			"error":       err.Error(),
+			"delivery_id": deliveryID,
+		})
+		return nil, err
+	}
+
+	fromStatus := delivery.Status
+	now := time.Now()
+	delivery.Status = entity.DeliveryDelivered
+	delivery.ProofPhotoURL = &result.URL
+	delivery.DeliveredAt = &now
+
+	updated, err := s.updateStatus(ctx, &delivery.Delivery, fromStatus)
+	if err != nil {
+		s.s3Service.DeleteFile(key)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *deliveryService) MarkFailed(ctx context.Context, deliveryID, courierID string, req deliveries.MarkFailedRequest) (*entity.DeliveryWithDetails, error) {
+	delivery, err := s.getAssignedDelivery(ctx, deliveryID, courierID, entity.DeliveryFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := delivery.Status
+	reason := strings.TrimSpace(req.Reason)
+	now := time.Now()
+	delivery.Status = entity.DeliveryFailed
+	delivery.FailureReason = &reason
+	delivery.FailedAt = &now
+
+	return s.updateStatus(ctx, &delivery.Delivery, fromStatus)
+}
+
+func (s *deliveryService) GetCustomerDeliveries(ctx context.Context, userID string, date time.Time) ([]entity.DeliveryWithDetails, error) {
+	return s.deliveryRepo.GetByUserAndDate(ctx, userID, date)
+}
+
+func (s *deliveryService) GenerateDeliveries(ctx context.Context, req deliveries.GenerateDeliveriesRequest) (*deliveries.GenerateDeliveriesResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, deliveries.ErrInvalidDeliveryDate
+	}
+
+	subscriptionList, err := s.deliveryRepo.GetDueSubscriptions(ctx, date)
+	if err != nil {
+		s.logger.Error("Failed to get subscriptions due for delivery", logger.Fields{
+			"error": err.Error(),
+			"date":  req.Date,
+		})
+		return nil, err
+	}
+
+	now := time.Now()
+	var deliveryList []entity.Delivery
+	for _, sub := range subscriptionList {
+		for _, mealType := range sub.MealTypes {
+			deliveryList = append(deliveryList, entity.Delivery{
+				ID:              s.utils.GenerateULID(),
+				SubscriptionID:  sub.ID,
+				UserID:          sub.UserID,
+				DeliveryDate:    date,
+				MealType:        mealType,
+				DeliveryAddress: sub.DeliveryAddress,
+				Latitude:        sub.DeliveryLatitude,
+				Longitude:       sub.DeliveryLongitude,
+				Zone:            sub.DeliveryZone,
+				Status:          entity.DeliveryScheduled,
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			})
+		}
+	}
+
+	created, err := s.deliveryRepo.CreateBatch(ctx, deliveryList)
+	if err != nil {
+		s.logger.Error("Failed to generate deliveries", logger.Fields{
+			"error": err.Error(),
+			"date":  req.Date,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Deliveries generated", logger.Fields{
+		"date":          req.Date,
+		"subscriptions": len(subscriptionList),
+		"created":       created,
+	})
+
+	return &deliveries.GenerateDeliveriesResponse{
+		Date:    req.Date,
+		Created: created,
+	}, nil
+}
+
+func (s *deliveryService) ListDeliveries(ctx context.Context, req deliveries.DeliveryListRequest) (*deliveries.DeliveryListResponse, error) {
+	deliveryList, meta, err := s.deliveryRepo.List(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to list deliveries", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &deliveries.DeliveryListResponse{
+		Deliveries: deliveryList,
+		Meta:       meta,
+	}, nil
+}
+
+func (s *deliveryService) AssignDeliveries(ctx context.Context, req deliveries.AssignDeliveriesRequest) (*deliveries.AssignDeliveriesResponse, error) {
+	if _, err := s.getCourier(ctx, req.CourierID); err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.deliveryRepo.Assign(ctx, req.DeliveryIDs, req.CourierID)
+	if err != nil {
+		s.logger.Error("Failed to assign deliveries", logger.Fields{
+			"error":      err.Error(),
+			"courier_id": req.CourierID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Deliveries assigned", logger.Fields{
+		"courier_id": req.CourierID,
+		"requested":  len(req.DeliveryIDs),
+		"assigned":   assigned,
+	})
+
+	return &deliveries.AssignDeliveriesResponse{
+		CourierID: req.CourierID,
+		Assigned:  assigned,
+		Skipped:   len(req.DeliveryIDs) - assigned,
+	}, nil
+}
+
+func (s *deliveryService) GetCouriers(ctx context.Context, date time.Time) ([]deliveries.CourierResponse, error) {
+	return s.deliveryRepo.GetCouriers(ctx, date)
+}
+
+func (s *deliveryService) CreateCourier(ctx context.Context, req deliveries.CreateCourierRequest) (*entity.UserResponse, error) {
+	hashedPassword, err := s.bcryptService.HashPassword(req.Password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	now := time.Now()
+	courier := &entity.User{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		Phone:     &req.Phone,
+		Password:  hashedPassword,
+		Role:      entity.RoleCourier,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.userRepo.Create(ctx, courier); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Courier account created", logger.Fields{
+		"courier_id": courier.ID.String(),
+		"email":      courier.Email,
+	})
+
+	response := courier.ToResponse()
+	return &response, nil
+}
+
+// getAssignedDelivery loads a delivery the courier is allowed to move to next.
+func (s *deliveryService) getAssignedDelivery(ctx context.Context, deliveryID, courierID string, next entity.DeliveryStatus) (*entity.DeliveryWithDetails, error) {
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.CourierID == nil || *delivery.CourierID != courierID {
+		return nil, deliveries.ErrNotAssignedCourier
+	}
+
+	if !delivery.CanTransitionTo(next) {
+		return nil, deliveries.ErrInvalidStatusTransition
+	}
+
+	return delivery, nil
+}
+
+func (s *deliveryService) updateStatus(ctx context.Context, delivery *entity.Delivery, fromStatus entity.DeliveryStatus) (*entity.DeliveryWithDetails, error) {
+	if err := s.deliveryRepo.UpdateStatus(ctx, delivery, fromStatus); err != nil {
+		s.logger.Error("Failed to update delivery status", logger.Fields{
+			"error":       err.Error(),
+			"delivery_id": delivery.ID,
+			"status":      delivery.Status,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Delivery status updated", logger.Fields{
+		"delivery_id": delivery.ID,
+		"courier_id":  *delivery.CourierID,
+		"from":        fromStatus,
+		"to":          delivery.Status,
+	})
+
+	s.publishStatusChange(ctx, delivery)
+
+	return s.deliveryRepo.GetByID(ctx, delivery.ID)
+}
+
+var statusEvents = map[entity.DeliveryStatus]string{
+	entity.DeliveryOutForDelivery: deliveries.EventDeliveryOutForDelivery,
+	entity.DeliveryDelivered:      deliveries.EventDeliveryDelivered,
+	entity.DeliveryFailed:         deliveries.EventDeliveryFailed,
+}
+
+func (s *deliveryService) publishStatusChange(ctx context.Context, delivery *entity.Delivery) {
+	name, ok := statusEvents[delivery.Status]
+	if !ok {
+		return
+	}
+
+	event := deliveries.DeliveryEvent{
+		DeliveryID:   delivery.ID,
+		UserID:       delivery.UserID,
+		Status:       delivery.Status,
+		MealType:     delivery.MealType,
+		DeliveryDate: delivery.DeliveryDate,
+	}
+	if delivery.FailureReason != nil {
+		event.FailureReason = *delivery.FailureReason
+	}
+
+	s.eventBus.Publish(ctx, name, event)
+}
+
+func (s *deliveryService) getCourier(ctx context.Context, courierID string) (*entity.User, error) {
+	id, err := uuid.Parse(courierID)
+	if err != nil {
+		return nil, deliveries.ErrCourierNotFound
+	}
+
+	// GetByID only returns active accounts, so deactivated couriers cannot
+	// be handed new work.
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil || user == nil || !user.IsCourier() {
+		return nil, deliveries.ErrCourierNotFound
+	}
+
+	return user, nil
+}
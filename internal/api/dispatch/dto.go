@@ -0,0 +1,49 @@
+package dispatch
+
+import "time"
+
+type RoutePlanRequest struct {
+	Date      string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	CourierID string `query:"courier_id" validate:"omitempty,max=36"`
+	Zone      string `query:"zone" validate:"omitempty,max=50"`
+}
+
+type RouteSheetRequest struct {
+	Date      string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	CourierID string `query:"courier_id" validate:"required,max=36"`
+}
+
+type RoutePlanResponse struct {
+	Date            string       `json:"date"`
+	Batches         []RouteBatch `json:"batches"`
+	TotalStops      int          `json:"total_stops"`
+	UnlocatedStops  int          `json:"unlocated_stops"`
+	TotalDistanceKm float64      `json:"total_distance_km"`
+	GeneratedAt     time.Time    `json:"generated_at"`
+}
+
+// RouteBatch is one run a courier makes: every stop in the same zone and
+// delivery window, in driving order.
+type RouteBatch struct {
+	Zone        string      `json:"zone"`
+	TimeWindow  string      `json:"time_window"`
+	CourierID   *string     `json:"courier_id,omitempty"`
+	CourierName *string     `json:"courier_name,omitempty"`
+	Stops       []RouteStop `json:"stops"`
+	DistanceKm  float64     `json:"distance_km"`
+}
+
+type RouteStop struct {
+	Sequence      int      `json:"sequence"`
+	DeliveryID    string   `json:"delivery_id"`
+	CustomerName  string   `json:"customer_name"`
+	CustomerPhone *string  `json:"customer_phone,omitempty"`
+	Address       string   `json:"address"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	MealType      string   `json:"meal_type"`
+	MealPlanName  string   `json:"meal_plan_name"`
+	Allergies     string   `json:"allergies,omitempty"`
+	Status        string   `json:"status"`
+	LegDistanceKm *float64 `json:"leg_distance_km,omitempty"`
+}
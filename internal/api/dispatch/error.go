@@ -0,0 +1,9 @@
+package dispatch
+
+import "errors"
+
+var (
+	ErrInvalidDate     = errors.New("invalid dispatch date")
+	ErrNoDeliveries    = errors.New("no deliveries to route")
+	ErrSheetGeneration = errors.New("failed to generate route sheet")
+)
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/dispatch"
+	"sea-catering-backend/internal/api/dispatch/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+)
+
+type DispatchHandler struct {
+	dispatchService service.DispatchService
+	validator       *validator.Validate
+	middleware      middleware.Interface
+	logger          *logger.Logger
+}
+
+func NewDispatchHandler(
+	dispatchService service.DispatchService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *DispatchHandler {
+	return &DispatchHandler{
+		dispatchService: dispatchService,
+		validator:       validator,
+		middleware:      middleware,
+		logger:          logger,
+	}
+}
+
+func (h *DispatchHandler) RegisterRoutes(router fiber.Router) {
+	dispatchGroup := router.Group("/dispatch")
+
+	admin := dispatchGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/routes", h.PlanRoutes)
+	admin.Get("/routes/sheet", h.GetRouteSheet)
+
+	courier := dispatchGroup.Group("/courier", h.middleware.CourierMiddleware())
+	courier.Get("/route", h.GetCourierRoute)
+	courier.Get("/route/sheet", h.GetCourierRouteSheet)
+}
+
+func (h *DispatchHandler) PlanRoutes(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req dispatch.RoutePlanRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	plan, err := h.dispatchService.PlanRoutes(ctx, req)
+	if err != nil {
+		return h.handleDispatchError(c, errHandler, requestID, err, c.Path(), "plan_routes")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, plan)
+}
+
+func (h *DispatchHandler) GetRouteSheet(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req dispatch.RouteSheetRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	sheet, filename, err := h.dispatchService.GenerateRouteSheet(ctx, req)
+	if err != nil {
+		return h.handleDispatchError(c, errHandler, requestID, err, c.Path(), "get_route_sheet")
+	}
+
+	return h.sendSheet(c, sheet, filename)
+}
+
+func (h *DispatchHandler) GetCourierRoute(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req dispatch.RoutePlanRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+	req.CourierID = courierID
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	plan, err := h.dispatchService.PlanRoutes(ctx, req)
+	if err != nil {
+		return h.handleDispatchError(c, errHandler, requestID, err, c.Path(), "get_courier_route")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, plan)
+}
+
+func (h *DispatchHandler) GetCourierRouteSheet(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	courierID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	req := dispatch.RouteSheetRequest{
+		Date:      c.Query("date"),
+		CourierID: courierID,
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	sheet, filename, err := h.dispatchService.GenerateRouteSheet(ctx, req)
+	if err != nil {
+		return h.handleDispatchError(c, errHandler, requestID, err, c.Path(), "get_courier_route_sheet")
+	}
+
+	return h.sendSheet(c, sheet, filename)
+}
+
+func (h *DispatchHandler) sendSheet(c *fiber.Ctx, sheet []byte, filename string) error {
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(sheet)
+}
+
+func (h *DispatchHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *DispatchHandler) handleDispatchError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case dispatch.ErrInvalidDate:
+		return errHandler.HandleBadRequest(c, requestID, "Invalid dispatch date. Use YYYY-MM-DD")
+	case dispatch.ErrNoDeliveries:
+		return errHandler.HandleNotFound(c, requestID, "Deliveries")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	deliveryRepo "sea-catering-backend/internal/api/deliveries/repository"
+	"sea-catering-backend/internal/api/dispatch"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/export"
+	"sea-catering-backend/pkg/logger"
+)
+
+const (
+	earthRadiusKm  = 6371.0
+	unzonedLabel   = "unzoned"
+	sheetDateLabel = "2006-01-02"
+)
+
+type DispatchService interface {
+	PlanRoutes(ctx context.Context, req dispatch.RoutePlanRequest) (*dispatch.RoutePlanResponse, error)
+	GenerateRouteSheet(ctx context.Context, req dispatch.RouteSheetRequest) ([]byte, string, error)
+}
+
+type Config struct {
+	// DepotLatitude and DepotLongitude mark the kitchen every route starts
+	// from. When unset, each route starts at its first stop.
+	DepotLatitude  *float64
+	DepotLongitude *float64
+}
+
+func LoadConfig() *Config {
+	config := &Config{}
+
+	lat, latErr := strconv.ParseFloat(os.Getenv("DISPATCH_DEPOT_LATITUDE"), 64)
+	lng, lngErr := strconv.ParseFloat(os.Getenv("DISPATCH_DEPOT_LONGITUDE"), 64)
+	if latErr == nil && lngErr == nil {
+		config.DepotLatitude = &lat
+		config.DepotLongitude = &lng
+	}
+
+	return config
+}
+
+type dispatchService struct {
+	deliveryRepo deliveryRepo.DeliveryRepository
+	config       *Config
+	logger       *logger.Logger
+}
+
+func NewDispatchService(
+	deliveryRepo deliveryRepo.DeliveryRepository,
+	config *Config,
+	logger *logger.Logger,
+) DispatchService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	return &dispatchService{
+		deliveryRepo: deliveryRepo,
+		config:       config,
+		logger:       logger,
+	}
+}
+
+func (s *dispatchService) PlanRoutes(ctx context.Context, req dispatch.RoutePlanRequest) (*dispatch.RoutePlanResponse, error) {
+	date, err := parseDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryList, err := s.deliveryRepo.GetForDispatch(ctx, date, req.CourierID, req.Zone)
+	if err != nil {
+		s.logger.Error("Failed to load deliveries for route planning", logger.Fields{
+			"error": err.Error(),
+			"date":  date.Format(sheetDateLabel),
+		})
+		return nil, err
+	}
+
+	plan := &dispatch.RoutePlanResponse{
+		Date:        date.Format(sheetDateLabel),
+		Batches:     []dispatch.RouteBatch{},
+		GeneratedAt: time.Now(),
+	}
+
+	for _, group := range groupDeliveries(deliveryList) {
+		batch := s.buildBatch(group)
+		plan.Batches = append(plan.Batches, batch)
+		plan.TotalStops += len(batch.Stops)
+		plan.TotalDistanceKm += batch.DistanceKm
+
+		for _, d := range group {
+			if !d.HasCoordinates() {
+				plan.UnlocatedStops++
+			}
+		}
+	}
+
+	plan.TotalDistanceKm = math.Round(plan.TotalDistanceKm*100) / 100
+
+	return plan, nil
+}
+
+func (s *dispatchService) GenerateRouteSheet(ctx context.Context, req dispatch.RouteSheetRequest) ([]byte, string, error) {
+	plan, err := s.PlanRoutes(ctx, dispatch.RoutePlanRequest{
+		Date:      req.Date,
+		CourierID: req.CourierID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if plan.TotalStops == 0 {
+		return nil, "", dispatch.ErrNoDeliveries
+	}
+
+	// The export writer escapes customer text that a spreadsheet would
+	// otherwise run as a formula.
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, export.FormatCSV, "")
+	if err != nil {
+		return nil, "", dispatch.ErrSheetGeneration
+	}
+
+	err = writer.WriteHeader(
+		"Window", "Zone", "Stop", "Customer", "Phone", "Address",
+		"Meal", "Plan", "Allergies", "Leg (km)", "Delivered", "Notes",
+	)
+	if err != nil {
+		return nil, "", dispatch.ErrSheetGeneration
+	}
+
+	courierName := req.CourierID
+	for _, batch := range plan.Batches {
+		if batch.CourierName != nil {
+			courierName = *batch.CourierName
+		}
+
+		for _, stop := range batch.Stops {
+			leg := ""
+			if stop.LegDistanceKm != nil {
+				leg = strconv.FormatFloat(*stop.LegDistanceKm, 'f', 2, 64)
+			}
+
+			err := writer.WriteRow(
+				batch.TimeWindow, batch.Zone, stop.Sequence, stop.CustomerName, stop.CustomerPhone,
+				stop.Address, stop.MealType, stop.MealPlanName, stop.Allergies, leg, "", "",
+			)
+			if err != nil {
+				return nil, "", dispatch.ErrSheetGeneration
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", dispatch.ErrSheetGeneration
+	}
+
+	s.logger.Info("Route sheet generated", logger.Fields{
+		"date":       plan.Date,
+		"courier_id": req.CourierID,
+		"courier":    courierName,
+		"stops":      plan.TotalStops,
+	})
+
+	filename := fmt.Sprintf("route-sheet-%s-%s.csv", plan.Date, req.CourierID)
+	return buf.Bytes(), filename, nil
+}
+
+// buildBatch orders a batch with a nearest-neighbour walk from the depot.
+// Stops without coordinates cannot be placed, so they go at the end in
+// address order for the courier to slot in by hand.
+func (s *dispatchService) buildBatch(group []entity.DeliveryWithDetails) dispatch.RouteBatch {
+	first := group[0]
+	batch := dispatch.RouteBatch{
+		Zone:        zoneLabel(first.Zone),
+		TimeWindow:  first.MealType.DeliveryWindow(),
+		CourierID:   first.CourierID,
+		CourierName: first.CourierName,
+		Stops:       make([]dispatch.RouteStop, 0, len(group)),
+	}
+
+	var located, unlocated []entity.DeliveryWithDetails
+	for _, d := range group {
+		if d.HasCoordinates() {
+			located = append(located, d)
+		} else {
+			unlocated = append(unlocated, d)
+		}
+	}
+
+	var curLat, curLng float64
+	hasPosition := false
+	if s.config.DepotLatitude != nil && s.config.DepotLongitude != nil {
+		curLat, curLng = *s.config.DepotLatitude, *s.config.DepotLongitude
+		hasPosition = true
+	}
+
+	visited := make([]bool, len(located))
+	for range located {
+		next := -1
+		nextDistance := math.MaxFloat64
+		for i, d := range located {
+			if visited[i] {
+				continue
+			}
+			if !hasPosition {
+				next = i
+				break
+			}
+			distance := haversineKm(curLat, curLng, *d.Latitude, *d.Longitude)
+			if distance < nextDistance {
+				next, nextDistance = i, distance
+			}
+		}
+
+		visited[next] = true
+		stop := newRouteStop(located[next], len(batch.Stops)+1)
+		if hasPosition {
+			leg := math.Round(nextDistance*100) / 100
+			stop.LegDistanceKm = &leg
+			batch.DistanceKm += nextDistance
+		}
+
+		curLat, curLng = *located[next].Latitude, *located[next].Longitude
+		hasPosition = true
+		batch.Stops = append(batch.Stops, stop)
+	}
+
+	sort.SliceStable(unlocated, func(i, j int) bool {
+		return unlocated[i].DeliveryAddress < unlocated[j].DeliveryAddress
+	})
+	for _, d := range unlocated {
+		batch.Stops = append(batch.Stops, newRouteStop(d, len(batch.Stops)+1))
+	}
+
+	batch.DistanceKm = math.Round(batch.DistanceKm*100) / 100
+	return batch
+}
+
+// groupDeliveries splits deliveries into batches keyed by time window, zone
+// and courier, returned in window then zone order.
+func groupDeliveries(deliveryList []entity.DeliveryWithDetails) [][]entity.DeliveryWithDetails {
+	type batchKey struct {
+		window  string
+		zone    string
+		courier string
+	}
+
+	groups := make(map[batchKey][]entity.DeliveryWithDetails)
+	var keys []batchKey
+	for _, d := range deliveryList {
+		key := batchKey{window: d.MealType.DeliveryWindow(), zone: zoneLabel(d.Zone)}
+		if d.CourierID != nil {
+			key.courier = *d.CourierID
+		}
+
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], d)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].window != keys[j].window {
+			return keys[i].window < keys[j].window
+		}
+		if keys[i].zone != keys[j].zone {
+			return keys[i].zone < keys[j].zone
+		}
+		return keys[i].courier < keys[j].courier
+	})
+
+	result := make([][]entity.DeliveryWithDetails, len(keys))
+	for i, key := range keys {
+		result[i] = groups[key]
+	}
+
+	return result
+}
+
+func newRouteStop(d entity.DeliveryWithDetails, sequence int) dispatch.RouteStop {
+	return dispatch.RouteStop{
+		Sequence:      sequence,
+		DeliveryID:    d.ID,
+		CustomerName:  d.CustomerName,
+		CustomerPhone: d.CustomerPhone,
+		Address:       d.DeliveryAddress,
+		Latitude:      d.Latitude,
+		Longitude:     d.Longitude,
+		MealType:      string(d.MealType),
+		MealPlanName:  d.MealPlanName,
+		Allergies:     d.Allergies,
+		Status:        string(d.Status),
+	}
+}
+
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func zoneLabel(zone string) string {
+	if zone == "" {
+		return unzonedLabel
+	}
+	return zone
+}
+
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Now(), nil
+	}
+
+	parsed, err := time.Parse(sheetDateLabel, date)
+	if err != nil {
+		return time.Time{}, dispatch.ErrInvalidDate
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,35 @@
+package exports
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+// Options are read alongside the list filters of every export.
+type Options struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+	// Async queues a job even when the export is small enough to stream.
+	Async bool `query:"async"`
+}
+
+type RevenueExportRequest struct {
+	StartDate string `query:"start_date" json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+type JobListRequest struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type JobResponse struct {
+	entity.ExportJob
+	// DownloadURL is a presigned link, created on each request for a
+	// completed job.
+	DownloadURL       *string    `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+type JobListResponse struct {
+	Jobs []entity.ExportJob `json:"jobs"`
+}
@@ -0,0 +1,7 @@
+package exports
+
+import "errors"
+
+var (
+	ErrJobNotFound = errors.New("export job not found")
+)
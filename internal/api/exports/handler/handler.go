@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/admin"
+	"sea-catering-backend/internal/api/exports"
+	"sea-catering-backend/internal/api/exports/service"
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/api/testimonials"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/export"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+)
+
+// streamTimeout bounds an export written straight into the response.
+const streamTimeout = 5 * time.Minute
+
+type ExportHandler struct {
+	exportService service.ExportService
+	validator     *validator.Validate
+	middleware    middleware.Interface
+	logger        *logger.Logger
+}
+
+func NewExportHandler(
+	exportService service.ExportService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		validator:     validator,
+		middleware:    middleware,
+		logger:        logger,
+	}
+}
+
+func (h *ExportHandler) RegisterRoutes(router fiber.Router) {
+	exportsGroup := router.Group("/exports")
+
+	admin := exportsGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/users", h.ExportUsers)
+	admin.Get("/subscriptions", h.ExportSubscriptions)
+	admin.Get("/testimonials", h.ExportTestimonials)
+	admin.Get("/revenue", h.ExportRevenue)
+	admin.Get("/jobs", h.ListJobs)
+	admin.Get("/jobs/:id", h.GetJob)
+}
+
+func (h *ExportHandler) ExportUsers(c *fiber.Ctx) error {
+	var req admin.UserListRequest
+	options, err := h.parseRequest(c, &req)
+	if options == nil {
+		return err
+	}
+	return h.export(c, entity.ExportUsers, options, req)
+}
+
+func (h *ExportHandler) ExportSubscriptions(c *fiber.Ctx) error {
+	var req admin.SubscriptionSearchRequest
+	options, err := h.parseRequest(c, &req)
+	if options == nil {
+		return err
+	}
+	return h.export(c, entity.ExportSubscriptions, options, req)
+}
+
+func (h *ExportHandler) ExportTestimonials(c *fiber.Ctx) error {
+	var req testimonials.ModerationQueueRequest
+	options, err := h.parseRequest(c, &req)
+	if options == nil {
+		return err
+	}
+	return h.export(c, entity.ExportTestimonials, options, req)
+}
+
+func (h *ExportHandler) ExportRevenue(c *fiber.Ctx) error {
+	var req exports.RevenueExportRequest
+	options, err := h.parseRequest(c, &req)
+	if options == nil {
+		return err
+	}
+	return h.export(c, entity.ExportRevenue, options, req)
+}
+
+// parseRequest reads the export options and the list filters into req. It
+// returns nil options once an error response has been written.
+func (h *ExportHandler) parseRequest(c *fiber.Ctx, req interface{}) (*exports.Options, error) {
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var options exports.Options
+	if err := c.QueryParser(&options); err != nil {
+		return nil, errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+	if err := c.QueryParser(req); err != nil {
+		return nil, errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(options); err != nil {
+		return nil, errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return nil, errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	return &options, nil
+}
+
+// export either queues a job, answering 202, or streams the file.
+func (h *ExportHandler) export(c *fiber.Ctx, dataset entity.ExportDataset, options *exports.Options, filters interface{}) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	adminID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	format := export.FormatCSV
+	if options.Format != "" {
+		format = export.Format(options.Format)
+	}
+
+	job, err := h.exportService.Prepare(ctx, dataset, format, filters, options.Async, adminID)
+	if err != nil {
+		return h.handleExportError(c, errHandler, requestID, err, c.Path(), "export_"+string(dataset))
+	}
+	if job != nil {
+		return errHandler.HandleSuccess(c, fiber.StatusAccepted, job)
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", dataset, time.Now().Format("20060102-150405"), format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	// The body is written after the handler returns, so the export runs on
+	// its own context. An error part-way can only be logged; the client
+	// sees a truncated file.
+	c.Status(fiber.StatusOK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+		defer cancel()
+
+		rows, err := h.exportService.Write(streamCtx, dataset, format, filters, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			h.logger.Error("Export stream failed", logger.Fields{
+				"error":      err.Error(),
+				"dataset":    dataset,
+				"rows":       rows,
+				"request_id": requestID,
+			})
+		}
+	})
+
+	return nil
+}
+
+func (h *ExportHandler) ListJobs(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req exports.JobListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.exportService.ListJobs(ctx, req)
+	if err != nil {
+		return h.handleExportError(c, errHandler, requestID, err, c.Path(), "list_export_jobs")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *ExportHandler) GetJob(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	result, err := h.exportService.GetJob(ctx, c.Params("id"))
+	if err != nil {
+		return h.handleExportError(c, errHandler, requestID, err, c.Path(), "get_export_job")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *ExportHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *ExportHandler) handleExportError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case exports.ErrJobNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Export job")
+	case export.ErrUnsupportedFormat, metrics.ErrInvalidDateRange, metrics.ErrDateRangeTooLong:
+		return errHandler.HandleBadRequest(c, requestID, err.Error())
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
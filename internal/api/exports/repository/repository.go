@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"sea-catering-backend/internal/api/exports"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/jobs"
+)
+
+type ExportRepository interface {
+	Create(ctx context.Context, job *entity.ExportJob) error
+	GetByID(ctx context.Context, id string) (*entity.ExportJob, error)
+	List(ctx context.Context, limit int) ([]entity.ExportJob, error)
+	// Claim takes the oldest pending job, or a running one whose worker
+	// stopped before finishing, and locks it for lockFor.
+	Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.ExportJob, error)
+	MarkCompleted(ctx context.Context, id, fileKey string, rowCount int, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id, lastError string, expiresAt time.Time) error
+	ListExpired(ctx context.Context, before time.Time) ([]entity.ExportJob, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type exportRepository struct {
+	db *database.DB
+}
+
+func NewExportRepository(db *sqlx.DB) ExportRepository {
+	return &exportRepository{db: database.New(db)}
+}
+
+const jobColumns = `
+	id, dataset, format, filters, status, requested_by, attempts, row_count, file_key,
+	last_error, locked_until, started_at, completed_at, expires_at, created_at
+`
+
+func (r *exportRepository) Create(ctx context.Context, job *entity.ExportJob) error {
+	query := `
+		INSERT INTO export_jobs (id, dataset, format, filters, status, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		job.ID, job.Dataset, job.Format, []byte(job.Filters), job.Status, job.RequestedBy, job.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) GetByID(ctx context.Context, id string) (*entity.ExportJob, error) {
+	query := fmt.Sprintf(`SELECT %s FROM export_jobs WHERE id = $1`, jobColumns)
+
+	var job entity.ExportJob
+	if err := r.db.GetContext(ctx, &job, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exports.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *exportRepository) List(ctx context.Context, limit int) ([]entity.ExportJob, error) {
+	query := fmt.Sprintf(`SELECT %s FROM export_jobs ORDER BY created_at DESC, id DESC LIMIT $1`, jobColumns)
+
+	jobs := []entity.ExportJob{}
+	if err := r.db.SelectContext(ctx, &jobs, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *exportRepository) Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.ExportJob, error) {
+	return jobs.Claim[entity.ExportJob](ctx, r.db, jobs.Table{
+		Name:    "export_jobs",
+		Columns: jobColumns,
+		Order:   "created_at",
+	}, lockFor, maxAttempts)
+}
+
+func (r *exportRepository) MarkCompleted(ctx context.Context, id, fileKey string, rowCount int, expiresAt time.Time) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'completed', file_key = $2, row_count = $3, completed_at = $4, expires_at = $5,
+		    locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, fileKey, rowCount, time.Now(), expiresAt); err != nil {
+		return fmt.Errorf("failed to complete export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) MarkFailed(ctx context.Context, id, lastError string, expiresAt time.Time) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'failed', last_error = $2, completed_at = $3, expires_at = $4, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, time.Now(), expiresAt); err != nil {
+		return fmt.Errorf("failed to fail export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) ListExpired(ctx context.Context, before time.Time) ([]entity.ExportJob, error) {
+	query := fmt.Sprintf(`SELECT %s FROM export_jobs WHERE expires_at < $1 ORDER BY expires_at LIMIT 100`, jobColumns)
+
+	jobs := []entity.ExportJob{}
+	if err := r.db.SelectContext(ctx, &jobs, query, before); err != nil {
+		return nil, fmt.Errorf("failed to list expired export jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *exportRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM export_jobs WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete export job: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,575 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sea-catering-backend/internal/api/admin"
+	adminRepository "sea-catering-backend/internal/api/admin/repository"
+	"sea-catering-backend/internal/api/exports"
+	"sea-catering-backend/internal/api/exports/repository"
+	"sea-catering-backend/internal/api/metrics"
+	metricsRepository "sea-catering-backend/internal/api/metrics/repository"
+	"sea-catering-backend/internal/api/testimonials"
+	testimonialRepository "sea-catering-backend/internal/api/testimonials/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/export"
+	"sea-catering-backend/pkg/jobs"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/s3"
+	"sea-catering-backend/pkg/utils"
+)
+
+type ExportService interface {
+	Start(ctx context.Context)
+	Stop()
+
+	// Prepare checks the filters and decides how the export is delivered.
+	// It returns a queued job when the export is too large to stream or an
+	// async export was asked for, and nil when the caller should stream it
+	// with Write.
+	Prepare(ctx context.Context, dataset entity.ExportDataset, format export.Format, filters interface{}, async bool, requestedBy string) (*entity.ExportJob, error)
+	// Write streams every row matching filters to w and returns how many
+	// were written.
+	Write(ctx context.Context, dataset entity.ExportDataset, format export.Format, filters interface{}, w io.Writer) (int, error)
+
+	GetJob(ctx context.Context, id string) (*exports.JobResponse, error)
+	ListJobs(ctx context.Context, req exports.JobListRequest) (*exports.JobListResponse, error)
+}
+
+type Config struct {
+	// SyncRowLimit is the largest export streamed in the response. Bigger
+	// ones become background jobs.
+	SyncRowLimit int
+	Workers      int
+	PollInterval time.Duration
+	// JobTimeout bounds building and uploading one file.
+	JobTimeout time.Duration
+	// LinkTTL is how long a download link stays valid.
+	LinkTTL time.Duration
+	// Retention is how long finished files are kept.
+	Retention time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		SyncRowLimit: 5000,
+		Workers:      1,
+		PollInterval: 5 * time.Second,
+		JobTimeout:   30 * time.Minute,
+		LinkTTL:      time.Hour,
+		Retention:    7 * 24 * time.Hour,
+	}
+
+	if limit, err := strconv.Atoi(os.Getenv("EXPORT_SYNC_ROW_LIMIT")); err == nil && limit >= 0 {
+		config.SyncRowLimit = limit
+	}
+
+	if workers, err := strconv.Atoi(os.Getenv("EXPORT_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv("EXPORT_JOB_TIMEOUT")); err == nil && timeout > 0 {
+		config.JobTimeout = timeout
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("EXPORT_LINK_TTL")); err == nil && ttl > 0 {
+		config.LinkTTL = ttl
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv("EXPORT_RETENTION")); err == nil && retention > 0 {
+		config.Retention = retention
+	}
+
+	return config
+}
+
+type exportService struct {
+	exportRepo      repository.ExportRepository
+	adminRepo       adminRepository.AdminRepository
+	testimonialRepo testimonialRepository.TestimonialRepository
+	metricsRepo     metricsRepository.MetricsRepository
+	s3              s3.Interface
+	utils           utils.Interface
+	config          *Config
+	logger          *logger.Logger
+
+	runner *jobs.Runner[entity.ExportJob]
+}
+
+func NewExportService(
+	exportRepo repository.ExportRepository,
+	adminRepo adminRepository.AdminRepository,
+	testimonialRepo testimonialRepository.TestimonialRepository,
+	metricsRepo metricsRepository.MetricsRepository,
+	s3 s3.Interface,
+	utils utils.Interface,
+	config *Config,
+	logger *logger.Logger,
+) ExportService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	s := &exportService{
+		exportRepo:      exportRepo,
+		adminRepo:       adminRepo,
+		testimonialRepo: testimonialRepo,
+		metricsRepo:     metricsRepo,
+		s3:              s3,
+		utils:           utils,
+		config:          config,
+		logger:          logger,
+	}
+	s.runner = jobs.NewRunner[entity.ExportJob](s, jobs.Config{
+		Name:         "Export",
+		Workers:      config.Workers,
+		PollInterval: config.PollInterval,
+		JobTimeout:   config.JobTimeout,
+	}, logger)
+
+	return s
+}
+
+func (s *exportService) Prepare(ctx context.Context, dataset entity.ExportDataset, format export.Format, filters interface{}, async bool, requestedBy string) (*entity.ExportJob, error) {
+	if format.Extension() == "" {
+		return nil, export.ErrUnsupportedFormat
+	}
+
+	rows, err := s.count(ctx, dataset, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if !async && rows <= s.config.SyncRowLimit {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export filters: %w", err)
+	}
+
+	job := &entity.ExportJob{
+		ID:        s.utils.GenerateULID(),
+		Dataset:   dataset,
+		Format:    string(format),
+		Filters:   encoded,
+		Status:    entity.ExportJobPending,
+		CreatedAt: time.Now(),
+	}
+	if requestedBy != "" {
+		job.RequestedBy = &requestedBy
+	}
+
+	if err := s.exportRepo.Create(ctx, job); err != nil {
+		s.logger.Error("Failed to queue export", logger.Fields{
+			"error":   err.Error(),
+			"dataset": dataset,
+		})
+		return nil, err
+	}
+
+	s.runner.Nudge()
+
+	s.logger.Info("Export queued", logger.Fields{
+		"job_id":       job.ID,
+		"dataset":      dataset,
+		"format":       format,
+		"rows":         rows,
+		"requested_by": requestedBy,
+	})
+
+	return job, nil
+}
+
+func (s *exportService) count(ctx context.Context, dataset entity.ExportDataset, filters interface{}) (int, error) {
+	switch req := filters.(type) {
+	case admin.UserListRequest:
+		req.Page, req.Limit = 1, 1
+		_, meta, err := s.adminRepo.GetAllUsers(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		return meta.Total, nil
+	case admin.SubscriptionSearchRequest:
+		req.Page, req.Limit = 1, 1
+		_, meta, err := s.adminRepo.SearchSubscriptions(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		return meta.Total, nil
+	case testimonials.ModerationQueueRequest:
+		req.Page, req.Limit = 1, 1
+		_, meta, err := s.testimonialRepo.ListForModeration(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		return meta.Total, nil
+	case exports.RevenueExportRequest:
+		startDate, endDate, err := parseRange(req.StartDate, req.EndDate)
+		if err != nil {
+			return 0, err
+		}
+		return int(endDate.Sub(startDate).Hours()/24) + 1, nil
+	default:
+		return 0, fmt.Errorf("unexpected filters %T for %s export", filters, dataset)
+	}
+}
+
+func (s *exportService) Write(ctx context.Context, dataset entity.ExportDataset, format export.Format, filters interface{}, w io.Writer) (int, error) {
+	out, err := export.NewWriter(w, format, string(dataset))
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int
+	switch req := filters.(type) {
+	case admin.UserListRequest:
+		rows, err = s.writeUsers(ctx, req, out)
+	case admin.SubscriptionSearchRequest:
+		rows, err = s.writeSubscriptions(ctx, req, out)
+	case testimonials.ModerationQueueRequest:
+		rows, err = s.writeTestimonials(ctx, req, out)
+	case exports.RevenueExportRequest:
+		rows, err = s.writeRevenue(ctx, req, out)
+	default:
+		err = fmt.Errorf("unexpected filters %T for %s export", filters, dataset)
+	}
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, out.Close()
+}
+
+func (s *exportService) writeUsers(ctx context.Context, req admin.UserListRequest, out export.Writer) (int, error) {
+	err := out.WriteHeader("id", "name", "email", "phone", "role", "is_active", "is_verified",
+		"subscription_count", "total_spent", "last_login_at", "created_at")
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = s.adminRepo.EachUser(ctx, req, func(u *admin.UserResponse) error {
+		rows++
+		return out.WriteRow(u.ID, u.Name, u.Email, u.Phone, u.Role, u.IsActive, u.IsVerified,
+			u.SubscriptionCount, u.TotalSpent, u.LastLoginAt, u.CreatedAt)
+	})
+	return rows, err
+}
+
+func (s *exportService) writeSubscriptions(ctx context.Context, req admin.SubscriptionSearchRequest, out export.Writer) (int, error) {
+	err := out.WriteHeader("id", "user_id", "user_name", "user_email", "user_phone", "meal_plan_id",
+		"meal_plan_name", "meal_types", "delivery_days", "total_price", "status",
+		"pause_start_date", "pause_end_date", "created_at", "updated_at")
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = s.adminRepo.EachSubscription(ctx, req, func(sub *admin.SubscriptionSearchResponse) error {
+		rows++
+		return out.WriteRow(sub.ID, sub.UserID, sub.UserName, sub.UserEmail, sub.UserPhone, sub.MealPlanID,
+			sub.MealPlanName, strings.Join(sub.MealTypes, ", "), strings.Join(sub.DeliveryDays, ", "),
+			sub.TotalPrice, sub.Status, sub.PauseStart, sub.PauseEnd, sub.CreatedAt, sub.UpdatedAt)
+	})
+	return rows, err
+}
+
+func (s *exportService) writeTestimonials(ctx context.Context, req testimonials.ModerationQueueRequest, out export.Writer) (int, error) {
+	err := out.WriteHeader("id", "customer_name", "rating", "message", "status", "is_verified",
+		"spam_score", "rejection_reason", "meal_plan_id", "user_id", "moderated_at", "created_at")
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = s.testimonialRepo.EachForModeration(ctx, req, func(t *entity.Testimonial) error {
+		var rejectionReason *string
+		if t.RejectionReason != nil {
+			reason := string(*t.RejectionReason)
+			rejectionReason = &reason
+		}
+
+		rows++
+		return out.WriteRow(t.ID, t.CustomerName, t.Rating, t.Message, string(t.Status), t.IsVerified,
+			t.SpamScore, rejectionReason, t.MealPlanID, t.UserID, t.ModeratedAt, t.CreatedAt)
+	})
+	return rows, err
+}
+
+// writeRevenue writes one row per recorded day, with a column for the MRR
+// of every plan that had revenue in the range.
+func (s *exportService) writeRevenue(ctx context.Context, req exports.RevenueExportRequest, out export.Writer) (int, error) {
+	startDate, endDate, err := parseRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return 0, err
+	}
+
+	days, err := s.metricsRepo.ListRange(ctx, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	var planIDs []string
+	planNames := map[string]string{}
+	for _, day := range days {
+		for _, plan := range day.RevenueByPlan {
+			if _, ok := planNames[plan.MealPlanID]; !ok {
+				planIDs = append(planIDs, plan.MealPlanID)
+			}
+			planNames[plan.MealPlanID] = plan.MealPlanName
+		}
+	}
+	sort.Slice(planIDs, func(i, j int) bool {
+		return planNames[planIDs[i]] < planNames[planIDs[j]]
+	})
+
+	columns := []string{"date", "mrr", "active_subscriptions", "paused_subscriptions",
+		"cancelled_subscriptions", "new_subscriptions", "churned_subscriptions", "reactivations", "source"}
+	for _, id := range planIDs {
+		columns = append(columns, "mrr_"+planNames[id])
+	}
+	if err := out.WriteHeader(columns...); err != nil {
+		return 0, err
+	}
+
+	for _, day := range days {
+		values := []interface{}{day.Date.Format("2006-01-02"), day.MRR, day.ActiveSubscriptions,
+			day.PausedSubscriptions, day.CancelledSubscriptions, day.NewSubscriptions,
+			day.ChurnedSubscriptions, day.Reactivations, string(day.Source)}
+
+		mrr := map[string]float64{}
+		for _, plan := range day.RevenueByPlan {
+			mrr[plan.MealPlanID] = plan.MRR
+		}
+		for _, id := range planIDs {
+			values = append(values, mrr[id])
+		}
+
+		if err := out.WriteRow(values...); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(days), nil
+}
+
+func (s *exportService) GetJob(ctx context.Context, id string) (*exports.JobResponse, error) {
+	job, err := s.exportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &exports.JobResponse{ExportJob: *job}
+	if job.Status != entity.ExportJobCompleted || job.FileKey == nil {
+		return result, nil
+	}
+
+	url, err := s.s3.GetPresignedURL(*job.FileKey, s.config.LinkTTL)
+	if err != nil {
+		s.logger.Error("Failed to sign export download link", logger.Fields{
+			"error":  err.Error(),
+			"job_id": job.ID,
+		})
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.config.LinkTTL)
+	result.DownloadURL = &url
+	result.DownloadExpiresAt = &expiresAt
+
+	return result, nil
+}
+
+func (s *exportService) ListJobs(ctx context.Context, req exports.JobListRequest) (*exports.JobListResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	jobs, err := s.exportRepo.List(ctx, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exports.JobListResponse{Jobs: jobs}, nil
+}
+
+func (s *exportService) Start(ctx context.Context) {
+	s.runner.Start(ctx)
+}
+
+func (s *exportService) Stop() {
+	s.runner.Stop()
+}
+
+func (s *exportService) Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.ExportJob, error) {
+	return s.exportRepo.Claim(ctx, lockFor, maxAttempts)
+}
+
+// Run writes the file and uploads it as it is produced, so a large export
+// is never held in memory.
+func (s *exportService) Run(ctx context.Context, job *entity.ExportJob) error {
+	filters, err := decodeFilters(job.Dataset, job.Filters)
+	if err != nil {
+		return err
+	}
+
+	format := export.Format(job.Format)
+	key := fmt.Sprintf("exports/%s/%s.%s", job.Dataset, job.ID, format.Extension())
+
+	var rows int
+	err = s3.StreamPrivateFile(s.s3, key, format.ContentType(), func(w io.Writer) error {
+		var err error
+		rows, err = s.Write(ctx, job.Dataset, format, filters, w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	job.FileKey = &key
+	job.RowCount = &rows
+	return nil
+}
+
+func (s *exportService) Record(ctx context.Context, job *entity.ExportJob, err error) {
+	expiresAt := time.Now().Add(s.config.Retention)
+
+	if err != nil {
+		s.logger.Error("Export job failed", logger.Fields{
+			"error":   err.Error(),
+			"job_id":  job.ID,
+			"dataset": job.Dataset,
+		})
+		if err := s.exportRepo.MarkFailed(ctx, job.ID, err.Error(), expiresAt); err != nil {
+			s.logger.Error("Failed to record export failure", logger.Fields{
+				"error":  err.Error(),
+				"job_id": job.ID,
+			})
+		}
+		return
+	}
+
+	if err := s.exportRepo.MarkCompleted(ctx, job.ID, *job.FileKey, *job.RowCount, expiresAt); err != nil {
+		s.logger.Error("Failed to record export completion", logger.Fields{
+			"error":  err.Error(),
+			"job_id": job.ID,
+		})
+		return
+	}
+
+	s.logger.Info("Export job completed", logger.Fields{
+		"job_id":  job.ID,
+		"dataset": job.Dataset,
+		"rows":    *job.RowCount,
+	})
+}
+
+func decodeFilters(dataset entity.ExportDataset, raw json.RawMessage) (interface{}, error) {
+	var (
+		filters interface{}
+		err     error
+	)
+
+	switch dataset {
+	case entity.ExportUsers:
+		var req admin.UserListRequest
+		err = json.Unmarshal(raw, &req)
+		filters = req
+	case entity.ExportSubscriptions:
+		var req admin.SubscriptionSearchRequest
+		err = json.Unmarshal(raw, &req)
+		filters = req
+	case entity.ExportTestimonials:
+		var req testimonials.ModerationQueueRequest
+		err = json.Unmarshal(raw, &req)
+		filters = req
+	case entity.ExportRevenue:
+		var req exports.RevenueExportRequest
+		err = json.Unmarshal(raw, &req)
+		filters = req
+	default:
+		return nil, fmt.Errorf("unknown export dataset %q", dataset)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode export filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+// Purge deletes files past their retention along with their jobs. A job
+// whose file could not be deleted is kept for the next run.
+func (s *exportService) Purge(ctx context.Context) {
+	jobs, err := s.exportRepo.ListExpired(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to list expired exports", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	purged := 0
+	for _, job := range jobs {
+		if job.FileKey != nil {
+			if err := s.s3.DeleteFile(*job.FileKey); err != nil {
+				s.logger.Error("Failed to delete export file", logger.Fields{
+					"error":  err.Error(),
+					"job_id": job.ID,
+				})
+				continue
+			}
+		}
+
+		if err := s.exportRepo.Delete(ctx, job.ID); err != nil {
+			s.logger.Error("Failed to delete export job", logger.Fields{
+				"error":  err.Error(),
+				"job_id": job.ID,
+			})
+			continue
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.Info("Expired exports purged", logger.Fields{
+			"purged": purged,
+		})
+	}
+}
+
+// maxRevenueDays matches the longest range the daily metrics endpoint
+// returns.
+const maxRevenueDays = 366
+
+func parseRange(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.ParseInLocation("2006-01-02", start, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	endDate, err := time.ParseInLocation("2006-01-02", end, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, metrics.ErrInvalidDateRange
+	}
+
+	if endDate.Sub(startDate) > maxRevenueDays*24*time.Hour {
+		return time.Time{}, time.Time{}, metrics.ErrDateRangeTooLong
+	}
+
+	return startDate, endDate, nil
+}
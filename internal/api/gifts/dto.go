@@ -0,0 +1,53 @@
+package gifts
+
+import "sea-catering-backend/internal/entity"
+
+type PurchaseGiftRequest struct {
+	RecipientName  string               `json:"recipient_name" validate:"required,min=2,max=100"`
+	RecipientEmail string               `json:"recipient_email" validate:"required,email"`
+	Message        string               `json:"message,omitempty" validate:"omitempty,max=500"`
+	MealPlanID     string               `json:"meal_plan_id" validate:"required"`
+	MealTypes      []entity.MealType    `json:"meal_types" validate:"required,min=1"`
+	DeliveryDays   []entity.DeliveryDay `json:"delivery_days" validate:"required,min=1"`
+	DurationMonths int                  `json:"duration_months" validate:"required,oneof=1 3 6 12"`
+}
+
+type RedeemGiftRequest struct {
+	Code            string `json:"code" validate:"required,min=6,max=20"`
+	DeliveryAddress string `json:"delivery_address" validate:"required,min=10,max=500"`
+	Allergies       string `json:"allergies,omitempty" validate:"omitempty,max=500"`
+}
+
+type ResendGiftRequest struct {
+	RecipientEmail string `json:"recipient_email,omitempty" validate:"omitempty,email"`
+}
+
+type OutstandingGiftsRequest struct {
+	Page  int    `query:"page" validate:"omitempty,min=1"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Email string `query:"email" validate:"omitempty,max=255"`
+}
+
+type GiftListResponse struct {
+	Gifts []entity.GiftSubscriptionWithDetails `json:"gifts"`
+	Meta  *PaginationMeta                      `json:"meta"`
+}
+
+type RedeemGiftResponse struct {
+	Gift         entity.GiftSubscription        `json:"gift"`
+	Subscription entity.SubscriptionWithDetails `json:"subscription"`
+}
+
+type ProcessExpiredGiftsResponse struct {
+	ExpiredGifts       int `json:"expired_gifts"`
+	EndedSubscriptions int `json:"ended_subscriptions"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
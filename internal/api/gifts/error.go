@@ -0,0 +1,20 @@
+package gifts
+
+import "errors"
+
+var (
+	ErrGiftNotFound           = errors.New("gift not found")
+	ErrGiftAlreadyRedeemed    = errors.New("gift has already been redeemed")
+	ErrGiftExpired            = errors.New("gift code has expired")
+	ErrGiftCancelled          = errors.New("gift has been cancelled")
+	ErrInvalidMealPlan        = errors.New("invalid meal plan")
+	ErrInvalidMealTypes       = errors.New("invalid meal types")
+	ErrInvalidDeliveryDays    = errors.New("invalid delivery days")
+	ErrUnauthorizedAccess     = errors.New("unauthorized access to gift")
+	ErrCannotRedeemOwnGift    = errors.New("buyers cannot redeem their own gift")
+	ErrResendTooSoon          = errors.New("gift email was sent too recently")
+	ErrResendLimitReached     = errors.New("gift email resend limit reached")
+	ErrGiftNotResendable      = errors.New("only unredeemed gifts can be resent")
+	ErrGiftNotPaid            = errors.New("gift has not been paid for yet")
+	ErrGiftNotAwaitingPayment = errors.New("gift is not awaiting payment")
+)
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/gifts"
+	"sea-catering-backend/internal/api/gifts/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type GiftHandler struct {
+	giftService service.GiftService
+	validator   *validator.Validate
+	middleware  middleware.Interface
+	logger      *logger.Logger
+}
+
+func NewGiftHandler(
+	giftService service.GiftService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *GiftHandler {
+	return &GiftHandler{
+		giftService: giftService,
+		validator:   validator,
+		middleware:  middleware,
+		logger:      logger,
+	}
+}
+
+func (h *GiftHandler) RegisterRoutes(router fiber.Router) {
+	giftsGroup := router.Group("/gifts")
+
+	admin := giftsGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/outstanding", h.GetOutstandingGifts)
+	admin.Post("/process-expired", h.ProcessExpiredGifts)
+	admin.Post("/:id/resend", h.AdminResendGift)
+	admin.Post("/:id/confirm-payment", h.ConfirmGiftPayment)
+
+	protected := giftsGroup.Use(h.middleware.AuthMiddleware())
+	protected.Post("/", h.PurchaseGift)
+	protected.Get("/my", h.GetMyGifts)
+	protected.Post("/redeem", h.RedeemGift)
+	protected.Post("/:id/resend", h.ResendGift)
+}
+
+func (h *GiftHandler) PurchaseGift(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req gifts.PurchaseGiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	gift, err := h.giftService.PurchaseGift(ctx, userID, req)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "purchase_gift")
+	}
+
+	return response.Created(c, gift, "Gift ordered. The recipient will be emailed their gift code once payment is confirmed")
+}
+
+func (h *GiftHandler) GetMyGifts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	giftList, err := h.giftService.GetPurchasedGifts(ctx, userID)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "get_my_gifts")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, giftList)
+}
+
+func (h *GiftHandler) RedeemGift(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req gifts.RedeemGiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.giftService.RedeemGift(ctx, userID, req)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "redeem_gift")
+	}
+
+	return response.Created(c, result, "Gift redeemed successfully. Your subscription is now active")
+}
+
+func (h *GiftHandler) ResendGift(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	giftID := c.Params("id")
+	if giftID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Gift ID is required")
+	}
+
+	var req gifts.ResendGiftRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	if err := h.giftService.ResendGift(ctx, giftID, userID, req); err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "resend_gift")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Gift email resent successfully",
+	})
+}
+
+func (h *GiftHandler) AdminResendGift(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	giftID := c.Params("id")
+	if giftID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Gift ID is required")
+	}
+
+	if err := h.giftService.AdminResendGift(ctx, giftID); err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "admin_resend_gift")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Gift email resent successfully",
+	})
+}
+
+func (h *GiftHandler) ConfirmGiftPayment(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	giftID := c.Params("id")
+	if giftID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Gift ID is required")
+	}
+
+	gift, err := h.giftService.ConfirmGiftPayment(ctx, giftID)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "confirm_gift_payment")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, gift)
+}
+
+func (h *GiftHandler) GetOutstandingGifts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req gifts.OutstandingGiftsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.giftService.GetOutstandingGifts(ctx, req)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "get_outstanding_gifts")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *GiftHandler) ProcessExpiredGifts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	result, err := h.giftService.ProcessExpiredGifts(ctx)
+	if err != nil {
+		return h.handleGiftError(c, errHandler, requestID, err, c.Path(), "process_expired_gifts")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *GiftHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *GiftHandler) handleGiftError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case gifts.ErrGiftNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Gift")
+	case gifts.ErrInvalidMealPlan:
+		return errHandler.HandleBadRequest(c, requestID, "Selected meal plan is invalid or inactive")
+	case gifts.ErrInvalidMealTypes:
+		return errHandler.HandleBadRequest(c, requestID, "Invalid meal types selected. Please choose from: breakfast, lunch, dinner")
+	case gifts.ErrInvalidDeliveryDays:
+		return errHandler.HandleBadRequest(c, requestID, "Invalid delivery days selected. Please choose valid weekdays")
+	case gifts.ErrUnauthorizedAccess:
+		return errHandler.HandleForbidden(c, requestID, "You don't have permission to access this gift")
+	case gifts.ErrCannotRedeemOwnGift:
+		return errHandler.HandleForbidden(c, requestID, "You cannot redeem a gift you purchased")
+	case gifts.ErrGiftAlreadyRedeemed:
+		return response.Conflict(c, "This gift code has already been redeemed")
+	case gifts.ErrGiftExpired:
+		return response.UnprocessableEntity(c, "This gift code has expired")
+	case gifts.ErrGiftCancelled:
+		return response.UnprocessableEntity(c, "This gift has been cancelled")
+	case gifts.ErrGiftNotPaid:
+		return response.UnprocessableEntity(c, "This gift has not been paid for yet")
+	case gifts.ErrGiftNotAwaitingPayment:
+		return response.Conflict(c, "This gift is not awaiting payment")
+	case gifts.ErrGiftNotResendable:
+		return response.UnprocessableEntity(c, "Only unredeemed gifts can be resent")
+	case gifts.ErrResendTooSoon:
+		return errHandler.HandleBadRequest(c, requestID, "Please wait a few minutes before resending the gift email")
+	case gifts.ErrResendLimitReached:
+		return errHandler.HandleBadRequest(c, requestID, "This gift email has been resent too many times")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
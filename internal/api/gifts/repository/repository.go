@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/gifts"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/logger"
+)
+
+type GiftRepository interface {
+	Create(ctx context.Context, gift *entity.GiftSubscription) error
+	GetByID(ctx context.Context, id string) (*entity.GiftSubscription, error)
+	GetByCode(ctx context.Context, code string) (*entity.GiftSubscription, error)
+	GetByBuyerID(ctx context.Context, buyerUserID string) ([]entity.GiftSubscriptionWithDetails, error)
+	GetOutstanding(ctx context.Context, req gifts.OutstandingGiftsRequest) ([]entity.GiftSubscriptionWithDetails, *gifts.PaginationMeta, error)
+	Redeem(ctx context.Context, gift *entity.GiftSubscription, subscription *entity.Subscription) error
+	MarkSent(ctx context.Context, id, recipientEmail string) error
+	// MarkPaid issues a gift that is awaiting payment, starting its expiry
+	// from now.
+	MarkPaid(ctx context.Context, gift *entity.GiftSubscription, expiresAt time.Time) error
+	ExpireOverdue(ctx context.Context) (int, error)
+	GetEndedSubscriptionIDs(ctx context.Context) ([]string, error)
+}
+
+type giftRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+func NewGiftRepository(db *sqlx.DB, logger *logger.Logger) GiftRepository {
+	return &giftRepository{
+		db:     database.New(db),
+		logger: logger,
+	}
+}
+
+const giftColumns = `
+	g.id, g.code, g.buyer_user_id, g.recipient_name, g.recipient_email,
+	COALESCE(g.message, '') as message, g.meal_plan_id, g.meal_types, g.delivery_days,
+	g.duration_months, g.total_price, g.status, g.expires_at,
+	g.redeemed_by_user_id, g.subscription_id, g.redeemed_at, g.last_sent_at,
+	g.send_count, g.created_at, g.updated_at
+`
+
+func (r *giftRepository) Create(ctx context.Context, gift *entity.GiftSubscription) error {
+	query := `
+		INSERT INTO gift_subscriptions (
+			id, code, buyer_user_id, recipient_name, recipient_email, message,
+			meal_plan_id, meal_types, delivery_days, duration_months, total_price,
+			status, expires_at, last_sent_at, send_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		gift.ID, gift.Code, gift.BuyerUserID, gift.RecipientName, gift.RecipientEmail, gift.Message,
+		gift.MealPlanID, pq.Array(gift.MealTypes), pq.Array(gift.DeliveryDays), gift.DurationMonths, gift.TotalPrice,
+		gift.Status, gift.ExpiresAt, gift.LastSentAt, gift.SendCount, gift.CreatedAt, gift.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to create gift subscription", logger.Fields{
+			"error":    err.Error(),
+			"gift_id":  gift.ID,
+			"buyer_id": gift.BuyerUserID,
+		})
+		return fmt.Errorf("failed to create gift subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *giftRepository) GetByID(ctx context.Context, id string) (*entity.GiftSubscription, error) {
+	query := fmt.Sprintf(`SELECT %s FROM gift_subscriptions g WHERE g.id = $1`, giftColumns)
+
+	gift, err := r.scanGift(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, gifts.ErrGiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get gift subscription: %w", err)
+	}
+
+	return gift, nil
+}
+
+func (r *giftRepository) GetByCode(ctx context.Context, code string) (*entity.GiftSubscription, error) {
+	query := fmt.Sprintf(`SELECT %s FROM gift_subscriptions g WHERE g.code = $1`, giftColumns)
+
+	gift, err := r.scanGift(r.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, gifts.ErrGiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get gift subscription by code: %w", err)
+	}
+
+	return gift, nil
+}
+
+func (r *giftRepository) GetByBuyerID(ctx context.Context, buyerUserID string) ([]entity.GiftSubscriptionWithDetails, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+			mp.name, mp.description, mp.price, u.name, u.email
+		FROM gift_subscriptions g
+		JOIN meal_plans mp ON g.meal_plan_id = mp.id
+		JOIN users u ON g.buyer_user_id = u.id
+		WHERE g.buyer_user_id = $1
+		ORDER BY g.created_at DESC
+	`, giftColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, buyerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gifts by buyer: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanGiftsWithDetails(rows)
+}
+
+func (r *giftRepository) GetOutstanding(ctx context.Context, req gifts.OutstandingGiftsRequest) ([]entity.GiftSubscriptionWithDetails, *gifts.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereConditions := []string{"g.status IN ('pending_payment', 'issued')"}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Email != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf(
+			"(LOWER(g.recipient_email) LIKE LOWER($%d) OR LOWER(u.email) LIKE LOWER($%d))", argIndex, argIndex))
+		args = append(args, "%"+req.Email+"%")
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(whereConditions, " AND ")
+
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM gift_subscriptions g
+		JOIN users u ON g.buyer_user_id = u.id
+		%s
+	`, whereClause)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count outstanding gifts: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+			mp.name, mp.description, mp.price, u.name, u.email
+		FROM gift_subscriptions g
+		JOIN meal_plans mp ON g.meal_plan_id = mp.id
+		JOIN users u ON g.buyer_user_id = u.id
+		%s
+		ORDER BY g.expires_at ASC
+		LIMIT $%d OFFSET $%d
+	`, giftColumns, whereClause, argIndex, argIndex+1)
+
+	args = append(args, req.Limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query outstanding gifts: %w", err)
+	}
+	defer rows.Close()
+
+	giftList, err := r.scanGiftsWithDetails(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &gifts.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return giftList, meta, nil
+}
+
+func (r *giftRepository) Redeem(ctx context.Context, gift *entity.GiftSubscription, subscription *entity.Subscription) error {
+	claimQuery := `
+		UPDATE gift_subscriptions
+		SET status = 'redeemed', redeemed_by_user_id = $2, redeemed_at = $3, updated_at = $3
+		WHERE id = $1 AND status = 'issued' AND expires_at > $3
+	`
+
+	insertQuery := `
+		INSERT INTO subscriptions (
+			id, user_id, meal_plan_id, meal_types, delivery_days,
+			allergies, total_price, status, delivery_address, gift_id,
+			ends_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	now := time.Now()
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, claimQuery, gift.ID, subscription.UserID, now)
+		if err != nil {
+			return fmt.Errorf("failed to claim gift: %w", err)
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return gifts.ErrGiftAlreadyRedeemed
+		}
+
+		_, err = r.db.ExecContext(ctx, insertQuery,
+			subscription.ID, subscription.UserID, subscription.MealPlanID,
+			pq.Array(subscription.MealTypes), pq.Array(subscription.DeliveryDays),
+			subscription.Allergies, subscription.TotalPrice, subscription.Status,
+			subscription.DeliveryAddress, subscription.GiftID, subscription.EndsAt,
+			subscription.CreatedAt, subscription.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create gifted subscription: %w", err)
+		}
+
+		_, err = r.db.ExecContext(ctx, `UPDATE gift_subscriptions SET subscription_id = $2 WHERE id = $1`, gift.ID, subscription.ID)
+		if err != nil {
+			return fmt.Errorf("failed to link gifted subscription: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	gift.Status = entity.GiftStatusRedeemed
+	gift.RedeemedByUserID = &subscription.UserID
+	gift.SubscriptionID = &subscription.ID
+	gift.RedeemedAt = &now
+	gift.UpdatedAt = now
+
+	return nil
+}
+
+func (r *giftRepository) MarkSent(ctx context.Context, id, recipientEmail string) error {
+	query := `
+		UPDATE gift_subscriptions
+		SET recipient_email = $2, last_sent_at = $3, send_count = send_count + 1, updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, recipientEmail, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark gift as sent: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return gifts.ErrGiftNotFound
+	}
+
+	return nil
+}
+
+func (r *giftRepository) MarkPaid(ctx context.Context, gift *entity.GiftSubscription, expiresAt time.Time) error {
+	query := `
+		UPDATE gift_subscriptions
+		SET status = 'issued', expires_at = $2, last_sent_at = $3, send_count = 1, updated_at = $3
+		WHERE id = $1 AND status = 'pending_payment'
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, gift.ID, expiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark gift as paid: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return gifts.ErrGiftNotAwaitingPayment
+	}
+
+	gift.Status = entity.GiftStatusIssued
+	gift.ExpiresAt = expiresAt
+	gift.LastSentAt = &now
+	gift.SendCount = 1
+	gift.UpdatedAt = now
+
+	return nil
+}
+
+func (r *giftRepository) ExpireOverdue(ctx context.Context) (int, error) {
+	query := `
+		UPDATE gift_subscriptions
+		SET status = 'expired', updated_at = NOW()
+		WHERE status = 'issued' AND expires_at < NOW()
+	`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire overdue gifts: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+func (r *giftRepository) GetEndedSubscriptionIDs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT id FROM subscriptions
+		WHERE gift_id IS NOT NULL AND ends_at < NOW() AND status NOT IN ('cancelled', 'expired')
+		AND deleted_at IS NULL
+	`
+
+	var ids []string
+	if err := r.db.SelectContext(ctx, &ids, query); err != nil {
+		return nil, fmt.Errorf("failed to get ended gifted subscriptions: %w", err)
+	}
+
+	return ids, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *giftRepository) scanGift(row rowScanner, extra ...interface{}) (*entity.GiftSubscription, error) {
+	var gift entity.GiftSubscription
+	var mealTypes pq.StringArray
+	var deliveryDays pq.StringArray
+
+	dest := []interface{}{
+		&gift.ID, &gift.Code, &gift.BuyerUserID, &gift.RecipientName, &gift.RecipientEmail,
+		&gift.Message, &gift.MealPlanID, &mealTypes, &deliveryDays,
+		&gift.DurationMonths, &gift.TotalPrice, &gift.Status, &gift.ExpiresAt,
+		&gift.RedeemedByUserID, &gift.SubscriptionID, &gift.RedeemedAt, &gift.LastSentAt,
+		&gift.SendCount, &gift.CreatedAt, &gift.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	gift.MealTypes = make([]entity.MealType, len(mealTypes))
+	for i, mt := range mealTypes {
+		gift.MealTypes[i] = entity.MealType(mt)
+	}
+
+	gift.DeliveryDays = make([]entity.DeliveryDay, len(deliveryDays))
+	for i, dd := range deliveryDays {
+		gift.DeliveryDays[i] = entity.DeliveryDay(dd)
+	}
+
+	return &gift, nil
+}
+
+func (r *giftRepository) scanGiftsWithDetails(rows *sql.Rows) ([]entity.GiftSubscriptionWithDetails, error) {
+	giftList := []entity.GiftSubscriptionWithDetails{}
+	for rows.Next() {
+		var details entity.GiftSubscriptionWithDetails
+
+		gift, err := r.scanGift(rows,
+			&details.MealPlan.Name, &details.MealPlan.Description, &details.MealPlan.Price,
+			&details.BuyerName, &details.BuyerEmail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gift subscription: %w", err)
+		}
+
+		details.GiftSubscription = *gift
+		details.MealPlan.ID = gift.MealPlanID
+		giftList = append(giftList, details)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return giftList, nil
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/gifts"
+	"sea-catering-backend/internal/api/gifts/repository"
+	mealPlanRepo "sea-catering-backend/internal/api/meal_plans/repository"
+	"sea-catering-backend/internal/api/subscriptions"
+	subscriptionRepo "sea-catering-backend/internal/api/subscriptions/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/utils"
+)
+
+const (
+	giftCodeValidity   = 365 * 24 * time.Hour
+	giftResendCooldown = 10 * time.Minute
+	giftMaxSends       = 5
+)
+
+type GiftService interface {
+	// PurchaseGift records the order. The code is only issued to the
+	// recipient once ConfirmGiftPayment is called for it.
+	PurchaseGift(ctx context.Context, buyerUserID string, req gifts.PurchaseGiftRequest) (*entity.GiftSubscription, error)
+	ConfirmGiftPayment(ctx context.Context, giftID string) (*entity.GiftSubscription, error)
+	GetPurchasedGifts(ctx context.Context, buyerUserID string) ([]entity.GiftSubscriptionWithDetails, error)
+	RedeemGift(ctx context.Context, userID string, req gifts.RedeemGiftRequest) (*gifts.RedeemGiftResponse, error)
+	ResendGift(ctx context.Context, giftID, buyerUserID string, req gifts.ResendGiftRequest) error
+	AdminResendGift(ctx context.Context, giftID string) error
+	GetOutstandingGifts(ctx context.Context, req gifts.OutstandingGiftsRequest) (*gifts.GiftListResponse, error)
+	ProcessExpiredGifts(ctx context.Context) (*gifts.ProcessExpiredGiftsResponse, error)
+}
+
+type giftService struct {
+	giftRepo         repository.GiftRepository
+	subscriptionRepo subscriptionRepo.SubscriptionRepository
+	mealPlanRepo     mealPlanRepo.MealPlanRepository
+	userRepo         authRepo.UserRepository
+	txManager        database.TxManager
+	emailService     email.Interface
+	utils            utils.Interface
+	logger           *logger.Logger
+}
+
+func NewGiftService(
+	giftRepo repository.GiftRepository,
+	subscriptionRepo subscriptionRepo.SubscriptionRepository,
+	mealPlanRepo mealPlanRepo.MealPlanRepository,
+	userRepo authRepo.UserRepository,
+	txManager database.TxManager,
+	emailService email.Interface,
+	utils utils.Interface,
+	logger *logger.Logger,
+) GiftService {
+	return &giftService{
+		giftRepo:         giftRepo,
+		subscriptionRepo: subscriptionRepo,
+		mealPlanRepo:     mealPlanRepo,
+		userRepo:         userRepo,
+		txManager:        txManager,
+		emailService:     emailService,
+		utils:            utils,
+		logger:           logger,
+	}
+}
+
+func (s *giftService) PurchaseGift(ctx context.Context, buyerUserID string, req gifts.PurchaseGiftRequest) (*entity.GiftSubscription, error) {
+	mealTypes := make([]string, len(req.MealTypes))
+	for i, mt := range req.MealTypes {
+		mealTypes[i] = string(mt)
+	}
+
+	deliveryDays := make([]string, len(req.DeliveryDays))
+	for i, dd := range req.DeliveryDays {
+		deliveryDays[i] = string(dd)
+	}
+
+	if err := s.utils.ValidateMealTypes(mealTypes); err != nil {
+		return nil, gifts.ErrInvalidMealTypes
+	}
+
+	if err := s.utils.ValidateDeliveryDays(deliveryDays); err != nil {
+		return nil, gifts.ErrInvalidDeliveryDays
+	}
+
+	mealPlan, err := s.mealPlanRepo.GetByID(ctx, req.MealPlanID)
+	if err != nil || mealPlan == nil || !mealPlan.IsActive {
+		return nil, gifts.ErrInvalidMealPlan
+	}
+
+	if _, err := s.getUser(ctx, buyerUserID); err != nil {
+		return nil, err
+	}
+
+	monthlyPrice := s.utils.CalculateSubscriptionPrice(mealPlan.Price, mealTypes, deliveryDays)
+
+	now := time.Now()
+	gift := &entity.GiftSubscription{
+		ID:             s.utils.GenerateULID(),
+		Code:           s.generateGiftCode(),
+		BuyerUserID:    buyerUserID,
+		RecipientName:  strings.TrimSpace(req.RecipientName),
+		RecipientEmail: strings.ToLower(strings.TrimSpace(req.RecipientEmail)),
+		Message:        strings.TrimSpace(req.Message),
+		MealPlanID:     req.MealPlanID,
+		MealTypes:      req.MealTypes,
+		DeliveryDays:   req.DeliveryDays,
+		DurationMonths: req.DurationMonths,
+		TotalPrice:     monthlyPrice * float64(req.DurationMonths),
+		Status:         entity.GiftStatusPendingPayment,
+		ExpiresAt:      now.Add(giftCodeValidity),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.giftRepo.Create(ctx, gift); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Gift subscription ordered", logger.Fields{
+		"gift_id":         gift.ID,
+		"buyer_id":        buyerUserID,
+		"recipient_email": gift.RecipientEmail,
+		"plan":            mealPlan.Name,
+		"duration_months": gift.DurationMonths,
+		"total_price":     gift.TotalPrice,
+	})
+
+	return gift, nil
+}
+
+func (s *giftService) ConfirmGiftPayment(ctx context.Context, giftID string) (*entity.GiftSubscription, error) {
+	gift, err := s.giftRepo.GetByID(ctx, giftID)
+	if err != nil {
+		return nil, err
+	}
+
+	if gift.Status != entity.GiftStatusPendingPayment {
+		return nil, gifts.ErrGiftNotAwaitingPayment
+	}
+
+	buyer, err := s.getUser(ctx, gift.BuyerUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.giftRepo.MarkPaid(ctx, gift, time.Now().Add(giftCodeValidity)); err != nil {
+		return nil, err
+	}
+
+	planName := ""
+	if mealPlan, err := s.mealPlanRepo.GetByID(ctx, gift.MealPlanID); err == nil && mealPlan != nil {
+		planName = mealPlan.Name
+	}
+
+	s.sendGiftEmail(gift, buyer.Name, planName)
+
+	s.logger.Info("Gift payment confirmed", logger.Fields{
+		"gift_id":         gift.ID,
+		"buyer_id":        gift.BuyerUserID,
+		"recipient_email": gift.RecipientEmail,
+		"total_price":     gift.TotalPrice,
+	})
+
+	return gift, nil
+}
+
+func (s *giftService) GetPurchasedGifts(ctx context.Context, buyerUserID string) ([]entity.GiftSubscriptionWithDetails, error) {
+	giftList, err := s.giftRepo.GetByBuyerID(ctx, buyerUserID)
+	if err != nil {
+		s.logger.Error("Failed to get purchased gifts", logger.Fields{
+			"error":    err.Error(),
+			"buyer_id": buyerUserID,
+		})
+		return nil, err
+	}
+
+	return giftList, nil
+}
+
+func (s *giftService) RedeemGift(ctx context.Context, userID string, req gifts.RedeemGiftRequest) (*gifts.RedeemGiftResponse, error) {
+	gift, err := s.giftRepo.GetByCode(ctx, normalizeGiftCode(req.Code))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRedeemable(gift); err != nil {
+		return nil, err
+	}
+
+	if gift.BuyerUserID == userID {
+		return nil, gifts.ErrCannotRedeemOwnGift
+	}
+
+	mealTypes := make([]string, len(gift.MealTypes))
+	for i, mt := range gift.MealTypes {
+		mealTypes[i] = string(mt)
+	}
+
+	deliveryDays := make([]string, len(gift.DeliveryDays))
+	for i, dd := range gift.DeliveryDays {
+		deliveryDays[i] = string(dd)
+	}
+
+	mealPlan, err := s.mealPlanRepo.GetByID(ctx, gift.MealPlanID)
+	if err != nil || mealPlan == nil {
+		return nil, gifts.ErrInvalidMealPlan
+	}
+
+	now := time.Now()
+	endsAt := now.AddDate(0, gift.DurationMonths, 0)
+	subscription := &entity.Subscription{
+		ID:              s.utils.GenerateULID(),
+		UserID:          userID,
+		MealPlanID:      gift.MealPlanID,
+		MealTypes:       gift.MealTypes,
+		DeliveryDays:    gift.DeliveryDays,
+		Allergies:       strings.TrimSpace(req.Allergies),
+		TotalPrice:      s.utils.CalculateSubscriptionPrice(mealPlan.Price, mealTypes, deliveryDays),
+		Status:          entity.StatusActive,
+		DeliveryAddress: strings.TrimSpace(req.DeliveryAddress),
+		GiftID:          &gift.ID,
+		EndsAt:          &endsAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.giftRepo.Redeem(ctx, gift, subscription); err != nil {
+			return err
+		}
+		return s.subscriptionRepo.LogSubscriptionAction(ctx, subscription.ID, userID, "created", "", string(subscription.Status))
+	})
+	if err != nil {
+		s.logger.Error("Failed to redeem gift", logger.Fields{
+			"error":   err.Error(),
+			"gift_id": gift.ID,
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	subscriptionDetails, err := s.subscriptionRepo.GetByID(ctx, subscription.ID)
+	if err != nil || subscriptionDetails == nil {
+		subscriptionDetails = &entity.SubscriptionWithDetails{
+			Subscription: *subscription,
+			MealPlan:     *mealPlan,
+		}
+	}
+
+	s.logger.Info("Gift redeemed successfully", logger.Fields{
+		"gift_id":         gift.ID,
+		"subscription_id": subscription.ID,
+		"user_id":         userID,
+		"ends_at":         endsAt,
+	})
+
+	return &gifts.RedeemGiftResponse{
+		Gift:         *gift,
+		Subscription: *subscriptionDetails,
+	}, nil
+}
+
+func (s *giftService) ResendGift(ctx context.Context, giftID, buyerUserID string, req gifts.ResendGiftRequest) error {
+	gift, err := s.giftRepo.GetByID(ctx, giftID)
+	if err != nil {
+		return err
+	}
+
+	if gift.BuyerUserID != buyerUserID {
+		return gifts.ErrUnauthorizedAccess
+	}
+
+	if gift.SendCount >= giftMaxSends {
+		return gifts.ErrResendLimitReached
+	}
+
+	if gift.LastSentAt != nil && time.Since(*gift.LastSentAt) < giftResendCooldown {
+		return gifts.ErrResendTooSoon
+	}
+
+	if req.RecipientEmail != "" {
+		gift.RecipientEmail = strings.ToLower(strings.TrimSpace(req.RecipientEmail))
+	}
+
+	return s.resend(ctx, gift)
+}
+
+func (s *giftService) AdminResendGift(ctx context.Context, giftID string) error {
+	gift, err := s.giftRepo.GetByID(ctx, giftID)
+	if err != nil {
+		return err
+	}
+
+	return s.resend(ctx, gift)
+}
+
+func (s *giftService) GetOutstandingGifts(ctx context.Context, req gifts.OutstandingGiftsRequest) (*gifts.GiftListResponse, error) {
+	giftList, meta, err := s.giftRepo.GetOutstanding(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to get outstanding gifts", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &gifts.GiftListResponse{
+		Gifts: giftList,
+		Meta:  meta,
+	}, nil
+}
+
+func (s *giftService) ProcessExpiredGifts(ctx context.Context) (*gifts.ProcessExpiredGiftsResponse, error) {
+	expired, err := s.giftRepo.ExpireOverdue(ctx)
+	if err != nil {
+		s.logger.Error("Failed to expire overdue gifts", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	endedIDs, err := s.giftRepo.GetEndedSubscriptionIDs(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get ended gifted subscriptions", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	// Each subscription is expired on its own, so one that fails is left
+	// for the next run without holding up the rest.
+	audit := subscriptionRepo.AuditEntry{Reason: "Gift term ended"}
+	ended := 0
+	for _, id := range endedIDs {
+		_, err := s.subscriptionRepo.Modify(ctx, id, subscriptions.ActionExpire, audit, func(*entity.Subscription) error {
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("Failed to expire gifted subscription", logger.Fields{
+				"error":           err.Error(),
+				"subscription_id": id,
+			})
+			continue
+		}
+		ended++
+	}
+
+	s.logger.Info("Processed expired gifts", logger.Fields{
+		"expired_gifts":       expired,
+		"ended_subscriptions": ended,
+	})
+
+	return &gifts.ProcessExpiredGiftsResponse{
+		ExpiredGifts:       expired,
+		EndedSubscriptions: ended,
+	}, nil
+}
+
+func (s *giftService) resend(ctx context.Context, gift *entity.GiftSubscription) error {
+	if gift.Status != entity.GiftStatusIssued {
+		return gifts.ErrGiftNotResendable
+	}
+
+	if gift.IsExpired() {
+		return gifts.ErrGiftExpired
+	}
+
+	buyer, err := s.getUser(ctx, gift.BuyerUserID)
+	if err != nil {
+		return err
+	}
+
+	planName := ""
+	if mealPlan, err := s.mealPlanRepo.GetByID(ctx, gift.MealPlanID); err == nil && mealPlan != nil {
+		planName = mealPlan.Name
+	}
+
+	if err := s.giftRepo.MarkSent(ctx, gift.ID, gift.RecipientEmail); err != nil {
+		return err
+	}
+
+	s.sendGiftEmail(gift, buyer.Name, planName)
+
+	s.logger.Info("Gift email resent", logger.Fields{
+		"gift_id":         gift.ID,
+		"recipient_email": gift.RecipientEmail,
+		"send_count":      gift.SendCount + 1,
+	})
+
+	return nil
+}
+
+func (s *giftService) sendGiftEmail(gift *entity.GiftSubscription, buyerName, planName string) {
+	mealTypes := make([]string, len(gift.MealTypes))
+	for i, mt := range gift.MealTypes {
+		mealTypes[i] = s.utils.GetMealTypeDisplayName(string(mt))
+	}
+
+	deliveryDays := make([]string, len(gift.DeliveryDays))
+	for i, dd := range gift.DeliveryDays {
+		deliveryDays[i] = s.utils.GetDayDisplayName(string(dd))
+	}
+
+	err := s.emailService.SendGiftSubscriptionEmail(gift.RecipientEmail, gift.RecipientName, &email.GiftDetails{
+		Code:           gift.Code,
+		BuyerName:      buyerName,
+		Message:        gift.Message,
+		PlanName:       planName,
+		MealTypes:      mealTypes,
+		DeliveryDays:   deliveryDays,
+		DurationMonths: gift.DurationMonths,
+		ExpiresAt:      gift.ExpiresAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send gift email", logger.Fields{
+			"error":           err.Error(),
+			"gift_id":         gift.ID,
+			"recipient_email": gift.RecipientEmail,
+		})
+	}
+}
+
+func (s *giftService) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, gifts.ErrUnauthorizedAccess
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *giftService) generateGiftCode() string {
+	code := s.utils.GenerateAlphanumericCode(12)
+	return fmt.Sprintf("GIFT-%s-%s-%s", code[:4], code[4:8], code[8:])
+}
+
+func normalizeGiftCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func checkRedeemable(gift *entity.GiftSubscription) error {
+	switch gift.Status {
+	case entity.GiftStatusPendingPayment:
+		return gifts.ErrGiftNotPaid
+	case entity.GiftStatusRedeemed:
+		return gifts.ErrGiftAlreadyRedeemed
+	case entity.GiftStatusCancelled:
+		return gifts.ErrGiftCancelled
+	}
+
+	if gift.IsExpired() {
+		return gifts.ErrGiftExpired
+	}
+
+	return nil
+}
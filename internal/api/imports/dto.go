@@ -0,0 +1,62 @@
+package imports
+
+import "sea-catering-backend/internal/api/meal_plans"
+
+type ImportRequest struct {
+	// Commit writes the rows. Without it the import is a dry run that
+	// reports what would happen.
+	Commit bool `query:"commit"`
+}
+
+// MealPlanRow is one line of a meal plan import, checked with the same
+// rules as creating a plan through the API.
+type MealPlanRow struct {
+	ExternalID string `json:"external_id" validate:"required,max=100"`
+	meal_plans.CreateMealPlanRequest
+	IsActive bool `json:"is_active"`
+}
+
+// SubscriberRow is one line of a legacy subscriber import: the customer
+// and their subscription.
+type SubscriberRow struct {
+	ExternalID      string   `json:"external_id" validate:"required,max=100"`
+	Name            string   `json:"name" validate:"required,min=2,max=100"`
+	Email           string   `json:"email" validate:"required,email"`
+	Phone           string   `json:"phone" validate:"omitempty,phone_id"`
+	MealPlan        string   `json:"meal_plan" validate:"required,max=100"`
+	MealTypes       []string `json:"meal_types" validate:"required,min=1,dive,meal_type"`
+	DeliveryDays    []string `json:"delivery_days" validate:"required,min=1,dive,day_of_week"`
+	Allergies       string   `json:"allergies" validate:"omitempty,max=500"`
+	DeliveryAddress string   `json:"delivery_address" validate:"omitempty,max=500"`
+	Status          string   `json:"status" validate:"omitempty,oneof=active paused cancelled"`
+	StartedAt       string   `json:"started_at" validate:"omitempty,datetime=2006-01-02"`
+	PauseStartDate  string   `json:"pause_start_date" validate:"required_if=Status paused,omitempty,datetime=2006-01-02"`
+	PauseEndDate    string   `json:"pause_end_date" validate:"required_if=Status paused,omitempty,datetime=2006-01-02"`
+}
+
+type Report struct {
+	Kind string `json:"kind"`
+	// Committed is false for a dry run and for any import with errors;
+	// in both cases nothing was written.
+	Committed   bool `json:"committed"`
+	TotalRows   int  `json:"total_rows"`
+	InvalidRows int  `json:"invalid_rows"`
+	// Created, Updated and Skipped count what was written, or for a dry
+	// run what would be. Skipped rows were imported before and are left
+	// unchanged.
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	// ExistingAccounts counts subscribers whose email already had an
+	// account, which the subscription was added to.
+	ExistingAccounts int        `json:"existing_accounts,omitempty"`
+	Errors           []RowError `json:"errors"`
+}
+
+type RowError struct {
+	// Row is the line in the file, counting the header as line 1.
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Message    string `json:"message"`
+}
@@ -0,0 +1,14 @@
+package imports
+
+import "errors"
+
+var (
+	ErrEmptyFile        = errors.New("the file has no rows")
+	ErrTooManyRows      = errors.New("the file has more rows than an import allows")
+	ErrMissingColumns   = errors.New("the file is missing required columns")
+	ErrInvalidFile      = errors.New("the file is not valid CSV")
+	ErrUnknownMealPlan  = errors.New("no meal plan matches this external ID, ID or name")
+	ErrMealPlanNameUsed = errors.New("another meal plan with a different external ID already has this name")
+	ErrPhoneUsed        = errors.New("phone number belongs to another account")
+	ErrMealPlanDeleted  = errors.New("the meal plan with this external ID was deleted; restore it before importing it again")
+)
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	contexts "context"
+	"mime/multipart"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/imports"
+	"sea-catering-backend/internal/api/imports/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type ImportHandler struct {
+	importService service.ImportService
+	validator     *validator.Validate
+	middleware    middleware.Interface
+	logger        *logger.Logger
+}
+
+func NewImportHandler(
+	importService service.ImportService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		validator:     validator,
+		middleware:    middleware,
+		logger:        logger,
+	}
+}
+
+func (h *ImportHandler) RegisterRoutes(router fiber.Router) {
+	importsGroup := router.Group("/imports")
+
+	admin := importsGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Post("/meal-plans", h.ImportMealPlans)
+	admin.Post("/subscribers", h.ImportSubscribers)
+}
+
+func (h *ImportHandler) ImportMealPlans(c *fiber.Ctx) error {
+	return h.handleImport(c, "import_meal_plans", h.importService.ImportMealPlans)
+}
+
+func (h *ImportHandler) ImportSubscribers(c *fiber.Ctx) error {
+	return h.handleImport(c, "import_subscribers", h.importService.ImportSubscribers)
+}
+
+type importFunc func(ctx contexts.Context, file *multipart.FileHeader, commit bool) (*imports.Report, error)
+
+func (h *ImportHandler) handleImport(c *fiber.Ctx, operation string, run importFunc) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 2*time.Minute)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req imports.ImportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "A CSV file is required")
+	}
+
+	report, err := run(ctx, file, req.Commit)
+	if err != nil {
+		return h.handleImportError(c, errHandler, requestID, err, c.Path(), operation)
+	}
+
+	if req.Commit && !report.Committed {
+		return response.UnprocessableEntity(c, "The file has invalid rows; nothing was imported", report)
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, report)
+}
+
+func (h *ImportHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *ImportHandler) handleImportError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case imports.ErrEmptyFile, imports.ErrTooManyRows, imports.ErrMissingColumns, imports.ErrInvalidFile:
+		return errHandler.HandleBadRequest(c, requestID, err.Error())
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/imports"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionSkipped Action = "skipped"
+)
+
+type MealPlanRecord struct {
+	Row        int
+	ExternalID string
+	// Plan.ID is used only when the plan is new.
+	Plan entity.MealPlan
+}
+
+type SubscriberRecord struct {
+	Row        int
+	ExternalID string
+	// User is created unless an account with the same email exists.
+	User         entity.User
+	Subscription entity.Subscription
+	AuditID      string
+}
+
+type Outcome struct {
+	Row    int
+	Action Action
+	// ExistingAccount is set when a subscriber was added to an account
+	// found by email.
+	ExistingAccount bool
+	// Err is a problem with the row itself, such as a name or phone number
+	// already in use.
+	Err error
+}
+
+// PlanReference identifies a meal plan that subscriber rows may name.
+type PlanReference struct {
+	ID         string  `db:"id"`
+	ExternalID *string `db:"external_id"`
+	Name       string  `db:"name"`
+	Price      float64 `db:"price"`
+}
+
+type ImportRepository interface {
+	ListMealPlanReferences(ctx context.Context) ([]PlanReference, error)
+	// ImportMealPlans and ImportSubscribers write every record in one
+	// transaction, each under its own savepoint so one failing row does not
+	// hide problems in the rest. The transaction is committed only when
+	// commit is set and no row failed; otherwise it is rolled back, which
+	// makes a dry run exercise the same constraints as a real import.
+	ImportMealPlans(ctx context.Context, records []MealPlanRecord, commit bool) ([]Outcome, bool, error)
+	ImportSubscribers(ctx context.Context, records []SubscriberRecord, commit bool) ([]Outcome, bool, error)
+}
+
+type importRepository struct {
+	db *database.DB
+}
+
+func NewImportRepository(db *sqlx.DB) ImportRepository {
+	return &importRepository{db: database.New(db)}
+}
+
+func (r *importRepository) ListMealPlanReferences(ctx context.Context) ([]PlanReference, error) {
+	plans := []PlanReference{}
+	query := `SELECT id, external_id, name, price FROM meal_plans WHERE deleted_at IS NULL ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &plans, query); err != nil {
+		return nil, fmt.Errorf("failed to list meal plans: %w", err)
+	}
+	return plans, nil
+}
+
+func (r *importRepository) ImportMealPlans(ctx context.Context, records []MealPlanRecord, commit bool) ([]Outcome, bool, error) {
+	return r.run(ctx, len(records), commit, func(ctx context.Context, i int) Outcome {
+		action, err := r.importMealPlan(ctx, records[i])
+		return Outcome{Row: records[i].Row, Action: action, Err: err}
+	})
+}
+
+func (r *importRepository) ImportSubscribers(ctx context.Context, records []SubscriberRecord, commit bool) ([]Outcome, bool, error) {
+	return r.run(ctx, len(records), commit, func(ctx context.Context, i int) Outcome {
+		action, existing, err := r.importSubscriber(ctx, records[i])
+		return Outcome{Row: records[i].Row, Action: action, ExistingAccount: existing, Err: err}
+	})
+}
+
+// errNotCommitted rolls back an import that was a dry run or had a failing
+// row.
+var errNotCommitted = errors.New("import not committed")
+
+// run applies each row under a savepoint. Errors caused by the row's data
+// are recorded on its outcome; anything else aborts the import. It reports
+// whether the transaction was committed.
+func (r *importRepository) run(ctx context.Context, n int, commit bool, apply func(ctx context.Context, i int) Outcome) ([]Outcome, bool, error) {
+	outcomes := make([]Outcome, 0, n)
+	failed := false
+
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for i := 0; i < n; i++ {
+			var outcome Outcome
+			err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+				outcome = apply(ctx, i)
+				return outcome.Err
+			})
+			if err != nil {
+				if !isRowError(err) {
+					return err
+				}
+				failed = true
+			}
+
+			outcomes = append(outcomes, outcome)
+		}
+
+		if !commit || failed {
+			return errNotCommitted
+		}
+		return nil
+	})
+
+	switch err {
+	case nil:
+		return outcomes, true, nil
+	case errNotCommitted:
+		return outcomes, false, nil
+	default:
+		return nil, false, err
+	}
+}
+
+// isRowError reports whether err comes from the row's data rather than the
+// database being unavailable: integrity and data exceptions, and the
+// import's own checks.
+func isRowError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		class := pqErr.Code.Class()
+		return class == "22" || class == "23"
+	}
+
+	switch err {
+	case imports.ErrMealPlanNameUsed, imports.ErrPhoneUsed, imports.ErrMealPlanDeleted:
+		return true
+	}
+	return false
+}
+
+func (r *importRepository) importMealPlan(ctx context.Context, rec MealPlanRecord) (Action, error) {
+	plan := rec.Plan
+	now := time.Now()
+
+	var existing struct {
+		ID      string `db:"id"`
+		Deleted bool   `db:"deleted"`
+	}
+	err := r.db.GetContext(ctx, &existing, `SELECT id, deleted_at IS NOT NULL AS deleted FROM meal_plans WHERE external_id = $1`, rec.ExternalID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to look up meal plan: %w", err)
+	}
+	if existing.Deleted {
+		return "", imports.ErrMealPlanDeleted
+	}
+	existingID := existing.ID
+
+	// A plan created by hand before the import is adopted when the name
+	// matches, so the sheet does not duplicate it.
+	if err == sql.ErrNoRows {
+		var byName struct {
+			ID         string  `db:"id"`
+			ExternalID *string `db:"external_id"`
+		}
+		err := r.db.GetContext(ctx, &byName, `SELECT id, external_id FROM meal_plans WHERE LOWER(name) = LOWER($1) AND deleted_at IS NULL`, plan.Name)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return "", fmt.Errorf("failed to look up meal plan: %w", err)
+		case byName.ExternalID != nil:
+			return "", imports.ErrMealPlanNameUsed
+		default:
+			existingID = byName.ID
+		}
+	}
+
+	if existingID == "" {
+		query := `
+			INSERT INTO meal_plans (id, external_id, name, description, price, image_url, features, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		`
+		_, err := r.db.ExecContext(ctx, query,
+			plan.ID, rec.ExternalID, plan.Name, plan.Description, plan.Price,
+			plan.ImageURL, pq.Array(plan.Features), plan.IsActive, now,
+		)
+		if err != nil {
+			return "", mealPlanError(err)
+		}
+		return ActionCreated, nil
+	}
+
+	query := `
+		UPDATE meal_plans
+		SET external_id = $2, name = $3, description = $4, price = $5, image_url = $6,
+		    features = $7, is_active = $8, updated_at = $9, version = version + 1
+		WHERE id = $1
+		AND (external_id, name, COALESCE(description, ''), price, COALESCE(image_url, ''), COALESCE(features, '{}'), is_active)
+		    IS DISTINCT FROM ($2, $3, $4, $5::numeric, $6, $7::text[], $8)
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		existingID, rec.ExternalID, plan.Name, plan.Description, plan.Price,
+		plan.ImageURL, pq.Array(plan.Features), plan.IsActive, now,
+	)
+	if err != nil {
+		return "", mealPlanError(err)
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return ActionSkipped, nil
+	}
+	return ActionUpdated, nil
+}
+
+func mealPlanError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint != "uq_meal_plans_external_id" {
+		return imports.ErrMealPlanNameUsed
+	}
+	return fmt.Errorf("failed to import meal plan: %w", err)
+}
+
+// importSubscriber never changes a subscription imported before, since the
+// customer may have changed it since.
+func (r *importRepository) importSubscriber(ctx context.Context, rec SubscriberRecord) (Action, bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM subscriptions WHERE external_id = $1)`, rec.ExternalID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up subscription: %w", err)
+	}
+	if exists {
+		return ActionSkipped, false, nil
+	}
+
+	user := rec.User
+	sub := rec.Subscription
+
+	var userID string
+	err = r.db.GetContext(ctx, &userID, `SELECT id FROM users WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL`, user.Email)
+	existing := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	if !existing {
+		query := `
+			INSERT INTO users (id, name, email, phone, password, role, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`
+		_, err := r.db.ExecContext(ctx, query,
+			user.ID, user.Name, user.Email, user.Phone, user.Password,
+			user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Constraint == "users_phone_key" {
+				return "", false, imports.ErrPhoneUsed
+			}
+			return "", false, fmt.Errorf("failed to create user: %w", err)
+		}
+		userID = user.ID.String()
+	}
+
+	query := `
+		INSERT INTO subscriptions (
+			id, external_id, user_id, meal_plan_id, meal_types, delivery_days, allergies,
+			total_price, status, pause_start_date, pause_end_date, delivery_address,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err = r.db.ExecContext(ctx, query,
+		sub.ID, rec.ExternalID, userID, sub.MealPlanID, pq.Array(sub.MealTypes), pq.Array(sub.DeliveryDays),
+		sub.Allergies, sub.TotalPrice, sub.Status, sub.PauseStartDate, sub.PauseEndDate, sub.DeliveryAddress,
+		sub.CreatedAt, sub.UpdatedAt,
+	)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	// Dated at the original start, so cohorts and metrics backfills place
+	// the subscriber correctly.
+	auditQuery := `
+		INSERT INTO subscription_audit (id, subscription_id, user_id, new_status, action, reason, created_at)
+		VALUES ($1, $2, $3, $4, 'created', $5, $6)
+	`
+	reason := "Imported from legacy subscriber " + rec.ExternalID
+	_, err = r.db.ExecContext(ctx, auditQuery, rec.AuditID, sub.ID, userID, sub.Status, reason, sub.CreatedAt)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to audit subscription: %w", err)
+	}
+
+	return ActionCreated, existing, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"sea-catering-backend/internal/api/imports"
+)
+
+// table is an uploaded CSV with columns looked up by header name.
+type table struct {
+	columns map[string]int
+	rows    [][]string
+	// lines holds the line in the file each row started on.
+	lines []int
+}
+
+// readTable reads the whole file. Spreadsheet exports often start with a
+// byte order mark and, in Indonesian locales, separate fields with
+// semicolons; both are handled. Blank rows are dropped.
+func readTable(file *multipart.FileHeader, required []string, maxRows int) (*table, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, imports.ErrEmptyFile
+	}
+	if err != nil {
+		return nil, imports.ErrInvalidFile
+	}
+
+	t := &table{columns: map[string]int{}}
+	for i, name := range header {
+		t.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range required {
+		if _, ok := t.columns[name]; !ok {
+			return nil, imports.ErrMissingColumns
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, imports.ErrInvalidFile
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		if len(t.rows) == maxRows {
+			return nil, imports.ErrTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		t.rows = append(t.rows, record)
+		t.lines = append(t.lines, line)
+	}
+
+	if len(t.rows) == 0 {
+		return nil, imports.ErrEmptyFile
+	}
+
+	return t, nil
+}
+
+func (t *table) get(row int, column string) string {
+	i, ok := t.columns[column]
+	if !ok || i >= len(t.rows[row]) {
+		return ""
+	}
+	return strings.TrimSpace(t.rows[row][i])
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitList splits a cell holding several values, such as "breakfast;
+// dinner", on any of separators.
+func splitList(cell, separators string) []string {
+	var values []string
+	for _, value := range strings.FieldsFunc(cell, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	}) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseBool accepts the spellings spreadsheets tend to use. An empty cell
+// gives def.
+func parseBool(cell string, def bool) (bool, bool) {
+	switch strings.ToLower(cell) {
+	case "":
+		return def, true
+	case "true", "yes", "y", "1", "ya":
+		return true, true
+	case "false", "no", "n", "0", "tidak":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func parsePrice(cell string) (float64, bool) {
+	if cell == "" {
+		return 0, true
+	}
+	price, err := strconv.ParseFloat(cell, 64)
+	return price, err == nil
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/imports"
+	"sea-catering-backend/internal/api/imports/repository"
+	"sea-catering-backend/internal/api/meal_plans"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/bcrypt"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/utils"
+)
+
+var (
+	mealPlanColumns   = []string{"external_id", "name", "description", "price", "features"}
+	subscriberColumns = []string{"external_id", "name", "email", "meal_plan", "meal_types", "delivery_days"}
+)
+
+type ImportService interface {
+	ImportMealPlans(ctx context.Context, file *multipart.FileHeader, commit bool) (*imports.Report, error)
+	ImportSubscribers(ctx context.Context, file *multipart.FileHeader, commit bool) (*imports.Report, error)
+}
+
+type Config struct {
+	MaxRows int
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		MaxRows: 5000,
+	}
+
+	if rows, err := strconv.Atoi(os.Getenv("IMPORT_MAX_ROWS")); err == nil && rows > 0 {
+		config.MaxRows = rows
+	}
+
+	return config
+}
+
+type importService struct {
+	importRepo repository.ImportRepository
+	validator  *validator.Validate
+	bcrypt     bcrypt.Interface
+	utils      utils.Interface
+	config     *Config
+	logger     *logger.Logger
+}
+
+func NewImportService(
+	importRepo repository.ImportRepository,
+	validator *validator.Validate,
+	bcrypt bcrypt.Interface,
+	utils utils.Interface,
+	config *Config,
+	logger *logger.Logger,
+) ImportService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	return &importService{
+		importRepo: importRepo,
+		validator:  validator,
+		bcrypt:     bcrypt,
+		utils:      utils,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+func (s *importService) ImportMealPlans(ctx context.Context, file *multipart.FileHeader, commit bool) (*imports.Report, error) {
+	t, err := readTable(file, mealPlanColumns, s.config.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport("meal_plans", len(t.rows))
+	seen := map[string]int{}
+	var records []repository.MealPlanRecord
+
+	for i := range t.rows {
+		line := t.lines[i]
+		row := imports.MealPlanRow{
+			ExternalID: t.get(i, "external_id"),
+			CreateMealPlanRequest: meal_plans.CreateMealPlanRequest{
+				Name:        t.get(i, "name"),
+				Description: t.get(i, "description"),
+				ImageURL:    t.get(i, "image_url"),
+				// Features often contain commas, so only semicolons and
+				// pipes separate them.
+				Features: splitList(t.get(i, "features"), ";|"),
+			},
+		}
+
+		var errs []imports.RowError
+		price, ok := parsePrice(t.get(i, "price"))
+		if !ok {
+			errs = append(errs, imports.RowError{Field: "price", Message: "price must be a number such as 45000"})
+		}
+		row.Price = price
+
+		row.IsActive, ok = parseBool(t.get(i, "is_active"), true)
+		if !ok {
+			errs = append(errs, imports.RowError{Field: "is_active", Message: "is_active must be true or false"})
+		}
+
+		errs = append(errs, s.validate(row, errs)...)
+		errs = append(errs, checkDuplicate(seen, row.ExternalID, line)...)
+
+		if len(errs) > 0 {
+			addRowErrors(report, line, row.ExternalID, errs)
+			continue
+		}
+
+		now := time.Now()
+		records = append(records, repository.MealPlanRecord{
+			Row:        line,
+			ExternalID: row.ExternalID,
+			Plan: entity.MealPlan{
+				ID:          s.utils.GenerateULID(),
+				Name:        row.Name,
+				Description: row.Description,
+				Price:       row.Price,
+				ImageURL:    row.ImageURL,
+				Features:    row.Features,
+				IsActive:    row.IsActive,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			},
+		})
+	}
+
+	// Valid rows are still run through the database when others failed, so
+	// the report lists every problem at once.
+	outcomes, committed, err := s.importRepo.ImportMealPlans(ctx, records, commit && report.InvalidRows == 0)
+	if err != nil {
+		s.logger.Error("Failed to import meal plans", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	externalIDs := make(map[int]string, len(records))
+	for _, rec := range records {
+		externalIDs[rec.Row] = rec.ExternalID
+	}
+
+	return s.finish(report, outcomes, externalIDs, committed), nil
+}
+
+func (s *importService) ImportSubscribers(ctx context.Context, file *multipart.FileHeader, commit bool) (*imports.Report, error) {
+	t, err := readTable(file, subscriberColumns, s.config.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.importRepo.ListMealPlanReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvePlan := planResolver(plans)
+
+	report := newReport("subscribers", len(t.rows))
+	seen := map[string]int{}
+	var records []repository.SubscriberRecord
+
+	for i := range t.rows {
+		line := t.lines[i]
+		row := imports.SubscriberRow{
+			ExternalID:      t.get(i, "external_id"),
+			Name:            t.get(i, "name"),
+			Email:           strings.ToLower(t.get(i, "email")),
+			Phone:           t.get(i, "phone"),
+			MealPlan:        t.get(i, "meal_plan"),
+			MealTypes:       lowerAll(splitList(t.get(i, "meal_types"), ",;|")),
+			DeliveryDays:    lowerAll(splitList(t.get(i, "delivery_days"), ",;|")),
+			Allergies:       t.get(i, "allergies"),
+			DeliveryAddress: t.get(i, "delivery_address"),
+			Status:          strings.ToLower(t.get(i, "status")),
+			StartedAt:       t.get(i, "started_at"),
+			PauseStartDate:  t.get(i, "pause_start_date"),
+			PauseEndDate:    t.get(i, "pause_end_date"),
+		}
+
+		errs := s.validate(row, nil)
+		errs = append(errs, checkDuplicate(seen, row.ExternalID, line)...)
+
+		plan := resolvePlan(row.MealPlan)
+		if plan == nil && row.MealPlan != "" {
+			errs = append(errs, imports.RowError{Field: "meal_plan", Message: imports.ErrUnknownMealPlan.Error()})
+		}
+
+		if len(errs) > 0 {
+			addRowErrors(report, line, row.ExternalID, errs)
+			continue
+		}
+
+		sub, err := s.buildSubscription(row, plan)
+		if err != nil {
+			addRowErrors(report, line, row.ExternalID, []imports.RowError{{Message: err.Error()}})
+			continue
+		}
+
+		var phone *string
+		if row.Phone != "" {
+			phone = &row.Phone
+		}
+
+		records = append(records, repository.SubscriberRecord{
+			Row:        line,
+			ExternalID: row.ExternalID,
+			User: entity.User{
+				ID:        uuid.New(),
+				Name:      row.Name,
+				Email:     row.Email,
+				Phone:     phone,
+				Role:      entity.RoleUser,
+				IsActive:  true,
+				CreatedAt: sub.CreatedAt,
+				UpdatedAt: sub.UpdatedAt,
+			},
+			Subscription: *sub,
+			AuditID:      s.utils.GenerateULID(),
+		})
+	}
+
+	if len(records) > 0 {
+		// Imported customers set a password through the reset flow. Until
+		// then their account has the hash of a password nobody knows; one
+		// hash serves the whole file, as bcrypt is slow by design.
+		password, err := s.bcrypt.HashPassword(s.utils.GenerateRandomString(32))
+		if err != nil {
+			return nil, err
+		}
+		for i := range records {
+			records[i].User.Password = password
+		}
+	}
+
+	outcomes, committed, err := s.importRepo.ImportSubscribers(ctx, records, commit && report.InvalidRows == 0)
+	if err != nil {
+		s.logger.Error("Failed to import subscribers", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	externalIDs := make(map[int]string, len(records))
+	for _, rec := range records {
+		externalIDs[rec.Row] = rec.ExternalID
+	}
+
+	return s.finish(report, outcomes, externalIDs, committed), nil
+}
+
+func (s *importService) buildSubscription(row imports.SubscriberRow, plan *repository.PlanReference) (*entity.Subscription, error) {
+	now := time.Now()
+	sub := &entity.Subscription{
+		ID:              s.utils.GenerateULID(),
+		MealPlanID:      plan.ID,
+		Allergies:       row.Allergies,
+		TotalPrice:      s.utils.CalculateSubscriptionPrice(plan.Price, row.MealTypes, row.DeliveryDays),
+		Status:          entity.StatusActive,
+		DeliveryAddress: row.DeliveryAddress,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	for _, mealType := range row.MealTypes {
+		sub.MealTypes = append(sub.MealTypes, entity.MealType(mealType))
+	}
+	for _, day := range row.DeliveryDays {
+		sub.DeliveryDays = append(sub.DeliveryDays, entity.DeliveryDay(day))
+	}
+
+	if row.Status != "" {
+		sub.Status = entity.SubscriptionStatus(row.Status)
+	}
+
+	if row.StartedAt != "" {
+		startedAt, _ := time.ParseInLocation("2006-01-02", row.StartedAt, time.Local)
+		if startedAt.After(now) {
+			return nil, errors.New("started_at cannot be in the future")
+		}
+		sub.CreatedAt = startedAt
+	}
+
+	if sub.Status == entity.StatusPaused {
+		start, _ := time.ParseInLocation("2006-01-02", row.PauseStartDate, time.Local)
+		end, _ := time.ParseInLocation("2006-01-02", row.PauseEndDate, time.Local)
+		if !end.After(start) {
+			return nil, errors.New("pause_end_date must be after pause_start_date")
+		}
+		sub.PauseStartDate = &start
+		sub.PauseEndDate = &end
+	}
+
+	return sub, nil
+}
+
+// validate applies the API's validation rules to row, leaving out fields
+// that already failed to parse.
+func (s *importService) validate(row interface{}, parsed []imports.RowError) []imports.RowError {
+	err := s.validator.Struct(row)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []imports.RowError{{Message: err.Error()}}
+	}
+
+	var errs []imports.RowError
+	for _, fieldErr := range validationErrors {
+		if hasField(parsed, fieldErr.Field()) {
+			continue
+		}
+		errs = append(errs, imports.RowError{
+			Field:   fieldErr.Field(),
+			Message: handlerutil.ValidationMessage(fieldErr),
+		})
+	}
+	return errs
+}
+
+func (s *importService) finish(report *imports.Report, outcomes []repository.Outcome, externalIDs map[int]string, committed bool) *imports.Report {
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			addRowErrors(report, outcome.Row, externalIDs[outcome.Row], []imports.RowError{{Message: rowMessage(outcome.Err)}})
+			continue
+		}
+
+		switch outcome.Action {
+		case repository.ActionCreated:
+			report.Created++
+		case repository.ActionUpdated:
+			report.Updated++
+		case repository.ActionSkipped:
+			report.Skipped++
+		}
+		if outcome.ExistingAccount {
+			report.ExistingAccounts++
+		}
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Row < report.Errors[j].Row
+	})
+	report.Committed = committed
+
+	s.logger.Info("Import processed", logger.Fields{
+		"kind":         report.Kind,
+		"committed":    report.Committed,
+		"total_rows":   report.TotalRows,
+		"invalid_rows": report.InvalidRows,
+		"created":      report.Created,
+		"updated":      report.Updated,
+		"skipped":      report.Skipped,
+	})
+
+	return report
+}
+
+func newReport(kind string, rows int) *imports.Report {
+	return &imports.Report{
+		Kind:      kind,
+		TotalRows: rows,
+		Errors:    []imports.RowError{},
+	}
+}
+
+// planResolver matches a subscriber's meal_plan cell against plan external
+// IDs first, then IDs, then names ignoring case.
+func planResolver(plans []repository.PlanReference) func(string) *repository.PlanReference {
+	byExternalID := map[string]*repository.PlanReference{}
+	byID := map[string]*repository.PlanReference{}
+	byName := map[string]*repository.PlanReference{}
+
+	for i := range plans {
+		plan := &plans[i]
+		if plan.ExternalID != nil {
+			byExternalID[*plan.ExternalID] = plan
+		}
+		byID[plan.ID] = plan
+		byName[strings.ToLower(plan.Name)] = plan
+	}
+
+	return func(ref string) *repository.PlanReference {
+		if plan, ok := byExternalID[ref]; ok {
+			return plan
+		}
+		if plan, ok := byID[ref]; ok {
+			return plan
+		}
+		return byName[strings.ToLower(ref)]
+	}
+}
+
+// addRowErrors records errs against a row and counts the row as invalid.
+func addRowErrors(report *imports.Report, line int, externalID string, errs []imports.RowError) {
+	for _, err := range errs {
+		err.Row = line
+		err.ExternalID = externalID
+		report.Errors = append(report.Errors, err)
+	}
+	report.InvalidRows++
+}
+
+func checkDuplicate(seen map[string]int, externalID string, line int) []imports.RowError {
+	if externalID == "" {
+		return nil
+	}
+	if first, ok := seen[externalID]; ok {
+		return []imports.RowError{{
+			Field:   "external_id",
+			Message: fmt.Sprintf("external_id is also used on row %d", first),
+		}}
+	}
+	seen[externalID] = line
+	return nil
+}
+
+func hasField(errs []imports.RowError, field string) bool {
+	for _, err := range errs {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(values []string) []string {
+	for i, value := range values {
+		values[i] = strings.ToLower(value)
+	}
+	return values
+}
+
+// rowMessage describes a database error caused by a row without exposing
+// more than the constraint that failed.
+func rowMessage(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Constraint != "" {
+			return fmt.Sprintf("rejected by the database (%s)", pqErr.Constraint)
+		}
+		return "rejected by the database: " + pqErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,37 @@
+package kitchen
+
+import "time"
+
+type ProductionReportRequest struct {
+	Date string `query:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type ProductionReport struct {
+	Date            string                `json:"date"`
+	TotalMeals      int                   `json:"total_meals"`
+	TotalExceptions int                   `json:"total_exceptions"`
+	Lines           []ProductionLine      `json:"lines"`
+	Exceptions      []ProductionException `json:"exceptions"`
+	GeneratedAt     time.Time             `json:"generated_at"`
+}
+
+// ProductionLine is how many portions of one meal plan to cook for one meal
+// of the day. StandardCount excludes the portions listed as exceptions.
+type ProductionLine struct {
+	MealPlanID     string `db:"meal_plan_id" json:"meal_plan_id"`
+	MealPlanName   string `db:"meal_plan_name" json:"meal_plan_name"`
+	MealType       string `db:"meal_type" json:"meal_type"`
+	Count          int    `db:"count" json:"count"`
+	ExceptionCount int    `db:"exception_count" json:"exception_count"`
+	StandardCount  int    `db:"-" json:"standard_count"`
+}
+
+// ProductionException is a single portion that has to be prepared apart
+// because the customer recorded allergies or dietary restrictions.
+type ProductionException struct {
+	DeliveryID   string `db:"delivery_id" json:"delivery_id"`
+	CustomerName string `db:"customer_name" json:"customer_name"`
+	MealPlanName string `db:"meal_plan_name" json:"meal_plan_name"`
+	MealType     string `db:"meal_type" json:"meal_type"`
+	Allergies    string `db:"allergies" json:"allergies"`
+}
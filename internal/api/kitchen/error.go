@@ -0,0 +1,8 @@
+package kitchen
+
+import "errors"
+
+var (
+	ErrInvalidReportDate = errors.New("invalid production report date")
+	ErrReportGeneration  = errors.New("failed to generate production report")
+)
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/kitchen"
+	"sea-catering-backend/internal/api/kitchen/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+)
+
+type KitchenHandler struct {
+	kitchenService service.KitchenService
+	validator      *validator.Validate
+	middleware     middleware.Interface
+	logger         *logger.Logger
+}
+
+func NewKitchenHandler(
+	kitchenService service.KitchenService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *KitchenHandler {
+	return &KitchenHandler{
+		kitchenService: kitchenService,
+		validator:      validator,
+		middleware:     middleware,
+		logger:         logger,
+	}
+}
+
+func (h *KitchenHandler) RegisterRoutes(router fiber.Router) {
+	kitchenGroup := router.Group("/kitchen")
+
+	admin := kitchenGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/production", h.GetProductionReport)
+	admin.Get("/production/csv", h.ExportProductionCSV)
+	admin.Get("/production/print", h.PrintProductionSheet)
+}
+
+func (h *KitchenHandler) GetProductionReport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	req, err := h.parseRequest(c, errHandler, requestID)
+	if req == nil {
+		return err
+	}
+
+	report, err := h.kitchenService.GetProductionReport(ctx, req.Date)
+	if err != nil {
+		return h.handleKitchenError(c, errHandler, requestID, err, c.Path(), "get_production_report")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, report)
+}
+
+func (h *KitchenHandler) ExportProductionCSV(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	req, err := h.parseRequest(c, errHandler, requestID)
+	if req == nil {
+		return err
+	}
+
+	data, filename, err := h.kitchenService.ExportProductionCSV(ctx, req.Date)
+	if err != nil {
+		return h.handleKitchenError(c, errHandler, requestID, err, c.Path(), "export_production_csv")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+func (h *KitchenHandler) PrintProductionSheet(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	req, err := h.parseRequest(c, errHandler, requestID)
+	if req == nil {
+		return err
+	}
+
+	page, err := h.kitchenService.RenderProductionSheet(ctx, req.Date)
+	if err != nil {
+		return h.handleKitchenError(c, errHandler, requestID, err, c.Path(), "print_production_sheet")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(page)
+}
+
+// parseRequest returns a nil request once it has already written the error
+// response.
+func (h *KitchenHandler) parseRequest(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string) (*kitchen.ProductionReportRequest, error) {
+	var req kitchen.ProductionReportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return nil, errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return nil, errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	return &req, nil
+}
+
+func (h *KitchenHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *KitchenHandler) handleKitchenError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case kitchen.ErrInvalidReportDate:
+		return errHandler.HandleBadRequest(c, requestID, "Invalid report date. Use YYYY-MM-DD")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
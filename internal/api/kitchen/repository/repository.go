@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"sea-catering-backend/internal/api/kitchen"
+	"sea-catering-backend/pkg/database"
+)
+
+type KitchenRepository interface {
+	GetProductionLines(ctx context.Context, date time.Time) ([]kitchen.ProductionLine, error)
+	GetProductionExceptions(ctx context.Context, date time.Time) ([]kitchen.ProductionException, error)
+}
+
+type kitchenRepository struct {
+	db *database.DB
+}
+
+func NewKitchenRepository(db *sqlx.DB) KitchenRepository {
+	return &kitchenRepository{db: database.New(db)}
+}
+
+// mealTypeOrder lists meals in the order the kitchen prepares them.
+const mealTypeOrder = `CASE d.meal_type WHEN 'breakfast' THEN 1 WHEN 'lunch' THEN 2 ELSE 3 END`
+
+// GetProductionLines counts every delivery scheduled for the date, whatever
+// its status, since each one was cooked once it exists.
+func (r *kitchenRepository) GetProductionLines(ctx context.Context, date time.Time) ([]kitchen.ProductionLine, error) {
+	query := fmt.Sprintf(`
+		SELECT mp.id as meal_plan_id, mp.name as meal_plan_name, d.meal_type::text as meal_type,
+		       COUNT(*) as count,
+		       COUNT(*) FILTER (WHERE TRIM(COALESCE(s.allergies, '')) != '') as exception_count
+		FROM deliveries d
+		JOIN subscriptions s ON d.subscription_id = s.id
+		JOIN meal_plans mp ON s.meal_plan_id = mp.id
+		WHERE d.delivery_date = $1
+		GROUP BY mp.id, mp.name, d.meal_type
+		ORDER BY %s, mp.name`, mealTypeOrder)
+
+	var lines []kitchen.ProductionLine
+	if err := r.db.SelectContext(ctx, &lines, query, date.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf("failed to get production lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *kitchenRepository) GetProductionExceptions(ctx context.Context, date time.Time) ([]kitchen.ProductionException, error) {
+	query := fmt.Sprintf(`
+		SELECT d.id as delivery_id, u.name as customer_name, mp.name as meal_plan_name,
+		       d.meal_type::text as meal_type, TRIM(s.allergies) as allergies
+		FROM deliveries d
+		JOIN users u ON d.user_id = u.id
+		JOIN subscriptions s ON d.subscription_id = s.id
+		JOIN meal_plans mp ON s.meal_plan_id = mp.id
+		WHERE d.delivery_date = $1 AND TRIM(COALESCE(s.allergies, '')) != ''
+		ORDER BY %s, mp.name, u.name`, mealTypeOrder)
+
+	var exceptions []kitchen.ProductionException
+	if err := r.db.SelectContext(ctx, &exceptions, query, date.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf("failed to get production exceptions: %w", err)
+	}
+
+	return exceptions, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"sea-catering-backend/internal/api/kitchen"
+	"sea-catering-backend/internal/api/kitchen/repository"
+	"sea-catering-backend/pkg/logger"
+)
+
+type KitchenService interface {
+	GetProductionReport(ctx context.Context, date string) (*kitchen.ProductionReport, error)
+	ExportProductionCSV(ctx context.Context, date string) ([]byte, string, error)
+	RenderProductionSheet(ctx context.Context, date string) ([]byte, error)
+}
+
+type kitchenService struct {
+	kitchenRepo repository.KitchenRepository
+	logger      *logger.Logger
+}
+
+func NewKitchenService(kitchenRepo repository.KitchenRepository, logger *logger.Logger) KitchenService {
+	return &kitchenService{
+		kitchenRepo: kitchenRepo,
+		logger:      logger,
+	}
+}
+
+func (s *kitchenService) GetProductionReport(ctx context.Context, date string) (*kitchen.ProductionReport, error) {
+	reportDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, kitchen.ErrInvalidReportDate
+	}
+
+	lines, err := s.kitchenRepo.GetProductionLines(ctx, reportDate)
+	if err != nil {
+		s.logger.Error("Failed to load production lines", logger.Fields{
+			"error": err.Error(),
+			"date":  date,
+		})
+		return nil, err
+	}
+
+	exceptions, err := s.kitchenRepo.GetProductionExceptions(ctx, reportDate)
+	if err != nil {
+		s.logger.Error("Failed to load production exceptions", logger.Fields{
+			"error": err.Error(),
+			"date":  date,
+		})
+		return nil, err
+	}
+
+	report := &kitchen.ProductionReport{
+		Date:            date,
+		TotalExceptions: len(exceptions),
+		Lines:           make([]kitchen.ProductionLine, 0, len(lines)),
+		Exceptions:      exceptions,
+		GeneratedAt:     time.Now(),
+	}
+	if report.Exceptions == nil {
+		report.Exceptions = []kitchen.ProductionException{}
+	}
+
+	for _, line := range lines {
+		line.StandardCount = line.Count - line.ExceptionCount
+		report.TotalMeals += line.Count
+		report.Lines = append(report.Lines, line)
+	}
+
+	return report, nil
+}
+
+// ExportProductionCSV writes the counts first and the exceptions below them,
+// separated by a blank row, so the sheet can be printed as-is.
+func (s *kitchenService) ExportProductionCSV(ctx context.Context, date string) ([]byte, string, error) {
+	report, err := s.GetProductionReport(ctx, date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	rows := [][]string{{"Meal Type", "Meal Plan", "Total", "Standard", "Exceptions"}}
+	for _, line := range report.Lines {
+		rows = append(rows, []string{
+			line.MealType, line.MealPlanName, strconv.Itoa(line.Count),
+			strconv.Itoa(line.StandardCount), strconv.Itoa(line.ExceptionCount),
+		})
+	}
+	rows = append(rows, []string{"", "Total", strconv.Itoa(report.TotalMeals), "", strconv.Itoa(report.TotalExceptions)})
+
+	rows = append(rows, []string{}, []string{"Meal Type", "Meal Plan", "Customer", "Allergies / Restrictions", "Delivery ID"})
+	for _, exception := range report.Exceptions {
+		rows = append(rows, []string{
+			exception.MealType, exception.MealPlanName, exception.CustomerName,
+			exception.Allergies, exception.DeliveryID,
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		s.logger.Error("Failed to write production CSV", logger.Fields{
+			"error": err.Error(),
+			"date":  date,
+		})
+		return nil, "", kitchen.ErrReportGeneration
+	}
+
+	return buf.Bytes(), fmt.Sprintf("production-%s.csv", report.Date), nil
+}
+
+// RenderProductionSheet renders a print-ready HTML page; browsers save it as
+// PDF from the print dialog.
+func (s *kitchenService) RenderProductionSheet(ctx context.Context, date string) ([]byte, error) {
+	report, err := s.GetProductionReport(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := productionSheetTemplate.Execute(&buf, report); err != nil {
+		s.logger.Error("Failed to render production sheet", logger.Fields{
+			"error": err.Error(),
+			"date":  date,
+		})
+		return nil, kitchen.ErrReportGeneration
+	}
+
+	return buf.Bytes(), nil
+}
+
+var productionSheetTemplate = template.Must(template.New("production").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Production Sheet {{.Date}} - SEA Catering</title>
+    <style>
+        body { font-family: Arial, sans-serif; color: #333; margin: 24px; }
+        h1 { font-size: 22px; margin-bottom: 4px; }
+        h2 { font-size: 16px; margin-top: 28px; }
+        .meta { color: #666; font-size: 12px; }
+        table { width: 100%; border-collapse: collapse; margin-top: 8px; }
+        th, td { border: 1px solid #ccc; padding: 6px 8px; text-align: left; font-size: 13px; }
+        th { background: #f5f5f5; }
+        td.num { text-align: right; }
+        tfoot td { font-weight: bold; }
+        .flag { color: #b00020; font-weight: bold; }
+        @media print { body { margin: 0; } h2 { page-break-after: avoid; } tr { page-break-inside: avoid; } }
+    </style>
+</head>
+<body>
+    <h1>Kitchen Production Sheet - {{.Date}}</h1>
+    <div class="meta">Generated {{.GeneratedAt.Format "2006-01-02 15:04"}} &middot; {{.TotalMeals}} meals &middot; {{.TotalExceptions}} exceptions</div>
+
+    <h2>Portions</h2>
+    <table>
+        <thead>
+            <tr><th>Meal Type</th><th>Meal Plan</th><th>Total</th><th>Standard</th><th>Exceptions</th></tr>
+        </thead>
+        <tbody>
+            {{range .Lines}}
+            <tr><td>{{.MealType}}</td><td>{{.MealPlanName}}</td><td class="num">{{.Count}}</td><td class="num">{{.StandardCount}}</td><td class="num">{{.ExceptionCount}}</td></tr>
+            {{else}}
+            <tr><td colspan="5">No deliveries scheduled for this date.</td></tr>
+            {{end}}
+        </tbody>
+        <tfoot>
+            <tr><td colspan="2">Total</td><td class="num">{{.TotalMeals}}</td><td></td><td class="num">{{.TotalExceptions}}</td></tr>
+        </tfoot>
+    </table>
+
+    <h2>Allergy &amp; Dietary Exceptions</h2>
+    <table>
+        <thead>
+            <tr><th>Meal Type</th><th>Meal Plan</th><th>Customer</th><th>Allergies / Restrictions</th></tr>
+        </thead>
+        <tbody>
+            {{range .Exceptions}}
+            <tr><td>{{.MealType}}</td><td>{{.MealPlanName}}</td><td>{{.CustomerName}}</td><td class="flag">{{.Allergies}}</td></tr>
+            {{else}}
+            <tr><td colspan="4">No exceptions.</td></tr>
+            {{end}}
+        </tbody>
+    </table>
+</body>
+</html>`))
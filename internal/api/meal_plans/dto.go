@@ -12,6 +12,7 @@ type MealPlanResponse struct {
 	ImageURL    string    `json:"image_url,omitempty"`
 	Features    []string  `json:"features"`
 	IsActive    bool      `json:"is_active"`
+	Version     int       `json:"version"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -31,6 +32,8 @@ type UpdateMealPlanRequest struct {
 	ImageURL    *string   `json:"image_url,omitempty" validate:"omitempty,url"`
 	Features    *[]string `json:"features,omitempty" validate:"omitempty,min=1"`
 	IsActive    *bool     `json:"is_active,omitempty"`
+	// Version may be sent here instead of in an If-Match header.
+	Version *int `json:"version,omitempty"`
 }
 
 type MealPlanListRequest struct {
@@ -13,13 +13,14 @@ var (
 	ErrInvalidImageURL          = errors.New("invalid image URL")
 	ErrMealPlanNameTaken        = errors.New("meal plan name is already taken")
 	ErrInvalidPaginationParams  = errors.New("invalid pagination parameters")
+	ErrVersionConflict          = errors.New("meal plan was changed by another request")
 )
 
 func GetHTTPStatusCode(err error) int {
 	switch err {
 	case ErrMealPlanNotFound:
 		return 404
-	case ErrMealPlanAlreadyExists, ErrMealPlanNameTaken:
+	case ErrMealPlanAlreadyExists, ErrMealPlanNameTaken, ErrVersionConflict:
 		return 409
 	case ErrMealPlanInactive, ErrInvalidPrice, ErrInvalidFeatures,
 		ErrInvalidSortField, ErrInvalidImageURL, ErrInvalidPaginationParams:
@@ -53,6 +54,8 @@ func GetErrorMessage(err error) string {
 		return "Invalid image URL format"
 	case ErrInvalidPaginationParams:
 		return "Invalid pagination parameters"
+	case ErrVersionConflict:
+		return "This meal plan has changed since you last loaded it. Reload it and try again"
 	default:
 		return "An unexpected error occurred"
 	}
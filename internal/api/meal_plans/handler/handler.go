@@ -51,6 +51,7 @@ func (h *MealPlanHandler) RegisterRoutes(router fiber.Router) {
 	admin.Post("/", h.CreateMealPlan)
 	admin.Put("/:id", h.UpdateMealPlan)
 	admin.Delete("/:id", h.DeleteMealPlan)
+	admin.Post("/:id/restore", h.RestoreMealPlan)
 	admin.Patch("/:id/activate", h.ActivateMealPlan)
 	admin.Patch("/:id/deactivate", h.DeactivateMealPlan)
 	admin.Patch("/bulk-status", h.BulkUpdateStatus)
@@ -158,6 +159,7 @@ func (h *MealPlanHandler) GetMealPlan(c *fiber.Ctx) error {
 		return errHandler.Handle(c, requestID, err, c.Path(), "get_meal_plan")
 	}
 
+	handlerutil.SetETag(c, mealPlan.Version)
 	return response.Success(c, mealPlan)
 }
 
@@ -206,11 +208,17 @@ func (h *MealPlanHandler) UpdateMealPlan(c *fiber.Ctx) error {
 		return errHandler.HandleValidationError(c, requestID, err, c.Path())
 	}
 
-	mealPlan, err := h.mealPlanService.UpdateMealPlan(ctx, mealPlanID, req)
+	version, ok := handlerutil.ExpectedVersion(c, req.Version)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	mealPlan, err := h.mealPlanService.UpdateMealPlan(ctx, mealPlanID, version, req)
 	if err != nil {
 		return h.handleMealPlanError(c, errHandler, requestID, err, c.Path(), "update_meal_plan")
 	}
 
+	handlerutil.SetETag(c, mealPlan.Version)
 	return response.Updated(c, mealPlan, "Meal plan updated successfully")
 }
 
@@ -234,6 +242,26 @@ func (h *MealPlanHandler) DeleteMealPlan(c *fiber.Ctx) error {
 	return response.Deleted(c, "Meal plan deleted successfully")
 }
 
+func (h *MealPlanHandler) RestoreMealPlan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.middleware.GetRequestID(c)
+
+	mealPlanID := c.Params("id")
+	if mealPlanID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Meal plan ID is required")
+	}
+
+	err := h.mealPlanService.RestoreMealPlan(ctx, mealPlanID)
+	if err != nil {
+		return h.handleMealPlanError(c, errHandler, requestID, err, c.Path(), "restore_meal_plan")
+	}
+
+	return response.Success(c, nil, "Meal plan restored successfully")
+}
+
 func (h *MealPlanHandler) ActivateMealPlan(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
 	defer cancel()
@@ -246,7 +274,12 @@ func (h *MealPlanHandler) ActivateMealPlan(c *fiber.Ctx) error {
 		return errHandler.HandleBadRequest(c, requestID, "Meal plan ID is required")
 	}
 
-	err := h.mealPlanService.ActivateMealPlan(ctx, mealPlanID)
+	version, ok := handlerutil.ReadVersion(c)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	err := h.mealPlanService.ActivateMealPlan(ctx, mealPlanID, version)
 	if err != nil {
 		return h.handleMealPlanError(c, errHandler, requestID, err, c.Path(), "activate_meal_plan")
 	}
@@ -266,7 +299,12 @@ func (h *MealPlanHandler) DeactivateMealPlan(c *fiber.Ctx) error {
 		return errHandler.HandleBadRequest(c, requestID, "Meal plan ID is required")
 	}
 
-	err := h.mealPlanService.DeactivateMealPlan(ctx, mealPlanID)
+	version, ok := handlerutil.ReadVersion(c)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	err := h.mealPlanService.DeactivateMealPlan(ctx, mealPlanID, version)
 	if err != nil {
 		return h.handleMealPlanError(c, errHandler, requestID, err, c.Path(), "deactivate_meal_plan")
 	}
@@ -326,7 +364,7 @@ func (h *MealPlanHandler) handleMealPlanError(c *fiber.Ctx, errHandler *handleru
 	switch err {
 	case meal_plans.ErrMealPlanNotFound:
 		return errHandler.HandleNotFound(c, requestID, "Meal plan")
-	case meal_plans.ErrMealPlanAlreadyExists, meal_plans.ErrMealPlanNameTaken:
+	case meal_plans.ErrMealPlanAlreadyExists, meal_plans.ErrMealPlanNameTaken, meal_plans.ErrVersionConflict:
 		return response.Conflict(c, meal_plans.GetErrorMessage(err))
 	case meal_plans.ErrMealPlanInactive, meal_plans.ErrInvalidPrice, meal_plans.ErrInvalidFeatures,
 		meal_plans.ErrInvalidSortField, meal_plans.ErrInvalidImageURL, meal_plans.ErrInvalidPaginationParams:
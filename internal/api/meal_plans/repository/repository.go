@@ -12,6 +12,7 @@ import (
 
 	"sea-catering-backend/internal/api/meal_plans"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
 )
 
 type MealPlanRepository interface {
@@ -19,7 +20,10 @@ type MealPlanRepository interface {
 	GetByID(ctx context.Context, id string) (*entity.MealPlan, error)
 	GetByName(ctx context.Context, name string) (*entity.MealPlan, error)
 	Update(ctx context.Context, mealPlan *entity.MealPlan) error
+	// Delete marks the plan deleted; reads leave it out until Restore.
+	// Subscriptions already on the plan keep showing it.
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 
 	List(ctx context.Context, params meal_plans.MealPlanListRequest) ([]entity.MealPlan, *meal_plans.PaginationMeta, error)
 	GetActive(ctx context.Context) ([]entity.MealPlan, error)
@@ -38,12 +42,12 @@ type MealPlanRepository interface {
 }
 
 type mealPlanRepository struct {
-	db *sqlx.DB
+	db *database.DB
 }
 
 func NewMealPlanRepository(db *sqlx.DB) MealPlanRepository {
 	return &mealPlanRepository{
-		db: db,
+		db: database.New(db),
 	}
 }
 
@@ -76,9 +80,9 @@ func (r *mealPlanRepository) Create(ctx context.Context, mealPlan *entity.MealPl
 
 func (r *mealPlanRepository) GetByID(ctx context.Context, id string) (*entity.MealPlan, error) {
 	query := `
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var mealPlan entity.MealPlan
@@ -86,7 +90,7 @@ func (r *mealPlanRepository) GetByID(ctx context.Context, id string) (*entity.Me
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-		&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+		&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 		&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 	)
 
@@ -103,9 +107,9 @@ func (r *mealPlanRepository) GetByID(ctx context.Context, id string) (*entity.Me
 
 func (r *mealPlanRepository) GetByName(ctx context.Context, name string) (*entity.MealPlan, error) {
 	query := `
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
-		WHERE LOWER(name) = LOWER($1)
+		WHERE LOWER(name) = LOWER($1) AND deleted_at IS NULL
 	`
 
 	var mealPlan entity.MealPlan
@@ -113,7 +117,7 @@ func (r *mealPlanRepository) GetByName(ctx context.Context, name string) (*entit
 
 	err := r.db.QueryRowContext(ctx, query, name).Scan(
 		&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-		&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+		&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 		&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 	)
 
@@ -128,19 +132,34 @@ func (r *mealPlanRepository) GetByName(ctx context.Context, name string) (*entit
 	return &mealPlan, nil
 }
 
+// Update saves the plan only if it is still at mealPlan.Version, and
+// advances the version on success.
 func (r *mealPlanRepository) Update(ctx context.Context, mealPlan *entity.MealPlan) error {
 	query := `
 		UPDATE meal_plans
 		SET name = $2, description = $3, price = $4, image_url = $5, 
-		    features = $6, is_active = $7, updated_at = $8
-		WHERE id = $1
+		    features = $6, is_active = $7, updated_at = $8, version = version + 1
+		WHERE id = $1 AND version = $9 AND deleted_at IS NULL
+		RETURNING version
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	var version int
+	err := r.db.QueryRowContext(ctx, query,
 		mealPlan.ID, mealPlan.Name, mealPlan.Description, mealPlan.Price,
 		mealPlan.ImageURL, pq.Array(mealPlan.Features), mealPlan.IsActive,
-		mealPlan.UpdatedAt,
-	)
+		mealPlan.UpdatedAt, mealPlan.Version,
+	).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		exists, existsErr := r.ExistsByID(ctx, mealPlan.ID)
+		if existsErr != nil {
+			return existsErr
+		}
+		if exists {
+			return meal_plans.ErrVersionConflict
+		}
+		return meal_plans.ErrMealPlanNotFound
+	}
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -154,46 +173,75 @@ func (r *mealPlanRepository) Update(ctx context.Context, mealPlan *entity.MealPl
 		return fmt.Errorf("failed to update meal plan: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return meal_plans.ErrMealPlanNotFound
-	}
-
+	mealPlan.Version = version
 	return nil
 }
 
+// Delete marks the plan deleted. It locks the plan before counting its
+// subscriptions, and a subscription being created on it holds a share lock
+// on the plan, so one cannot slip in between.
 func (r *mealPlanRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var lockedID string
+		err := r.db.GetContext(ctx, &lockedID, `SELECT id FROM meal_plans WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return meal_plans.ErrMealPlanNotFound
+			}
+			return fmt.Errorf("failed to lock meal plan: %w", err)
+		}
 
-	subscriptionCount, err := r.GetSubscriptionCount(ctx, id)
-	if err != nil {
-		return err
-	}
-
-	if subscriptionCount > 0 {
-		return meal_plans.ErrMealPlanHasSubscriptions
-	}
+		subscriptionCount, err := r.GetSubscriptionCount(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	query := `DELETE FROM meal_plans WHERE id = $1`
+		if subscriptionCount > 0 {
+			return meal_plans.ErrMealPlanHasSubscriptions
+		}
 
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete meal plan: %w", err)
-	}
+		query := `
+			UPDATE meal_plans
+			SET deleted_at = $2, updated_at = $2, version = version + 1
+			WHERE id = $1
+		`
+		if _, err := r.db.ExecContext(ctx, query, id, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete meal plan: %w", err)
+		}
+		return nil
+	})
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+// Restore undeletes the plan unless another plan has taken its name since.
+func (r *mealPlanRepository) Restore(ctx context.Context, id string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var name string
+		err := r.db.GetContext(ctx, &name, `SELECT name FROM meal_plans WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return meal_plans.ErrMealPlanNotFound
+			}
+			return fmt.Errorf("failed to lock meal plan: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		return meal_plans.ErrMealPlanNotFound
-	}
+		taken, err := r.ExistsByName(ctx, name, id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return meal_plans.ErrMealPlanNameTaken
+		}
 
-	return nil
+		query := `
+			UPDATE meal_plans
+			SET deleted_at = NULL, updated_at = $2, version = version + 1
+			WHERE id = $1
+		`
+		if _, err := r.db.ExecContext(ctx, query, id, time.Now()); err != nil {
+			return fmt.Errorf("failed to restore meal plan: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPlanListRequest) ([]entity.MealPlan, *meal_plans.PaginationMeta, error) {
@@ -214,7 +262,7 @@ func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPla
 		params.SortDir = "desc"
 	}
 
-	whereConditions := []string{}
+	whereConditions := []string{"deleted_at IS NULL"}
 	args := []interface{}{}
 	argIndex := 1
 
@@ -230,10 +278,7 @@ func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPla
 		argIndex++
 	}
 
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
-	}
+	whereClause := "WHERE " + strings.Join(whereConditions, " AND ")
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM meal_plans %s", whereClause)
 	var total int
@@ -259,7 +304,7 @@ func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPla
 	orderClause := fmt.Sprintf("ORDER BY %s %s", params.SortBy, strings.ToUpper(params.SortDir))
 
 	query := fmt.Sprintf(`
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
 		%s
 		%s
@@ -281,7 +326,7 @@ func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPla
 
 		err := rows.Scan(
 			&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-			&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+			&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 			&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 		)
 		if err != nil {
@@ -310,9 +355,9 @@ func (r *mealPlanRepository) List(ctx context.Context, params meal_plans.MealPla
 
 func (r *mealPlanRepository) GetActive(ctx context.Context) ([]entity.MealPlan, error) {
 	query := `
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
-		WHERE is_active = true
+		WHERE is_active = true AND deleted_at IS NULL
 		ORDER BY name
 	`
 
@@ -329,7 +374,7 @@ func (r *mealPlanRepository) GetActive(ctx context.Context) ([]entity.MealPlan,
 
 		err := rows.Scan(
 			&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-			&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+			&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 			&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 		)
 		if err != nil {
@@ -349,9 +394,9 @@ func (r *mealPlanRepository) Search(ctx context.Context, query string, limit int
 	}
 
 	searchQuery := `
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
-		WHERE is_active = true AND (
+		WHERE is_active = true AND deleted_at IS NULL AND (
 			LOWER(name) LIKE LOWER($1) OR 
 			LOWER(description) LIKE LOWER($1) OR
 			EXISTS (
@@ -379,7 +424,7 @@ func (r *mealPlanRepository) Search(ctx context.Context, query string, limit int
 
 		err := rows.Scan(
 			&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-			&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+			&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 			&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 		)
 		if err != nil {
@@ -397,7 +442,7 @@ func (r *mealPlanRepository) ExistsByName(ctx context.Context, name string, excl
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM meal_plans 
-			WHERE LOWER(name) = LOWER($1) AND ($2 = '' OR id != $2)
+			WHERE LOWER(name) = LOWER($1) AND ($2 = '' OR id != $2) AND deleted_at IS NULL
 		)
 	`
 
@@ -411,7 +456,7 @@ func (r *mealPlanRepository) ExistsByName(ctx context.Context, name string, excl
 }
 
 func (r *mealPlanRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM meal_plans WHERE id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM meal_plans WHERE id = $1 AND deleted_at IS NULL)`
 
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
@@ -423,7 +468,7 @@ func (r *mealPlanRepository) ExistsByID(ctx context.Context, id string) (bool, e
 }
 
 func (r *mealPlanRepository) IsActive(ctx context.Context, id string) (bool, error) {
-	query := `SELECT is_active FROM meal_plans WHERE id = $1`
+	query := `SELECT is_active FROM meal_plans WHERE id = $1 AND deleted_at IS NULL`
 
 	var isActive bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&isActive)
@@ -445,6 +490,7 @@ func (r *mealPlanRepository) GetStats(ctx context.Context) (*meal_plans.MealPlan
 			COUNT(CASE WHEN is_active = false THEN 1 END) as inactive,
 			COALESCE(AVG(price), 0) as average_price
 		FROM meal_plans
+		WHERE deleted_at IS NULL
 	`
 
 	var stats meal_plans.MealPlanStatsResponse
@@ -461,8 +507,8 @@ func (r *mealPlanRepository) GetStats(ctx context.Context) (*meal_plans.MealPlan
 	popularQuery := `
 		SELECT mp.name
 		FROM meal_plans mp
-		LEFT JOIN subscriptions s ON mp.id = s.meal_plan_id AND s.status = 'active'
-		WHERE mp.is_active = true
+		LEFT JOIN subscriptions s ON mp.id = s.meal_plan_id AND s.status = 'active' AND s.deleted_at IS NULL
+		WHERE mp.is_active = true AND mp.deleted_at IS NULL
 		GROUP BY mp.id, mp.name
 		ORDER BY COUNT(s.id) DESC
 		LIMIT 1
@@ -477,7 +523,8 @@ func (r *mealPlanRepository) GetStats(ctx context.Context) (*meal_plans.MealPlan
 		SELECT COUNT(DISTINCT user_id)
 		FROM subscriptions s
 		JOIN meal_plans mp ON s.meal_plan_id = mp.id
-		WHERE s.status = 'active' AND mp.is_active = true
+		WHERE s.status = 'active' AND s.deleted_at IS NULL
+		AND mp.is_active = true AND mp.deleted_at IS NULL
 	`
 
 	err = r.db.QueryRowContext(ctx, subscriberQuery).Scan(&stats.TotalSubscribers)
@@ -492,7 +539,7 @@ func (r *mealPlanRepository) GetSubscriptionCount(ctx context.Context, mealPlanI
 	query := `
 		SELECT COUNT(*)
 		FROM subscriptions
-		WHERE meal_plan_id = $1 AND status = 'active'
+		WHERE meal_plan_id = $1 AND status = 'active' AND deleted_at IS NULL
 	`
 
 	var count int
@@ -508,11 +555,11 @@ func (r *mealPlanRepository) GetPopularityRanking(ctx context.Context) ([]entity
 	query := `
 		SELECT 
 			mp.id, mp.name, mp.description, mp.price, mp.image_url, 
-			mp.features, mp.is_active, mp.created_at, mp.updated_at,
+			mp.features, mp.is_active, mp.version, mp.created_at, mp.updated_at,
 			COUNT(s.id) as subscription_count
 		FROM meal_plans mp
-		LEFT JOIN subscriptions s ON mp.id = s.meal_plan_id AND s.status = 'active'
-		WHERE mp.is_active = true
+		LEFT JOIN subscriptions s ON mp.id = s.meal_plan_id AND s.status = 'active' AND s.deleted_at IS NULL
+		WHERE mp.is_active = true AND mp.deleted_at IS NULL
 		GROUP BY mp.id, mp.name, mp.description, mp.price, mp.image_url, 
 		         mp.features, mp.is_active, mp.created_at, mp.updated_at
 		ORDER BY subscription_count DESC, mp.name
@@ -532,7 +579,7 @@ func (r *mealPlanRepository) GetPopularityRanking(ctx context.Context) ([]entity
 
 		err := rows.Scan(
 			&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-			&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+			&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 			&mealPlan.CreatedAt, &mealPlan.UpdatedAt, &subscriptionCount,
 		)
 		if err != nil {
@@ -553,8 +600,8 @@ func (r *mealPlanRepository) UpdateActiveStatus(ctx context.Context, ids []strin
 
 	query := `
 		UPDATE meal_plans 
-		SET is_active = $1, updated_at = $2
-		WHERE id = ANY($3)
+		SET is_active = $1, updated_at = $2, version = version + 1
+		WHERE id = ANY($3) AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query, isActive, time.Now(), pq.Array(ids))
@@ -571,9 +618,9 @@ func (r *mealPlanRepository) GetByIDs(ctx context.Context, ids []string) ([]enti
 	}
 
 	query := `
-		SELECT id, name, description, price, image_url, features, is_active, created_at, updated_at
+		SELECT id, name, description, price, image_url, features, is_active, version, created_at, updated_at
 		FROM meal_plans
-		WHERE id = ANY($1)
+		WHERE id = ANY($1) AND deleted_at IS NULL
 		ORDER BY name
 	`
 
@@ -590,7 +637,7 @@ func (r *mealPlanRepository) GetByIDs(ctx context.Context, ids []string) ([]enti
 
 		err := rows.Scan(
 			&mealPlan.ID, &mealPlan.Name, &mealPlan.Description, &mealPlan.Price,
-			&mealPlan.ImageURL, &features, &mealPlan.IsActive,
+			&mealPlan.ImageURL, &features, &mealPlan.IsActive, &mealPlan.Version,
 			&mealPlan.CreatedAt, &mealPlan.UpdatedAt,
 		)
 		if err != nil {
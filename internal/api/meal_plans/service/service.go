@@ -15,15 +15,18 @@ import (
 type MealPlanService interface {
 	CreateMealPlan(ctx context.Context, req meal_plans.CreateMealPlanRequest) (*meal_plans.MealPlanResponse, error)
 	GetMealPlanByID(ctx context.Context, id string) (*meal_plans.MealPlanResponse, error)
-	UpdateMealPlan(ctx context.Context, id string, req meal_plans.UpdateMealPlanRequest) (*meal_plans.MealPlanResponse, error)
+	// UpdateMealPlan, ActivateMealPlan and DeactivateMealPlan fail with
+	// ErrVersionConflict unless the plan is still at version.
+	UpdateMealPlan(ctx context.Context, id string, version int, req meal_plans.UpdateMealPlanRequest) (*meal_plans.MealPlanResponse, error)
 	DeleteMealPlan(ctx context.Context, id string) error
+	RestoreMealPlan(ctx context.Context, id string) error
 
 	GetAllMealPlans(ctx context.Context, params meal_plans.MealPlanListRequest) (*meal_plans.MealPlanListResponse, error)
 	GetActiveMealPlans(ctx context.Context) ([]meal_plans.MealPlanResponse, error)
 	SearchMealPlans(ctx context.Context, query string, limit int) ([]meal_plans.MealPlanResponse, error)
 
-	ActivateMealPlan(ctx context.Context, id string) error
-	DeactivateMealPlan(ctx context.Context, id string) error
+	ActivateMealPlan(ctx context.Context, id string, version int) error
+	DeactivateMealPlan(ctx context.Context, id string, version int) error
 	BulkUpdateActiveStatus(ctx context.Context, ids []string, isActive bool) error
 
 	GetMealPlanStats(ctx context.Context) (*meal_plans.MealPlanStatsResponse, error)
@@ -78,6 +81,7 @@ func (s *mealPlanService) CreateMealPlan(ctx context.Context, req meal_plans.Cre
 		ImageURL:    req.ImageURL,
 		Features:    s.cleanFeatures(req.Features),
 		IsActive:    true,
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -115,13 +119,17 @@ func (s *mealPlanService) GetMealPlanByID(ctx context.Context, id string) (*meal
 	return s.entityToResponse(mealPlan), nil
 }
 
-func (s *mealPlanService) UpdateMealPlan(ctx context.Context, id string, req meal_plans.UpdateMealPlanRequest) (*meal_plans.MealPlanResponse, error) {
+func (s *mealPlanService) UpdateMealPlan(ctx context.Context, id string, version int, req meal_plans.UpdateMealPlanRequest) (*meal_plans.MealPlanResponse, error) {
 
 	existingPlan, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if existingPlan.Version != version {
+		return nil, meal_plans.ErrVersionConflict
+	}
+
 	if err := s.validateUpdateRequest(ctx, req, id); err != nil {
 		return nil, err
 	}
@@ -167,6 +175,22 @@ func (s *mealPlanService) DeleteMealPlan(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *mealPlanService) RestoreMealPlan(ctx context.Context, id string) error {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		s.logger.Error("Failed to restore meal plan", logger.Fields{
+			"id":    id,
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	s.logger.Info("Meal plan restored", logger.Fields{
+		"id": id,
+	})
+
+	return nil
+}
+
 func (s *mealPlanService) GetAllMealPlans(ctx context.Context, params meal_plans.MealPlanListRequest) (*meal_plans.MealPlanListResponse, error) {
 
 	if params.Page < 0 || params.Limit < 0 || params.Limit > 100 {
@@ -237,12 +261,16 @@ func (s *mealPlanService) SearchMealPlans(ctx context.Context, query string, lim
 	return responses, nil
 }
 
-func (s *mealPlanService) ActivateMealPlan(ctx context.Context, id string) error {
+func (s *mealPlanService) ActivateMealPlan(ctx context.Context, id string, version int) error {
 	mealPlan, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if mealPlan.Version != version {
+		return meal_plans.ErrVersionConflict
+	}
+
 	if mealPlan.IsActive {
 		return nil
 	}
@@ -266,12 +294,16 @@ func (s *mealPlanService) ActivateMealPlan(ctx context.Context, id string) error
 	return nil
 }
 
-func (s *mealPlanService) DeactivateMealPlan(ctx context.Context, id string) error {
+func (s *mealPlanService) DeactivateMealPlan(ctx context.Context, id string, version int) error {
 	mealPlan, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	if mealPlan.Version != version {
+		return meal_plans.ErrVersionConflict
+	}
+
 	if !mealPlan.IsActive {
 		return nil
 	}
@@ -483,6 +515,7 @@ func (s *mealPlanService) entityToResponse(plan *entity.MealPlan) *meal_plans.Me
 		ImageURL:    plan.ImageURL,
 		Features:    plan.Features,
 		IsActive:    plan.IsActive,
+		Version:     plan.Version,
 		CreatedAt:   plan.CreatedAt,
 		UpdatedAt:   plan.UpdatedAt,
 	}
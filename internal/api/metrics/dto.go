@@ -0,0 +1,81 @@
+package metrics
+
+import "sea-catering-backend/internal/entity"
+
+type DailyMetricsRequest struct {
+	StartDate string `query:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+type DailyMetricsResponse struct {
+	Days []entity.DailyMetrics `json:"days"`
+}
+
+type BackfillRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+type BackfillResponse struct {
+	Written int `json:"written"`
+	// Skipped days already have a live snapshot, which is kept.
+	Skipped int `json:"skipped"`
+}
+
+type CohortRequest struct {
+	StartMonth string `query:"start_month" validate:"omitempty,datetime=2006-01"`
+	EndMonth   string `query:"end_month" validate:"omitempty,datetime=2006-01"`
+	// Months is how many months after signup to report retention for.
+	Months     int    `query:"months" validate:"omitempty,min=1,max=24"`
+	MealPlanID string `query:"meal_plan_id" validate:"omitempty,max=36"`
+}
+
+type CohortResponse struct {
+	StartMonth string      `json:"start_month"`
+	EndMonth   string      `json:"end_month"`
+	Cohorts    []Cohort    `json:"cohorts"`
+	Plans      []PlanChurn `json:"plans"`
+	Lifetime   Lifetime    `json:"lifetime"`
+}
+
+// Cohort groups the subscriptions started in one month.
+type Cohort struct {
+	Month         string            `json:"month"`
+	Subscriptions int               `json:"subscriptions"`
+	Retention     []CohortRetention `json:"retention"`
+}
+
+// CohortRetention is the share of a cohort not cancelled the given number
+// of months after signup. Only subscriptions old enough to have reached
+// that point are counted, so the latest cohorts have fewer entries.
+type CohortRetention struct {
+	Month    int     `json:"month"`
+	Eligible int     `json:"eligible"`
+	Retained int     `json:"retained"`
+	Rate     float64 `json:"rate"`
+}
+
+type PlanChurn struct {
+	MealPlanID    string `json:"meal_plan_id"`
+	MealPlanName  string `json:"meal_plan_name"`
+	Subscriptions int    `json:"subscriptions"`
+	Cancellations int    `json:"cancellations"`
+	// MonthlyChurnRate is cancellations per 100 months of subscription.
+	MonthlyChurnRate      float64  `json:"monthly_churn_rate"`
+	AverageLifetimeDays   float64  `json:"average_lifetime_days"`
+	AverageMonthlyRevenue float64  `json:"average_monthly_revenue"`
+	LifetimeValue         *float64 `json:"lifetime_value"`
+}
+
+type Lifetime struct {
+	Subscriptions         int     `json:"subscriptions"`
+	Customers             int     `json:"customers"`
+	Cancellations         int     `json:"cancellations"`
+	MonthlyChurnRate      float64 `json:"monthly_churn_rate"`
+	AverageLifetimeDays   float64 `json:"average_lifetime_days"`
+	AverageMonthlyRevenue float64 `json:"average_monthly_revenue"`
+	RevenueToDate         float64 `json:"revenue_to_date"`
+	// CustomerLifetimeValue is nil until a cancellation has been seen, as
+	// the expected lifetime is unbounded without one.
+	CustomerLifetimeValue *float64 `json:"customer_lifetime_value"`
+}
@@ -0,0 +1,10 @@
+package metrics
+
+import "errors"
+
+var (
+	ErrMetricsNotFound   = errors.New("no metrics recorded for the requested period")
+	ErrInvalidDateRange  = errors.New("start_date must not be after end_date")
+	ErrDateRangeTooLong  = errors.New("date range is too long")
+	ErrInvalidMonthRange = errors.New("start_month must not be after end_month")
+)
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/api/metrics/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+)
+
+type MetricsHandler struct {
+	metricsService service.MetricsService
+	validator      *validator.Validate
+	middleware     middleware.Interface
+	logger         *logger.Logger
+}
+
+func NewMetricsHandler(
+	metricsService service.MetricsService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: metricsService,
+		validator:      validator,
+		middleware:     middleware,
+		logger:         logger,
+	}
+}
+
+func (h *MetricsHandler) RegisterRoutes(router fiber.Router) {
+	metricsGroup := router.Group("/metrics")
+
+	admin := metricsGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/daily", h.GetDailyMetrics)
+	admin.Get("/cohorts", h.GetCohorts)
+	admin.Post("/snapshot", h.Snapshot)
+	admin.Post("/backfill", h.Backfill)
+}
+
+func (h *MetricsHandler) GetDailyMetrics(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req metrics.DailyMetricsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.metricsService.GetDailyMetrics(ctx, req)
+	if err != nil {
+		return h.handleMetricsError(c, errHandler, requestID, err, c.Path(), "get_daily_metrics")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *MetricsHandler) GetCohorts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req metrics.CohortRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.metricsService.GetCohorts(ctx, req)
+	if err != nil {
+		return h.handleMetricsError(c, errHandler, requestID, err, c.Path(), "get_cohorts")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *MetricsHandler) Snapshot(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	result, err := h.metricsService.Snapshot(ctx)
+	if err != nil {
+		return h.handleMetricsError(c, errHandler, requestID, err, c.Path(), "snapshot_daily_metrics")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *MetricsHandler) Backfill(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 5*time.Minute)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req metrics.BackfillRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.metricsService.Backfill(ctx, req)
+	if err != nil {
+		return h.handleMetricsError(c, errHandler, requestID, err, c.Path(), "backfill_daily_metrics")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *MetricsHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *MetricsHandler) handleMetricsError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case metrics.ErrInvalidDateRange, metrics.ErrInvalidMonthRange, metrics.ErrDateRangeTooLong:
+		return errHandler.HandleBadRequest(c, requestID, err.Error())
+	case metrics.ErrMetricsNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Daily metrics")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type MetricsRepository interface {
+	// Compute reads end-of-day figures from the subscriptions as they are
+	// now. It is only accurate for a day that has just ended.
+	Compute(ctx context.Context, day time.Time) (*entity.DailyMetrics, error)
+	// Reconstruct rebuilds figures for a past day from the subscription
+	// audit log.
+	Reconstruct(ctx context.Context, day time.Time) (*entity.DailyMetrics, error)
+	// Upsert stores the day's figures. A backfilled row never replaces a
+	// snapshot; it reports false when the existing row was kept.
+	Upsert(ctx context.Context, m *entity.DailyMetrics) (bool, error)
+	Get(ctx context.Context, day time.Time) (*entity.DailyMetrics, error)
+	GetLatestOnOrBefore(ctx context.Context, day time.Time) (*entity.DailyMetrics, error)
+	ListRange(ctx context.Context, startDate, endDate time.Time) ([]entity.DailyMetrics, error)
+	SumActivity(ctx context.Context, startDate, endDate time.Time) (*Activity, error)
+	// ListLifecycles returns subscriptions started in [from, to) with their
+	// audited status changes, oldest first.
+	ListLifecycles(ctx context.Context, from, to time.Time, mealPlanID string) ([]Lifecycle, error)
+}
+
+// Activity totals what happened over a range of days.
+type Activity struct {
+	NewSubscriptions     int `db:"new_subscriptions"`
+	ChurnedSubscriptions int `db:"churned_subscriptions"`
+	Reactivations        int `db:"reactivations"`
+}
+
+// Lifecycle is a subscription with the status changes recorded for it.
+type Lifecycle struct {
+	ID           string
+	UserID       string
+	MealPlanID   string
+	MealPlanName string
+	TotalPrice   float64
+	Status       entity.SubscriptionStatus
+	CreatedAt    time.Time
+	Changes      []StatusChange
+}
+
+type StatusChange struct {
+	Status entity.SubscriptionStatus
+	At     time.Time
+}
+
+type metricsRepository struct {
+	db *database.DB
+}
+
+func NewMetricsRepository(db *sqlx.DB) MetricsRepository {
+	return &metricsRepository{
+		db: database.New(db),
+	}
+}
+
+const metricsColumns = `metric_date, mrr, active_subscriptions, paused_subscriptions, cancelled_subscriptions,
+	new_subscriptions, churned_subscriptions, reactivations, revenue_by_plan, source, computed_at`
+
+// Status of every subscription that existed at $1, by plan.
+const liveStatusQuery = `
+	SELECT s.status::text, s.meal_plan_id, mp.name, COUNT(*), COALESCE(SUM(s.total_price), 0)
+	FROM subscriptions s
+	JOIN meal_plans mp ON mp.id = s.meal_plan_id
+	WHERE s.created_at < $1 AND s.deleted_at IS NULL
+	GROUP BY s.status, s.meal_plan_id, mp.name
+`
+
+// The status at $1 is the latest audited status before it. Without one,
+// the first later change tells us what it was changed from; subscriptions
+// that were never audited are assumed to have always had their current
+// status. Prices are today's, as price changes are not audited.
+const auditedStatusQuery = `
+	WITH as_of AS (
+		SELECT s.meal_plan_id, s.total_price,
+			COALESCE(
+				(SELECT a.new_status FROM subscription_audit a
+				 WHERE a.subscription_id = s.id AND a.created_at < $1
+				 ORDER BY a.created_at DESC, a.id DESC LIMIT 1),
+				(SELECT NULLIF(a.old_status, '') FROM subscription_audit a
+				 WHERE a.subscription_id = s.id AND a.created_at >= $1
+				 ORDER BY a.created_at, a.id LIMIT 1),
+				s.status::text
+			) AS status
+		FROM subscriptions s
+		WHERE s.created_at < $1 AND s.deleted_at IS NULL
+	)
+	SELECT h.status, h.meal_plan_id, mp.name, COUNT(*), COALESCE(SUM(h.total_price), 0)
+	FROM as_of h
+	JOIN meal_plans mp ON mp.id = h.meal_plan_id
+	GROUP BY h.status, h.meal_plan_id, mp.name
+`
+
+// Churn counts only audited moves into cancelled or expired. updated_at is
+// no guide, as erasing or restoring a closed subscription bumps it too.
+const activityQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM subscriptions
+		 WHERE created_at >= $1 AND created_at < $2 AND deleted_at IS NULL) AS new_subscriptions,
+		(SELECT COUNT(DISTINCT subscription_id) FROM subscription_audit
+		 WHERE new_status IN ('cancelled', 'expired')
+		 AND COALESCE(old_status, '') NOT IN ('cancelled', 'expired')
+		 AND created_at >= $1 AND created_at < $2) AS churned_subscriptions,
+		(SELECT COUNT(DISTINCT subscription_id) FROM subscription_audit
+		 WHERE action = 'reactivated' AND created_at >= $1 AND created_at < $2) AS reactivations
+`
+
+func (r *metricsRepository) Compute(ctx context.Context, day time.Time) (*entity.DailyMetrics, error) {
+	return r.build(ctx, day, liveStatusQuery, entity.MetricsSnapshot)
+}
+
+func (r *metricsRepository) Reconstruct(ctx context.Context, day time.Time) (*entity.DailyMetrics, error) {
+	return r.build(ctx, day, auditedStatusQuery, entity.MetricsBackfill)
+}
+
+func (r *metricsRepository) build(ctx context.Context, day time.Time, statusQuery string, source entity.MetricsSource) (*entity.DailyMetrics, error) {
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	m := &entity.DailyMetrics{
+		Date:          dayStart,
+		RevenueByPlan: []entity.PlanRevenue{},
+		Source:        source,
+		ComputedAt:    time.Now(),
+	}
+
+	rows, err := r.db.QueryContext(ctx, statusQuery, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subscriptions by status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, planID, planName string
+		var count int
+		var revenue float64
+		if err := rows.Scan(&status, &planID, &planName, &count, &revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription counts: %w", err)
+		}
+
+		switch entity.SubscriptionStatus(status) {
+		case entity.StatusActive:
+			m.ActiveSubscriptions += count
+			m.MRR += revenue
+			m.RevenueByPlan = append(m.RevenueByPlan, entity.PlanRevenue{
+				MealPlanID:          planID,
+				MealPlanName:        planName,
+				ActiveSubscriptions: count,
+				MRR:                 revenue,
+			})
+		case entity.StatusPaused:
+			m.PausedSubscriptions += count
+		case entity.StatusCancelled, entity.StatusExpired:
+			m.CancelledSubscriptions += count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	sort.Slice(m.RevenueByPlan, func(i, j int) bool {
+		return m.RevenueByPlan[i].MRR > m.RevenueByPlan[j].MRR
+	})
+
+	var activity Activity
+	if err := r.db.GetContext(ctx, &activity, activityQuery, dayStart, dayEnd); err != nil {
+		return nil, fmt.Errorf("failed to count subscription activity: %w", err)
+	}
+
+	m.NewSubscriptions = activity.NewSubscriptions
+	m.ChurnedSubscriptions = activity.ChurnedSubscriptions
+	m.Reactivations = activity.Reactivations
+
+	return m, nil
+}
+
+func (r *metricsRepository) Upsert(ctx context.Context, m *entity.DailyMetrics) (bool, error) {
+	revenueByPlan, err := json.Marshal(m.RevenueByPlan)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode revenue by plan: %w", err)
+	}
+
+	query := `
+		INSERT INTO daily_metrics (` + metricsColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (metric_date) DO UPDATE SET
+			mrr = EXCLUDED.mrr,
+			active_subscriptions = EXCLUDED.active_subscriptions,
+			paused_subscriptions = EXCLUDED.paused_subscriptions,
+			cancelled_subscriptions = EXCLUDED.cancelled_subscriptions,
+			new_subscriptions = EXCLUDED.new_subscriptions,
+			churned_subscriptions = EXCLUDED.churned_subscriptions,
+			reactivations = EXCLUDED.reactivations,
+			revenue_by_plan = EXCLUDED.revenue_by_plan,
+			source = EXCLUDED.source,
+			computed_at = EXCLUDED.computed_at
+		WHERE daily_metrics.source = 'backfill' OR EXCLUDED.source = 'snapshot'
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		m.Date.Format("2006-01-02"), m.MRR, m.ActiveSubscriptions, m.PausedSubscriptions, m.CancelledSubscriptions,
+		m.NewSubscriptions, m.ChurnedSubscriptions, m.Reactivations, revenueByPlan, m.Source, m.ComputedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to save daily metrics: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *metricsRepository) Get(ctx context.Context, day time.Time) (*entity.DailyMetrics, error) {
+	query := `SELECT ` + metricsColumns + ` FROM daily_metrics WHERE metric_date = $1`
+
+	m, err := scanMetrics(r.db.QueryRowContext(ctx, query, day.Format("2006-01-02")))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, metrics.ErrMetricsNotFound
+		}
+		return nil, fmt.Errorf("failed to get daily metrics: %w", err)
+	}
+
+	return m, nil
+}
+
+func (r *metricsRepository) GetLatestOnOrBefore(ctx context.Context, day time.Time) (*entity.DailyMetrics, error) {
+	query := `
+		SELECT ` + metricsColumns + `
+		FROM daily_metrics
+		WHERE metric_date <= $1
+		ORDER BY metric_date DESC
+		LIMIT 1
+	`
+
+	m, err := scanMetrics(r.db.QueryRowContext(ctx, query, day.Format("2006-01-02")))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, metrics.ErrMetricsNotFound
+		}
+		return nil, fmt.Errorf("failed to get daily metrics: %w", err)
+	}
+
+	return m, nil
+}
+
+func (r *metricsRepository) ListRange(ctx context.Context, startDate, endDate time.Time) ([]entity.DailyMetrics, error) {
+	query := `
+		SELECT ` + metricsColumns + `
+		FROM daily_metrics
+		WHERE metric_date BETWEEN $1 AND $2
+		ORDER BY metric_date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily metrics: %w", err)
+	}
+	defer rows.Close()
+
+	days := []entity.DailyMetrics{}
+	for rows.Next() {
+		m, err := scanMetrics(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily metrics: %w", err)
+		}
+		days = append(days, *m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return days, nil
+}
+
+func (r *metricsRepository) SumActivity(ctx context.Context, startDate, endDate time.Time) (*Activity, error) {
+	query := `
+		SELECT
+			COALESCE(SUM(new_subscriptions), 0) AS new_subscriptions,
+			COALESCE(SUM(churned_subscriptions), 0) AS churned_subscriptions,
+			COALESCE(SUM(reactivations), 0) AS reactivations
+		FROM daily_metrics
+		WHERE metric_date BETWEEN $1 AND $2
+	`
+
+	var activity Activity
+	if err := r.db.GetContext(ctx, &activity, query, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf("failed to sum daily metrics: %w", err)
+	}
+
+	return &activity, nil
+}
+
+func (r *metricsRepository) ListLifecycles(ctx context.Context, from, to time.Time, mealPlanID string) ([]Lifecycle, error) {
+	query := `
+		SELECT s.id, s.user_id, s.meal_plan_id, mp.name, s.total_price, s.status::text, s.created_at
+		FROM subscriptions s
+		JOIN meal_plans mp ON mp.id = s.meal_plan_id
+		WHERE s.created_at >= $1 AND s.created_at < $2 AND s.deleted_at IS NULL
+		AND ($3 = '' OR s.meal_plan_id = $3)
+		ORDER BY s.created_at, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, mealPlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	lifecycles := []Lifecycle{}
+	index := map[string]int{}
+	for rows.Next() {
+		var l Lifecycle
+		var status string
+		if err := rows.Scan(&l.ID, &l.UserID, &l.MealPlanID, &l.MealPlanName, &l.TotalPrice, &status, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		l.Status = entity.SubscriptionStatus(status)
+		index[l.ID] = len(lifecycles)
+		lifecycles = append(lifecycles, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	auditQuery := `
+		SELECT a.subscription_id, a.new_status, a.created_at
+		FROM subscription_audit a
+		JOIN subscriptions s ON s.id = a.subscription_id
+		WHERE s.created_at >= $1 AND s.created_at < $2 AND s.deleted_at IS NULL
+		AND ($3 = '' OR s.meal_plan_id = $3)
+		AND a.action <> 'updated'
+		ORDER BY a.created_at, a.id
+	`
+
+	auditRows, err := r.db.QueryContext(ctx, auditQuery, from, to, mealPlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription audit: %w", err)
+	}
+	defer auditRows.Close()
+
+	for auditRows.Next() {
+		var subscriptionID, status string
+		var at time.Time
+		if err := auditRows.Scan(&subscriptionID, &status, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription audit: %w", err)
+		}
+		if i, ok := index[subscriptionID]; ok {
+			lifecycles[i].Changes = append(lifecycles[i].Changes, StatusChange{
+				Status: entity.SubscriptionStatus(status),
+				At:     at,
+			})
+		}
+	}
+
+	if err := auditRows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return lifecycles, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMetrics(row rowScanner) (*entity.DailyMetrics, error) {
+	var m entity.DailyMetrics
+	var revenueByPlan []byte
+	err := row.Scan(
+		&m.Date, &m.MRR, &m.ActiveSubscriptions, &m.PausedSubscriptions, &m.CancelledSubscriptions,
+		&m.NewSubscriptions, &m.ChurnedSubscriptions, &m.Reactivations, &revenueByPlan, &m.Source, &m.ComputedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(revenueByPlan, &m.RevenueByPlan); err != nil {
+		return nil, fmt.Errorf("failed to decode revenue by plan: %w", err)
+	}
+	return &m, nil
+}
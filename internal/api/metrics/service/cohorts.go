@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/api/metrics/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/logger"
+)
+
+const (
+	defaultCohortMonths    = 12
+	defaultRetentionMonths = 12
+	maxCohortMonths        = 36
+	// daysPerMonth converts durations to the monthly prices subscriptions
+	// are charged at.
+	daysPerMonth = 30
+)
+
+// GetCohorts groups subscriptions by the month they started in and follows
+// each one through its audited status changes up to now.
+func (s *metricsService) GetCohorts(ctx context.Context, req metrics.CohortRequest) (*metrics.CohortResponse, error) {
+	startMonth, endMonth, err := parseMonthRange(req.StartMonth, req.EndMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	months := req.Months
+	if months == 0 {
+		months = defaultRetentionMonths
+	}
+
+	lifecycles, err := s.metricsRepo.ListLifecycles(ctx, startMonth, endMonth.AddDate(0, 1, 0), req.MealPlanID)
+	if err != nil {
+		s.logger.Error("Failed to list subscription lifecycles", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	now := wallClockNow()
+
+	cohorts := []metrics.Cohort{}
+	cohortIndex := map[string]int{}
+	for month := startMonth; !month.After(endMonth); month = month.AddDate(0, 1, 0) {
+		cohortIndex[month.Format("2006-01")] = len(cohorts)
+		cohorts = append(cohorts, metrics.Cohort{
+			Month:     month.Format("2006-01"),
+			Retention: []metrics.CohortRetention{},
+		})
+	}
+
+	var overall lifetimeTotals
+	plans := map[string]*lifetimeTotals{}
+	customers := map[string]bool{}
+
+	for _, l := range lifecycles {
+		t := newTimeline(l, now)
+
+		if i, ok := cohortIndex[l.CreatedAt.Format("2006-01")]; ok {
+			cohort := &cohorts[i]
+			cohort.Subscriptions++
+			for n := 1; n <= months; n++ {
+				point := l.CreatedAt.AddDate(0, n, 0)
+				if point.After(now) {
+					break
+				}
+				for len(cohort.Retention) < n {
+					cohort.Retention = append(cohort.Retention, metrics.CohortRetention{Month: len(cohort.Retention) + 1})
+				}
+				cohort.Retention[n-1].Eligible++
+				if !t.statusAt(point).IsClosed() {
+					cohort.Retention[n-1].Retained++
+				}
+			}
+		}
+
+		plan, ok := plans[l.MealPlanID]
+		if !ok {
+			plan = &lifetimeTotals{planID: l.MealPlanID, planName: l.MealPlanName}
+			plans[l.MealPlanID] = plan
+		}
+		plan.add(t)
+		overall.add(t)
+		customers[l.UserID] = true
+	}
+
+	for i := range cohorts {
+		for j := range cohorts[i].Retention {
+			r := &cohorts[i].Retention[j]
+			r.Rate = percentage(r.Retained, r.Eligible)
+		}
+	}
+
+	planChurn := make([]metrics.PlanChurn, 0, len(plans))
+	for _, plan := range plans {
+		planChurn = append(planChurn, metrics.PlanChurn{
+			MealPlanID:            plan.planID,
+			MealPlanName:          plan.planName,
+			Subscriptions:         plan.subscriptions,
+			Cancellations:         plan.cancellations,
+			MonthlyChurnRate:      round2(plan.churnRate()),
+			AverageLifetimeDays:   round2(plan.averageLifetimeDays()),
+			AverageMonthlyRevenue: round2(plan.averageMonthlyRevenue()),
+			LifetimeValue:         plan.lifetimeValue(1),
+		})
+	}
+	sort.Slice(planChurn, func(i, j int) bool {
+		return planChurn[i].MonthlyChurnRate > planChurn[j].MonthlyChurnRate
+	})
+
+	lifetime := metrics.Lifetime{
+		Subscriptions:         overall.subscriptions,
+		Customers:             len(customers),
+		Cancellations:         overall.cancellations,
+		MonthlyChurnRate:      round2(overall.churnRate()),
+		AverageLifetimeDays:   round2(overall.averageLifetimeDays()),
+		AverageMonthlyRevenue: round2(overall.averageMonthlyRevenue()),
+		RevenueToDate:         round2(overall.revenue),
+	}
+	if len(customers) > 0 {
+		lifetime.CustomerLifetimeValue = overall.lifetimeValue(float64(overall.subscriptions) / float64(len(customers)))
+	}
+
+	return &metrics.CohortResponse{
+		StartMonth: startMonth.Format("2006-01"),
+		EndMonth:   endMonth.Format("2006-01"),
+		Cohorts:    cohorts,
+		Plans:      planChurn,
+		Lifetime:   lifetime,
+	}, nil
+}
+
+// timeline is the sequence of statuses a subscription has been in, taken
+// from its audit log. Every change is audited, admin force-cancels included,
+// so the log is followed as it is. A subscription from before auditing has
+// no entries and is taken to have always had its current status, as in the
+// daily metrics backfill.
+type timeline struct {
+	price float64
+	spans []span
+}
+
+type span struct {
+	status   entity.SubscriptionStatus
+	from, to time.Time
+}
+
+func newTimeline(l repository.Lifecycle, now time.Time) *timeline {
+	t := &timeline{price: l.TotalPrice}
+
+	status := entity.StatusActive
+	if len(l.Changes) == 0 {
+		status = l.Status
+	}
+
+	at := l.CreatedAt
+	for _, change := range l.Changes {
+		if change.At.Before(at) {
+			change.At = at
+		}
+		if change.Status == status {
+			continue
+		}
+		t.spans = append(t.spans, span{status: status, from: at, to: change.At})
+		status, at = change.Status, change.At
+	}
+
+	if now.Before(at) {
+		now = at
+	}
+	t.spans = append(t.spans, span{status: status, from: at, to: now})
+
+	return t
+}
+
+func (t *timeline) statusAt(point time.Time) entity.SubscriptionStatus {
+	status := t.spans[0].status
+	for _, sp := range t.spans {
+		if sp.from.After(point) {
+			break
+		}
+		status = sp.status
+	}
+	return status
+}
+
+type lifetimeTotals struct {
+	planID   string
+	planName string
+
+	subscriptions int
+	cancellations int
+	ended         int
+	// aliveDays is time spent in a live status; endedDays is the same for
+	// subscriptions that are now cancelled or expired.
+	aliveDays float64
+	endedDays float64
+	revenue   float64
+}
+
+func (l *lifetimeTotals) add(t *timeline) {
+	var alive float64
+	for i, sp := range t.spans {
+		days := sp.to.Sub(sp.from).Hours() / 24
+		switch sp.status {
+		case entity.StatusActive:
+			alive += days
+			l.revenue += t.price * days / daysPerMonth
+		case entity.StatusPaused:
+			alive += days
+		case entity.StatusCancelled, entity.StatusExpired:
+			if i > 0 {
+				l.cancellations++
+			}
+		}
+	}
+
+	l.subscriptions++
+	l.aliveDays += alive
+	if t.spans[len(t.spans)-1].status.IsClosed() {
+		l.ended++
+		l.endedDays += alive
+	}
+}
+
+// churnRate is cancellations per 100 subscription-months.
+func (l *lifetimeTotals) churnRate() float64 {
+	if l.aliveDays == 0 {
+		return 0
+	}
+	return float64(l.cancellations) / (l.aliveDays / daysPerMonth) * 100
+}
+
+func (l *lifetimeTotals) averageLifetimeDays() float64 {
+	if l.ended == 0 {
+		return 0
+	}
+	return l.endedDays / float64(l.ended)
+}
+
+// averageMonthlyRevenue is revenue per month a subscription stays, paused
+// months included.
+func (l *lifetimeTotals) averageMonthlyRevenue() float64 {
+	if l.aliveDays == 0 {
+		return 0
+	}
+	return l.revenue / (l.aliveDays / daysPerMonth)
+}
+
+// lifetimeValue is monthly revenue times the expected lifetime in months,
+// the inverse of the monthly churn rate, scaled by subscriptions per
+// customer.
+func (l *lifetimeTotals) lifetimeValue(perCustomer float64) *float64 {
+	if l.cancellations == 0 {
+		return nil
+	}
+	value := round2(l.averageMonthlyRevenue() * (100 / l.churnRate()) * perCustomer)
+	return &value
+}
+
+func parseMonthRange(start, end string) (time.Time, time.Time, error) {
+	now := time.Now()
+	endMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if end != "" {
+		parsed, err := time.ParseInLocation("2006-01", end, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		endMonth = parsed
+	}
+
+	startMonth := endMonth.AddDate(0, 1-defaultCohortMonths, 0)
+	if start != "" {
+		parsed, err := time.ParseInLocation("2006-01", start, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		startMonth = parsed
+	}
+
+	if startMonth.After(endMonth) {
+		return time.Time{}, time.Time{}, metrics.ErrInvalidMonthRange
+	}
+
+	if startMonth.AddDate(0, maxCohortMonths, 0).Before(endMonth.AddDate(0, 1, 0)) {
+		return time.Time{}, time.Time{}, metrics.ErrDateRangeTooLong
+	}
+
+	return startMonth, endMonth, nil
+}
+
+// wallClockNow returns the local time labelled as UTC, which is how
+// TIMESTAMP columns written with time.Now() are read back.
+func wallClockNow() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+}
+
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return round2(float64(part) / float64(whole) * 100)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
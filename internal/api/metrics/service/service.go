@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"sea-catering-backend/internal/api/metrics"
+	"sea-catering-backend/internal/api/metrics/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/logger"
+)
+
+// maxRangeDays bounds listing and backfill requests.
+const maxRangeDays = 366
+
+type MetricsService interface {
+	Start(ctx context.Context)
+	Stop()
+
+	// Snapshot records today's figures so far and closes yesterday's if it
+	// was last recorded before midnight.
+	Snapshot(ctx context.Context) (*entity.DailyMetrics, error)
+	Backfill(ctx context.Context, req metrics.BackfillRequest) (*metrics.BackfillResponse, error)
+	GetDailyMetrics(ctx context.Context, req metrics.DailyMetricsRequest) (*metrics.DailyMetricsResponse, error)
+	GetCohorts(ctx context.Context, req metrics.CohortRequest) (*metrics.CohortResponse, error)
+}
+
+type Config struct {
+	// Interval is how often today's row is refreshed.
+	Interval time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		Interval: time.Hour,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("METRICS_SNAPSHOT_INTERVAL")); err == nil && interval > 0 {
+		config.Interval = interval
+	}
+
+	return config
+}
+
+type metricsService struct {
+	metricsRepo repository.MetricsRepository
+	config      *Config
+	logger      *logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMetricsService(
+	metricsRepo repository.MetricsRepository,
+	config *Config,
+	logger *logger.Logger,
+) MetricsService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	return &metricsService{
+		metricsRepo: metricsRepo,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// Start runs the snapshot job. Writes are idempotent upserts, so it is safe
+// to run on every replica.
+func (s *metricsService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go s.run(ctx)
+
+	s.logger.Info("Daily metrics snapshot job started", logger.Fields{
+		"interval": s.config.Interval.String(),
+	})
+}
+
+func (s *metricsService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Daily metrics snapshot job stopped")
+}
+
+func (s *metricsService) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Snapshot(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to record daily metrics", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *metricsService) Snapshot(ctx context.Context) (*entity.DailyMetrics, error) {
+	today := startOfDay(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+
+	// The last run before midnight can miss up to an interval of changes,
+	// so the first run after it records yesterday again. Timestamps are
+	// stored as local wall-clock time, so the dates are compared as text.
+	previous, err := s.metricsRepo.Get(ctx, yesterday)
+	if err != nil && err != metrics.ErrMetricsNotFound {
+		return nil, err
+	}
+	if previous == nil || previous.Source != entity.MetricsSnapshot ||
+		previous.ComputedAt.Format("2006-01-02") < today.Format("2006-01-02") {
+		if _, err := s.record(ctx, yesterday); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.record(ctx, today)
+}
+
+func (s *metricsService) record(ctx context.Context, day time.Time) (*entity.DailyMetrics, error) {
+	m, err := s.metricsRepo.Compute(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.metricsRepo.Upsert(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Recorded daily metrics", logger.Fields{
+		"date":   day.Format("2006-01-02"),
+		"mrr":    m.MRR,
+		"active": m.ActiveSubscriptions,
+	})
+
+	return m, nil
+}
+
+// Backfill reconstructs days from before snapshots were recorded. Days
+// that already have a snapshot keep it.
+func (s *metricsService) Backfill(ctx context.Context, req metrics.BackfillRequest) (*metrics.BackfillResponse, error) {
+	startDate, endDate, err := parseRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if today := startOfDay(time.Now()); endDate.After(today) {
+		endDate = today
+	}
+
+	result := &metrics.BackfillResponse{}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		m, err := s.metricsRepo.Reconstruct(ctx, day)
+		if err != nil {
+			s.logger.Error("Failed to reconstruct daily metrics", logger.Fields{
+				"error": err.Error(),
+				"date":  day.Format("2006-01-02"),
+			})
+			return nil, err
+		}
+
+		written, err := s.metricsRepo.Upsert(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		if written {
+			result.Written++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	s.logger.Info("Daily metrics backfilled", logger.Fields{
+		"start_date": req.StartDate,
+		"end_date":   req.EndDate,
+		"written":    result.Written,
+		"skipped":    result.Skipped,
+	})
+
+	return result, nil
+}
+
+func (s *metricsService) GetDailyMetrics(ctx context.Context, req metrics.DailyMetricsRequest) (*metrics.DailyMetricsResponse, error) {
+	startDate, endDate, err := parseRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.metricsRepo.ListRange(ctx, startDate, endDate)
+	if err != nil {
+		s.logger.Error("Failed to list daily metrics", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &metrics.DailyMetricsResponse{Days: days}, nil
+}
+
+func parseRange(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.ParseInLocation("2006-01-02", start, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	endDate, err := time.ParseInLocation("2006-01-02", end, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, metrics.ErrInvalidDateRange
+	}
+
+	if endDate.Sub(startDate) > maxRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, metrics.ErrDateRangeTooLong
+	}
+
+	return startDate, endDate, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import "sea-catering-backend/internal/entity"
+
+type PreferencesResponse struct {
+	Preferences []entity.NotificationPreference `json:"preferences"`
+}
+
+type UpdatePreferencesRequest struct {
+	Preferences []PreferenceUpdate `json:"preferences" validate:"required,min=1,max=12,dive"`
+}
+
+type PreferenceUpdate struct {
+	Channel  entity.NotificationChannel  `json:"channel" validate:"required,oneof=email sms in_app"`
+	Category entity.NotificationCategory `json:"category" validate:"required,oneof=transactional delivery_updates marketing menu_of_the_week"`
+	Enabled  *bool                       `json:"enabled" validate:"required"`
+}
+
+type UnsubscribeRequest struct {
+	Token string `query:"token" validate:"required,max=512"`
+}
+
+type UnsubscribeResponse struct {
+	Channel  entity.NotificationChannel  `json:"channel"`
+	Category entity.NotificationCategory `json:"category"`
+	Message  string                      `json:"message"`
+}
+
+type InboxListRequest struct {
+	Page       int  `query:"page" validate:"omitempty,min=1"`
+	Limit      int  `query:"limit" validate:"omitempty,min=1,max=100"`
+	UnreadOnly bool `query:"unread_only"`
+}
+
+type InboxListResponse struct {
+	Notifications []entity.Notification `json:"notifications"`
+	UnreadCount   int                   `json:"unread_count"`
+	Meta          *PaginationMeta       `json:"meta,omitempty"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+type MarkAllReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int   `json:"unread_count"`
+}
+
+// Stream event types pushed to connected clients.
+const (
+	StreamEventNotification = "notification"
+	StreamEventUnreadCount  = "unread_count"
+)
+
+// StreamEvent is published on a user's Redis channel and relayed as-is to
+// every stream the user has open, on any replica.
+type StreamEvent struct {
+	Type         string               `json:"type"`
+	Notification *entity.Notification `json:"notification,omitempty"`
+	UnreadCount  int                  `json:"unread_count"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
@@ -0,0 +1,9 @@
+package notifications
+
+import "errors"
+
+var (
+	ErrTransactionalRequired   = errors.New("transactional notifications cannot be turned off")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+	ErrNotificationNotFound    = errors.New("notification not found")
+)
@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/notifications"
+	"sea-catering-backend/internal/api/notifications/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+)
+
+type NotificationHandler struct {
+	preferenceService service.PreferenceService
+	inboxService      service.InboxService
+	validator         *validator.Validate
+	middleware        middleware.Interface
+	logger            *logger.Logger
+}
+
+func NewNotificationHandler(
+	preferenceService service.PreferenceService,
+	inboxService service.InboxService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *NotificationHandler {
+	return &NotificationHandler{
+		preferenceService: preferenceService,
+		inboxService:      inboxService,
+		validator:         validator,
+		middleware:        middleware,
+		logger:            logger,
+	}
+}
+
+func (h *NotificationHandler) RegisterRoutes(router fiber.Router) {
+	notificationGroup := router.Group("/notifications")
+
+	// Unsubscribe links are opened straight from an inbox, so the signed
+	// token is the only credential. POST supports one-click List-Unsubscribe.
+	notificationGroup.Get("/unsubscribe", h.Unsubscribe)
+	notificationGroup.Post("/unsubscribe", h.Unsubscribe)
+
+	notificationGroup.Use(h.middleware.AuthMiddleware())
+	notificationGroup.Get("/preferences", h.GetPreferences)
+	notificationGroup.Put("/preferences", h.UpdatePreferences)
+
+	notificationGroup.Get("/", h.ListNotifications)
+	notificationGroup.Get("/unread-count", h.GetUnreadCount)
+	notificationGroup.Get("/stream", h.Stream)
+	notificationGroup.Post("/read-all", h.MarkAllRead)
+	notificationGroup.Patch("/:id/read", h.MarkRead)
+}
+
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	preferences, err := h.preferenceService.GetPreferences(ctx, userID)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "get_notification_preferences")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req notifications.UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	preferences, err := h.preferenceService.UpdatePreferences(ctx, userID, req)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "update_notification_preferences")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) Unsubscribe(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req notifications.UnsubscribeRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.preferenceService.Unsubscribe(ctx, req.Token)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "unsubscribe")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req notifications.InboxListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.inboxService.List(ctx, userID, req)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "list_notifications")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	result, err := h.inboxService.UnreadCount(ctx, userID)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "get_unread_count")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	notificationID := c.Params("id")
+	if notificationID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Notification ID is required")
+	}
+
+	result, err := h.inboxService.MarkRead(ctx, userID, notificationID)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "mark_notification_read")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	result, err := h.inboxService.MarkAllRead(ctx, userID)
+	if err != nil {
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "mark_all_notifications_read")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+// streamHeartbeat keeps proxies from closing an idle stream. Each write also
+// pushes the connection's write deadline forward, since the server's
+// WriteTimeout would otherwise end every stream after 30 seconds.
+const streamHeartbeat = 20 * time.Second
+
+// Stream pushes new notifications as Server-Sent Events. Each notification
+// is sent with its ID, so a client that reconnects with Last-Event-ID first
+// receives whatever it missed.
+func (h *NotificationHandler) Stream(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	// Subscribe before reading the backlog, so a notification stored in
+	// between reaches the client either way. The subscription outlives the
+	// handler, so it is not tied to the request context.
+	pubsub := h.inboxService.Subscribe(context.Background(), userID)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "stream_notifications")
+	}
+
+	missed, err := h.inboxService.ListMissed(ctx, userID, c.Get("Last-Event-ID"))
+	if err != nil {
+		pubsub.Close()
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "stream_notifications")
+	}
+
+	unread, err := h.inboxService.UnreadCount(ctx, userID)
+	if err != nil {
+		pubsub.Close()
+		return h.handleNotificationError(c, errHandler, requestID, err, c.Path(), "stream_notifications")
+	}
+
+	// Notifications stored after subscribing can be both in the backlog and
+	// on the channel; they are only sent once.
+	sent := make(map[string]struct{}, len(missed))
+	for _, notification := range missed {
+		sent[notification.ID] = struct{}{}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	conn := c.Context().Conn()
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer pubsub.Close()
+
+		flush := func() bool {
+			conn.SetWriteDeadline(time.Now().Add(2 * streamHeartbeat))
+			return w.Flush() == nil
+		}
+
+		fmt.Fprintf(w, "retry: 5000\n\n")
+		for _, notification := range missed {
+			writeStreamEvent(w, notifications.StreamEvent{
+				Type:         notifications.StreamEventNotification,
+				Notification: &notification,
+			})
+		}
+		writeStreamEvent(w, notifications.StreamEvent{
+			Type:        notifications.StreamEventUnreadCount,
+			UnreadCount: unread.UnreadCount,
+		})
+		if !flush() {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event notifications.StreamEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					continue
+				}
+				if event.Notification != nil {
+					if _, ok := sent[event.Notification.ID]; ok {
+						continue
+					}
+				}
+				writeStreamEvent(w, event)
+			case <-heartbeat.C:
+				fmt.Fprintf(w, ": ping\n\n")
+			}
+
+			if !flush() {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeStreamEvent writes one SSE frame. Notification frames carry the
+// notification ID so the client's EventSource reports it as Last-Event-ID.
+func writeStreamEvent(w *bufio.Writer, event notifications.StreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	if event.Notification != nil {
+		fmt.Fprintf(w, "id: %s\n", event.Notification.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
+
+func (h *NotificationHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *NotificationHandler) handleNotificationError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case notifications.ErrTransactionalRequired:
+		return errHandler.HandleBadRequest(c, requestID, "Transactional notifications cannot be turned off")
+	case notifications.ErrInvalidUnsubscribeToken:
+		return errHandler.HandleBadRequest(c, requestID, "Unsubscribe link is invalid")
+	case notifications.ErrNotificationNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Notification")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"sea-catering-backend/internal/api/notifications"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type InboxRepository interface {
+	Create(ctx context.Context, notification *entity.Notification) error
+	List(ctx context.Context, userID string, req notifications.InboxListRequest) ([]entity.Notification, *notifications.PaginationMeta, error)
+	ListAfter(ctx context.Context, userID, afterID string, limit int) ([]entity.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+}
+
+type inboxRepository struct {
+	db *database.DB
+}
+
+func NewInboxRepository(db *sqlx.DB) InboxRepository {
+	return &inboxRepository{db: database.New(db)}
+}
+
+const notificationColumns = `id, user_id, category, type, title, body, reference_type, reference_id, read_at, created_at`
+
+func (r *inboxRepository) Create(ctx context.Context, notification *entity.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, category, type, title, body, reference_type, reference_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		notification.ID, notification.UserID, notification.Category, notification.Type,
+		notification.Title, notification.Body, notification.ReferenceType, notification.ReferenceID,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *inboxRepository) List(ctx context.Context, userID string, req notifications.InboxListRequest) ([]entity.Notification, *notifications.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereClause := "WHERE user_id = $1"
+	if req.UnreadOnly {
+		whereClause += " AND read_at IS NULL"
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM notifications %s`, whereClause)
+	if err := r.db.GetContext(ctx, &total, countQuery, userID); err != nil {
+		return nil, nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notifications
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, notificationColumns, whereClause)
+
+	list := []entity.Notification{}
+	if err := r.db.SelectContext(ctx, &list, query, userID, req.Limit, offset); err != nil {
+		return nil, nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	meta := &notifications.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return list, meta, nil
+}
+
+// ListAfter returns notifications newer than afterID, oldest first. IDs are
+// ULIDs, so they sort in creation order.
+func (r *inboxRepository) ListAfter(ctx context.Context, userID, afterID string, limit int) ([]entity.Notification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notifications
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, notificationColumns)
+
+	list := []entity.Notification{}
+	if err := r.db.SelectContext(ctx, &list, query, userID, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *inboxRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead is idempotent; reading a notification twice keeps the first
+// read time.
+func (r *inboxRepository) MarkRead(ctx context.Context, userID, notificationID string) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return notifications.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (r *inboxRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	query := `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	return result.RowsAffected()
+}
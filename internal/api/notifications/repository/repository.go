@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type PreferenceRepository interface {
+	GetByUser(ctx context.Context, userID string) ([]entity.NotificationPreference, error)
+	Get(ctx context.Context, userID string, channel entity.NotificationChannel, category entity.NotificationCategory) (*entity.NotificationPreference, error)
+	Upsert(ctx context.Context, preferences []entity.NotificationPreference) error
+}
+
+type preferenceRepository struct {
+	db *database.DB
+}
+
+func NewPreferenceRepository(db *sqlx.DB) PreferenceRepository {
+	return &preferenceRepository{db: database.New(db)}
+}
+
+func (r *preferenceRepository) GetByUser(ctx context.Context, userID string) ([]entity.NotificationPreference, error) {
+	query := `
+		SELECT user_id, channel, category, enabled, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+
+	var preferences []entity.NotificationPreference
+	if err := r.db.SelectContext(ctx, &preferences, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// Get returns nil without an error when the user has not chosen yet.
+func (r *preferenceRepository) Get(ctx context.Context, userID string, channel entity.NotificationChannel, category entity.NotificationCategory) (*entity.NotificationPreference, error) {
+	query := `
+		SELECT user_id, channel, category, enabled, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1 AND channel = $2 AND category = $3
+	`
+
+	var preference entity.NotificationPreference
+	if err := r.db.GetContext(ctx, &preference, query, userID, channel, category); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	return &preference, nil
+}
+
+func (r *preferenceRepository) Upsert(ctx context.Context, preferences []entity.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, channel, category, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (user_id, channel, category)
+		DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at
+	`
+
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for _, p := range preferences {
+			if _, err := r.db.ExecContext(ctx, query, p.UserID, p.Channel, p.Category, p.Enabled, p.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to save notification preference: %w", err)
+			}
+		}
+		return nil
+	})
+}
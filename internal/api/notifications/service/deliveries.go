@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/sms"
+)
+
+// onDeliveryStatusChanged texts the customer as their meal moves through the
+// day. Only verified numbers are used, so a typo in a profile never sends a
+// stranger someone else's delivery updates.
+func (s *notificationService) onDeliveryStatusChanged(ctx context.Context, event events.Event) error {
+	payload, ok := event.Payload.(deliveries.DeliveryEvent)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	userID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user id %q: %w", payload.UserID, err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Skipping notification for unavailable user", logger.Fields{
+			"event":   event.Name,
+			"user_id": payload.UserID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+
+	if user.Phone == nil || user.PhoneVerifiedAt == nil {
+		return nil
+	}
+
+	enabled, err := s.preferenceService.IsEnabled(ctx, payload.UserID, entity.ChannelSMS, entity.CategoryDeliveryUpdates)
+	if err != nil {
+		return fmt.Errorf("failed to check notification preference: %w", err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	update := &sms.DeliveryUpdate{
+		Status:        sms.DeliveryStatus(payload.Status),
+		MealType:      string(payload.MealType),
+		Window:        payload.MealType.DeliveryWindow(),
+		Date:          payload.DeliveryDate,
+		FailureReason: payload.FailureReason,
+	}
+
+	if err := s.smsService.SendDeliveryUpdate(ctx, *user.Phone, sms.Locale(user.PreferredLanguage), update); err != nil {
+		return fmt.Errorf("failed to send delivery update for %s: %w", payload.DeliveryID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/subscriptions"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/logger"
+)
+
+type inAppMessage struct {
+	category entity.NotificationCategory
+	title    map[string]string
+	// body is a format string; subscription messages take the plan name,
+	// delivery messages the meal type and date.
+	body map[string]string
+}
+
+var inAppMessages = map[string]inAppMessage{
+	subscriptions.EventSubscriptionCreated: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan aktif", "en": "Subscription active"},
+		body:     map[string]string{"id": "Langganan %s Anda sudah aktif.", "en": "Your %s subscription is now active."},
+	},
+	subscriptions.EventSubscriptionReactivated: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan diaktifkan kembali", "en": "Subscription reactivated"},
+		body:     map[string]string{"id": "Langganan %s Anda aktif kembali.", "en": "Your %s subscription is active again."},
+	},
+	subscriptions.EventSubscriptionPaused: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan dijeda", "en": "Subscription paused"},
+		body:     map[string]string{"id": "Pengiriman %s dijeda sesuai permintaan Anda.", "en": "Deliveries for %s are paused as requested."},
+	},
+	subscriptions.EventSubscriptionResumed: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan dilanjutkan", "en": "Subscription resumed"},
+		body:     map[string]string{"id": "Pengiriman %s dilanjutkan.", "en": "Deliveries for %s have resumed."},
+	},
+	subscriptions.EventSubscriptionPauseEnding: {
+		category: entity.CategoryDeliveryUpdates,
+		title:    map[string]string{"id": "Jeda segera berakhir", "en": "Pause ending soon"},
+		body:     map[string]string{"id": "Pengiriman %s akan dimulai lagi sebentar lagi.", "en": "Deliveries for %s start again soon."},
+	},
+	subscriptions.EventSubscriptionCancelled: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan dibatalkan", "en": "Subscription cancelled"},
+		body:     map[string]string{"id": "Langganan %s Anda telah dibatalkan.", "en": "Your %s subscription has been cancelled."},
+	},
+	subscriptions.EventSubscriptionForceCancelled: {
+		category: entity.CategoryTransactional,
+		title:    map[string]string{"id": "Langganan dihentikan", "en": "Subscription stopped"},
+		body:     map[string]string{"id": "Langganan %s Anda dihentikan oleh tim kami.", "en": "Your %s subscription was stopped by our team."},
+	},
+	deliveries.EventDeliveryOutForDelivery: {
+		category: entity.CategoryDeliveryUpdates,
+		title:    map[string]string{"id": "Pesanan sedang diantar", "en": "On its way"},
+		body:     map[string]string{"id": "%s Anda untuk %s sedang diantar.", "en": "Your %s for %s is on its way."},
+	},
+	deliveries.EventDeliveryDelivered: {
+		category: entity.CategoryDeliveryUpdates,
+		title:    map[string]string{"id": "Pesanan diterima", "en": "Delivered"},
+		body:     map[string]string{"id": "%s Anda untuk %s telah diterima.", "en": "Your %s for %s has been delivered."},
+	},
+	deliveries.EventDeliveryFailed: {
+		category: entity.CategoryDeliveryUpdates,
+		title:    map[string]string{"id": "Pengiriman gagal", "en": "Delivery failed"},
+		body:     map[string]string{"id": "%s Anda untuk %s gagal diantar. Tim kami akan menghubungi Anda.", "en": "We could not deliver your %s for %s. Our team will contact you."},
+	},
+}
+
+var indonesianMealTypes = map[entity.MealType]string{
+	entity.MealTypeBreakfast: "Sarapan",
+	entity.MealTypeLunch:     "Makan siang",
+	entity.MealTypeDinner:    "Makan malam",
+}
+
+var indonesianShortMonths = [...]string{
+	"Jan", "Feb", "Mar", "Apr", "Mei", "Jun",
+	"Jul", "Agu", "Sep", "Okt", "Nov", "Des",
+}
+
+func (s *notificationService) registerInAppHandlers(bus events.Interface) {
+	for name := range inAppMessages {
+		switch name {
+		case deliveries.EventDeliveryOutForDelivery, deliveries.EventDeliveryDelivered, deliveries.EventDeliveryFailed:
+			bus.Subscribe(name, s.onDeliveryInApp)
+		default:
+			bus.Subscribe(name, s.onSubscriptionInApp)
+		}
+	}
+}
+
+func (s *notificationService) onSubscriptionInApp(ctx context.Context, event events.Event) error {
+	if payload, ok := event.Payload.(subscriptions.SubscriptionEvent); ok && event.Name == subscriptions.EventSubscriptionForceCancelled && !payload.NotifyUser {
+		return nil
+	}
+
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	message := inAppMessages[event.Name]
+	lang := recipient.PreferredLanguage
+
+	return s.inboxService.Notify(ctx, &entity.Notification{
+		UserID:        recipient.ID.String(),
+		Category:      message.category,
+		Type:          event.Name,
+		Title:         localized(message.title, lang),
+		Body:          fmt.Sprintf(localized(message.body, lang), subscription.MealPlan.Name),
+		ReferenceType: stringPtr("subscription"),
+		ReferenceID:   stringPtr(subscription.ID),
+	})
+}
+
+func (s *notificationService) onDeliveryInApp(ctx context.Context, event events.Event) error {
+	payload, ok := event.Payload.(deliveries.DeliveryEvent)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	userID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user id %q: %w", payload.UserID, err)
+	}
+
+	recipient, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Skipping notification for unavailable user", logger.Fields{
+			"event":   event.Name,
+			"user_id": payload.UserID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+
+	message := inAppMessages[event.Name]
+	lang := recipient.PreferredLanguage
+
+	mealType, date := string(payload.MealType), payload.DeliveryDate.Format("Jan 2")
+	if lang != entity.LanguageEnglish {
+		mealType, date = indonesianMealTypes[payload.MealType], shortIndonesianDate(payload.DeliveryDate)
+	}
+
+	return s.inboxService.Notify(ctx, &entity.Notification{
+		UserID:        payload.UserID,
+		Category:      message.category,
+		Type:          event.Name,
+		Title:         localized(message.title, lang),
+		Body:          fmt.Sprintf(localized(message.body, lang), mealType, date),
+		ReferenceType: stringPtr("delivery"),
+		ReferenceID:   stringPtr(payload.DeliveryID),
+	})
+}
+
+func localized(texts map[string]string, lang string) string {
+	if text, ok := texts[lang]; ok {
+		return text
+	}
+	return texts[entity.DefaultLanguage]
+}
+
+func shortIndonesianDate(t time.Time) string {
+	return fmt.Sprintf("%d %s", t.Day(), indonesianShortMonths[t.Month()-1])
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
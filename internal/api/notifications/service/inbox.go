@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"sea-catering-backend/internal/api/notifications"
+	"sea-catering-backend/internal/api/notifications/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/redis"
+	"sea-catering-backend/pkg/utils"
+)
+
+// missedNotificationLimit caps how many notifications a reconnecting stream
+// replays; clients that were away longer should reload the inbox.
+const missedNotificationLimit = 50
+
+// InboxService stores in-app notifications and pushes them to open streams.
+// Pushes go through Redis pub/sub so a client connected to any replica
+// receives them.
+type InboxService interface {
+	Notify(ctx context.Context, notification *entity.Notification) error
+	List(ctx context.Context, userID string, req notifications.InboxListRequest) (*notifications.InboxListResponse, error)
+	UnreadCount(ctx context.Context, userID string) (*notifications.UnreadCountResponse, error)
+	MarkRead(ctx context.Context, userID, notificationID string) (*notifications.UnreadCountResponse, error)
+	MarkAllRead(ctx context.Context, userID string) (*notifications.MarkAllReadResponse, error)
+	ListMissed(ctx context.Context, userID, lastEventID string) ([]entity.Notification, error)
+	Subscribe(ctx context.Context, userID string) *goredis.PubSub
+}
+
+type inboxService struct {
+	inboxRepo         repository.InboxRepository
+	preferenceService PreferenceService
+	redisService      redis.Interface
+	utils             utils.Interface
+	logger            *logger.Logger
+}
+
+func NewInboxService(
+	inboxRepo repository.InboxRepository,
+	preferenceService PreferenceService,
+	redisService redis.Interface,
+	utils utils.Interface,
+	logger *logger.Logger,
+) InboxService {
+	return &inboxService{
+		inboxRepo:         inboxRepo,
+		preferenceService: preferenceService,
+		redisService:      redisService,
+		utils:             utils,
+		logger:            logger,
+	}
+}
+
+func streamChannel(userID string) string {
+	return fmt.Sprintf("notifications:user:%s", userID)
+}
+
+// Notify saves the notification unless the user has switched in-app
+// messages off for its category, then pushes it to the user's streams.
+func (s *inboxService) Notify(ctx context.Context, notification *entity.Notification) error {
+	enabled, err := s.preferenceService.IsEnabled(ctx, notification.UserID, entity.ChannelInApp, notification.Category)
+	if err != nil {
+		return fmt.Errorf("failed to check notification preference: %w", err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	notification.ID = s.utils.GenerateULID()
+	notification.CreatedAt = time.Now()
+
+	if err := s.inboxRepo.Create(ctx, notification); err != nil {
+		s.logger.Error("Failed to create notification", logger.Fields{
+			"error":   err.Error(),
+			"user_id": notification.UserID,
+			"type":    notification.Type,
+		})
+		return err
+	}
+
+	s.publish(ctx, notification.UserID, notifications.StreamEvent{
+		Type:         notifications.StreamEventNotification,
+		Notification: notification,
+	})
+
+	return nil
+}
+
+func (s *inboxService) List(ctx context.Context, userID string, req notifications.InboxListRequest) (*notifications.InboxListResponse, error) {
+	list, meta, err := s.inboxRepo.List(ctx, userID, req)
+	if err != nil {
+		s.logger.Error("Failed to list notifications", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifications.InboxListResponse{
+		Notifications: list,
+		UnreadCount:   unread,
+		Meta:          meta,
+	}, nil
+}
+
+func (s *inboxService) UnreadCount(ctx context.Context, userID string) (*notifications.UnreadCountResponse, error) {
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifications.UnreadCountResponse{UnreadCount: unread}, nil
+}
+
+func (s *inboxService) MarkRead(ctx context.Context, userID, notificationID string) (*notifications.UnreadCountResponse, error) {
+	if err := s.inboxRepo.MarkRead(ctx, userID, notificationID); err != nil {
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishUnreadCount(ctx, userID, unread)
+
+	return &notifications.UnreadCountResponse{UnreadCount: unread}, nil
+}
+
+func (s *inboxService) MarkAllRead(ctx context.Context, userID string) (*notifications.MarkAllReadResponse, error) {
+	updated, err := s.inboxRepo.MarkAllRead(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to mark notifications read", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	unread, err := s.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated > 0 {
+		s.publishUnreadCount(ctx, userID, unread)
+	}
+
+	return &notifications.MarkAllReadResponse{Updated: updated, UnreadCount: unread}, nil
+}
+
+// ListMissed returns what a reconnecting stream missed, using the ID of the
+// last notification the client saw (the SSE Last-Event-ID header).
+func (s *inboxService) ListMissed(ctx context.Context, userID, lastEventID string) ([]entity.Notification, error) {
+	if lastEventID == "" {
+		return nil, nil
+	}
+
+	return s.inboxRepo.ListAfter(ctx, userID, lastEventID, missedNotificationLimit)
+}
+
+func (s *inboxService) Subscribe(ctx context.Context, userID string) *goredis.PubSub {
+	return s.redisService.Subscribe(ctx, streamChannel(userID))
+}
+
+// publishUnreadCount keeps badges in sync across a user's devices when one
+// of them reads notifications.
+func (s *inboxService) publishUnreadCount(ctx context.Context, userID string, unread int) {
+	s.publish(ctx, userID, notifications.StreamEvent{
+		Type:        notifications.StreamEventUnreadCount,
+		UnreadCount: unread,
+	})
+}
+
+// publish is best effort: the notification is already stored, so a client
+// that misses the push still sees it on its next load or reconnect.
+func (s *inboxService) publish(ctx context.Context, userID string, event notifications.StreamEvent) {
+	if event.Type == notifications.StreamEventNotification {
+		unread, err := s.inboxRepo.CountUnread(ctx, userID)
+		if err == nil {
+			event.UnreadCount = unread
+		}
+	}
+
+	if err := s.redisService.Publish(ctx, streamChannel(userID), event); err != nil {
+		s.logger.Warn("Failed to publish notification event", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+			"type":    event.Type,
+		})
+	}
+}
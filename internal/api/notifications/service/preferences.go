@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"sea-catering-backend/internal/api/auth"
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/notifications"
+	"sea-catering-backend/internal/api/notifications/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/logger"
+)
+
+type PreferenceService interface {
+	email.ConsentChecker
+	email.LocaleResolver
+	GetPreferences(ctx context.Context, userID string) (*notifications.PreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID string, req notifications.UpdatePreferencesRequest) (*notifications.PreferencesResponse, error)
+	Unsubscribe(ctx context.Context, token string) (*notifications.UnsubscribeResponse, error)
+	IsEnabled(ctx context.Context, userID string, channel entity.NotificationChannel, category entity.NotificationCategory) (bool, error)
+}
+
+type PreferenceConfig struct {
+	// UnsubscribeSecret signs unsubscribe links. Rotating it invalidates
+	// links in emails already sent.
+	UnsubscribeSecret string
+	// BaseURL is the public address of this API, used to build links.
+	BaseURL string
+}
+
+func LoadPreferenceConfig() *PreferenceConfig {
+	secret := os.Getenv("NOTIFICATION_UNSUBSCRIBE_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	return &PreferenceConfig{
+		UnsubscribeSecret: secret,
+		BaseURL:           strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type preferenceService struct {
+	preferenceRepo repository.PreferenceRepository
+	userRepo       authRepo.UserRepository
+	config         *PreferenceConfig
+	logger         *logger.Logger
+}
+
+func NewPreferenceService(
+	preferenceRepo repository.PreferenceRepository,
+	userRepo authRepo.UserRepository,
+	config *PreferenceConfig,
+	logger *logger.Logger,
+) PreferenceService {
+	if config == nil {
+		config = LoadPreferenceConfig()
+	}
+
+	return &preferenceService{
+		preferenceRepo: preferenceRepo,
+		userRepo:       userRepo,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// GetPreferences returns every channel and category, filling in defaults for
+// the ones the user has never changed.
+func (s *preferenceService) GetPreferences(ctx context.Context, userID string) (*notifications.PreferencesResponse, error) {
+	stored, err := s.preferenceRepo.GetByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get notification preferences", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	type key struct {
+		channel  entity.NotificationChannel
+		category entity.NotificationCategory
+	}
+
+	byKey := make(map[key]entity.NotificationPreference, len(stored))
+	for _, p := range stored {
+		byKey[key{p.Channel, p.Category}] = p
+	}
+
+	preferences := make([]entity.NotificationPreference, 0, len(entity.NotificationChannels)*len(entity.NotificationCategories))
+	for _, channel := range entity.NotificationChannels {
+		for _, category := range entity.NotificationCategories {
+			if p, ok := byKey[key{channel, category}]; ok {
+				preferences = append(preferences, p)
+				continue
+			}
+
+			preferences = append(preferences, entity.NotificationPreference{
+				UserID:   userID,
+				Channel:  channel,
+				Category: category,
+				Enabled:  category.DefaultEnabled(),
+			})
+		}
+	}
+
+	return &notifications.PreferencesResponse{Preferences: preferences}, nil
+}
+
+func (s *preferenceService) UpdatePreferences(ctx context.Context, userID string, req notifications.UpdatePreferencesRequest) (*notifications.PreferencesResponse, error) {
+	now := time.Now()
+	updates := make([]entity.NotificationPreference, 0, len(req.Preferences))
+
+	for _, p := range req.Preferences {
+		if !p.Category.IsOptional() && !*p.Enabled {
+			return nil, notifications.ErrTransactionalRequired
+		}
+
+		updates = append(updates, entity.NotificationPreference{
+			UserID:    userID,
+			Channel:   p.Channel,
+			Category:  p.Category,
+			Enabled:   *p.Enabled,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.preferenceRepo.Upsert(ctx, updates); err != nil {
+		s.logger.Error("Failed to update notification preferences", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Notification preferences updated", logger.Fields{
+		"user_id": userID,
+		"changes": len(updates),
+	})
+
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *preferenceService) Unsubscribe(ctx context.Context, token string) (*notifications.UnsubscribeResponse, error) {
+	userID, channel, category, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.preferenceRepo.Upsert(ctx, []entity.NotificationPreference{{
+		UserID:    userID,
+		Channel:   channel,
+		Category:  category,
+		Enabled:   false,
+		UpdatedAt: time.Now(),
+	}})
+	if err != nil {
+		s.logger.Error("Failed to unsubscribe", logger.Fields{
+			"error":    err.Error(),
+			"user_id":  userID,
+			"channel":  channel,
+			"category": category,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("User unsubscribed via link", logger.Fields{
+		"user_id":  userID,
+		"channel":  channel,
+		"category": category,
+	})
+
+	return &notifications.UnsubscribeResponse{
+		Channel:  channel,
+		Category: category,
+		Message:  "You have been unsubscribed",
+	}, nil
+}
+
+func (s *preferenceService) IsEnabled(ctx context.Context, userID string, channel entity.NotificationChannel, category entity.NotificationCategory) (bool, error) {
+	if !category.IsOptional() {
+		return true, nil
+	}
+
+	preference, err := s.preferenceRepo.Get(ctx, userID, channel, category)
+	if err != nil {
+		return false, err
+	}
+
+	if preference == nil {
+		return category.DefaultEnabled(), nil
+	}
+
+	return preference.Enabled, nil
+}
+
+// CheckConsent implements email.ConsentChecker. Addresses that do not belong
+// to an active account have never consented to anything optional.
+func (s *preferenceService) CheckConsent(to string, category email.Category) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notificationCategory := entity.NotificationCategory(category)
+
+	user, err := s.userRepo.GetByEmail(ctx, to)
+	if err != nil {
+		if err == auth.ErrUserNotFound {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	userID := user.ID.String()
+
+	enabled, err := s.IsEnabled(ctx, userID, entity.ChannelEmail, notificationCategory)
+	if err != nil || !enabled {
+		return false, "", err
+	}
+
+	return true, s.unsubscribeURL(userID, entity.ChannelEmail, notificationCategory), nil
+}
+
+// PreferredLocale implements email.LocaleResolver. Addresses without an
+// account get the mailer's default language.
+func (s *preferenceService) PreferredLocale(to string) email.Locale {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetByEmail(ctx, to)
+	if err != nil {
+		if err != auth.ErrUserNotFound {
+			s.logger.Warn("Failed to look up recipient language", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+		return ""
+	}
+
+	return email.Locale(user.PreferredLanguage)
+}
+
+func (s *preferenceService) unsubscribeURL(userID string, channel entity.NotificationChannel, category entity.NotificationCategory) string {
+	token := s.signUnsubscribeToken(userID, channel, category)
+	return fmt.Sprintf("%s/api/v1/notifications/unsubscribe?token=%s", s.config.BaseURL, url.QueryEscape(token))
+}
+
+// Unsubscribe tokens are "<payload>.<signature>", both base64url encoded,
+// where the payload is "userID|channel|category". They do not expire so old
+// emails keep working.
+func (s *preferenceService) signUnsubscribeToken(userID string, channel entity.NotificationChannel, category entity.NotificationCategory) string {
+	payload := strings.Join([]string{userID, string(channel), string(category)}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + s.sign(encoded)
+}
+
+func (s *preferenceService) parseUnsubscribeToken(token string) (string, entity.NotificationChannel, entity.NotificationCategory, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return "", "", "", notifications.ErrInvalidUnsubscribeToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", "", notifications.ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 3 {
+		return "", "", "", notifications.ErrInvalidUnsubscribeToken
+	}
+
+	category := entity.NotificationCategory(parts[2])
+	if !category.IsOptional() {
+		return "", "", "", notifications.ErrInvalidUnsubscribeToken
+	}
+
+	return parts[0], entity.NotificationChannel(parts[1]), category, nil
+}
+
+func (s *preferenceService) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.UnsubscribeSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/subscriptions"
+	subscriptionRepo "sea-catering-backend/internal/api/subscriptions/repository"
+	"sea-catering-backend/internal/api/testimonials"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/sms"
+)
+
+// NotificationService turns domain events into customer messages.
+type NotificationService interface {
+	RegisterHandlers(bus events.Interface)
+}
+
+type notificationService struct {
+	userRepo          authRepo.UserRepository
+	subscriptionRepo  subscriptionRepo.SubscriptionRepository
+	preferenceService PreferenceService
+	inboxService      InboxService
+	emailService      email.Interface
+	smsService        sms.Interface
+	logger            *logger.Logger
+}
+
+func NewNotificationService(
+	userRepo authRepo.UserRepository,
+	subscriptionRepo subscriptionRepo.SubscriptionRepository,
+	preferenceService PreferenceService,
+	inboxService InboxService,
+	emailService email.Interface,
+	smsService sms.Interface,
+	logger *logger.Logger,
+) NotificationService {
+	return &notificationService{
+		userRepo:          userRepo,
+		subscriptionRepo:  subscriptionRepo,
+		preferenceService: preferenceService,
+		inboxService:      inboxService,
+		emailService:      emailService,
+		smsService:        smsService,
+		logger:            logger,
+	}
+}
+
+func (s *notificationService) RegisterHandlers(bus events.Interface) {
+	bus.Subscribe(subscriptions.EventSubscriptionCreated, s.onSubscriptionCreated)
+	bus.Subscribe(subscriptions.EventSubscriptionReactivated, s.onSubscriptionReactivated)
+	bus.Subscribe(subscriptions.EventSubscriptionPaused, s.onSubscriptionPaused)
+	bus.Subscribe(subscriptions.EventSubscriptionResumed, s.onSubscriptionResumed)
+	bus.Subscribe(subscriptions.EventSubscriptionPauseEnding, s.onPauseEnding)
+	bus.Subscribe(subscriptions.EventSubscriptionCancelled, s.onSubscriptionCancelled)
+	bus.Subscribe(subscriptions.EventSubscriptionForceCancelled, s.onSubscriptionForceCancelled)
+
+	bus.Subscribe(deliveries.EventDeliveryOutForDelivery, s.onDeliveryStatusChanged)
+	bus.Subscribe(deliveries.EventDeliveryDelivered, s.onDeliveryStatusChanged)
+	bus.Subscribe(deliveries.EventDeliveryFailed, s.onDeliveryStatusChanged)
+
+	bus.Subscribe(testimonials.EventTestimonialApproved, s.onTestimonialModerated)
+	bus.Subscribe(testimonials.EventTestimonialRejected, s.onTestimonialModerated)
+	bus.Subscribe(testimonials.EventTestimonialHidden, s.onTestimonialModerated)
+
+	s.registerInAppHandlers(bus)
+}
+
+func (s *notificationService) onSubscriptionCreated(ctx context.Context, event events.Event) error {
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.emailService.SendSubscriptionConfirmationEmail(recipient.Email, recipient.Name, subscriptionDetails(subscription))
+}
+
+func (s *notificationService) onSubscriptionReactivated(ctx context.Context, event events.Event) error {
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.emailService.SendSubscriptionReactivatedEmail(recipient.Email, recipient.Name, subscriptionDetails(subscription))
+}
+
+func (s *notificationService) onSubscriptionPaused(ctx context.Context, event events.Event) error {
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	pause, ok := pauseDetails(event, subscription)
+	if !ok {
+		return fmt.Errorf("pause event for subscription %s has no pause dates", subscription.ID)
+	}
+
+	return s.emailService.SendSubscriptionPausedEmail(recipient.Email, recipient.Name, pause)
+}
+
+func (s *notificationService) onSubscriptionResumed(ctx context.Context, event events.Event) error {
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.emailService.SendSubscriptionResumedEmail(recipient.Email, recipient.Name, subscription.MealPlan.Name)
+}
+
+func (s *notificationService) onPauseEnding(ctx context.Context, event events.Event) error {
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	pause, ok := pauseDetails(event, subscription)
+	if !ok {
+		return fmt.Errorf("pause ending event for subscription %s has no pause dates", subscription.ID)
+	}
+
+	return ignoreOptOut(s.emailService.SendPauseEndingReminderEmail(recipient.Email, recipient.Name, pause))
+}
+
+func (s *notificationService) onSubscriptionCancelled(ctx context.Context, event events.Event) error {
+	recipient, _, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.emailService.SendSubscriptionCancellationEmail(recipient.Email, recipient.Name)
+}
+
+func (s *notificationService) onSubscriptionForceCancelled(ctx context.Context, event events.Event) error {
+	payload, ok := event.Payload.(subscriptions.SubscriptionEvent)
+	if !ok || !payload.NotifyUser {
+		return nil
+	}
+
+	recipient, subscription, err := s.loadSubscriptionEvent(ctx, event)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	return s.emailService.SendSubscriptionForceCancelledEmail(recipient.Email, recipient.Name, subscription.MealPlan.Name, payload.Reason)
+}
+
+// loadSubscriptionEvent fetches the customer and subscription an event is
+// about. A nil user with no error means there is nobody to notify, e.g. the
+// account has been deactivated since.
+func (s *notificationService) loadSubscriptionEvent(ctx context.Context, event events.Event) (*entity.User, *entity.SubscriptionWithDetails, error) {
+	payload, ok := event.Payload.(subscriptions.SubscriptionEvent)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	userID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user id %q: %w", payload.UserID, err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Skipping notification for unavailable user", logger.Fields{
+			"event":   event.Name,
+			"user_id": payload.UserID,
+			"error":   err.Error(),
+		})
+		return nil, nil, nil
+	}
+
+	subscription, err := s.subscriptionRepo.GetByID(ctx, payload.SubscriptionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load subscription %s: %w", payload.SubscriptionID, err)
+	}
+	if subscription == nil {
+		return nil, nil, subscriptions.ErrSubscriptionNotFound
+	}
+
+	return user, subscription, nil
+}
+
+// ignoreOptOut treats a message suppressed by the customer's preferences as
+// handled.
+func ignoreOptOut(err error) error {
+	if errors.Is(err, email.ErrRecipientOptedOut) {
+		return nil
+	}
+	return err
+}
+
+func subscriptionDetails(subscription *entity.SubscriptionWithDetails) *email.SubscriptionDetails {
+	mealTypes := make([]string, len(subscription.MealTypes))
+	for i, mt := range subscription.MealTypes {
+		mealTypes[i] = string(mt)
+	}
+
+	deliveryDays := make([]string, len(subscription.DeliveryDays))
+	for i, day := range subscription.DeliveryDays {
+		deliveryDays[i] = string(day)
+	}
+
+	return &email.SubscriptionDetails{
+		PlanName:     subscription.MealPlan.Name,
+		MealTypes:    mealTypes,
+		DeliveryDays: deliveryDays,
+		TotalPrice:   subscription.TotalPrice,
+		StartDate:    subscription.CreatedAt,
+		NextDelivery: subscription.NextDeliveryAfter(time.Now()),
+	}
+}
+
+// pauseDetails prefers the dates carried by the event, since the
+// subscription may have been resumed or cancelled by the time it is handled.
+func pauseDetails(event events.Event, subscription *entity.SubscriptionWithDetails) (*email.PauseDetails, bool) {
+	start, end := subscription.PauseStartDate, subscription.PauseEndDate
+	if payload, ok := event.Payload.(subscriptions.SubscriptionEvent); ok && payload.PauseEndDate != nil {
+		start, end = payload.PauseStartDate, payload.PauseEndDate
+	}
+
+	if start == nil || end == nil {
+		return nil, false
+	}
+
+	return &email.PauseDetails{
+		PlanName:   subscription.MealPlan.Name,
+		PauseStart: *start,
+		PauseEnd:   *end,
+	}, true
+}
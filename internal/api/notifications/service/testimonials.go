@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"sea-catering-backend/internal/api/testimonials"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/logger"
+)
+
+// testimonialExcerptLength keeps quoted reviews short enough to read at a
+// glance in an inbox.
+const testimonialExcerptLength = 160
+
+// onTestimonialModerated emails the author when their review goes live, is
+// rejected or is taken down.
+func (s *notificationService) onTestimonialModerated(ctx context.Context, event events.Event) error {
+	payload, ok := event.Payload.(testimonials.TestimonialEvent)
+	if !ok {
+		return fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+
+	userID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user id %q: %w", payload.UserID, err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Skipping notification for unavailable user", logger.Fields{
+			"event":   event.Name,
+			"user_id": payload.UserID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+
+	details := &email.TestimonialDetails{
+		PlanName:     payload.MealPlanName,
+		Rating:       payload.Rating,
+		Excerpt:      excerpt(payload.Message, testimonialExcerptLength),
+		Reason:       string(payload.Reason),
+		Note:         payload.Note,
+		WasPublished: event.Name == testimonials.EventTestimonialHidden,
+	}
+
+	if event.Name == testimonials.EventTestimonialApproved {
+		return s.emailService.SendTestimonialApprovedEmail(user.Email, user.Name, details)
+	}
+	return s.emailService.SendTestimonialRejectedEmail(user.Email, user.Name, details)
+}
+
+func excerpt(message string, limit int) string {
+	runes := []rune(message)
+	if len(runes) <= limit {
+		return message
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,85 @@
+package organizations
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+type CreateOrganizationRequest struct {
+	Name                    string   `json:"name" validate:"required,min=2,max=255"`
+	BillingEmail            string   `json:"billing_email" validate:"required,email"`
+	DefaultMonthlyAllowance *float64 `json:"default_monthly_allowance,omitempty" validate:"omitempty,min=0"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name                    string   `json:"name" validate:"required,min=2,max=255"`
+	BillingEmail            string   `json:"billing_email" validate:"required,email"`
+	DefaultMonthlyAllowance *float64 `json:"default_monthly_allowance,omitempty" validate:"omitempty,min=0"`
+}
+
+type InviteMemberRequest struct {
+	Email            string   `json:"email" validate:"required,email"`
+	Role             string   `json:"role,omitempty" validate:"omitempty,oneof=org_admin member"`
+	MonthlyAllowance *float64 `json:"monthly_allowance,omitempty" validate:"omitempty,min=0"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required,min=16"`
+}
+
+type UpdateMemberAllowanceRequest struct {
+	MonthlyAllowance *float64 `json:"monthly_allowance" validate:"omitempty,min=0"`
+}
+
+type GenerateInvoiceRequest struct {
+	Period string `json:"period" validate:"required,datetime=2006-01"`
+	Issue  bool   `json:"issue"`
+}
+
+type OrganizationMembership struct {
+	entity.Organization
+	Role entity.OrganizationMemberRole `json:"role"`
+}
+
+type MemberSpendSummary struct {
+	MemberID            string                          `json:"member_id"`
+	UserID              *string                         `json:"user_id,omitempty"`
+	Name                string                          `json:"name"`
+	Email               string                          `json:"email"`
+	Role                entity.OrganizationMemberRole   `json:"role"`
+	Status              entity.OrganizationMemberStatus `json:"status"`
+	MonthlyAllowance    *float64                        `json:"monthly_allowance,omitempty"`
+	RemainingAllowance  *float64                        `json:"remaining_allowance,omitempty"`
+	MonthlySpend        float64                         `json:"monthly_spend"`
+	ActiveSubscriptions int                             `json:"active_subscriptions"`
+}
+
+type MemberSubscription struct {
+	SubscriptionID string     `json:"subscription_id"`
+	UserID         string     `json:"user_id"`
+	MemberName     string     `json:"member_name"`
+	MemberEmail    string     `json:"member_email"`
+	MealPlanID     string     `json:"meal_plan_id"`
+	MealPlanName   string     `json:"meal_plan_name"`
+	MealTypes      []string   `json:"meal_types"`
+	DeliveryDays   []string   `json:"delivery_days"`
+	TotalPrice     float64    `json:"total_price"`
+	Status         string     `json:"status"`
+	PauseStart     *time.Time `json:"pause_start_date,omitempty"`
+	PauseEnd       *time.Time `json:"pause_end_date,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type OrganizationDashboardResponse struct {
+	Organization        entity.Organization  `json:"organization"`
+	TotalMembers        int                  `json:"total_members"`
+	ActiveMembers       int                  `json:"active_members"`
+	PendingInvitations  int                  `json:"pending_invitations"`
+	ActiveSubscriptions int                  `json:"active_subscriptions"`
+	PausedSubscriptions int                  `json:"paused_subscriptions"`
+	MonthlySpend        float64              `json:"monthly_spend"`
+	MonthlyBudget       *float64             `json:"monthly_budget,omitempty"`
+	Members             []MemberSpendSummary `json:"members"`
+	GeneratedAt         time.Time            `json:"generated_at"`
+}
@@ -0,0 +1,21 @@
+package organizations
+
+import "errors"
+
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrOrganizationInactive    = errors.New("organization is inactive")
+	ErrMissingTenant           = errors.New("organization context is missing")
+	ErrNotOrganizationAdmin    = errors.New("organization admin access required")
+	ErrNotOrganizationMember   = errors.New("user is not an active member of this organization")
+	ErrMemberNotFound          = errors.New("organization member not found")
+	ErrMemberAlreadyExists     = errors.New("email is already a member of this organization")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationExpired       = errors.New("invitation has expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email address")
+	ErrCannotRemoveOwner       = errors.New("organization owner cannot be removed")
+	ErrAllowanceExceeded       = errors.New("subscription exceeds the member's monthly allowance")
+	ErrInvoiceNotFound         = errors.New("invoice not found")
+	ErrInvoiceAlreadyIssued    = errors.New("invoice has already been issued")
+	ErrInvalidInvoicePeriod    = errors.New("invalid invoice period")
+)
@@ -0,0 +1,431 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/organizations"
+	"sea-catering-backend/internal/api/organizations/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type OrganizationHandler struct {
+	organizationService service.OrganizationService
+	validator           *validator.Validate
+	middleware          middleware.Interface
+	logger              *logger.Logger
+}
+
+func NewOrganizationHandler(
+	organizationService service.OrganizationService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		validator:           validator,
+		middleware:          middleware,
+		logger:              logger,
+	}
+}
+
+func (h *OrganizationHandler) RegisterRoutes(router fiber.Router) {
+	orgGroup := router.Group("/organizations", h.middleware.AuthMiddleware())
+
+	orgGroup.Post("/", h.CreateOrganization)
+	orgGroup.Get("/my", h.GetMyOrganizations)
+	orgGroup.Post("/invitations/accept", h.AcceptInvitation)
+
+	scoped := orgGroup.Group("/:orgId", h.requireOrgAdmin)
+	scoped.Get("/", h.GetOrganization)
+	scoped.Put("/", h.UpdateOrganization)
+	scoped.Get("/dashboard", h.GetDashboard)
+	scoped.Get("/members", h.ListMembers)
+	scoped.Post("/members/invite", h.InviteMember)
+	scoped.Put("/members/:memberId/allowance", h.UpdateMemberAllowance)
+	scoped.Delete("/members/:memberId", h.RemoveMember)
+	scoped.Get("/subscriptions", h.GetMemberSubscriptions)
+	scoped.Post("/invoices", h.GenerateInvoice)
+	scoped.Get("/invoices", h.ListInvoices)
+	scoped.Get("/invoices/:invoiceId", h.GetInvoice)
+}
+
+// requireOrgAdmin checks that the caller administers the organization in the
+// path and stores it as the request tenant, which context.FromFiberContext
+// then carries into the service layer.
+func (h *OrganizationHandler) requireOrgAdmin(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	orgID := c.Params("orgId")
+	if orgID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Organization ID is required")
+	}
+
+	if err := h.organizationService.AuthorizeOrgAdmin(ctx, orgID, userID); err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "authorize_org_admin")
+	}
+
+	c.Locals("tenant_id", orgID)
+	return c.Next()
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req organizations.CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	org, err := h.organizationService.CreateOrganization(ctx, userID, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "create_organization")
+	}
+
+	return response.Created(c, org, "Organization created successfully")
+}
+
+func (h *OrganizationHandler) GetMyOrganizations(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	memberships, err := h.organizationService.GetMyOrganizations(ctx, userID)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "get_my_organizations")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, memberships)
+}
+
+func (h *OrganizationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req organizations.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	member, err := h.organizationService.AcceptInvitation(ctx, userID, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "accept_invitation")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, member)
+}
+
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	org, err := h.organizationService.GetOrganization(ctx)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "get_organization")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, org)
+}
+
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req organizations.UpdateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	org, err := h.organizationService.UpdateOrganization(ctx, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "update_organization")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, org)
+}
+
+func (h *OrganizationHandler) GetDashboard(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 15*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	dashboard, err := h.organizationService.GetDashboard(ctx)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "get_organization_dashboard")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, dashboard)
+}
+
+func (h *OrganizationHandler) ListMembers(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	members, err := h.organizationService.ListMembers(ctx)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "list_organization_members")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, members)
+}
+
+func (h *OrganizationHandler) InviteMember(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req organizations.InviteMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	member, err := h.organizationService.InviteMember(ctx, userID, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "invite_organization_member")
+	}
+
+	return response.Created(c, member, "Invitation sent successfully")
+}
+
+func (h *OrganizationHandler) UpdateMemberAllowance(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	memberID := c.Params("memberId")
+	if memberID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Member ID is required")
+	}
+
+	var req organizations.UpdateMemberAllowanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	member, err := h.organizationService.UpdateMemberAllowance(ctx, memberID, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "update_member_allowance")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, member)
+}
+
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	memberID := c.Params("memberId")
+	if memberID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Member ID is required")
+	}
+
+	if err := h.organizationService.RemoveMember(ctx, memberID); err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "remove_organization_member")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+func (h *OrganizationHandler) GetMemberSubscriptions(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	subscriptions, err := h.organizationService.GetMemberSubscriptions(ctx)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "get_member_subscriptions")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, subscriptions)
+}
+
+func (h *OrganizationHandler) GenerateInvoice(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req organizations.GenerateInvoiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	invoice, err := h.organizationService.GenerateInvoice(ctx, req)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "generate_invoice")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, invoice)
+}
+
+func (h *OrganizationHandler) ListInvoices(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	invoices, err := h.organizationService.ListInvoices(ctx)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "list_invoices")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, invoices)
+}
+
+func (h *OrganizationHandler) GetInvoice(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	invoiceID := c.Params("invoiceId")
+	if invoiceID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Invoice ID is required")
+	}
+
+	invoice, err := h.organizationService.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return h.handleOrganizationError(c, errHandler, requestID, err, c.Path(), "get_invoice")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, invoice)
+}
+
+func (h *OrganizationHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *OrganizationHandler) handleOrganizationError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case organizations.ErrOrganizationNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Organization")
+	case organizations.ErrMemberNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Member")
+	case organizations.ErrInvitationNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Invitation")
+	case organizations.ErrInvoiceNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Invoice")
+	case organizations.ErrNotOrganizationAdmin:
+		return errHandler.HandleForbidden(c, requestID, "Organization admin access required")
+	case organizations.ErrNotOrganizationMember:
+		return errHandler.HandleForbidden(c, requestID, "You are not an active member of this organization")
+	case organizations.ErrInvitationEmailMismatch:
+		return errHandler.HandleForbidden(c, requestID, "This invitation was sent to a different email address")
+	case organizations.ErrOrganizationInactive:
+		return response.UnprocessableEntity(c, "This organization is inactive")
+	case organizations.ErrInvitationExpired:
+		return response.UnprocessableEntity(c, "This invitation has expired. Ask your organization admin to resend it")
+	case organizations.ErrCannotRemoveOwner:
+		return errHandler.HandleBadRequest(c, requestID, "The organization owner cannot be removed")
+	case organizations.ErrMemberAlreadyExists:
+		return response.Conflict(c, "This email is already a member of the organization")
+	case organizations.ErrInvoiceAlreadyIssued:
+		return response.Conflict(c, "An invoice for this period has already been issued")
+	case organizations.ErrInvalidInvoicePeriod:
+		return errHandler.HandleBadRequest(c, requestID, "Invoice period must be a past or current month in YYYY-MM format")
+	case organizations.ErrMissingTenant:
+		return errHandler.HandleBadRequest(c, requestID, "Organization context is missing")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,586 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/organizations"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *entity.Organization, owner *entity.OrganizationMember) error
+	GetByID(ctx context.Context, id string) (*entity.Organization, error)
+	Update(ctx context.Context, org *entity.Organization) error
+	GetByUserID(ctx context.Context, userID string) ([]organizations.OrganizationMembership, error)
+
+	CreateMember(ctx context.Context, member *entity.OrganizationMember) error
+	GetMemberByID(ctx context.Context, orgID, memberID string) (*entity.OrganizationMember, error)
+	GetMemberByUserID(ctx context.Context, orgID, userID string) (*entity.OrganizationMember, error)
+	GetMemberByEmail(ctx context.Context, orgID, email string) (*entity.OrganizationMember, error)
+	// LockMember reads the user's membership and holds its row lock until
+	// the transaction in ctx ends, so allowance checks for one member run
+	// one at a time.
+	LockMember(ctx context.Context, orgID, userID string) (*entity.OrganizationMember, error)
+	GetMemberByInviteToken(ctx context.Context, tokenHash string) (*entity.OrganizationMember, error)
+	GetMembers(ctx context.Context, orgID string) ([]entity.OrganizationMember, error)
+	UpdateMember(ctx context.Context, member *entity.OrganizationMember) error
+	// RemoveMember marks the member removed and moves their open
+	// subscriptions off the organization's bill, returning how many moved.
+	RemoveMember(ctx context.Context, member *entity.OrganizationMember) (int64, error)
+
+	GetMemberMonthlySpend(ctx context.Context, orgID, userID string) (float64, error)
+	GetMemberSpendSummaries(ctx context.Context, orgID string) ([]organizations.MemberSpendSummary, error)
+	GetMemberSubscriptions(ctx context.Context, orgID string) ([]organizations.MemberSubscription, error)
+
+	GetInvoiceLineItems(ctx context.Context, orgID string, periodStart, periodEnd time.Time) ([]entity.InvoiceLineItem, error)
+	UpsertInvoice(ctx context.Context, invoice *entity.OrganizationInvoice) error
+	GetInvoices(ctx context.Context, orgID string) ([]entity.OrganizationInvoice, error)
+	GetInvoiceByID(ctx context.Context, orgID, invoiceID string) (*entity.OrganizationInvoice, error)
+	GetInvoiceByPeriod(ctx context.Context, orgID string, periodStart time.Time) (*entity.OrganizationInvoice, error)
+}
+
+type organizationRepository struct {
+	db *database.DB
+}
+
+func NewOrganizationRepository(db *sqlx.DB) OrganizationRepository {
+	return &organizationRepository{db: database.New(db)}
+}
+
+const memberColumns = `
+	id, organization_id, user_id, email, role, status, monthly_allowance,
+	invite_token_hash, invite_expires_at, invited_by, joined_at, created_at, updated_at
+`
+
+func (r *organizationRepository) Create(ctx context.Context, org *entity.Organization, owner *entity.OrganizationMember) error {
+	query := `
+		INSERT INTO organizations (
+			id, name, billing_email, owner_user_id, default_monthly_allowance,
+			is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query,
+			org.ID, org.Name, org.BillingEmail, org.OwnerUserID, org.DefaultMonthlyAllowance,
+			org.IsActive, org.CreatedAt, org.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+
+		return r.CreateMember(ctx, owner)
+	})
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id string) (*entity.Organization, error) {
+	query := `
+		SELECT id, name, billing_email, owner_user_id, default_monthly_allowance,
+		       is_active, created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`
+
+	var org entity.Organization
+	if err := r.db.GetContext(ctx, &org, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, organizations.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &org, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, org *entity.Organization) error {
+	query := `
+		UPDATE organizations
+		SET name = $2, billing_email = $3, default_monthly_allowance = $4,
+		    is_active = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		org.ID, org.Name, org.BillingEmail, org.DefaultMonthlyAllowance,
+		org.IsActive, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return organizations.ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) GetByUserID(ctx context.Context, userID string) ([]organizations.OrganizationMembership, error) {
+	query := `
+		SELECT o.id, o.name, o.billing_email, o.owner_user_id, o.default_monthly_allowance,
+		       o.is_active, o.created_at, o.updated_at, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1 AND m.status = 'active'
+		ORDER BY o.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user organizations: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []organizations.OrganizationMembership{}
+	for rows.Next() {
+		var m organizations.OrganizationMembership
+		err := rows.Scan(
+			&m.ID, &m.Name, &m.BillingEmail, &m.OwnerUserID, &m.DefaultMonthlyAllowance,
+			&m.IsActive, &m.CreatedAt, &m.UpdatedAt, &m.Role,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return memberships, nil
+}
+
+func (r *organizationRepository) CreateMember(ctx context.Context, member *entity.OrganizationMember) error {
+	query := `
+		INSERT INTO organization_members (
+			id, organization_id, user_id, email, role, status, monthly_allowance,
+			invite_token_hash, invite_expires_at, invited_by, joined_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		member.ID, member.OrganizationID, member.UserID, member.Email, member.Role, member.Status,
+		member.MonthlyAllowance, member.InviteTokenHash, member.InviteExpiresAt, member.InvitedBy,
+		member.JoinedAt, member.CreatedAt, member.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return organizations.ErrMemberAlreadyExists
+		}
+		return fmt.Errorf("failed to create organization member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) GetMemberByID(ctx context.Context, orgID, memberID string) (*entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`SELECT %s FROM organization_members WHERE organization_id = $1 AND id = $2`, memberColumns)
+	return r.getMember(ctx, query, orgID, memberID)
+}
+
+func (r *organizationRepository) GetMemberByUserID(ctx context.Context, orgID, userID string) (*entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`SELECT %s FROM organization_members WHERE organization_id = $1 AND user_id = $2`, memberColumns)
+	return r.getMember(ctx, query, orgID, userID)
+}
+
+func (r *organizationRepository) LockMember(ctx context.Context, orgID, userID string) (*entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`SELECT %s FROM organization_members WHERE organization_id = $1 AND user_id = $2 FOR UPDATE`, memberColumns)
+	return r.getMember(ctx, query, orgID, userID)
+}
+
+func (r *organizationRepository) GetMemberByEmail(ctx context.Context, orgID, email string) (*entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`SELECT %s FROM organization_members WHERE organization_id = $1 AND LOWER(email) = LOWER($2)`, memberColumns)
+	return r.getMember(ctx, query, orgID, email)
+}
+
+func (r *organizationRepository) GetMemberByInviteToken(ctx context.Context, tokenHash string) (*entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`SELECT %s FROM organization_members WHERE invite_token_hash = $1 AND status = 'invited'`, memberColumns)
+
+	member, err := r.getMember(ctx, query, tokenHash)
+	if err == organizations.ErrMemberNotFound {
+		return nil, organizations.ErrInvitationNotFound
+	}
+
+	return member, err
+}
+
+func (r *organizationRepository) getMember(ctx context.Context, query string, args ...interface{}) (*entity.OrganizationMember, error) {
+	var member entity.OrganizationMember
+	if err := r.db.GetContext(ctx, &member, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, organizations.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	return &member, nil
+}
+
+func (r *organizationRepository) GetMembers(ctx context.Context, orgID string) ([]entity.OrganizationMember, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM organization_members
+		WHERE organization_id = $1 AND status != 'removed'
+		ORDER BY created_at
+	`, memberColumns)
+
+	members := []entity.OrganizationMember{}
+	if err := r.db.SelectContext(ctx, &members, query, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, member *entity.OrganizationMember) error {
+	query := `
+		UPDATE organization_members
+		SET user_id = $2, role = $3, status = $4, monthly_allowance = $5,
+		    invite_token_hash = $6, invite_expires_at = $7, joined_at = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		member.ID, member.UserID, member.Role, member.Status, member.MonthlyAllowance,
+		member.InviteTokenHash, member.InviteExpiresAt, member.JoinedAt, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return organizations.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, member *entity.OrganizationMember) (int64, error) {
+	// Closed subscriptions stay with the organization as a record of what
+	// it was billed for.
+	query := `
+		UPDATE subscriptions
+		SET organization_id = NULL, version = version + 1, updated_at = $3
+		WHERE organization_id = $1 AND user_id = $2 AND status NOT IN ('cancelled', 'expired')
+	`
+
+	var detached int64
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		member.Status = entity.OrgMemberRemoved
+		member.InviteTokenHash = nil
+		member.InviteExpiresAt = nil
+
+		if err := r.UpdateMember(ctx, member); err != nil {
+			return err
+		}
+
+		if member.UserID == nil {
+			return nil
+		}
+
+		result, err := r.db.ExecContext(ctx, query, member.OrganizationID, *member.UserID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to detach member subscriptions: %w", err)
+		}
+
+		detached, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return detached, nil
+}
+
+func (r *organizationRepository) GetMemberMonthlySpend(ctx context.Context, orgID, userID string) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(total_price), 0)
+		FROM subscriptions
+		WHERE organization_id = $1 AND user_id = $2 AND status NOT IN ('cancelled', 'expired')
+		AND deleted_at IS NULL
+	`
+
+	var spend float64
+	if err := r.db.QueryRowContext(ctx, query, orgID, userID).Scan(&spend); err != nil {
+		return 0, fmt.Errorf("failed to get member monthly spend: %w", err)
+	}
+
+	return spend, nil
+}
+
+func (r *organizationRepository) GetMemberSpendSummaries(ctx context.Context, orgID string) ([]organizations.MemberSpendSummary, error) {
+	query := `
+		SELECT m.id, m.user_id, COALESCE(u.name, ''), m.email, m.role, m.status,
+		       COALESCE(m.monthly_allowance, o.default_monthly_allowance),
+		       COALESCE(SUM(s.total_price) FILTER (WHERE s.status NOT IN ('cancelled', 'expired')), 0),
+		       COUNT(s.id) FILTER (WHERE s.status = 'active')
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		LEFT JOIN users u ON u.id = m.user_id
+		LEFT JOIN subscriptions s ON s.user_id = m.user_id AND s.organization_id = m.organization_id
+		     AND s.deleted_at IS NULL
+		WHERE m.organization_id = $1 AND m.status != 'removed'
+		GROUP BY m.id, u.name, o.default_monthly_allowance
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member spend summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []organizations.MemberSpendSummary{}
+	for rows.Next() {
+		var summary organizations.MemberSpendSummary
+		err := rows.Scan(
+			&summary.MemberID, &summary.UserID, &summary.Name, &summary.Email, &summary.Role, &summary.Status,
+			&summary.MonthlyAllowance, &summary.MonthlySpend, &summary.ActiveSubscriptions,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member spend summary: %w", err)
+		}
+
+		if summary.MonthlyAllowance != nil {
+			remaining := *summary.MonthlyAllowance - summary.MonthlySpend
+			summary.RemainingAllowance = &remaining
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return summaries, nil
+}
+
+func (r *organizationRepository) GetMemberSubscriptions(ctx context.Context, orgID string) ([]organizations.MemberSubscription, error) {
+	query := `
+		SELECT s.id, s.user_id, u.name, u.email, s.meal_plan_id, mp.name,
+		       s.meal_types, s.delivery_days, s.total_price, s.status,
+		       s.pause_start_date, s.pause_end_date, s.created_at
+		FROM subscriptions s
+		JOIN users u ON s.user_id = u.id
+		JOIN meal_plans mp ON s.meal_plan_id = mp.id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+		ORDER BY s.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []organizations.MemberSubscription{}
+	for rows.Next() {
+		var sub organizations.MemberSubscription
+		var mealTypes, deliveryDays pq.StringArray
+
+		err := rows.Scan(
+			&sub.SubscriptionID, &sub.UserID, &sub.MemberName, &sub.MemberEmail, &sub.MealPlanID, &sub.MealPlanName,
+			&mealTypes, &deliveryDays, &sub.TotalPrice, &sub.Status,
+			&sub.PauseStart, &sub.PauseEnd, &sub.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member subscription: %w", err)
+		}
+
+		sub.MealTypes = []string(mealTypes)
+		sub.DeliveryDays = []string(deliveryDays)
+		subscriptions = append(subscriptions, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *organizationRepository) GetInvoiceLineItems(ctx context.Context, orgID string, periodStart, periodEnd time.Time) ([]entity.InvoiceLineItem, error) {
+	query := `
+		SELECT s.id, s.user_id, u.name, u.email, mp.name, s.status, s.total_price
+		FROM subscriptions s
+		JOIN users u ON s.user_id = u.id
+		JOIN meal_plans mp ON s.meal_plan_id = mp.id
+		WHERE s.organization_id = $1 AND s.deleted_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM organization_members m
+			WHERE m.organization_id = s.organization_id AND m.user_id = s.user_id AND m.status != 'removed'
+		)
+		AND s.created_at < $3
+		AND (s.status NOT IN ('cancelled', 'expired') OR s.updated_at >= $2)
+		ORDER BY u.name, s.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, periodStart, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice line items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []entity.InvoiceLineItem{}
+	for rows.Next() {
+		var item entity.InvoiceLineItem
+		err := rows.Scan(
+			&item.SubscriptionID, &item.UserID, &item.MemberName, &item.MemberEmail,
+			&item.MealPlanName, &item.Status, &item.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *organizationRepository) UpsertInvoice(ctx context.Context, invoice *entity.OrganizationInvoice) error {
+	lineItems, err := json.Marshal(invoice.LineItems)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoice line items: %w", err)
+	}
+
+	query := `
+		INSERT INTO organization_invoices (
+			id, organization_id, period_start, period_end, subscription_count,
+			total_amount, status, line_items, issued_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (organization_id, period_start) DO UPDATE
+		SET period_end = EXCLUDED.period_end,
+		    subscription_count = EXCLUDED.subscription_count,
+		    total_amount = EXCLUDED.total_amount,
+		    status = EXCLUDED.status,
+		    line_items = EXCLUDED.line_items,
+		    issued_at = EXCLUDED.issued_at,
+		    updated_at = EXCLUDED.updated_at
+		WHERE organization_invoices.status = 'draft'
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		invoice.ID, invoice.OrganizationID, invoice.PeriodStart, invoice.PeriodEnd, invoice.SubscriptionCount,
+		invoice.TotalAmount, invoice.Status, lineItems, invoice.IssuedAt, invoice.CreatedAt, invoice.UpdatedAt,
+	).Scan(&invoice.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return organizations.ErrInvoiceAlreadyIssued
+		}
+		return fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) GetInvoices(ctx context.Context, orgID string) ([]entity.OrganizationInvoice, error) {
+	query := `
+		SELECT id, organization_id, period_start, period_end, subscription_count,
+		       total_amount, status, line_items, issued_at, created_at, updated_at
+		FROM organization_invoices
+		WHERE organization_id = $1
+		ORDER BY period_start DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []entity.OrganizationInvoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return invoices, nil
+}
+
+func (r *organizationRepository) GetInvoiceByID(ctx context.Context, orgID, invoiceID string) (*entity.OrganizationInvoice, error) {
+	query := `
+		SELECT id, organization_id, period_start, period_end, subscription_count,
+		       total_amount, status, line_items, issued_at, created_at, updated_at
+		FROM organization_invoices
+		WHERE organization_id = $1 AND id = $2
+	`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, orgID, invoiceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, organizations.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *organizationRepository) GetInvoiceByPeriod(ctx context.Context, orgID string, periodStart time.Time) (*entity.OrganizationInvoice, error) {
+	query := `
+		SELECT id, organization_id, period_start, period_end, subscription_count,
+		       total_amount, status, line_items, issued_at, created_at, updated_at
+		FROM organization_invoices
+		WHERE organization_id = $1 AND period_start = $2
+	`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, orgID, periodStart))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, organizations.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row rowScanner) (*entity.OrganizationInvoice, error) {
+	var invoice entity.OrganizationInvoice
+	var lineItems []byte
+
+	err := row.Scan(
+		&invoice.ID, &invoice.OrganizationID, &invoice.PeriodStart, &invoice.PeriodEnd, &invoice.SubscriptionCount,
+		&invoice.TotalAmount, &invoice.Status, &lineItems, &invoice.IssuedAt, &invoice.CreatedAt, &invoice.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan invoice: %w", err)
+	}
+
+	if err := json.Unmarshal(lineItems, &invoice.LineItems); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invoice line items: %w", err)
+	}
+
+	return &invoice, nil
+}
@@ -0,0 +1,589 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/organizations"
+	"sea-catering-backend/internal/api/organizations/repository"
+	"sea-catering-backend/internal/entity"
+	appctx "sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/utils"
+)
+
+const invitationValidity = 7 * 24 * time.Hour
+
+// OrganizationService manages corporate accounts. Methods that act on a single
+// organization read its ID from the tenant set on the request context, so the
+// handler must authorize the caller with AuthorizeOrgAdmin first.
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userID string, req organizations.CreateOrganizationRequest) (*entity.Organization, error)
+	GetMyOrganizations(ctx context.Context, userID string) ([]organizations.OrganizationMembership, error)
+	AcceptInvitation(ctx context.Context, userID string, req organizations.AcceptInvitationRequest) (*entity.OrganizationMember, error)
+	AuthorizeOrgAdmin(ctx context.Context, orgID, userID string) error
+
+	GetOrganization(ctx context.Context) (*entity.Organization, error)
+	UpdateOrganization(ctx context.Context, req organizations.UpdateOrganizationRequest) (*entity.Organization, error)
+	GetDashboard(ctx context.Context) (*organizations.OrganizationDashboardResponse, error)
+	ListMembers(ctx context.Context) ([]entity.OrganizationMember, error)
+	InviteMember(ctx context.Context, inviterID string, req organizations.InviteMemberRequest) (*entity.OrganizationMember, error)
+	UpdateMemberAllowance(ctx context.Context, memberID string, req organizations.UpdateMemberAllowanceRequest) (*entity.OrganizationMember, error)
+	RemoveMember(ctx context.Context, memberID string) error
+	GetMemberSubscriptions(ctx context.Context) ([]organizations.MemberSubscription, error)
+	GenerateInvoice(ctx context.Context, req organizations.GenerateInvoiceRequest) (*entity.OrganizationInvoice, error)
+	ListInvoices(ctx context.Context) ([]entity.OrganizationInvoice, error)
+	GetInvoice(ctx context.Context, invoiceID string) (*entity.OrganizationInvoice, error)
+}
+
+type organizationService struct {
+	orgRepo      repository.OrganizationRepository
+	userRepo     authRepo.UserRepository
+	emailService email.Interface
+	utils        utils.Interface
+	logger       *logger.Logger
+}
+
+func NewOrganizationService(
+	orgRepo repository.OrganizationRepository,
+	userRepo authRepo.UserRepository,
+	emailService email.Interface,
+	utils utils.Interface,
+	logger *logger.Logger,
+) OrganizationService {
+	return &organizationService{
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		emailService: emailService,
+		utils:        utils,
+		logger:       logger,
+	}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, userID string, req organizations.CreateOrganizationRequest) (*entity.Organization, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	org := &entity.Organization{
+		ID:                      s.utils.GenerateULID(),
+		Name:                    strings.TrimSpace(req.Name),
+		BillingEmail:            strings.ToLower(strings.TrimSpace(req.BillingEmail)),
+		OwnerUserID:             userID,
+		DefaultMonthlyAllowance: req.DefaultMonthlyAllowance,
+		IsActive:                true,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+
+	owner := &entity.OrganizationMember{
+		ID:             s.utils.GenerateULID(),
+		OrganizationID: org.ID,
+		UserID:         &userID,
+		Email:          strings.ToLower(user.Email),
+		Role:           entity.OrgRoleAdmin,
+		Status:         entity.OrgMemberActive,
+		JoinedAt:       &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.orgRepo.Create(ctx, org, owner); err != nil {
+		s.logger.Error("Failed to create organization", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Organization created", logger.Fields{
+		"organization_id": org.ID,
+		"owner_id":        userID,
+	})
+
+	return org, nil
+}
+
+func (s *organizationService) GetMyOrganizations(ctx context.Context, userID string) ([]organizations.OrganizationMembership, error) {
+	return s.orgRepo.GetByUserID(ctx, userID)
+}
+
+func (s *organizationService) AcceptInvitation(ctx context.Context, userID string, req organizations.AcceptInvitationRequest) (*entity.OrganizationMember, error) {
+	member, err := s.orgRepo.GetMemberByInviteToken(ctx, s.utils.HashString(req.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if member.InviteExpiresAt != nil && time.Now().After(*member.InviteExpiresAt) {
+		return nil, organizations.ErrInvitationExpired
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(user.Email, member.Email) {
+		return nil, organizations.ErrInvitationEmailMismatch
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, member.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !org.IsActive {
+		return nil, organizations.ErrOrganizationInactive
+	}
+
+	now := time.Now()
+	member.UserID = &userID
+	member.Status = entity.OrgMemberActive
+	member.InviteTokenHash = nil
+	member.InviteExpiresAt = nil
+	member.JoinedAt = &now
+
+	if err := s.orgRepo.UpdateMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Organization invitation accepted", logger.Fields{
+		"organization_id": member.OrganizationID,
+		"member_id":       member.ID,
+		"user_id":         userID,
+	})
+
+	return member, nil
+}
+
+func (s *organizationService) AuthorizeOrgAdmin(ctx context.Context, orgID, userID string) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if !org.IsActive {
+		return organizations.ErrOrganizationInactive
+	}
+
+	member, err := s.orgRepo.GetMemberByUserID(ctx, orgID, userID)
+	if err != nil {
+		if err == organizations.ErrMemberNotFound {
+			return organizations.ErrNotOrganizationAdmin
+		}
+		return err
+	}
+
+	if !member.IsAdmin() {
+		return organizations.ErrNotOrganizationAdmin
+	}
+
+	return nil
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context) (*entity.Organization, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgRepo.GetByID(ctx, orgID)
+}
+
+func (s *organizationService) UpdateOrganization(ctx context.Context, req organizations.UpdateOrganizationRequest) (*entity.Organization, error) {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org.Name = strings.TrimSpace(req.Name)
+	org.BillingEmail = strings.ToLower(strings.TrimSpace(req.BillingEmail))
+	org.DefaultMonthlyAllowance = req.DefaultMonthlyAllowance
+	org.UpdatedAt = time.Now()
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+func (s *organizationService) GetDashboard(ctx context.Context) (*organizations.OrganizationDashboardResponse, error) {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.GetMemberSpendSummaries(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.orgRepo.GetMemberSubscriptions(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &organizations.OrganizationDashboardResponse{
+		Organization: *org,
+		TotalMembers: len(members),
+		Members:      members,
+		GeneratedAt:  time.Now(),
+	}
+
+	var budget float64
+	hasBudget := false
+	for _, member := range members {
+		switch member.Status {
+		case entity.OrgMemberActive:
+			dashboard.ActiveMembers++
+		case entity.OrgMemberInvited:
+			dashboard.PendingInvitations++
+		}
+
+		dashboard.MonthlySpend += member.MonthlySpend
+		if member.MonthlyAllowance != nil {
+			budget += *member.MonthlyAllowance
+			hasBudget = true
+		}
+	}
+
+	if hasBudget {
+		dashboard.MonthlyBudget = &budget
+	}
+
+	for _, sub := range subscriptions {
+		switch entity.SubscriptionStatus(sub.Status) {
+		case entity.StatusActive:
+			dashboard.ActiveSubscriptions++
+		case entity.StatusPaused:
+			dashboard.PausedSubscriptions++
+		}
+	}
+
+	return dashboard, nil
+}
+
+func (s *organizationService) ListMembers(ctx context.Context) ([]entity.OrganizationMember, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgRepo.GetMembers(ctx, orgID)
+}
+
+func (s *organizationService) InviteMember(ctx context.Context, inviterID string, req organizations.InviteMemberRequest) (*entity.OrganizationMember, error) {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inviter, err := s.getUser(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entity.OrgRoleMember
+	if req.Role != "" {
+		role = entity.OrganizationMemberRole(req.Role)
+	}
+
+	token := s.utils.GenerateSecureToken(32)
+	tokenHash := s.utils.HashString(token)
+	expiresAt := time.Now().Add(invitationValidity)
+	emailAddress := strings.ToLower(strings.TrimSpace(req.Email))
+
+	member, err := s.orgRepo.GetMemberByEmail(ctx, org.ID, emailAddress)
+	switch {
+	case err == nil:
+		// Pending invitations are refreshed and removed members re-invited,
+		// so an admin can resend without tripping the unique constraint.
+		if member.Status == entity.OrgMemberActive {
+			return nil, organizations.ErrMemberAlreadyExists
+		}
+
+		member.Role = role
+		member.Status = entity.OrgMemberInvited
+		member.MonthlyAllowance = req.MonthlyAllowance
+		member.InviteTokenHash = &tokenHash
+		member.InviteExpiresAt = &expiresAt
+		member.UserID = nil
+		member.JoinedAt = nil
+
+		if err := s.orgRepo.UpdateMember(ctx, member); err != nil {
+			return nil, err
+		}
+	case err == organizations.ErrMemberNotFound:
+		now := time.Now()
+		member = &entity.OrganizationMember{
+			ID:               s.utils.GenerateULID(),
+			OrganizationID:   org.ID,
+			Email:            emailAddress,
+			Role:             role,
+			Status:           entity.OrgMemberInvited,
+			MonthlyAllowance: req.MonthlyAllowance,
+			InviteTokenHash:  &tokenHash,
+			InviteExpiresAt:  &expiresAt,
+			InvitedBy:        &inviterID,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+
+		if err := s.orgRepo.CreateMember(ctx, member); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	allowance := 0.0
+	if effective := member.EffectiveAllowance(org); effective != nil {
+		allowance = *effective
+	}
+
+	err = s.emailService.SendOrganizationInvitationEmail(member.Email, &email.OrganizationInvitationDetails{
+		OrganizationName: org.Name,
+		InviterName:      inviter.Name,
+		InviteToken:      token,
+		MonthlyAllowance: allowance,
+		ExpiresAt:        expiresAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send organization invitation email", logger.Fields{
+			"error":           err.Error(),
+			"organization_id": org.ID,
+			"email":           member.Email,
+		})
+	}
+
+	s.logger.Info("Organization member invited", logger.Fields{
+		"organization_id": org.ID,
+		"member_id":       member.ID,
+		"invited_by":      inviterID,
+	})
+
+	return member, nil
+}
+
+func (s *organizationService) UpdateMemberAllowance(ctx context.Context, memberID string, req organizations.UpdateMemberAllowanceRequest) (*entity.OrganizationMember, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.orgRepo.GetMemberByID(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Status == entity.OrgMemberRemoved {
+		return nil, organizations.ErrMemberNotFound
+	}
+
+	member.MonthlyAllowance = req.MonthlyAllowance
+
+	if err := s.orgRepo.UpdateMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, memberID string) error {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.orgRepo.GetMemberByID(ctx, org.ID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if member.UserID != nil && *member.UserID == org.OwnerUserID {
+		return organizations.ErrCannotRemoveOwner
+	}
+
+	// The member's open subscriptions carry on, billed to them rather than
+	// to the organization.
+	detached, err := s.orgRepo.RemoveMember(ctx, member)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Organization member removed", logger.Fields{
+		"organization_id":        org.ID,
+		"member_id":              member.ID,
+		"detached_subscriptions": detached,
+	})
+
+	return nil
+}
+
+func (s *organizationService) GetMemberSubscriptions(ctx context.Context) ([]organizations.MemberSubscription, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgRepo.GetMemberSubscriptions(ctx, orgID)
+}
+
+func (s *organizationService) GenerateInvoice(ctx context.Context, req organizations.GenerateInvoiceRequest) (*entity.OrganizationInvoice, error) {
+	org, err := s.GetOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	periodStart, err := time.ParseInLocation("2006-01", req.Period, time.Local)
+	if err != nil {
+		return nil, organizations.ErrInvalidInvoicePeriod
+	}
+
+	if periodStart.After(time.Now()) {
+		return nil, organizations.ErrInvalidInvoicePeriod
+	}
+
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	existing, err := s.orgRepo.GetInvoiceByPeriod(ctx, org.ID, periodStart)
+	if err != nil && err != organizations.ErrInvoiceNotFound {
+		return nil, err
+	}
+
+	if existing != nil && existing.Status != entity.InvoiceStatusDraft {
+		return nil, organizations.ErrInvoiceAlreadyIssued
+	}
+
+	items, err := s.orgRepo.GetInvoiceLineItems(ctx, org.ID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invoice := &entity.OrganizationInvoice{
+		ID:                s.utils.GenerateULID(),
+		OrganizationID:    org.ID,
+		PeriodStart:       periodStart,
+		PeriodEnd:         periodEnd.AddDate(0, 0, -1),
+		SubscriptionCount: len(items),
+		Status:            entity.InvoiceStatusDraft,
+		LineItems:         items,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if existing != nil {
+		invoice.ID = existing.ID
+		invoice.CreatedAt = existing.CreatedAt
+	}
+
+	for _, item := range items {
+		invoice.TotalAmount += item.Amount
+	}
+
+	if req.Issue {
+		invoice.Status = entity.InvoiceStatusIssued
+		invoice.IssuedAt = &now
+	}
+
+	if err := s.orgRepo.UpsertInvoice(ctx, invoice); err != nil {
+		s.logger.Error("Failed to save organization invoice", logger.Fields{
+			"error":           err.Error(),
+			"organization_id": org.ID,
+			"period":          req.Period,
+		})
+		return nil, err
+	}
+
+	if req.Issue {
+		s.sendInvoiceEmail(org, invoice)
+	}
+
+	s.logger.Info("Organization invoice generated", logger.Fields{
+		"organization_id": org.ID,
+		"invoice_id":      invoice.ID,
+		"period":          req.Period,
+		"status":          invoice.Status,
+		"total_amount":    invoice.TotalAmount,
+	})
+
+	return invoice, nil
+}
+
+func (s *organizationService) ListInvoices(ctx context.Context) ([]entity.OrganizationInvoice, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgRepo.GetInvoices(ctx, orgID)
+}
+
+func (s *organizationService) GetInvoice(ctx context.Context, invoiceID string) (*entity.OrganizationInvoice, error) {
+	orgID, err := tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgRepo.GetInvoiceByID(ctx, orgID, invoiceID)
+}
+
+func (s *organizationService) sendInvoiceEmail(org *entity.Organization, invoice *entity.OrganizationInvoice) {
+	items := make([]email.InvoiceItem, len(invoice.LineItems))
+	for i, item := range invoice.LineItems {
+		items[i] = email.InvoiceItem{
+			MemberName:   item.MemberName,
+			MemberEmail:  item.MemberEmail,
+			MealPlanName: item.MealPlanName,
+			Amount:       item.Amount,
+		}
+	}
+
+	err := s.emailService.SendOrganizationInvoiceEmail(org.BillingEmail, org.Name, &email.InvoiceDetails{
+		InvoiceID:         invoice.ID,
+		PeriodStart:       invoice.PeriodStart,
+		PeriodEnd:         invoice.PeriodEnd,
+		SubscriptionCount: invoice.SubscriptionCount,
+		TotalAmount:       invoice.TotalAmount,
+		Items:             items,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send organization invoice email", logger.Fields{
+			"error":           err.Error(),
+			"organization_id": org.ID,
+			"invoice_id":      invoice.ID,
+		})
+	}
+}
+
+func (s *organizationService) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, organizations.ErrNotOrganizationMember
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func tenantID(ctx context.Context) (string, error) {
+	orgID := appctx.GetTenantID(ctx)
+	if orgID == "" {
+		return "", organizations.ErrMissingTenant
+	}
+	return orgID, nil
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/email"
+)
+
+type OutboxListRequest struct {
+	Page      int    `query:"page" validate:"omitempty,min=1"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status    string `query:"status" validate:"omitempty,oneof=pending sending sent dead"`
+	Recipient string `query:"recipient" validate:"omitempty,max=255"`
+}
+
+type OutboxListResponse struct {
+	Emails []entity.OutboxEmail `json:"emails"`
+	Meta   *PaginationMeta      `json:"meta,omitempty"`
+}
+
+type OutboxStatsResponse struct {
+	Pending int `db:"pending" json:"pending"`
+	Sending int `db:"sending" json:"sending"`
+	Sent    int `db:"sent" json:"sent"`
+	Dead    int `db:"dead" json:"dead"`
+}
+
+type TemplateListResponse struct {
+	Templates []string       `json:"templates"`
+	Locales   []email.Locale `json:"locales"`
+}
+
+type TemplatePreviewRequest struct {
+	Locale string `query:"locale" validate:"omitempty,oneof=id en"`
+	Format string `query:"format" validate:"omitempty,oneof=json html text"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
@@ -0,0 +1,10 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrEmailNotFound      = errors.New("outbox email not found")
+	ErrEmailNotResendable = errors.New("outbox email is still queued")
+	ErrNoRecipients       = errors.New("no recipients specified")
+	ErrTemplateNotFound   = errors.New("email template not found")
+)
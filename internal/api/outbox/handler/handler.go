@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/outbox"
+	"sea-catering-backend/internal/api/outbox/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type OutboxHandler struct {
+	outboxService service.OutboxService
+	validator     *validator.Validate
+	middleware    middleware.Interface
+	logger        *logger.Logger
+}
+
+func NewOutboxHandler(
+	outboxService service.OutboxService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+		validator:     validator,
+		middleware:    middleware,
+		logger:        logger,
+	}
+}
+
+func (h *OutboxHandler) RegisterRoutes(router fiber.Router) {
+	outboxGroup := router.Group("/outbox")
+
+	admin := outboxGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/emails", h.ListEmails)
+	admin.Get("/emails/stats", h.GetStats)
+	admin.Get("/emails/:id", h.GetEmail)
+	admin.Post("/emails/:id/resend", h.ResendEmail)
+	admin.Get("/templates", h.ListTemplates)
+	admin.Get("/templates/:name/preview", h.PreviewTemplate)
+}
+
+func (h *OutboxHandler) ListEmails(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req outbox.OutboxListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.outboxService.ListEmails(ctx, req)
+	if err != nil {
+		return h.handleOutboxError(c, errHandler, requestID, err, c.Path(), "list_outbox_emails")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *OutboxHandler) GetStats(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	stats, err := h.outboxService.GetStats(ctx)
+	if err != nil {
+		return h.handleOutboxError(c, errHandler, requestID, err, c.Path(), "get_outbox_stats")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, stats)
+}
+
+func (h *OutboxHandler) GetEmail(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	emailID := c.Params("id")
+	if emailID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Email ID is required")
+	}
+
+	message, err := h.outboxService.GetEmail(ctx, emailID)
+	if err != nil {
+		return h.handleOutboxError(c, errHandler, requestID, err, c.Path(), "get_outbox_email")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, message)
+}
+
+func (h *OutboxHandler) ResendEmail(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	emailID := c.Params("id")
+	if emailID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Email ID is required")
+	}
+
+	if err := h.outboxService.ResendEmail(ctx, emailID); err != nil {
+		return h.handleOutboxError(c, errHandler, requestID, err, c.Path(), "resend_outbox_email")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Email queued for delivery",
+	})
+}
+
+func (h *OutboxHandler) ListTemplates(c *fiber.Ctx) error {
+	errHandler := handlerutil.New(h.logger)
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, h.outboxService.ListTemplates())
+}
+
+// PreviewTemplate renders a template with sample data. format=html or
+// format=text returns the raw body so it can be opened in a browser.
+func (h *OutboxHandler) PreviewTemplate(c *fiber.Ctx) error {
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req outbox.TemplatePreviewRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	locale := email.LocaleIndonesian
+	if req.Locale != "" {
+		locale = email.Locale(req.Locale)
+	}
+
+	rendered, err := h.outboxService.PreviewTemplate(c.Params("name"), locale)
+	if err != nil {
+		return h.handleOutboxError(c, errHandler, requestID, err, c.Path(), "preview_email_template")
+	}
+
+	switch req.Format {
+	case "html":
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(rendered.HTML)
+	case "text":
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(rendered.Text)
+	default:
+		return errHandler.HandleSuccess(c, fiber.StatusOK, rendered)
+	}
+}
+
+func (h *OutboxHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *OutboxHandler) handleOutboxError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case outbox.ErrEmailNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Email")
+	case outbox.ErrEmailNotResendable:
+		return response.Conflict(c, "Email is already queued for delivery")
+	case outbox.ErrTemplateNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Email template")
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/outbox"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, email *entity.OutboxEmail) error
+	GetByID(ctx context.Context, id string) (*entity.OutboxEmail, error)
+	List(ctx context.Context, req outbox.OutboxListRequest) ([]entity.OutboxEmail, *outbox.PaginationMeta, error)
+	GetStats(ctx context.Context) (*outbox.OutboxStatsResponse, error)
+	ClaimDue(ctx context.Context, limit int, lockFor time.Duration) ([]entity.OutboxEmail, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkRetry(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id, lastError string) error
+	Requeue(ctx context.Context, id string) error
+	PurgeSent(ctx context.Context, before time.Time) (int, error)
+}
+
+type outboxRepository struct {
+	db *database.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) OutboxRepository {
+	return &outboxRepository{db: database.New(db)}
+}
+
+const outboxColumns = `
+	id, recipients, subject, body, text_body, unsubscribe_url, is_html, status, attempts, max_attempts,
+	last_error, next_attempt_at, locked_until, sent_at, created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOutboxEmail(row rowScanner) (*entity.OutboxEmail, error) {
+	var email entity.OutboxEmail
+	var recipients pq.StringArray
+
+	err := row.Scan(
+		&email.ID, &recipients, &email.Subject, &email.Body, &email.TextBody, &email.UnsubscribeURL, &email.IsHTML, &email.Status,
+		&email.Attempts, &email.MaxAttempts, &email.LastError, &email.NextAttemptAt,
+		&email.LockedUntil, &email.SentAt, &email.CreatedAt, &email.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	email.Recipients = []string(recipients)
+	return &email, nil
+}
+
+func (r *outboxRepository) Create(ctx context.Context, email *entity.OutboxEmail) error {
+	query := `
+		INSERT INTO email_outbox (
+			id, recipients, subject, body, text_body, unsubscribe_url, is_html, status, attempts, max_attempts,
+			next_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		email.ID, pq.Array(email.Recipients), email.Subject, email.Body, email.TextBody, email.UnsubscribeURL, email.IsHTML,
+		email.Status, email.Attempts, email.MaxAttempts, email.NextAttemptAt, email.CreatedAt, email.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox email: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id string) (*entity.OutboxEmail, error) {
+	query := fmt.Sprintf(`SELECT %s FROM email_outbox WHERE id = $1`, outboxColumns)
+
+	email, err := scanOutboxEmail(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, outbox.ErrEmailNotFound
+		}
+		return nil, fmt.Errorf("failed to get outbox email: %w", err)
+	}
+
+	return email, nil
+}
+
+// List leaves out message bodies; they can hold one-time codes and are only
+// returned when a single email is fetched.
+func (r *outboxRepository) List(ctx context.Context, req outbox.OutboxListRequest) ([]entity.OutboxEmail, *outbox.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Status != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if req.Recipient != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(recipients) rcpt WHERE LOWER(rcpt) = LOWER($%d))", argIndex))
+		args = append(args, req.Recipient)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM email_outbox %s`, whereClause)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count outbox emails: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		SELECT id, recipients, subject, '' as body, NULL as text_body, NULL as unsubscribe_url, is_html, status, attempts, max_attempts,
+		       last_error, next_attempt_at, locked_until, sent_at, created_at, updated_at
+		FROM email_outbox
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
+
+	args = append(args, req.Limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []entity.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan outbox email: %w", err)
+		}
+		emails = append(emails, *email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	meta := &outbox.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return emails, meta, nil
+}
+
+func (r *outboxRepository) GetStats(ctx context.Context) (*outbox.OutboxStatsResponse, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') as pending,
+			COUNT(*) FILTER (WHERE status = 'sending') as sending,
+			COUNT(*) FILTER (WHERE status = 'sent') as sent,
+			COUNT(*) FILTER (WHERE status = 'dead') as dead
+		FROM email_outbox
+	`
+
+	var stats outbox.OutboxStatsResponse
+	if err := r.db.GetContext(ctx, &stats, query); err != nil {
+		return nil, fmt.Errorf("failed to get outbox stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ClaimDue locks up to limit emails for the calling worker and counts the
+// attempt. Emails whose claim has expired (the worker died mid-send) are
+// picked up again. SKIP LOCKED lets several workers and instances poll at
+// once without handing out the same email twice.
+func (r *outboxRepository) ClaimDue(ctx context.Context, limit int, lockFor time.Duration) ([]entity.OutboxEmail, error) {
+	now := time.Now()
+
+	query := fmt.Sprintf(`
+		UPDATE email_outbox
+		SET status = 'sending', attempts = attempts + 1, locked_until = $2
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE (status = 'pending' AND next_attempt_at <= $1)
+			   OR (status = 'sending' AND locked_until < $1)
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, outboxColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lockFor), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []entity.OutboxEmail
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox email: %w", err)
+		}
+		emails = append(emails, *email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return emails, nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, id string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', sent_at = $2, locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark outbox email sent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'pending', last_error = $2, next_attempt_at = $3, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule outbox retry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkDead(ctx context.Context, id, lastError string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'dead', last_error = $2, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to dead-letter outbox email: %w", err)
+	}
+
+	return nil
+}
+
+// Requeue resets a sent or dead email so the workers deliver it again with a
+// fresh attempt budget.
+func (r *outboxRepository) Requeue(ctx context.Context, id string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, locked_until = NULL, sent_at = NULL
+		WHERE id = $1 AND status IN ('sent', 'dead')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to requeue outbox email: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return outbox.ErrEmailNotResendable
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) PurgeSent(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM email_outbox WHERE status = 'sent' AND sent_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge sent outbox emails: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
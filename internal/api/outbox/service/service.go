@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"sea-catering-backend/internal/api/outbox"
+	"sea-catering-backend/internal/api/outbox/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/jobs"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/utils"
+)
+
+type OutboxService interface {
+	email.Queue
+	Start(ctx context.Context)
+	Stop()
+	ListEmails(ctx context.Context, req outbox.OutboxListRequest) (*outbox.OutboxListResponse, error)
+	GetEmail(ctx context.Context, id string) (*entity.OutboxEmail, error)
+	GetStats(ctx context.Context) (*outbox.OutboxStatsResponse, error)
+	ResendEmail(ctx context.Context, id string) error
+	ListTemplates() *outbox.TemplateListResponse
+	PreviewTemplate(name string, locale email.Locale) (*email.RenderedEmail, error)
+}
+
+type Config struct {
+	Workers      int
+	MaxAttempts  int
+	PollInterval time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// ClaimTimeout is how long a worker holds an email before another worker
+	// may assume it crashed and retry it. It must exceed the SMTP timeout.
+	ClaimTimeout time.Duration
+	// Retention is how long delivered emails are kept for inspection.
+	Retention time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		Workers:      4,
+		MaxAttempts:  6,
+		PollInterval: 2 * time.Second,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   time.Hour,
+		ClaimTimeout: 2 * time.Minute,
+		Retention:    7 * 24 * time.Hour,
+	}
+
+	if workers, err := strconv.Atoi(os.Getenv("EMAIL_OUTBOX_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+
+	if attempts, err := strconv.Atoi(os.Getenv("EMAIL_OUTBOX_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.MaxAttempts = attempts
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("EMAIL_OUTBOX_POLL_INTERVAL")); err == nil && interval > 0 {
+		config.PollInterval = interval
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv("EMAIL_OUTBOX_RETENTION")); err == nil && retention > 0 {
+		config.Retention = retention
+	}
+
+	return config
+}
+
+type outboxService struct {
+	outboxRepo   repository.OutboxRepository
+	emailService email.Interface
+	utils        utils.Interface
+	config       *Config
+	logger       *logger.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewOutboxService(
+	outboxRepo repository.OutboxRepository,
+	emailService email.Interface,
+	utils utils.Interface,
+	config *Config,
+	logger *logger.Logger,
+) OutboxService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	return &outboxService{
+		outboxRepo:   outboxRepo,
+		emailService: emailService,
+		utils:        utils,
+		config:       config,
+		logger:       logger,
+		wake:         make(chan struct{}, config.Workers),
+	}
+}
+
+// Enqueue stores the email and nudges an idle worker. It only fails when the
+// email could not be persisted, so callers can treat success as "will be
+// delivered".
+func (s *outboxService) Enqueue(mail email.Message) error {
+	if len(mail.To) == 0 {
+		return outbox.ErrNoRecipients
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	message := &entity.OutboxEmail{
+		ID:            s.utils.GenerateULID(),
+		Recipients:    mail.To,
+		Subject:       mail.Subject,
+		Status:        entity.OutboxStatusPending,
+		MaxAttempts:   s.config.MaxAttempts,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// The HTML part is the body of record; a plain-text part is kept
+	// alongside it so both are sent as alternatives.
+	if mail.HTMLBody != "" {
+		message.Body = mail.HTMLBody
+		message.IsHTML = true
+		if mail.TextBody != "" {
+			message.TextBody = &mail.TextBody
+		}
+	} else {
+		message.Body = mail.TextBody
+	}
+
+	if mail.UnsubscribeURL != "" {
+		message.UnsubscribeURL = &mail.UnsubscribeURL
+	}
+
+	if err := s.outboxRepo.Create(ctx, message); err != nil {
+		s.logger.Error("Failed to enqueue email", logger.Fields{
+			"error":   err.Error(),
+			"subject": mail.Subject,
+		})
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (s *outboxService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go s.runWorker(ctx, i)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		jobs.RunEvery(ctx, time.Hour, s.purgeSent)
+	}()
+
+	s.logger.Info("Email outbox workers started", logger.Fields{
+		"workers":      s.config.Workers,
+		"max_attempts": s.config.MaxAttempts,
+	})
+}
+
+// Stop lets in-flight sends finish; anything still queued is picked up on
+// the next start.
+func (s *outboxService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Email outbox workers stopped")
+}
+
+func (s *outboxService) runWorker(ctx context.Context, worker int) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while there is work before going back to sleep.
+		for s.processNext(ctx, worker) {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *outboxService) processNext(ctx context.Context, worker int) bool {
+	emails, err := s.outboxRepo.ClaimDue(ctx, 1, s.config.ClaimTimeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("Failed to claim outbox email", logger.Fields{
+				"error":  err.Error(),
+				"worker": worker,
+			})
+		}
+		return false
+	}
+
+	if len(emails) == 0 {
+		return false
+	}
+
+	s.deliver(emails[0], worker)
+	return true
+}
+
+// deliver records the outcome with a fresh context so a shutdown mid-send
+// does not leave a delivered email marked as in flight.
+func (s *outboxService) deliver(message entity.OutboxEmail, worker int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sendErr := s.emailService.Deliver(toMessage(message))
+	if sendErr == nil {
+		if err := s.outboxRepo.MarkSent(ctx, message.ID); err != nil {
+			s.logger.Error("Failed to mark outbox email sent", logger.Fields{
+				"error":    err.Error(),
+				"email_id": message.ID,
+			})
+		}
+		return
+	}
+
+	fields := logger.Fields{
+		"error":    sendErr.Error(),
+		"email_id": message.ID,
+		"subject":  message.Subject,
+		"attempt":  message.Attempts,
+		"worker":   worker,
+	}
+
+	if message.Attempts >= message.MaxAttempts {
+		s.logger.Error("Email moved to dead letter after final attempt", fields)
+		if err := s.outboxRepo.MarkDead(ctx, message.ID, sendErr.Error()); err != nil {
+			s.logger.Error("Failed to dead-letter outbox email", logger.Fields{
+				"error":    err.Error(),
+				"email_id": message.ID,
+			})
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(jobs.Backoff(s.config.BaseBackoff, s.config.MaxBackoff, message.Attempts))
+	fields["next_attempt_at"] = nextAttemptAt
+	s.logger.Warn("Email delivery failed, retry scheduled", fields)
+
+	if err := s.outboxRepo.MarkRetry(ctx, message.ID, sendErr.Error(), nextAttemptAt); err != nil {
+		s.logger.Error("Failed to schedule outbox retry", logger.Fields{
+			"error":    err.Error(),
+			"email_id": message.ID,
+		})
+	}
+}
+
+func toMessage(message entity.OutboxEmail) email.Message {
+	result := email.Message{
+		To:      message.Recipients,
+		Subject: message.Subject,
+	}
+
+	if message.UnsubscribeURL != nil {
+		result.UnsubscribeURL = *message.UnsubscribeURL
+	}
+
+	if !message.IsHTML {
+		result.TextBody = message.Body
+		return result
+	}
+
+	result.HTMLBody = message.Body
+	if message.TextBody != nil {
+		result.TextBody = *message.TextBody
+	}
+
+	return result
+}
+
+func (s *outboxService) purgeSent(ctx context.Context) {
+	purged, err := s.outboxRepo.PurgeSent(ctx, time.Now().Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Error("Failed to purge sent outbox emails", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged sent outbox emails", logger.Fields{
+			"count": purged,
+		})
+	}
+}
+
+func (s *outboxService) ListEmails(ctx context.Context, req outbox.OutboxListRequest) (*outbox.OutboxListResponse, error) {
+	emails, meta, err := s.outboxRepo.List(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to list outbox emails", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &outbox.OutboxListResponse{
+		Emails: emails,
+		Meta:   meta,
+	}, nil
+}
+
+func (s *outboxService) GetEmail(ctx context.Context, id string) (*entity.OutboxEmail, error) {
+	return s.outboxRepo.GetByID(ctx, id)
+}
+
+func (s *outboxService) GetStats(ctx context.Context) (*outbox.OutboxStatsResponse, error) {
+	return s.outboxRepo.GetStats(ctx)
+}
+
+func (s *outboxService) ResendEmail(ctx context.Context, id string) error {
+	message, err := s.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !message.CanResend() {
+		return outbox.ErrEmailNotResendable
+	}
+
+	if err := s.outboxRepo.Requeue(ctx, id); err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	s.logger.Info("Outbox email requeued", logger.Fields{
+		"email_id":        id,
+		"previous_status": message.Status,
+	})
+
+	return nil
+}
+
+func (s *outboxService) ListTemplates() *outbox.TemplateListResponse {
+	return &outbox.TemplateListResponse{
+		Templates: s.emailService.TemplateNames(),
+		Locales:   email.Locales,
+	}
+}
+
+func (s *outboxService) PreviewTemplate(name string, locale email.Locale) (*email.RenderedEmail, error) {
+	rendered, err := s.emailService.PreviewTemplate(name, locale)
+	if err != nil {
+		if err == email.ErrTemplateNotFound {
+			return nil, outbox.ErrTemplateNotFound
+		}
+		s.logger.Error("Failed to render email template preview", logger.Fields{
+			"error":    err.Error(),
+			"template": name,
+			"locale":   locale,
+		})
+		return nil, err
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,53 @@
+package privacy
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+type DeleteAccountRequest struct {
+	// Password confirms the request comes from the account holder and not
+	// from a stolen session.
+	Password string  `json:"password" validate:"required"`
+	Reason   *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+type DataRequestResponse struct {
+	entity.DataRequest
+	// DownloadURL is a presigned link, created on each request for a
+	// completed export whose archive has not yet been deleted.
+	DownloadURL       *string    `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+type ErasureListRequest struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status string `query:"status" validate:"omitempty,oneof=pending running completed failed cancelled"`
+}
+
+// ErasureRequest is an erasure with the account it applies to. The name and
+// email are already anonymised once the request has completed.
+type ErasureRequest struct {
+	entity.DataRequest
+	UserName  *string `db:"user_name" json:"user_name,omitempty"`
+	UserEmail *string `db:"user_email" json:"user_email,omitempty"`
+	// LiveSubscriptions counts subscriptions that have not ended. The
+	// erasure fails while there are any.
+	LiveSubscriptions int `db:"live_subscriptions" json:"live_subscriptions"`
+}
+
+type ErasureListResponse struct {
+	Requests []ErasureRequest `json:"requests"`
+	Meta     *PaginationMeta  `json:"meta,omitempty"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
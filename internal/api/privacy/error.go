@@ -0,0 +1,13 @@
+package privacy
+
+import "errors"
+
+var (
+	ErrRequestNotFound      = errors.New("data request not found")
+	ErrExportInProgress     = errors.New("a data export is already being prepared")
+	ErrErasureInProgress    = errors.New("account erasure has already been requested")
+	ErrInvalidPassword      = errors.New("password is incorrect")
+	ErrLiveSubscriptions    = errors.New("account has subscriptions that have not ended")
+	ErrOrganizationOwner    = errors.New("account owns an organization")
+	ErrRequestNotCancelable = errors.New("only pending erasure requests can be cancelled")
+)
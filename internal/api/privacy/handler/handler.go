@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/auth"
+	"sea-catering-backend/internal/api/privacy"
+	"sea-catering-backend/internal/api/privacy/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+	validator      *validator.Validate
+	middleware     middleware.Interface
+	logger         *logger.Logger
+}
+
+func NewPrivacyHandler(
+	privacyService service.PrivacyService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		validator:      validator,
+		middleware:     middleware,
+		logger:         logger,
+	}
+}
+
+func (h *PrivacyHandler) RegisterRoutes(router fiber.Router) {
+	user := router.Group("/user", h.middleware.AuthMiddleware())
+	user.Post("/data-export", h.RequestExport)
+	user.Get("/data-export", h.GetLatestExport)
+	user.Delete("/account", h.DeleteAccount)
+
+	admin := router.Group("/admin/erasure-requests", h.middleware.AdminMiddleware())
+	admin.Get("/", h.ListErasures)
+	admin.Post("/:id/cancel", h.CancelErasure)
+}
+
+func (h *PrivacyHandler) RequestExport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	request, err := h.privacyService.RequestExport(ctx, userID)
+	if err != nil {
+		return h.handlePrivacyError(c, errHandler, requestID, err, c.Path(), "request_data_export")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusAccepted, request)
+}
+
+func (h *PrivacyHandler) GetLatestExport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	result, err := h.privacyService.GetLatestExport(ctx, userID)
+	if err != nil {
+		return h.handlePrivacyError(c, errHandler, requestID, err, c.Path(), "get_data_export")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *PrivacyHandler) DeleteAccount(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	var req privacy.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	request, err := h.privacyService.RequestErasure(ctx, userID, req)
+	if err != nil {
+		return h.handlePrivacyError(c, errHandler, requestID, err, c.Path(), "delete_account")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusAccepted, request)
+}
+
+func (h *PrivacyHandler) ListErasures(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req privacy.ErasureListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.privacyService.ListErasures(ctx, req)
+	if err != nil {
+		return h.handlePrivacyError(c, errHandler, requestID, err, c.Path(), "list_erasure_requests")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *PrivacyHandler) CancelErasure(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	adminID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	request, err := h.privacyService.CancelErasure(ctx, c.Params("id"), adminID)
+	if err != nil {
+		return h.handlePrivacyError(c, errHandler, requestID, err, c.Path(), "cancel_erasure_request")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, request)
+}
+
+func (h *PrivacyHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *PrivacyHandler) handlePrivacyError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch err {
+	case privacy.ErrRequestNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Data request")
+	case auth.ErrUserNotFound:
+		return errHandler.HandleNotFound(c, requestID, "User")
+	case privacy.ErrInvalidPassword:
+		return response.Unauthorized(c, "Password is incorrect")
+	case privacy.ErrExportInProgress, privacy.ErrErasureInProgress,
+		privacy.ErrLiveSubscriptions, privacy.ErrOrganizationOwner, privacy.ErrRequestNotCancelable:
+		return response.Conflict(c, err.Error())
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
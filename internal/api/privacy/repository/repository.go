@@ -0,0 +1,545 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/privacy"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/jobs"
+)
+
+type PrivacyRepository interface {
+	// Create fails with privacy.ErrExportInProgress or
+	// privacy.ErrErasureInProgress when the user already has an open
+	// request of the same type. An erasure fails with
+	// privacy.ErrLiveSubscriptions or privacy.ErrOrganizationOwner while
+	// the account cannot be erased, and otherwise deactivates it.
+	Create(ctx context.Context, request *entity.DataRequest) error
+	GetLatest(ctx context.Context, userID string, requestType entity.DataRequestType) (*entity.DataRequest, error)
+	ListErasures(ctx context.Context, req privacy.ErasureListRequest) ([]privacy.ErasureRequest, *privacy.PaginationMeta, error)
+	// CancelErasure cancels a pending erasure and puts the account back in
+	// the active state it had before.
+	CancelErasure(ctx context.Context, id, adminID string) (*entity.DataRequest, error)
+
+	// Claim takes the oldest request that is due, or a running one whose
+	// worker stopped before finishing, and locks it for lockFor.
+	Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.DataRequest, error)
+	MarkCompleted(ctx context.Context, id string, fileKey *string, expiresAt *time.Time) error
+	MarkFailed(ctx context.Context, id, lastError string) error
+	// Reschedule puts a request back in the queue until at.
+	Reschedule(ctx context.Context, id, lastError string, at time.Time) error
+	ListExpiredExports(ctx context.Context, before time.Time) ([]entity.DataRequest, error)
+	ListExportFiles(ctx context.Context, userID string) ([]entity.DataRequest, error)
+	ClearFile(ctx context.Context, id string) error
+
+	// EachSection passes each part of the user's data export to fn as a JSON
+	// document, in a fixed order. It stops at fn's first error.
+	EachSection(ctx context.Context, userID string, fn func(name string, data json.RawMessage) error) error
+	// Anonymise erases the user's personal data while keeping their
+	// subscriptions, audit history and payments. It returns the profile
+	// image URL the account had, so the file can be removed.
+	Anonymise(ctx context.Context, userID string) (profileImageURL *string, err error)
+}
+
+type privacyRepository struct {
+	db *database.DB
+}
+
+func NewPrivacyRepository(db *sqlx.DB) PrivacyRepository {
+	return &privacyRepository{db: database.New(db)}
+}
+
+const requestColumns = `
+	id, user_id, type, status, reason, user_was_active, attempts, file_key, last_error, scheduled_for,
+	locked_until, started_at, completed_at, expires_at, cancelled_by, created_at
+`
+
+func (r *privacyRepository) Create(ctx context.Context, request *entity.DataRequest) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		if request.Type == entity.DataRequestErasure {
+			var active bool
+			err := r.db.GetContext(ctx, &active, `SELECT is_active FROM users WHERE id = $1 FOR UPDATE`, request.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to lock user: %w", err)
+			}
+			request.UserWasActive = &active
+			if err := r.checkErasable(ctx, *request.UserID); err != nil {
+				return err
+			}
+		}
+
+		query := `
+			INSERT INTO data_requests (id, user_id, type, status, reason, user_was_active, scheduled_for, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+
+		_, err := r.db.ExecContext(ctx, query,
+			request.ID, request.UserID, request.Type, request.Status, request.Reason,
+			request.UserWasActive, request.ScheduledFor, request.CreatedAt,
+		)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				if request.Type == entity.DataRequestErasure {
+					return privacy.ErrErasureInProgress
+				}
+				return privacy.ErrExportInProgress
+			}
+			return fmt.Errorf("failed to create data request: %w", err)
+		}
+
+		if request.Type != entity.DataRequestErasure {
+			return nil
+		}
+
+		// The account stays inactive until the erasure runs or is cancelled.
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE users SET is_active = false, updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`,
+			request.UserID, request.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *privacyRepository) GetLatest(ctx context.Context, userID string, requestType entity.DataRequestType) (*entity.DataRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM data_requests
+		WHERE user_id = $1 AND type = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, requestColumns)
+
+	var request entity.DataRequest
+	if err := r.db.GetContext(ctx, &request, query, userID, requestType); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, privacy.ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get data request: %w", err)
+	}
+
+	return &request, nil
+}
+
+func (r *privacyRepository) ListErasures(ctx context.Context, req privacy.ErasureListRequest) ([]privacy.ErasureRequest, *privacy.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Status == "" {
+		req.Status = string(entity.DataRequestPending)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM data_requests WHERE type = 'erasure' AND status = $1`
+	if err := r.db.QueryRowContext(ctx, countQuery, req.Status).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count erasure requests: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := `
+		SELECT
+			dr.id, dr.user_id, dr.type, dr.status, dr.reason, dr.user_was_active, dr.attempts, dr.file_key, dr.last_error,
+			dr.scheduled_for, dr.locked_until, dr.started_at, dr.completed_at, dr.expires_at,
+			dr.cancelled_by, dr.created_at,
+			u.name AS user_name, u.email AS user_email,
+			(SELECT COUNT(*) FROM subscriptions s
+			 WHERE s.user_id = dr.user_id AND s.status NOT IN ('cancelled', 'expired') AND s.deleted_at IS NULL) AS live_subscriptions
+		FROM data_requests dr
+		LEFT JOIN users u ON u.id = dr.user_id
+		WHERE dr.type = 'erasure' AND dr.status = $1
+		ORDER BY dr.scheduled_for, dr.id
+		LIMIT $2 OFFSET $3
+	`
+
+	requests := []privacy.ErasureRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, req.Status, req.Limit, offset); err != nil {
+		return nil, nil, fmt.Errorf("failed to list erasure requests: %w", err)
+	}
+
+	meta := &privacy.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return requests, meta, nil
+}
+
+func (r *privacyRepository) CancelErasure(ctx context.Context, id, adminID string) (*entity.DataRequest, error) {
+	var request entity.DataRequest
+
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		query := fmt.Sprintf(`
+			UPDATE data_requests
+			SET status = 'cancelled', cancelled_by = $2, completed_at = $3, locked_until = NULL
+			WHERE id = $1 AND type = 'erasure' AND status = 'pending'
+			RETURNING %s
+		`, requestColumns)
+
+		err := r.db.GetContext(ctx, &request, query, id, adminID, time.Now())
+		if err == sql.ErrNoRows {
+			var exists bool
+			err = r.db.GetContext(ctx, &exists,
+				`SELECT EXISTS(SELECT 1 FROM data_requests WHERE id = $1 AND type = 'erasure')`, id)
+			if err != nil {
+				return fmt.Errorf("failed to check erasure request: %w", err)
+			}
+			if !exists {
+				return privacy.ErrRequestNotFound
+			}
+			return privacy.ErrRequestNotCancelable
+		}
+		if err != nil {
+			return fmt.Errorf("failed to cancel erasure request: %w", err)
+		}
+
+		// Accounts an admin had deactivated before the erasure stay inactive.
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE users SET is_active = COALESCE($3, true), updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`,
+			request.UserID, time.Now(), request.UserWasActive)
+		if err != nil {
+			return fmt.Errorf("failed to reactivate user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *privacyRepository) Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.DataRequest, error) {
+	return jobs.Claim[entity.DataRequest](ctx, r.db, jobs.Table{
+		Name:    "data_requests",
+		Columns: requestColumns,
+		Due:     "scheduled_for <= $1",
+		Order:   "scheduled_for",
+	}, lockFor, maxAttempts)
+}
+
+func (r *privacyRepository) MarkCompleted(ctx context.Context, id string, fileKey *string, expiresAt *time.Time) error {
+	query := `
+		UPDATE data_requests
+		SET status = 'completed', file_key = $2, completed_at = $3, expires_at = $4,
+		    locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, fileKey, time.Now(), expiresAt); err != nil {
+		return fmt.Errorf("failed to complete data request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *privacyRepository) MarkFailed(ctx context.Context, id, lastError string) error {
+	query := `
+		UPDATE data_requests
+		SET status = 'failed', last_error = $2, completed_at = $3, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, time.Now()); err != nil {
+		return fmt.Errorf("failed to fail data request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *privacyRepository) Reschedule(ctx context.Context, id, lastError string, at time.Time) error {
+	// Attempts count interrupted runs, so a request that ran to a known
+	// outcome starts again from zero.
+	query := `
+		UPDATE data_requests
+		SET status = 'pending', last_error = $2, scheduled_for = $3, attempts = 0, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, at); err != nil {
+		return fmt.Errorf("failed to reschedule data request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *privacyRepository) ListExpiredExports(ctx context.Context, before time.Time) ([]entity.DataRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM data_requests
+		WHERE type = 'export' AND file_key IS NOT NULL AND expires_at < $1
+		ORDER BY expires_at
+		LIMIT 100
+	`, requestColumns)
+
+	requests := []entity.DataRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, before); err != nil {
+		return nil, fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *privacyRepository) ListExportFiles(ctx context.Context, userID string) ([]entity.DataRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM data_requests
+		WHERE user_id = $1 AND type = 'export' AND file_key IS NOT NULL
+	`, requestColumns)
+
+	requests := []entity.DataRequest{}
+	if err := r.db.SelectContext(ctx, &requests, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list data export files: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *privacyRepository) ClearFile(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE data_requests SET file_key = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to clear data export file: %w", err)
+	}
+
+	return nil
+}
+
+// checkErasable refuses to erase an account with subscriptions that have
+// not ended, or that owns an organization. The caller holds the user's row
+// lock, which new subscriptions wait on. Every subscription of the user is
+// locked before counting, so a cancelled one cannot be reactivated until
+// the transaction ends; one reactivated just before is seen by the count.
+func (r *privacyRepository) checkErasable(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `SELECT id FROM subscriptions WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to lock subscriptions: %w", err)
+	}
+
+	query := `
+		SELECT COUNT(*) FROM subscriptions
+		WHERE user_id = $1 AND status NOT IN ('cancelled', 'expired') AND deleted_at IS NULL
+	`
+
+	var live int
+	if err := r.db.GetContext(ctx, &live, query, userID); err != nil {
+		return fmt.Errorf("failed to count live subscriptions: %w", err)
+	}
+	if live > 0 {
+		return privacy.ErrLiveSubscriptions
+	}
+
+	owns, err := r.ownsOrganization(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if owns {
+		return privacy.ErrOrganizationOwner
+	}
+
+	return nil
+}
+
+func (r *privacyRepository) ownsOrganization(ctx context.Context, userID string) (bool, error) {
+	var owns bool
+	err := r.db.GetContext(ctx, &owns, `SELECT EXISTS(SELECT 1 FROM organizations WHERE owner_user_id = $1)`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check organization ownership: %w", err)
+	}
+
+	return owns, nil
+}
+
+// exportSections lists the queries behind a data export. Each returns one
+// JSON document for $1, the user ID. Deleted subscriptions and reviews are
+// included because they are still held. Password hashes and verification
+// tokens are left out.
+var exportSections = []struct {
+	name  string
+	query string
+}{
+	{"profile", `
+		SELECT row_to_json(u) FROM (
+			SELECT id, email, name, phone, email_verified_at, phone_verified_at, profile_image_url,
+			       role, preferred_language, is_active, last_login_at, created_at, updated_at
+			FROM users WHERE id = $1
+		) u
+	`},
+	{"subscriptions", `
+		SELECT COALESCE(json_agg(s ORDER BY s.created_at), '[]') FROM (
+			SELECT s.id, s.meal_plan_id, mp.name AS meal_plan_name, s.meal_types, s.delivery_days,
+			       s.allergies, s.total_price, s.status, s.pause_start_date, s.pause_end_date,
+			       s.delivery_address, s.delivery_latitude, s.delivery_longitude, s.gift_id,
+			       s.organization_id, s.ends_at, s.created_at, s.updated_at, s.deleted_at
+			FROM subscriptions s
+			JOIN meal_plans mp ON mp.id = s.meal_plan_id
+			WHERE s.user_id = $1
+		) s
+	`},
+	{"audit_history", `
+		SELECT COALESCE(json_agg(a ORDER BY a.created_at), '[]') FROM (
+			SELECT id, subscription_id, old_status, new_status, action, reason,
+			       cancellation_reason, feedback, pause_offer, created_at
+			FROM subscription_audit
+			WHERE user_id = $1
+		) a
+	`},
+	{"testimonials", `
+		SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+			SELECT id, meal_plan_id, subscription_id, customer_name, rating, message, status,
+			       is_verified, rejection_reason, created_at, updated_at, deleted_at
+			FROM testimonials
+			WHERE user_id = $1
+		) t
+	`},
+	// Gifts are the only purchases made through the app so far; one counts
+	// once its payment is confirmed.
+	{"payments", `
+		SELECT COALESCE(json_agg(p ORDER BY p.created_at), '[]') FROM (
+			SELECT g.id, 'gift' AS type, g.id AS reference_id, 'gift_purchased' AS event,
+			       g.total_price AS amount, g.created_at
+			FROM gift_subscriptions g
+			WHERE g.buyer_user_id = $1 AND g.status != 'pending_payment'
+		) p
+	`},
+	{"gifts", `
+		SELECT COALESCE(json_agg(g ORDER BY g.created_at), '[]') FROM (
+			SELECT id, recipient_name, recipient_email, message, meal_plan_id, duration_months,
+			       total_price, status, expires_at, redeemed_at, created_at,
+			       buyer_user_id = $1 AS purchased
+			FROM gift_subscriptions
+			WHERE buyer_user_id = $1 OR redeemed_by_user_id = $1
+		) g
+	`},
+	{"deliveries", `
+		SELECT COALESCE(json_agg(d ORDER BY d.delivery_date, d.meal_type), '[]') FROM (
+			SELECT id, subscription_id, delivery_date, meal_type, delivery_address, latitude, longitude,
+			       status, failure_reason, delivered_at, created_at
+			FROM deliveries
+			WHERE user_id = $1
+		) d
+	`},
+	{"notification_preferences", `
+		SELECT COALESCE(json_agg(np ORDER BY np.channel, np.category), '[]') FROM (
+			SELECT channel, category, enabled, updated_at
+			FROM notification_preferences
+			WHERE user_id = $1
+		) np
+	`},
+	{"notifications", `
+		SELECT COALESCE(json_agg(n ORDER BY n.created_at), '[]') FROM (
+			SELECT id, category, type, title, body, read_at, created_at
+			FROM notifications
+			WHERE user_id = $1
+		) n
+	`},
+}
+
+func (r *privacyRepository) EachSection(ctx context.Context, userID string, fn func(name string, data json.RawMessage) error) error {
+	for _, section := range exportSections {
+		var data []byte
+		if err := r.db.QueryRowContext(ctx, section.query, userID).Scan(&data); err != nil {
+			return fmt.Errorf("failed to read %s for export: %w", section.name, err)
+		}
+
+		if err := fn(section.name, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *privacyRepository) Anonymise(ctx context.Context, userID string) (*string, error) {
+	var profileImageURL *string
+
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var user struct {
+			Email           string  `db:"email"`
+			ProfileImageURL *string `db:"profile_image_url"`
+		}
+		err := r.db.GetContext(ctx, &user,
+			`SELECT email, profile_image_url FROM users WHERE id = $1 AND anonymised_at IS NULL FOR UPDATE`, userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("user not found")
+			}
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+		profileImageURL = user.ProfileImageURL
+
+		if err := r.checkErasable(ctx, userID); err != nil {
+			return err
+		}
+
+		// Subscriptions are versioned, so a client still holding one from
+		// before the erasure cannot write its address back. Allergies are
+		// blanked rather than nulled, as subscription reads scan them into a
+		// string.
+		now := time.Now()
+		statements := []struct {
+			query string
+			args  []interface{}
+		}{
+			{`DELETE FROM email_outbox WHERE $1 = ANY(recipients)`, []interface{}{user.Email}},
+			{`DELETE FROM notifications WHERE user_id = $1`, []interface{}{userID}},
+			{`DELETE FROM notification_preferences WHERE user_id = $1`, []interface{}{userID}},
+			{`DELETE FROM testimonials WHERE user_id = $1`, []interface{}{userID}},
+			{`
+				UPDATE subscriptions
+				SET delivery_address = NULL, delivery_latitude = NULL, delivery_longitude = NULL,
+				    allergies = '', version = version + 1, updated_at = $2
+				WHERE user_id = $1
+			`, []interface{}{userID, now}},
+			{`
+				UPDATE deliveries
+				SET delivery_address = NULL, latitude = NULL, longitude = NULL, updated_at = $2
+				WHERE user_id = $1
+			`, []interface{}{userID, now}},
+			{`UPDATE subscription_audit SET feedback = NULL WHERE user_id = $1`, []interface{}{userID}},
+			{`
+				UPDATE gift_subscriptions
+				SET recipient_name = 'Deleted user', recipient_email = 'erased-' || id || '@erased.invalid', updated_at = $2
+				WHERE redeemed_by_user_id = $1
+			`, []interface{}{userID, now}},
+			{`
+				UPDATE organization_members
+				SET email = 'erased-' || id || '@erased.invalid', status = 'removed', updated_at = $2
+				WHERE user_id = $1
+			`, []interface{}{userID, now}},
+			{`
+				UPDATE users
+				SET email = 'erased-' || id || '@erased.invalid', name = 'Deleted user', phone = NULL,
+				    password = '', profile_image_url = NULL,
+				    email_verification_token = NULL, phone_verification_token = NULL,
+				    password_reset_token = NULL, is_active = false,
+				    deleted_at = COALESCE(deleted_at, $2), anonymised_at = $2, updated_at = $2
+				WHERE id = $1
+			`, []interface{}{userID, now}},
+		}
+
+		for _, statement := range statements {
+			if _, err := r.db.ExecContext(ctx, statement.query, statement.args...); err != nil {
+				return fmt.Errorf("failed to anonymise user: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profileImageURL, nil
+}
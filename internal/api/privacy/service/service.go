@@ -0,0 +1,552 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sea-catering-backend/internal/api/auth"
+	authRepository "sea-catering-backend/internal/api/auth/repository"
+	"sea-catering-backend/internal/api/privacy"
+	"sea-catering-backend/internal/api/privacy/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/bcrypt"
+	"sea-catering-backend/pkg/email"
+	"sea-catering-backend/pkg/jobs"
+	"sea-catering-backend/pkg/jwt"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/s3"
+	"sea-catering-backend/pkg/utils"
+)
+
+// blockedRetryInterval is how long an erasure waits before trying again
+// when the account still has live subscriptions or owns an organization.
+const blockedRetryInterval = 24 * time.Hour
+
+const exportReadme = `This archive holds the personal data SEA Catering keeps about your account.
+Each file is a JSON document:
+
+profile.json                   your account details
+subscriptions.json             every subscription, including deleted ones
+audit_history.json             status changes to your subscriptions
+testimonials.json              reviews you wrote
+payments.json                  subscription payments and gift purchases
+gifts.json                     gifts you bought or redeemed
+deliveries.json                scheduled and past deliveries
+notification_preferences.json  which messages you receive
+notifications.json             your in-app notifications
+`
+
+type PrivacyService interface {
+	Start(ctx context.Context)
+	Stop()
+
+	// RequestExport queues an archive of the user's data. The user is
+	// emailed a download link once it is built.
+	RequestExport(ctx context.Context, userID string) (*entity.DataRequest, error)
+	GetLatestExport(ctx context.Context, userID string) (*privacy.DataRequestResponse, error)
+	// RequestErasure deactivates the account and signs it out everywhere
+	// straight away. Personal data is anonymised once the grace period has
+	// passed.
+	RequestErasure(ctx context.Context, userID string, req privacy.DeleteAccountRequest) (*entity.DataRequest, error)
+
+	ListErasures(ctx context.Context, req privacy.ErasureListRequest) (*privacy.ErasureListResponse, error)
+	CancelErasure(ctx context.Context, id, adminID string) (*entity.DataRequest, error)
+}
+
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	// JobTimeout bounds building and uploading one export, or one erasure.
+	JobTimeout time.Duration
+	// LinkTTL is how long an emailed download link stays valid.
+	LinkTTL time.Duration
+	// Retention is how long export archives are kept.
+	Retention time.Duration
+	// ErasureGracePeriod is how long an erasure waits, so that support can
+	// cancel one made by mistake or from a stolen account.
+	ErasureGracePeriod time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		Workers:            1,
+		PollInterval:       30 * time.Second,
+		JobTimeout:         15 * time.Minute,
+		LinkTTL:            72 * time.Hour,
+		Retention:          7 * 24 * time.Hour,
+		ErasureGracePeriod: 72 * time.Hour,
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("DATA_EXPORT_LINK_TTL")); err == nil && ttl > 0 {
+		config.LinkTTL = ttl
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv("DATA_EXPORT_RETENTION")); err == nil && retention > 0 {
+		config.Retention = retention
+	}
+
+	if grace, err := time.ParseDuration(os.Getenv("ACCOUNT_ERASURE_GRACE_PERIOD")); err == nil && grace >= 0 {
+		config.ErasureGracePeriod = grace
+	}
+
+	// A link cannot outlive the file it points to.
+	if config.LinkTTL > config.Retention {
+		config.LinkTTL = config.Retention
+	}
+
+	return config
+}
+
+type privacyService struct {
+	privacyRepo   repository.PrivacyRepository
+	userRepo      authRepository.UserRepository
+	s3            s3.Interface
+	emailService  email.Interface
+	jwtService    jwt.Interface
+	bcryptService bcrypt.Interface
+	utils         utils.Interface
+	config        *Config
+	logger        *logger.Logger
+
+	runner *jobs.Runner[entity.DataRequest]
+}
+
+func NewPrivacyService(
+	privacyRepo repository.PrivacyRepository,
+	userRepo authRepository.UserRepository,
+	s3 s3.Interface,
+	emailService email.Interface,
+	jwtService jwt.Interface,
+	bcryptService bcrypt.Interface,
+	utils utils.Interface,
+	config *Config,
+	logger *logger.Logger,
+) PrivacyService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	s := &privacyService{
+		privacyRepo:   privacyRepo,
+		userRepo:      userRepo,
+		s3:            s3,
+		emailService:  emailService,
+		jwtService:    jwtService,
+		bcryptService: bcryptService,
+		utils:         utils,
+		config:        config,
+		logger:        logger,
+	}
+	s.runner = jobs.NewRunner[entity.DataRequest](s, jobs.Config{
+		Name:         "Data request",
+		Workers:      config.Workers,
+		PollInterval: config.PollInterval,
+		JobTimeout:   config.JobTimeout,
+	}, logger)
+
+	return s
+}
+
+func (s *privacyService) RequestExport(ctx context.Context, userID string) (*entity.DataRequest, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request := &entity.DataRequest{
+		ID:           s.utils.GenerateULID(),
+		UserID:       &userID,
+		Type:         entity.DataRequestExport,
+		Status:       entity.DataRequestPending,
+		ScheduledFor: now,
+		CreatedAt:    now,
+	}
+
+	if err := s.privacyRepo.Create(ctx, request); err != nil {
+		if err != privacy.ErrExportInProgress {
+			s.logger.Error("Failed to queue data export", logger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
+		return nil, err
+	}
+
+	s.runner.Nudge()
+
+	s.logger.Info("Data export requested", logger.Fields{
+		"request_id": request.ID,
+		"user_id":    userID,
+	})
+
+	return request, nil
+}
+
+func (s *privacyService) GetLatestExport(ctx context.Context, userID string) (*privacy.DataRequestResponse, error) {
+	request, err := s.privacyRepo.GetLatest(ctx, userID, entity.DataRequestExport)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &privacy.DataRequestResponse{DataRequest: *request}
+	if request.Status != entity.DataRequestCompleted || request.FileKey == nil {
+		return result, nil
+	}
+
+	url, expiresAt, err := s.downloadLink(*request)
+	if err != nil {
+		s.logger.Error("Failed to sign data export link", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return nil, err
+	}
+
+	result.DownloadURL = &url
+	result.DownloadExpiresAt = &expiresAt
+
+	return result, nil
+}
+
+// downloadLink signs a link to the archive, ending no later than the
+// archive itself.
+func (s *privacyService) downloadLink(request entity.DataRequest) (string, time.Time, error) {
+	ttl := s.config.LinkTTL
+	if request.ExpiresAt != nil {
+		if remaining := time.Until(*request.ExpiresAt); remaining < ttl {
+			ttl = remaining
+		}
+	}
+
+	url, err := s.s3.GetPresignedURL(*request.FileKey, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return url, time.Now().Add(ttl), nil
+}
+
+func (s *privacyService) RequestErasure(ctx context.Context, userID string, req privacy.DeleteAccountRequest) (*entity.DataRequest, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.bcryptService.ComparePassword(user.Password, req.Password); err != nil {
+		return nil, privacy.ErrInvalidPassword
+	}
+
+	now := time.Now()
+	request := &entity.DataRequest{
+		ID:           s.utils.GenerateULID(),
+		UserID:       &userID,
+		Type:         entity.DataRequestErasure,
+		Status:       entity.DataRequestPending,
+		Reason:       req.Reason,
+		ScheduledFor: now.Add(s.config.ErasureGracePeriod),
+		CreatedAt:    now,
+	}
+
+	if err := s.privacyRepo.Create(ctx, request); err != nil {
+		if err != privacy.ErrErasureInProgress && err != privacy.ErrLiveSubscriptions && err != privacy.ErrOrganizationOwner {
+			s.logger.Error("Failed to record erasure request", logger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
+		return nil, err
+	}
+
+	// The account was deactivated with the request, which blocks new
+	// sign-ins; revoking ends the sessions already open. The worker revokes
+	// again when it erases the account, should this fail.
+	s.revokeSessions(ctx, userID)
+
+	s.logger.Info("Account erasure requested", logger.Fields{
+		"request_id":    request.ID,
+		"user_id":       userID,
+		"scheduled_for": request.ScheduledFor,
+	})
+
+	return request, nil
+}
+
+func (s *privacyService) ListErasures(ctx context.Context, req privacy.ErasureListRequest) (*privacy.ErasureListResponse, error) {
+	requests, meta, err := s.privacyRepo.ListErasures(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to list erasure requests", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &privacy.ErasureListResponse{
+		Requests: requests,
+		Meta:     meta,
+	}, nil
+}
+
+func (s *privacyService) CancelErasure(ctx context.Context, id, adminID string) (*entity.DataRequest, error) {
+	request, err := s.privacyRepo.CancelErasure(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Account erasure cancelled", logger.Fields{
+		"request_id": id,
+		"user_id":    request.UserID,
+		"admin_id":   adminID,
+	})
+
+	return request, nil
+}
+
+func (s *privacyService) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, auth.ErrUserNotFound
+	}
+
+	return s.userRepo.GetByID(ctx, id)
+}
+
+func (s *privacyService) revokeSessions(ctx context.Context, userID string) {
+	if err := s.jwtService.RevokeUserTokens(ctx, userID); err != nil {
+		s.logger.Error("Failed to revoke sessions", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+	}
+}
+
+func (s *privacyService) Start(ctx context.Context) {
+	s.runner.Start(ctx)
+}
+
+func (s *privacyService) Stop() {
+	s.runner.Stop()
+}
+
+func (s *privacyService) Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*entity.DataRequest, error) {
+	return s.privacyRepo.Claim(ctx, lockFor, maxAttempts)
+}
+
+func (s *privacyService) Run(ctx context.Context, request *entity.DataRequest) error {
+	if request.UserID == nil {
+		return fmt.Errorf("account no longer exists")
+	}
+
+	if request.Type == entity.DataRequestExport {
+		return s.export(ctx, request)
+	}
+	return s.erase(ctx, request)
+}
+
+func (s *privacyService) Record(ctx context.Context, request *entity.DataRequest, err error) {
+	switch {
+	case errors.Is(err, privacy.ErrLiveSubscriptions) || errors.Is(err, privacy.ErrOrganizationOwner):
+		s.logger.Warn("Account erasure blocked", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+			"user_id":    *request.UserID,
+		})
+		err = s.privacyRepo.Reschedule(ctx, request.ID, err.Error(), time.Now().Add(blockedRetryInterval))
+	case err != nil:
+		s.logger.Error("Data request failed", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+			"type":       request.Type,
+		})
+		err = s.privacyRepo.MarkFailed(ctx, request.ID, err.Error())
+	default:
+		err = s.privacyRepo.MarkCompleted(ctx, request.ID, request.FileKey, request.ExpiresAt)
+		if err == nil && request.Type == entity.DataRequestExport {
+			s.sendExportLink(ctx, request)
+		}
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to record data request outcome", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+	}
+}
+
+func (s *privacyService) export(ctx context.Context, request *entity.DataRequest) error {
+	key := fmt.Sprintf("data-exports/%s/%s.zip", *request.UserID, request.ID)
+
+	err := s3.StreamPrivateFile(s.s3, key, "application/zip", func(w io.Writer) error {
+		return s.writeArchive(ctx, *request.UserID, w)
+	})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.config.Retention)
+	request.FileKey = &key
+	request.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (s *privacyService) writeArchive(ctx context.Context, userID string, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	readme, err := archive.Create("README.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(readme, exportReadme); err != nil {
+		return err
+	}
+
+	err = s.privacyRepo.EachSection(ctx, userID, func(name string, data json.RawMessage) error {
+		file, err := archive.Create(name + ".json")
+		if err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return err
+		}
+		_, err = indented.WriteTo(file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (s *privacyService) sendExportLink(ctx context.Context, request *entity.DataRequest) {
+	user, err := s.getUser(ctx, *request.UserID)
+	if err != nil {
+		s.logger.Error("Failed to load user for data export email", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return
+	}
+
+	url, linkExpiresAt, err := s.downloadLink(*request)
+	if err != nil {
+		s.logger.Error("Failed to sign data export link", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return
+	}
+
+	err = s.emailService.SendDataExportReadyEmail(user.Email, user.Name, &email.DataExportDetails{
+		DownloadURL:   url,
+		LinkExpiresAt: linkExpiresAt,
+		DeletedAt:     *request.ExpiresAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send data export email", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return
+	}
+
+	s.logger.Info("Data export ready", logger.Fields{
+		"request_id": request.ID,
+		"user_id":    *request.UserID,
+	})
+}
+
+// erase anonymises the account, then removes its files. Files that cannot
+// be deleted are logged; export archives are retried by the janitor.
+func (s *privacyService) erase(ctx context.Context, request *entity.DataRequest) error {
+	userID := *request.UserID
+
+	profileImageURL, err := s.privacyRepo.Anonymise(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.revokeSessions(ctx, userID)
+
+	if profileImageURL != nil && *profileImageURL != "" {
+		if err := s.s3.DeleteFile(s3.ExtractKeyFromURL(*profileImageURL)); err != nil {
+			s.logger.Error("Failed to delete profile image of erased account", logger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
+	}
+
+	exports, err := s.privacyRepo.ListExportFiles(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list data exports of erased account", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+	}
+	for _, export := range exports {
+		s.deleteExportFile(ctx, export)
+	}
+
+	s.logger.Info("Account erased", logger.Fields{
+		"request_id": request.ID,
+		"user_id":    userID,
+	})
+
+	return nil
+}
+
+func (s *privacyService) deleteExportFile(ctx context.Context, request entity.DataRequest) bool {
+	if err := s.s3.DeleteFile(*request.FileKey); err != nil {
+		s.logger.Error("Failed to delete data export file", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return false
+	}
+
+	if err := s.privacyRepo.ClearFile(ctx, request.ID); err != nil {
+		s.logger.Error("Failed to clear data export file", logger.Fields{
+			"error":      err.Error(),
+			"request_id": request.ID,
+		})
+		return false
+	}
+
+	return true
+}
+
+// Purge deletes export archives past their retention. The requests
+// themselves are kept as a record of what was asked for and when.
+func (s *privacyService) Purge(ctx context.Context) {
+	requests, err := s.privacyRepo.ListExpiredExports(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to list expired data exports", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	purged := 0
+	for _, request := range requests {
+		if s.deleteExportFile(ctx, request) {
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Expired data exports purged", logger.Fields{
+			"purged": purged,
+		})
+	}
+}
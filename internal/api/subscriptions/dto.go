@@ -7,12 +7,24 @@ import (
 )
 
 type CreateSubscriptionRequest struct {
-	Name         string               `json:"name" validate:"required,min=2,max=100"`
-	PhoneNumber  string               `json:"phone_number,omitempty" validate:"omitempty,phone_id"`
-	MealPlanID   string               `json:"meal_plan_id" validate:"required"`
-	MealTypes    []entity.MealType    `json:"meal_types" validate:"required,min=1"`
-	DeliveryDays []entity.DeliveryDay `json:"delivery_days" validate:"required,min=1"`
-	Allergies    string               `json:"allergies,omitempty"`
+	Name              string               `json:"name" validate:"required,min=2,max=100"`
+	PhoneNumber       string               `json:"phone_number,omitempty" validate:"omitempty,phone_id"`
+	MealPlanID        string               `json:"meal_plan_id" validate:"required"`
+	MealTypes         []entity.MealType    `json:"meal_types" validate:"required,min=1"`
+	DeliveryDays      []entity.DeliveryDay `json:"delivery_days" validate:"required,min=1"`
+	Allergies         string               `json:"allergies,omitempty"`
+	DeliveryAddress   string               `json:"delivery_address,omitempty" validate:"omitempty,max=500"`
+	OrganizationID    string               `json:"organization_id,omitempty" validate:"omitempty,max=36"`
+	DeliveryLatitude  *float64             `json:"delivery_latitude,omitempty" validate:"omitempty,min=-90,max=90"`
+	DeliveryLongitude *float64             `json:"delivery_longitude,omitempty" validate:"omitempty,min=-180,max=180"`
+	DeliveryZone      string               `json:"delivery_zone,omitempty" validate:"omitempty,max=50"`
+}
+
+// UpdateSubscriptionRequest replaces a subscription's terms. Version may be
+// sent here instead of in an If-Match header.
+type UpdateSubscriptionRequest struct {
+	CreateSubscriptionRequest
+	Version *int `json:"version,omitempty"`
 }
 
 type SubscriptionResponse struct {
@@ -24,6 +36,55 @@ type SubscriptionResponse struct {
 type PauseSubscriptionRequest struct {
 	StartDate time.Time `json:"start_date" validate:"required"`
 	EndDate   time.Time `json:"end_date" validate:"required"`
+	Version   *int      `json:"version,omitempty"`
+}
+
+// CancelSubscriptionRequest is the exit survey sent with a cancellation.
+// With PauseOffer "accepted" the subscription is paused for the given dates
+// instead of being cancelled.
+type CancelSubscriptionRequest struct {
+	Reason         entity.CancellationReason `json:"reason" validate:"required,oneof=price taste delivery moving health variety schedule other"`
+	Feedback       string                    `json:"feedback,omitempty" validate:"omitempty,max=1000"`
+	PauseOffer     *entity.PauseOffer        `json:"pause_offer,omitempty" validate:"omitempty,oneof=accepted declined"`
+	PauseStartDate *time.Time                `json:"pause_start_date,omitempty"`
+	PauseEndDate   *time.Time                `json:"pause_end_date,omitempty"`
+	Version        *int                      `json:"version,omitempty"`
+}
+
+type CancellationReportRequest struct {
+	StartDate  string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate    string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	MealPlanID string `query:"meal_plan_id" validate:"omitempty,max=36"`
+}
+
+type CancellationReportResponse struct {
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date"`
+	Cancellations int    `json:"cancellations"`
+	PausedInstead int    `json:"paused_instead"`
+	OfferDeclined int    `json:"offer_declined"`
+	// SaveRate is the share of customers shown the pause offer who took it.
+	SaveRate       float64                   `json:"save_rate"`
+	Reasons        []CancellationReasonStats `json:"reasons"`
+	RecentFeedback []CancellationFeedback    `json:"recent_feedback"`
+}
+
+type CancellationReasonStats struct {
+	Reason        entity.CancellationReason `json:"reason"`
+	Cancellations int                       `json:"cancellations"`
+	// Percentage is this reason's share of all cancellations.
+	Percentage    float64 `json:"percentage"`
+	PausedInstead int     `json:"paused_instead"`
+	OfferDeclined int     `json:"offer_declined"`
+}
+
+type CancellationFeedback struct {
+	SubscriptionID string                    `json:"subscription_id"`
+	MealPlanName   string                    `json:"meal_plan_name"`
+	Reason         entity.CancellationReason `json:"reason"`
+	Feedback       string                    `json:"feedback"`
+	PausedInstead  bool                      `json:"paused_instead"`
+	CreatedAt      time.Time                 `json:"created_at"`
 }
 
 type SubscriptionStatsResponse struct {
@@ -80,6 +141,8 @@ type CancelSubscriptionResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Note    string `json:"note"`
+	// Status is "paused" when the customer took the pause offer.
+	Status entity.SubscriptionStatus `json:"status"`
 }
 
 type UpdateSubscriptionResponse struct {
@@ -87,3 +150,10 @@ type UpdateSubscriptionResponse struct {
 	Message      string                         `json:"message"`
 	Subscription entity.SubscriptionWithDetails `json:"subscription"`
 }
+
+type AllowedActionsResponse struct {
+	SubscriptionID string                    `json:"subscription_id"`
+	Status         entity.SubscriptionStatus `json:"status"`
+	Version        int                       `json:"version"`
+	Actions        []Action                  `json:"actions"`
+}
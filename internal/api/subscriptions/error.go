@@ -17,19 +17,38 @@ var (
 	ErrInvalidSubscriptionStatus = errors.New("invalid subscription status for this operation")
 	ErrInvalidDateRange          = errors.New("invalid date range provided")
 	ErrSubscriptionUpdateFailed  = errors.New("failed to update subscription")
+	ErrGiftedSubscriptionLocked  = errors.New("gifted subscription plan cannot be changed")
+	ErrSubscriptionEnded         = errors.New("fixed-duration subscription has ended")
+	ErrNotOrganizationMember     = errors.New("user is not an active member of the billing organization")
+	ErrAllowanceExceeded         = errors.New("subscription exceeds the member's monthly allowance")
+	ErrIncompleteCoordinates     = errors.New("delivery latitude and longitude must be provided together")
+	ErrFeedbackRequired          = errors.New("feedback is required when the reason is other")
+	ErrVersionConflict           = errors.New("subscription was changed by another request")
+	ErrSubscriptionNotEnded      = errors.New("fixed-duration subscription has not ended yet")
+	ErrSubscriptionNotClosed     = errors.New("only cancelled or expired subscriptions can be deleted")
+	ErrAccountInactive           = errors.New("account is inactive or has been deleted")
 )
 
 // HTTP Status Code mappings
 func GetHTTPStatusCode(err error) int {
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		return 400
+	}
+
 	switch err {
 	case ErrSubscriptionNotFound:
 		return 404
 	case ErrInvalidMealPlan, ErrInvalidMealTypes, ErrInvalidDeliveryDays,
-		ErrInvalidPauseDates, ErrInvalidSubscriptionStatus, ErrInvalidDateRange:
+		ErrInvalidPauseDates, ErrInvalidSubscriptionStatus, ErrInvalidDateRange,
+		ErrGiftedSubscriptionLocked, ErrSubscriptionEnded, ErrAllowanceExceeded,
+		ErrIncompleteCoordinates, ErrFeedbackRequired, ErrSubscriptionNotEnded:
 		return 400
-	case ErrUnauthorizedAccess:
+	case ErrSubscriptionNotClosed:
+		return 409
+	case ErrUnauthorizedAccess, ErrNotOrganizationMember, ErrAccountInactive:
 		return 403
-	case ErrSubscriptionAlreadyExists:
+	case ErrSubscriptionAlreadyExists, ErrVersionConflict:
 		return 409
 	case ErrSubscriptionUpdateFailed:
 		return 422
@@ -40,6 +59,11 @@ func GetHTTPStatusCode(err error) int {
 
 // Error message mappings
 func GetErrorMessage(err error) string {
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		return transitionErr.Message()
+	}
+
 	switch err {
 	case ErrSubscriptionNotFound:
 		return "Subscription not found"
@@ -67,6 +91,26 @@ func GetErrorMessage(err error) string {
 		return "Invalid date range provided"
 	case ErrSubscriptionUpdateFailed:
 		return "Failed to update subscription. Please try again"
+	case ErrGiftedSubscriptionLocked:
+		return "Gifted subscriptions are prepaid; only the delivery address and allergies can be changed"
+	case ErrSubscriptionEnded:
+		return "This gifted subscription has reached the end of its term"
+	case ErrNotOrganizationMember:
+		return "You are not an active member of this organization"
+	case ErrAllowanceExceeded:
+		return "This subscription exceeds your monthly allowance from your organization"
+	case ErrIncompleteCoordinates:
+		return "Delivery latitude and longitude must be provided together"
+	case ErrFeedbackRequired:
+		return "Please tell us more when choosing \"other\" as the reason"
+	case ErrVersionConflict:
+		return "This subscription has changed since you last loaded it. Reload it and try again"
+	case ErrSubscriptionNotEnded:
+		return "This subscription has not reached the end of its term"
+	case ErrSubscriptionNotClosed:
+		return "Cancel this subscription before deleting it"
+	case ErrAccountInactive:
+		return "This account is inactive or being deleted"
 	default:
 		return "An unexpected error occurred"
 	}
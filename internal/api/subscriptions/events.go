@@ -0,0 +1,27 @@
+package subscriptions
+
+import "time"
+
+// Lifecycle events published on the app event bus.
+const (
+	EventSubscriptionCreated        = "subscription.created"
+	EventSubscriptionPaused         = "subscription.paused"
+	EventSubscriptionResumed        = "subscription.resumed"
+	EventSubscriptionCancelled      = "subscription.cancelled"
+	EventSubscriptionReactivated    = "subscription.reactivated"
+	EventSubscriptionForceCancelled = "subscription.force_cancelled"
+	EventSubscriptionPauseEnding    = "subscription.pause_ending"
+)
+
+type SubscriptionEvent struct {
+	SubscriptionID string
+	UserID         string
+	PauseStartDate *time.Time
+	PauseEndDate   *time.Time
+	// Reason and NotifyUser are set for admin force-cancellations.
+	Reason     string
+	NotifyUser bool
+	// AutoResumed marks a resume triggered by the pause period ending rather
+	// than by the customer.
+	AutoResumed bool
+}
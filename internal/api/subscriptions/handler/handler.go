@@ -11,6 +11,7 @@ import (
 	"sea-catering-backend/pkg/handlerutil"
 	"sea-catering-backend/pkg/jwt"
 	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
 	"strconv"
 	"time"
 )
@@ -43,6 +44,7 @@ func (h *SubscriptionHandler) RegisterRoutes(router fiber.Router) {
 	protected.Post("/", h.CreateSubscription)
 	protected.Get("/my", h.GetMySubscriptions)
 	protected.Get("/:id", h.GetSubscription)
+	protected.Get("/:id/actions", h.GetAllowedActions)
 	protected.Put("/:id", h.UpdateSubscription)
 	protected.Put("/:id/pause", h.PauseSubscription)
 	protected.Put("/:id/resume", h.ResumeSubscription)
@@ -51,8 +53,10 @@ func (h *SubscriptionHandler) RegisterRoutes(router fiber.Router) {
 
 	admin := subs.Group("/admin", h.middleware.AdminMiddleware())
 	admin.Get("/stats", h.GetSubscriptionStats)
+	admin.Get("/cancellation-reasons", h.GetCancellationReport)
 	admin.Get("/all", h.GetAllSubscriptions)
 	admin.Post("/process-expired", h.ProcessExpiredPauses)
+	admin.Post("/process-pause-reminders", h.ProcessPauseEndingReminders)
 }
 
 func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
@@ -80,7 +84,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 
 	subscription, err := h.subscriptionService.CreateSubscription(ctx, req)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "create_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "create_subscription")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusCreated, subscription)
@@ -130,16 +134,42 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 
 	subscription, err := h.subscriptionService.GetSubscriptionByID(ctx, subscriptionID)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "get_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "get_subscription")
 	}
 
 	if subscription.UserID != userID {
 		return errHandler.HandleForbidden(c, requestID, "Access denied")
 	}
 
+	handlerutil.SetETag(c, subscription.Version)
 	return errHandler.HandleSuccess(c, fiber.StatusOK, subscription)
 }
 
+func (h *SubscriptionHandler) GetAllowedActions(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	subscriptionID := c.Params("id")
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Unauthorized")
+	}
+
+	result, err := h.subscriptionService.GetAllowedActions(ctx, subscriptionID, userID)
+	if err != nil {
+		if err == subscriptions.ErrUnauthorizedAccess {
+			return errHandler.HandleForbidden(c, requestID, "Access denied")
+		}
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "get_allowed_actions")
+	}
+
+	handlerutil.SetETag(c, result.Version)
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
 func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
 	defer cancel()
@@ -153,7 +183,7 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleUnauthorized(c, requestID, "Unauthorized")
 	}
 
-	var req subscriptions.CreateSubscriptionRequest
+	var req subscriptions.UpdateSubscriptionRequest
 	if err := c.BodyParser(&req); err != nil {
 		return errHandler.Handle(c, requestID, err, c.Path(), "parse_request_body")
 	}
@@ -162,11 +192,17 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleValidationError(c, requestID, err, c.Path())
 	}
 
-	subscription, err := h.subscriptionService.UpdateSubscription(ctx, subscriptionID, userID, req)
+	version, ok := handlerutil.ExpectedVersion(c, req.Version)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	subscription, err := h.subscriptionService.UpdateSubscription(ctx, subscriptionID, userID, version, req.CreateSubscriptionRequest)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "update_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "update_subscription")
 	}
 
+	handlerutil.SetETag(c, subscription.Version)
 	return errHandler.HandleSuccess(c, fiber.StatusOK, subscription)
 }
 
@@ -192,9 +228,14 @@ func (h *SubscriptionHandler) PauseSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleValidationError(c, requestID, err, c.Path())
 	}
 
-	err = h.subscriptionService.PauseSubscription(ctx, subscriptionID, userID, req.StartDate, req.EndDate)
+	version, ok := handlerutil.ExpectedVersion(c, req.Version)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	err = h.subscriptionService.PauseSubscription(ctx, subscriptionID, userID, version, req.StartDate, req.EndDate)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "pause_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "pause_subscription")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
@@ -215,9 +256,14 @@ func (h *SubscriptionHandler) ResumeSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleUnauthorized(c, requestID, "Unauthorized")
 	}
 
-	err = h.subscriptionService.ResumeSubscription(ctx, subscriptionID, userID)
+	version, ok := handlerutil.ReadVersion(c)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	err = h.subscriptionService.ResumeSubscription(ctx, subscriptionID, userID, version)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "resume_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "resume_subscription")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
@@ -242,6 +288,11 @@ func (h *SubscriptionHandler) ReactivateSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
 	}
 
+	version, ok := handlerutil.ReadVersion(c)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
 	h.logger.Info("Processing subscription reactivation request", logger.Fields{
 		"subscription_id": subscriptionID,
 		"user_id":         userID,
@@ -250,7 +301,7 @@ func (h *SubscriptionHandler) ReactivateSubscription(c *fiber.Ctx) error {
 		"user_agent":      c.Get("User-Agent"),
 	})
 
-	_, err = h.subscriptionService.ReactivateSubscription(ctx, subscriptionID, userID)
+	_, err = h.subscriptionService.ReactivateSubscription(ctx, subscriptionID, userID, version)
 	if err != nil {
 		h.logger.Error("Subscription reactivation failed", logger.Fields{
 			"error":           err.Error(),
@@ -259,20 +310,11 @@ func (h *SubscriptionHandler) ReactivateSubscription(c *fiber.Ctx) error {
 			"request_id":      requestID,
 		})
 
-		switch err.Error() {
-		case "failed to get subscription: subscription not found":
-			return errHandler.HandleNotFound(c, requestID, "Subscription")
-		case "only cancelled subscriptions can be reactivated, current status: active":
-			return errHandler.HandleBadRequest(c, requestID, "Subscription is already active")
-		case "only cancelled subscriptions can be reactivated, current status: paused":
-			return errHandler.HandleBadRequest(c, requestID, "Cannot reactivate paused subscription, please resume instead")
-		default:
-			if err == subscriptions.ErrUnauthorizedAccess {
-				return errHandler.HandleForbidden(c, requestID, "Access denied")
-			}
-
-			return errHandler.Handle(c, requestID, err, c.Path(), "reactivate_subscription")
+		if err == subscriptions.ErrUnauthorizedAccess {
+			return errHandler.HandleForbidden(c, requestID, "Access denied")
 		}
+
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "reactivate_subscription")
 	}
 
 	h.logger.Info("Subscription reactivated successfully", logger.Fields{
@@ -305,14 +347,50 @@ func (h *SubscriptionHandler) CancelSubscription(c *fiber.Ctx) error {
 		return errHandler.HandleUnauthorized(c, requestID, "Unauthorized")
 	}
 
-	err = h.subscriptionService.CancelSubscription(ctx, subscriptionID, userID)
+	var req subscriptions.CancelSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "A reason is required: price, taste, delivery, moving, health, variety, schedule or other")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	version, ok := handlerutil.ExpectedVersion(c, req.Version)
+	if !ok {
+		return errHandler.HandleVersionRequired(c, requestID)
+	}
+
+	result, err := h.subscriptionService.CancelSubscription(ctx, subscriptionID, userID, version, req)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "cancel_subscription")
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "cancel_subscription")
 	}
 
-	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
-		"message": "Subscription cancelled successfully",
-	})
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *SubscriptionHandler) GetCancellationReport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req subscriptions.CancellationReportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.subscriptionService.GetCancellationReport(ctx, req)
+	if err != nil {
+		return h.handleSubscriptionError(c, errHandler, requestID, err, c.Path(), "get_cancellation_report")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
 }
 
 func (h *SubscriptionHandler) GetSubscriptionStats(c *fiber.Ctx) error {
@@ -440,6 +518,24 @@ func (h *SubscriptionHandler) ProcessExpiredPauses(c *fiber.Ctx) error {
 	})
 }
 
+func (h *SubscriptionHandler) ProcessPauseEndingReminders(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 30*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	reminded, err := h.subscriptionService.ProcessPauseEndingReminders(ctx)
+	if err != nil {
+		return errHandler.Handle(c, requestID, err, c.Path(), "process_pause_ending_reminders")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message":  "Pause ending reminders processed successfully",
+		"reminded": reminded,
+	})
+}
+
 func (h *SubscriptionHandler) getRequestID(c *fiber.Ctx) string {
 	if requestID := c.Locals("request_id"); requestID != nil {
 		if id, ok := requestID.(string); ok {
@@ -449,6 +545,13 @@ func (h *SubscriptionHandler) getRequestID(c *fiber.Ctx) string {
 	return c.Get("X-Request-ID", "unknown")
 }
 
+func (h *SubscriptionHandler) handleSubscriptionError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	if statusCode := subscriptions.GetHTTPStatusCode(err); statusCode != fiber.StatusInternalServerError {
+		return response.Error(c, statusCode, subscriptions.GetErrorMessage(err))
+	}
+	return errHandler.Handle(c, requestID, err, path, operation)
+}
+
 func calculateSubscriptionGrowth(stats *subscriptions.SubscriptionStatsResponse) float64 {
 	if stats.TotalSubscriptions == 0 {
 		return 0.0
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sea-catering-backend/pkg/database"
 	"sea-catering-backend/pkg/utils"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"sea-catering-backend/internal/api/subscriptions"
 	"sea-catering-backend/internal/entity"
 	"sea-catering-backend/pkg/logger"
 )
@@ -17,17 +19,36 @@ type SubscriptionRepository interface {
 	Create(ctx context.Context, subscription *entity.Subscription) error
 	GetByID(ctx context.Context, id string) (*entity.SubscriptionWithDetails, error)
 	GetByUserID(ctx context.Context, userID string) ([]entity.SubscriptionWithDetails, error)
-	Update(ctx context.Context, subscription *entity.Subscription) error
+	// Modify locks the subscription with SELECT ... FOR UPDATE and passes it
+	// to prepare, which may reject the change by returning an error or
+	// change anything but the status. action is then applied through the
+	// subscription state machine, and the subscription is saved with its
+	// version advanced and audited in the same transaction, so concurrent
+	// changes are applied one after another.
+	Modify(ctx context.Context, id string, action subscriptions.Action, audit AuditEntry, prepare func(subscription *entity.Subscription) error) (*entity.Subscription, error)
+	// Delete and Restore mark the subscription deleted and undo it. Deleted
+	// subscriptions are left out of every read.
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	GetActiveSubscriptions(ctx context.Context) ([]entity.SubscriptionWithDetails, error)
 	GetSubscriptionStats(ctx context.Context, startDate, endDate time.Time) (*SubscriptionStats, error)
 	GetReactivationsCount(ctx context.Context, startDate, endDate time.Time) (int, error)
 	ExistsByUserAndPlan(ctx context.Context, userID, planID string) (bool, error)
 	GetExpiredSubscriptions(ctx context.Context) ([]entity.Subscription, error)
-	BulkUpdateStatus(ctx context.Context, ids []string, status entity.SubscriptionStatus) error
+	ClaimPauseEndingReminders(ctx context.Context, endDate time.Time) ([]entity.Subscription, error)
 
 	LogSubscriptionAction(ctx context.Context, subscriptionID, userID, action, oldStatus, newStatus string) error
-	GetSubscriptionByIDForReactivation(ctx context.Context, id string) (*entity.Subscription, error)
+
+	CountCancellationReasons(ctx context.Context, startDate, endDate time.Time, mealPlanID string) ([]CancellationReasonCount, error)
+	ListCancellationFeedback(ctx context.Context, startDate, endDate time.Time, mealPlanID string, limit int) ([]CancellationFeedbackEntry, error)
+}
+
+// AuditEntry is what Modify records about a change besides the statuses
+// and the action.
+type AuditEntry struct {
+	AdminID  *string
+	Reason   string
+	Feedback *entity.CancellationFeedback
 }
 
 type SubscriptionStats struct {
@@ -41,28 +62,51 @@ type SubscriptionStats struct {
 	SubscriptionsByPlan    map[string]int
 }
 
+// CancellationReasonCount tallies exit surveys with one reason code.
+type CancellationReasonCount struct {
+	Reason        entity.CancellationReason `db:"cancellation_reason"`
+	Cancelled     int                       `db:"cancelled"`
+	PausedInstead int                       `db:"paused_instead"`
+	OfferDeclined int                       `db:"offer_declined"`
+}
+
+type CancellationFeedbackEntry struct {
+	SubscriptionID string                    `db:"subscription_id"`
+	MealPlanName   string                    `db:"meal_plan_name"`
+	Action         string                    `db:"action"`
+	Reason         entity.CancellationReason `db:"cancellation_reason"`
+	Feedback       string                    `db:"feedback"`
+	PauseOffer     *entity.PauseOffer        `db:"pause_offer"`
+	CreatedAt      time.Time                 `db:"created_at"`
+}
+
 type subscriptionRepository struct {
-	db     *sqlx.DB
+	db     *database.DB
 	logger *logger.Logger
-	utils  *utils.Interface
+	utils  utils.Interface
 }
 
 func NewSubscriptionRepository(db *sqlx.DB, logger *logger.Logger, utils utils.Interface) SubscriptionRepository {
 	return &subscriptionRepository{
-		db:     db,
+		db:     database.New(db),
 		logger: logger,
-		utils:  &utils,
+		utils:  utils,
 	}
 }
 
-func (r *subscriptionRepository) GetSubscriptionByIDForReactivation(ctx context.Context, id string) (*entity.Subscription, error) {
+// lockSubscription reads the subscription and holds its row lock until the
+// transaction in ctx ends.
+func (r *subscriptionRepository) lockSubscription(ctx context.Context, id string) (*entity.Subscription, error) {
 	query := `
         SELECT 
             id, user_id, meal_plan_id, meal_types, delivery_days,
             allergies, total_price, status, pause_start_date, pause_end_date,
-            created_at, updated_at
+            COALESCE(delivery_address, '') as delivery_address, gift_id, ends_at, organization_id,
+            delivery_latitude, delivery_longitude, COALESCE(delivery_zone, '') as delivery_zone,
+            version, created_at, updated_at
         FROM subscriptions 
-        WHERE id = $1
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
     `
 
 	var sub entity.Subscription
@@ -72,18 +116,16 @@ func (r *subscriptionRepository) GetSubscriptionByIDForReactivation(ctx context.
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&sub.ID, &sub.UserID, &sub.MealPlanID, &mealTypes, &deliveryDays,
 		&sub.Allergies, &sub.TotalPrice, &sub.Status, &sub.PauseStartDate, &sub.PauseEndDate,
-		&sub.CreatedAt, &sub.UpdatedAt,
+		&sub.DeliveryAddress, &sub.GiftID, &sub.EndsAt, &sub.OrganizationID,
+		&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+		&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
+			return nil, subscriptions.ErrSubscriptionNotFound
 		}
-		r.logger.Error("Failed to get subscription for reactivation", logger.Fields{
-			"error": err.Error(),
-			"id":    id,
-		})
-		return nil, err
+		return nil, fmt.Errorf("failed to lock subscription: %w", err)
 	}
 
 	sub.MealTypes = make([]entity.MealType, len(mealTypes))
@@ -99,51 +141,76 @@ func (r *subscriptionRepository) GetSubscriptionByIDForReactivation(ctx context.
 	return &sub, nil
 }
 
-func (r *subscriptionRepository) LogSubscriptionAction(ctx context.Context, subscriptionID, userID, action, oldStatus, newStatus string) error {
+// checkAccount refuses subscriptions for an account that is deactivated or
+// erased. Account erasure locks the user and then all of their
+// subscriptions before counting the live ones, so a change checked here
+// either commits first and stops the erasure, or waits and is refused.
+// Create passes lock to share-lock the user, as it has no subscription row
+// to wait on yet.
+func (r *subscriptionRepository) checkAccount(ctx context.Context, userID string, lock bool) error {
+	query := `SELECT is_active AND anonymised_at IS NULL FROM users WHERE id = $1 AND deleted_at IS NULL`
+	if lock {
+		query += ` FOR SHARE`
+	}
 
-	checkTableQuery := `
-		SELECT EXISTS (
-			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
-			AND table_name = 'subscription_audit'
-		)
-	`
+	var open bool
+	if err := r.db.GetContext(ctx, &open, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return subscriptions.ErrAccountInactive
+		}
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if !open {
+		return subscriptions.ErrAccountInactive
+	}
 
-	var tableExists bool
-	err := r.db.QueryRowContext(ctx, checkTableQuery).Scan(&tableExists)
-	if err != nil || !tableExists {
+	return nil
+}
 
-		r.logger.Debug("Subscription audit table not found, skipping audit log", logger.Fields{
+// LogSubscriptionAction records a change made outside Modify, such as a new
+// subscription. Run it in the same transaction as the change so neither can
+// be saved without the other.
+func (r *subscriptionRepository) LogSubscriptionAction(ctx context.Context, subscriptionID, userID, action, oldStatus, newStatus string) error {
+	err := r.insertAudit(ctx, subscriptionID, userID, oldStatus, newStatus, action, AuditEntry{})
+	if err != nil {
+		r.logger.Error("Failed to log subscription action", logger.Fields{
+			"error":           err.Error(),
 			"subscription_id": subscriptionID,
 			"action":          action,
 		})
-		return nil
+		return fmt.Errorf("failed to audit subscription: %w", err)
 	}
 
-	auditID := fmt.Sprintf("%s-%s", subscriptionID, time.Now().Format("20060102150405"))
+	return nil
+}
+
+func (r *subscriptionRepository) insertAudit(ctx context.Context, subscriptionID, userID, oldStatus, newStatus, action string, entry AuditEntry) error {
+	var reason, cancellationReason, comments *string
+	var pauseOffer *entity.PauseOffer
+	if entry.Reason != "" {
+		reason = &entry.Reason
+	}
+	if feedback := entry.Feedback; feedback != nil {
+		code := string(feedback.Reason)
+		cancellationReason = &code
+		if feedback.Feedback != "" {
+			comments = &feedback.Feedback
+		}
+		pauseOffer = feedback.PauseOffer
+	}
 
 	query := `
 		INSERT INTO subscription_audit (
-			id, subscription_id, user_id, old_status, new_status, 
-			action, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			id, subscription_id, user_id, old_status, new_status, action, reason,
+			admin_id, cancellation_reason, feedback, pause_offer, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
-		auditID, subscriptionID, userID, oldStatus, newStatus,
-		action, time.Now(),
+	_, err := r.db.ExecContext(ctx, query,
+		r.utils.GenerateULID(), subscriptionID, userID, oldStatus, newStatus, action, reason,
+		entry.AdminID, cancellationReason, comments, pauseOffer, time.Now(),
 	)
-
-	if err != nil {
-		r.logger.Error("Failed to log subscription action", logger.Fields{
-			"error":           err.Error(),
-			"subscription_id": subscriptionID,
-			"action":          action,
-		})
-
-	}
-
-	return nil
+	return err
 }
 
 func (r *subscriptionRepository) GetSubscriptionStats(ctx context.Context, startDate, endDate time.Time) (*SubscriptionStats, error) {
@@ -156,6 +223,7 @@ func (r *subscriptionRepository) GetSubscriptionStats(ctx context.Context, start
             COUNT(CASE WHEN created_at BETWEEN $1 AND $2 THEN 1 END) as new_subscriptions,
             COALESCE(SUM(CASE WHEN status = 'active' THEN total_price ELSE 0 END), 0) as monthly_revenue
         FROM subscriptions
+        WHERE deleted_at IS NULL
     `
 
 	var stats SubscriptionStats
@@ -188,7 +256,7 @@ func (r *subscriptionRepository) GetSubscriptionStats(ctx context.Context, start
         SELECT mp.name, COUNT(*) as count
         FROM subscriptions s
         JOIN meal_plans mp ON s.meal_plan_id = mp.id
-        WHERE s.status = 'active'
+        WHERE s.status = 'active' AND s.deleted_at IS NULL
         GROUP BY mp.name
     `
 
@@ -236,7 +304,7 @@ func (r *subscriptionRepository) GetReactivationsCount(ctx context.Context, star
 	fallbackQuery := `
 		SELECT COUNT(DISTINCT id)
 		FROM subscriptions 
-		WHERE status = 'active' 
+		WHERE status = 'active' AND deleted_at IS NULL
 		AND updated_at BETWEEN $1 AND $2
 		AND created_at < $1
 		AND updated_at > created_at + INTERVAL '1 hour'
@@ -265,15 +333,26 @@ func (r *subscriptionRepository) Create(ctx context.Context, subscription *entit
 	query := `
         INSERT INTO subscriptions (
             id, user_id, meal_plan_id, meal_types, delivery_days, 
-            allergies, total_price, status, created_at, updated_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+            allergies, total_price, status, delivery_address, gift_id,
+            ends_at, organization_id, delivery_latitude, delivery_longitude,
+            delivery_zone, created_at, updated_at
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
     `
 
-	_, err := r.db.ExecContext(ctx, query,
-		subscription.ID, subscription.UserID, subscription.MealPlanID,
-		pq.Array(subscription.MealTypes), pq.Array(subscription.DeliveryDays),
-		subscription.Allergies, subscription.TotalPrice, subscription.Status,
-		subscription.CreatedAt, subscription.UpdatedAt)
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.checkAccount(ctx, subscription.UserID, true); err != nil {
+			return err
+		}
+
+		_, err := r.db.ExecContext(ctx, query,
+			subscription.ID, subscription.UserID, subscription.MealPlanID,
+			pq.Array(subscription.MealTypes), pq.Array(subscription.DeliveryDays),
+			subscription.Allergies, subscription.TotalPrice, subscription.Status,
+			subscription.DeliveryAddress, subscription.GiftID, subscription.EndsAt,
+			subscription.OrganizationID, subscription.DeliveryLatitude, subscription.DeliveryLongitude,
+			subscription.DeliveryZone, subscription.CreatedAt, subscription.UpdatedAt)
+		return err
+	})
 
 	if err != nil {
 		r.logger.Error("Failed to create subscription", logger.Fields{
@@ -284,8 +363,6 @@ func (r *subscriptionRepository) Create(ctx context.Context, subscription *entit
 		return err
 	}
 
-	r.LogSubscriptionAction(ctx, subscription.ID, subscription.UserID, "created", "", string(subscription.Status))
-
 	r.logger.Info("Subscription created successfully", logger.Fields{
 		"subscription": subscription.ID,
 		"user_id":      subscription.UserID,
@@ -300,13 +377,15 @@ func (r *subscriptionRepository) GetByID(ctx context.Context, id string) (*entit
         SELECT 
             s.id, s.user_id, s.meal_plan_id, s.meal_types, s.delivery_days,
             s.allergies, s.total_price, s.status, s.pause_start_date, s.pause_end_date,
-            s.created_at, s.updated_at,
+            COALESCE(s.delivery_address, '') as delivery_address, s.gift_id, s.ends_at, s.organization_id,
+            s.delivery_latitude, s.delivery_longitude, COALESCE(s.delivery_zone, '') as delivery_zone,
+            s.version, s.created_at, s.updated_at,
             mp.name as meal_plan_name, mp.description as meal_plan_description,
             mp.price as meal_plan_price, mp.image_url as meal_plan_image_url,
             mp.features as meal_plan_features
         FROM subscriptions s
         JOIN meal_plans mp ON s.meal_plan_id = mp.id
-        WHERE s.id = $1
+        WHERE s.id = $1 AND s.deleted_at IS NULL
     `
 
 	var sub entity.SubscriptionWithDetails
@@ -317,7 +396,9 @@ func (r *subscriptionRepository) GetByID(ctx context.Context, id string) (*entit
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&sub.ID, &sub.UserID, &sub.MealPlanID, &mealTypes, &deliveryDays,
 		&sub.Allergies, &sub.TotalPrice, &sub.Status, &sub.PauseStartDate, &sub.PauseEndDate,
-		&sub.CreatedAt, &sub.UpdatedAt,
+		&sub.DeliveryAddress, &sub.GiftID, &sub.EndsAt, &sub.OrganizationID,
+		&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+		&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 		&sub.MealPlan.Name, &sub.MealPlan.Description,
 		&sub.MealPlan.Price, &sub.MealPlan.ImageURL, &features,
 	)
@@ -354,13 +435,15 @@ func (r *subscriptionRepository) GetByUserID(ctx context.Context, userID string)
         SELECT 
             s.id, s.user_id, s.meal_plan_id, s.meal_types, s.delivery_days,
             s.allergies, s.total_price, s.status, s.pause_start_date, s.pause_end_date,
-            s.created_at, s.updated_at,
+            COALESCE(s.delivery_address, '') as delivery_address, s.gift_id, s.ends_at, s.organization_id,
+            s.delivery_latitude, s.delivery_longitude, COALESCE(s.delivery_zone, '') as delivery_zone,
+            s.version, s.created_at, s.updated_at,
             mp.name as meal_plan_name, mp.description as meal_plan_description,
             mp.price as meal_plan_price, mp.image_url as meal_plan_image_url,
             mp.features as meal_plan_features
         FROM subscriptions s
         JOIN meal_plans mp ON s.meal_plan_id = mp.id
-        WHERE s.user_id = $1
+        WHERE s.user_id = $1 AND s.deleted_at IS NULL
         ORDER BY s.created_at DESC
     `
 
@@ -384,7 +467,9 @@ func (r *subscriptionRepository) GetByUserID(ctx context.Context, userID string)
 		err := rows.Scan(
 			&sub.ID, &sub.UserID, &sub.MealPlanID, &mealTypes, &deliveryDays,
 			&sub.Allergies, &sub.TotalPrice, &sub.Status, &sub.PauseStartDate, &sub.PauseEndDate,
-			&sub.CreatedAt, &sub.UpdatedAt,
+			&sub.DeliveryAddress, &sub.GiftID, &sub.EndsAt, &sub.OrganizationID,
+			&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+			&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 			&sub.MealPlan.Name, &sub.MealPlan.Description,
 			&sub.MealPlan.Price, &sub.MealPlan.ImageURL, &features,
 		)
@@ -414,81 +499,160 @@ func (r *subscriptionRepository) GetByUserID(ctx context.Context, userID string)
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepository) Update(ctx context.Context, subscription *entity.Subscription) error {
+func (r *subscriptionRepository) Modify(ctx context.Context, id string, action subscriptions.Action, audit AuditEntry, prepare func(subscription *entity.Subscription) error) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	var oldStatus entity.SubscriptionStatus
+	var transition subscriptions.Transition
 
-	var oldStatus string
-	statusQuery := `SELECT status FROM subscriptions WHERE id = $1`
-	r.db.QueryRowContext(ctx, statusQuery, subscription.ID).Scan(&oldStatus)
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = r.lockSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	query := `
-        UPDATE subscriptions 
-        SET meal_types = $2, delivery_days = $3, allergies = $4, 
-            total_price = $5, status = $6, pause_start_date = $7, 
-            pause_end_date = $8, updated_at = $9
-        WHERE id = $1
-    `
+		oldStatus = subscription.Status
+		if err := prepare(subscription); err != nil {
+			return err
+		}
 
-	result, err := r.db.ExecContext(ctx, query,
-		subscription.ID, pq.Array(subscription.MealTypes), pq.Array(subscription.DeliveryDays),
-		subscription.Allergies, subscription.TotalPrice, subscription.Status,
-		subscription.PauseStartDate, subscription.PauseEndDate, time.Now())
+		if action == subscriptions.ActionReactivate {
+			if err := r.checkAccount(ctx, subscription.UserID, false); err != nil {
+				return err
+			}
+		}
 
-	if err != nil {
-		r.logger.Error("Failed to update subscription", logger.Fields{
-			"error":        err.Error(),
-			"subscription": subscription.ID,
-		})
-		return err
-	}
+		now := time.Now()
+		transition, err = subscriptions.Apply(subscription, action, now)
+		if err != nil {
+			return err
+		}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+		subscription.UpdatedAt = now
+
+		query := `
+            UPDATE subscriptions 
+            SET meal_types = $2, delivery_days = $3, allergies = $4, 
+                total_price = $5, status = $6, pause_start_date = $7, 
+                pause_end_date = $8, updated_at = $9, meal_plan_id = $10,
+                delivery_address = $11, delivery_latitude = $12, delivery_longitude = $13,
+                delivery_zone = $14, version = version + 1
+            WHERE id = $1
+            RETURNING version
+        `
+
+		err = r.db.QueryRowContext(ctx, query,
+			subscription.ID, pq.Array(subscription.MealTypes), pq.Array(subscription.DeliveryDays),
+			subscription.Allergies, subscription.TotalPrice, subscription.Status,
+			subscription.PauseStartDate, subscription.PauseEndDate, subscription.UpdatedAt,
+			subscription.MealPlanID, subscription.DeliveryAddress, subscription.DeliveryLatitude,
+			subscription.DeliveryLongitude, subscription.DeliveryZone,
+		).Scan(&subscription.Version)
 
-	action := "updated"
-	if oldStatus != string(subscription.Status) {
-		if oldStatus == "cancelled" && subscription.Status == entity.StatusActive {
-			action = "reactivated"
-		} else if subscription.Status == entity.StatusCancelled {
-			action = "cancelled"
-		} else if subscription.Status == entity.StatusPaused {
-			action = "paused"
-		} else if subscription.Status == entity.StatusActive && oldStatus == "paused" {
-			action = "resumed"
+		if err != nil {
+			r.logger.Error("Failed to update subscription", logger.Fields{
+				"error":        err.Error(),
+				"subscription": subscription.ID,
+			})
+			return err
+		}
+
+		if err := r.dropUncoveredDeliveries(ctx, subscription.ID); err != nil {
+			return err
 		}
-	}
 
-	r.LogSubscriptionAction(ctx, subscription.ID, subscription.UserID, action, oldStatus, string(subscription.Status))
+		err = r.insertAudit(ctx, subscription.ID, subscription.UserID, string(oldStatus), string(subscription.Status), transition.Audit, audit)
+		if err != nil {
+			return fmt.Errorf("failed to audit subscription: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	r.logger.Info("Subscription updated successfully", logger.Fields{
 		"subscription": subscription.ID,
 		"old_status":   oldStatus,
 		"new_status":   subscription.Status,
-		"action":       action,
+		"action":       transition.Audit,
+		"version":      subscription.Version,
 	})
 
+	return subscription, nil
+}
+
+// dropUncoveredDeliveries removes the subscription's deliveries from today on
+// that have not left the kitchen and that it no longer covers, because it has
+// closed or they fall within its pause, so they are not cooked or routed.
+func (r *subscriptionRepository) dropUncoveredDeliveries(ctx context.Context, id string) error {
+	query := `
+        DELETE FROM deliveries d
+        USING subscriptions s
+        WHERE d.subscription_id = s.id AND s.id = $1
+        AND d.status = 'scheduled' AND d.delivery_date >= CURRENT_DATE
+        AND (s.status IN ('cancelled', 'expired')
+             OR (s.pause_start_date IS NOT NULL AND d.delivery_date BETWEEN s.pause_start_date AND s.pause_end_date))
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to drop uncovered deliveries: %w", err)
+	}
 	return nil
 }
 
+// Delete marks a cancelled or expired subscription deleted, keeping it and
+// its audit history until the retention purge. A live one must be cancelled
+// first so the customer is not left without deliveries unannounced.
 func (r *subscriptionRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM subscriptions WHERE id = $1`
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		subscription, err := r.lockSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !subscription.Status.IsClosed() {
+			return subscriptions.ErrSubscriptionNotClosed
+		}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+		query := `
+            UPDATE subscriptions
+            SET deleted_at = $2, updated_at = $2, version = version + 1
+            WHERE id = $1
+        `
+		if _, err := r.db.ExecContext(ctx, query, id, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		r.logger.Error("Failed to delete subscription", logger.Fields{
-			"error": err.Error(),
-			"id":    id,
-		})
 		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	r.logger.Info("Subscription deleted successfully", logger.Fields{
+		"subscription": id,
+	})
+
+	return nil
+}
+
+func (r *subscriptionRepository) Restore(ctx context.Context, id string) error {
+	query := `
+        UPDATE subscriptions
+        SET deleted_at = NULL, updated_at = $2, version = version + 1
+        WHERE id = $1 AND deleted_at IS NOT NULL
+    `
+
+	result, err := r.db.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to restore subscription: %w", err)
 	}
 
-	r.logger.Info("Subscription deleted successfully", logger.Fields{
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return subscriptions.ErrSubscriptionNotFound
+	}
+
+	r.logger.Info("Subscription restored", logger.Fields{
 		"subscription": id,
 	})
 
@@ -500,13 +664,15 @@ func (r *subscriptionRepository) GetActiveSubscriptions(ctx context.Context) ([]
         SELECT 
             s.id, s.user_id, s.meal_plan_id, s.meal_types, s.delivery_days,
             s.allergies, s.total_price, s.status, s.pause_start_date, s.pause_end_date,
-            s.created_at, s.updated_at,
+            COALESCE(s.delivery_address, '') as delivery_address, s.gift_id, s.ends_at, s.organization_id,
+            s.delivery_latitude, s.delivery_longitude, COALESCE(s.delivery_zone, '') as delivery_zone,
+            s.version, s.created_at, s.updated_at,
             mp.name as meal_plan_name, mp.description as meal_plan_description,
             mp.price as meal_plan_price, mp.image_url as meal_plan_image_url,
             mp.features as meal_plan_features
         FROM subscriptions s
         JOIN meal_plans mp ON s.meal_plan_id = mp.id
-        WHERE s.status = 'active'
+        WHERE s.status = 'active' AND s.deleted_at IS NULL
         ORDER BY s.created_at DESC
     `
 
@@ -529,7 +695,9 @@ func (r *subscriptionRepository) GetActiveSubscriptions(ctx context.Context) ([]
 		err := rows.Scan(
 			&sub.ID, &sub.UserID, &sub.MealPlanID, &mealTypes, &deliveryDays,
 			&sub.Allergies, &sub.TotalPrice, &sub.Status, &sub.PauseStartDate, &sub.PauseEndDate,
-			&sub.CreatedAt, &sub.UpdatedAt,
+			&sub.DeliveryAddress, &sub.GiftID, &sub.EndsAt, &sub.OrganizationID,
+			&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+			&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 			&sub.MealPlan.Name, &sub.MealPlan.Description,
 			&sub.MealPlan.Price, &sub.MealPlan.ImageURL, &features,
 		)
@@ -560,7 +728,8 @@ func (r *subscriptionRepository) ExistsByUserAndPlan(ctx context.Context, userID
 	query := `
         SELECT EXISTS(
             SELECT 1 FROM subscriptions 
-            WHERE user_id = $1 AND meal_plan_id = $2 AND status IN ('active', 'paused')
+            WHERE user_id = $1 AND meal_plan_id = $2 AND status NOT IN ('cancelled', 'expired')
+            AND deleted_at IS NULL
         )
     `
 
@@ -582,9 +751,11 @@ func (r *subscriptionRepository) GetExpiredSubscriptions(ctx context.Context) ([
 	query := `
         SELECT id, user_id, meal_plan_id, meal_types, delivery_days,
                allergies, total_price, status, pause_start_date, pause_end_date,
-               created_at, updated_at
+               COALESCE(delivery_address, '') as delivery_address, gift_id, ends_at, organization_id,
+               delivery_latitude, delivery_longitude, COALESCE(delivery_zone, '') as delivery_zone,
+               version, created_at, updated_at
         FROM subscriptions
-        WHERE status = 'paused' AND pause_end_date < NOW()
+        WHERE status = 'paused' AND pause_end_date < NOW() AND deleted_at IS NULL
     `
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -605,7 +776,9 @@ func (r *subscriptionRepository) GetExpiredSubscriptions(ctx context.Context) ([
 		err := rows.Scan(
 			&sub.ID, &sub.UserID, &sub.MealPlanID, &mealTypes, &deliveryDays,
 			&sub.Allergies, &sub.TotalPrice, &sub.Status, &sub.PauseStartDate, &sub.PauseEndDate,
-			&sub.CreatedAt, &sub.UpdatedAt,
+			&sub.DeliveryAddress, &sub.GiftID, &sub.EndsAt, &sub.OrganizationID,
+			&sub.DeliveryLatitude, &sub.DeliveryLongitude, &sub.DeliveryZone,
+			&sub.Version, &sub.CreatedAt, &sub.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -627,31 +800,83 @@ func (r *subscriptionRepository) GetExpiredSubscriptions(ctx context.Context) ([
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepository) BulkUpdateStatus(ctx context.Context, ids []string, status entity.SubscriptionStatus) error {
-	if len(ids) == 0 {
-		return nil
-	}
-
+// ClaimPauseEndingReminders returns paused subscriptions whose pause ends on
+// endDate and records that they were reminded, in one statement, so two
+// overlapping runs never remind the same customer twice.
+func (r *subscriptionRepository) ClaimPauseEndingReminders(ctx context.Context, endDate time.Time) ([]entity.Subscription, error) {
 	query := `
-        UPDATE subscriptions 
-        SET status = $1, updated_at = $2
-        WHERE id = ANY($3)
+        UPDATE subscriptions
+        SET pause_reminder_sent_for = pause_end_date
+        WHERE status = 'paused' AND deleted_at IS NULL
+        AND pause_end_date = $1
+        AND pause_reminder_sent_for IS DISTINCT FROM pause_end_date
+        RETURNING id, user_id, pause_start_date, pause_end_date
     `
 
-	_, err := r.db.ExecContext(ctx, query, status, time.Now(), pq.Array(ids))
+	rows, err := r.db.QueryContext(ctx, query, endDate.Format("2006-01-02"))
 	if err != nil {
-		r.logger.Error("Failed to bulk update subscription status", logger.Fields{
-			"error":  err.Error(),
-			"ids":    ids,
-			"status": status,
-		})
-		return err
+		return nil, fmt.Errorf("failed to claim pause ending reminders: %w", err)
 	}
+	defer rows.Close()
 
-	r.logger.Info("Bulk updated subscription status", logger.Fields{
-		"count":  len(ids),
-		"status": status,
-	})
+	var subscriptions []entity.Subscription
+	for rows.Next() {
+		var sub entity.Subscription
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.PauseStartDate, &sub.PauseEndDate); err != nil {
+			return nil, fmt.Errorf("failed to scan pause ending reminder: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) CountCancellationReasons(ctx context.Context, startDate, endDate time.Time, mealPlanID string) ([]CancellationReasonCount, error) {
+	query := `
+		SELECT
+			a.cancellation_reason,
+			COUNT(*) FILTER (WHERE a.action = 'cancelled') AS cancelled,
+			COUNT(*) FILTER (WHERE a.action = 'paused') AS paused_instead,
+			COUNT(*) FILTER (WHERE a.pause_offer = 'declined') AS offer_declined
+		FROM subscription_audit a
+		JOIN subscriptions s ON s.id = a.subscription_id AND s.deleted_at IS NULL
+		WHERE a.cancellation_reason IS NOT NULL
+		AND a.created_at >= $1 AND a.created_at < $2
+		AND ($3 = '' OR s.meal_plan_id = $3)
+		GROUP BY a.cancellation_reason
+		ORDER BY cancelled DESC, a.cancellation_reason
+	`
+
+	counts := []CancellationReasonCount{}
+	if err := r.db.SelectContext(ctx, &counts, query, startDate, endDate, mealPlanID); err != nil {
+		return nil, fmt.Errorf("failed to count cancellation reasons: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *subscriptionRepository) ListCancellationFeedback(ctx context.Context, startDate, endDate time.Time, mealPlanID string, limit int) ([]CancellationFeedbackEntry, error) {
+	query := `
+		SELECT a.subscription_id, mp.name AS meal_plan_name, a.action, a.cancellation_reason,
+			a.feedback, a.pause_offer, a.created_at
+		FROM subscription_audit a
+		JOIN subscriptions s ON s.id = a.subscription_id AND s.deleted_at IS NULL
+		JOIN meal_plans mp ON mp.id = s.meal_plan_id
+		WHERE a.cancellation_reason IS NOT NULL AND a.feedback IS NOT NULL
+		AND a.created_at >= $1 AND a.created_at < $2
+		AND ($3 = '' OR s.meal_plan_id = $3)
+		ORDER BY a.created_at DESC
+		LIMIT $4
+	`
+
+	entries := []CancellationFeedbackEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, startDate, endDate, mealPlanID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list cancellation feedback: %w", err)
+	}
+
+	return entries, nil
 }
@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"sea-catering-backend/internal/api/meal_plans/repository"
+	"sea-catering-backend/internal/api/organizations"
+	orgRepo "sea-catering-backend/internal/api/organizations/repository"
 	"sea-catering-backend/internal/api/subscriptions"
 	subscriptionRepo "sea-catering-backend/internal/api/subscriptions/repository"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/events"
 	"sea-catering-backend/pkg/logger"
 	"sea-catering-backend/pkg/utils"
 )
@@ -17,18 +23,31 @@ type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, req subscriptions.CreateSubscriptionRequest) (*entity.SubscriptionWithDetails, error)
 	GetUserSubscriptions(ctx context.Context, userID string) ([]entity.SubscriptionWithDetails, error)
 	GetSubscriptionByID(ctx context.Context, subscriptionID string) (*entity.SubscriptionWithDetails, error)
-	PauseSubscription(ctx context.Context, subscriptionID, userID string, startDate, endDate time.Time) error
-	ResumeSubscription(ctx context.Context, subscriptionID, userID string) error
-	CancelSubscription(ctx context.Context, subscriptionID, userID string) error
-	ReactivateSubscription(ctx context.Context, subscriptionID, userID string) (*entity.SubscriptionWithDetails, error)
-	UpdateSubscription(ctx context.Context, subscriptionID, userID string, req subscriptions.CreateSubscriptionRequest) (*entity.SubscriptionWithDetails, error)
+	GetAllowedActions(ctx context.Context, subscriptionID, userID string) (*subscriptions.AllowedActionsResponse, error)
+	// The changes below take the version the customer last read and fail
+	// with ErrVersionConflict if the subscription has changed since.
+	PauseSubscription(ctx context.Context, subscriptionID, userID string, version int, startDate, endDate time.Time) error
+	ResumeSubscription(ctx context.Context, subscriptionID, userID string, version int) error
+	CancelSubscription(ctx context.Context, subscriptionID, userID string, version int, req subscriptions.CancelSubscriptionRequest) (*subscriptions.CancelSubscriptionResponse, error)
+	ReactivateSubscription(ctx context.Context, subscriptionID, userID string, version int) (*entity.SubscriptionWithDetails, error)
+	UpdateSubscription(ctx context.Context, subscriptionID, userID string, version int, req subscriptions.CreateSubscriptionRequest) (*entity.SubscriptionWithDetails, error)
 	GetSubscriptionStats(ctx context.Context, startDate, endDate time.Time) (*subscriptions.SubscriptionStatsResponse, error)
 	ProcessExpiredPauses(ctx context.Context) error
+	ProcessPauseEndingReminders(ctx context.Context) (int, error)
+	GetCancellationReport(ctx context.Context, req subscriptions.CancellationReportRequest) (*subscriptions.CancellationReportResponse, error)
 }
 
+// recentFeedbackLimit caps the free-text answers in the cancellation report.
+const recentFeedbackLimit = 20
+
+var errPauseNotOver = errors.New("pause has not ended")
+
 type subscriptionService struct {
 	subscriptionRepo subscriptionRepo.SubscriptionRepository
 	mealPlanRepo     repository.MealPlanRepository
+	orgRepo          orgRepo.OrganizationRepository
+	txManager        database.TxManager
+	eventBus         events.Interface
 	utils            utils.Interface
 	logger           *logger.Logger
 }
@@ -36,12 +55,18 @@ type subscriptionService struct {
 func NewSubscriptionService(
 	subscriptionRepo subscriptionRepo.SubscriptionRepository,
 	mealPlanRepo repository.MealPlanRepository,
+	orgRepo orgRepo.OrganizationRepository,
+	txManager database.TxManager,
+	eventBus events.Interface,
 	utils utils.Interface,
 	logger *logger.Logger,
 ) SubscriptionService {
 	return &subscriptionService{
 		subscriptionRepo: subscriptionRepo,
 		mealPlanRepo:     mealPlanRepo,
+		orgRepo:          orgRepo,
+		txManager:        txManager,
+		eventBus:         eventBus,
 		utils:            utils,
 		logger:           logger,
 	}
@@ -57,6 +82,10 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req subscr
 		return nil, subscriptions.ErrInvalidDeliveryDays
 	}
 
+	if (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return nil, subscriptions.ErrIncompleteCoordinates
+	}
+
 	mealPlan, err := s.mealPlanRepo.GetByID(ctx, req.MealPlanID)
 	if err != nil {
 		s.logger.Error("Failed to get meal plan", logger.Fields{
@@ -79,20 +108,45 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req subscr
 	subscriptionID := s.utils.GenerateULID()
 	userID := ctx.Value("user_id").(string)
 
+	var organizationID *string
+	if req.OrganizationID != "" {
+		organizationID = &req.OrganizationID
+	}
+
 	subscription := &entity.Subscription{
-		ID:           subscriptionID,
-		UserID:       userID,
-		MealPlanID:   req.MealPlanID,
-		MealTypes:    req.MealTypes,
-		DeliveryDays: req.DeliveryDays,
-		Allergies:    req.Allergies,
-		TotalPrice:   totalPrice,
-		Status:       entity.StatusActive,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		ID:                subscriptionID,
+		UserID:            userID,
+		MealPlanID:        req.MealPlanID,
+		MealTypes:         req.MealTypes,
+		DeliveryDays:      req.DeliveryDays,
+		Allergies:         req.Allergies,
+		TotalPrice:        totalPrice,
+		Status:            entity.StatusActive,
+		DeliveryAddress:   req.DeliveryAddress,
+		DeliveryLatitude:  req.DeliveryLatitude,
+		DeliveryLongitude: req.DeliveryLongitude,
+		DeliveryZone:      strings.TrimSpace(req.DeliveryZone),
+		OrganizationID:    organizationID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if organizationID != nil {
+			if err := s.checkOrganizationAllowance(ctx, *organizationID, userID, totalPrice, 0); err != nil {
+				return err
+			}
+		}
+		if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+			return err
+		}
+		return s.subscriptionRepo.LogSubscriptionAction(ctx, subscriptionID, userID, "created", "", string(subscription.Status))
+	})
+	switch err {
+	case subscriptions.ErrAccountInactive, subscriptions.ErrNotOrganizationMember, subscriptions.ErrAllowanceExceeded:
+		return nil, err
+	}
+	if err != nil {
 		s.logger.Error("Failed to create subscription", logger.Fields{
 			"error":        err.Error(),
 			"subscription": subscriptionID,
@@ -117,53 +171,53 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req subscr
 		"status":       "active",
 	})
 
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionCreated, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+
 	return subscriptionDetails, nil
 }
 
-func (s *subscriptionService) ReactivateSubscription(ctx context.Context, subscriptionID, userID string) (*entity.SubscriptionWithDetails, error) {
+func (s *subscriptionService) ReactivateSubscription(ctx context.Context, subscriptionID, userID string, version int) (*entity.SubscriptionWithDetails, error) {
 	s.logger.Info("Starting subscription reactivation", logger.Fields{
 		"subscription_id": subscriptionID,
 		"user_id":         userID,
 	})
 
-	subscription, err := s.subscriptionRepo.GetSubscriptionByIDForReactivation(ctx, subscriptionID)
-	if err != nil {
-		s.logger.Error("Failed to get subscription for reactivation", logger.Fields{
-			"error":           err.Error(),
-			"subscription_id": subscriptionID,
-		})
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
-	}
-
-	if subscription.UserID != userID {
-		s.logger.Warn("Unauthorized reactivation attempt", logger.Fields{
-			"subscription_id":    subscriptionID,
-			"requesting_user_id": userID,
-			"actual_user_id":     subscription.UserID,
-		})
-		return nil, subscriptions.ErrUnauthorizedAccess
-	}
+	// The allowance is checked in the same transaction as the change, which
+	// holds the member's lock until it commits.
+	var subscription *entity.Subscription
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionReactivate, subscriptionRepo.AuditEntry{}, func(subscription *entity.Subscription) error {
+			if err := checkOwnership(subscription, userID); err != nil {
+				s.logger.Warn("Unauthorized reactivation attempt", logger.Fields{
+					"subscription_id":    subscriptionID,
+					"requesting_user_id": userID,
+					"actual_user_id":     subscription.UserID,
+				})
+				return err
+			}
 
-	if subscription.Status != entity.StatusCancelled {
-		s.logger.Warn("Cannot reactivate subscription with status", logger.Fields{
-			"subscription_id": subscriptionID,
-			"current_status":  subscription.Status,
-		})
-		return nil, fmt.Errorf("only cancelled subscriptions can be reactivated, current status: %s", subscription.Status)
-	}
+			if err := checkVersion(subscription, version); err != nil {
+				return err
+			}
 
-	oldStatus := subscription.Status
-	subscription.Status = entity.StatusActive
-	subscription.PauseStartDate = nil
-	subscription.PauseEndDate = nil
-	subscription.UpdatedAt = time.Now()
+			// Checked ahead of the allowance so a refusal names the status.
+			if err := subscriptions.Check(subscription, subscriptions.ActionReactivate, time.Now()); err != nil {
+				return err
+			}
 
-	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
-		s.logger.Error("Failed to update subscription for reactivation", logger.Fields{
-			"error":           err.Error(),
-			"subscription_id": subscriptionID,
+			if subscription.IsOrganizationBilled() {
+				return s.checkOrganizationAllowance(ctx, *subscription.OrganizationID, userID, subscription.TotalPrice, 0)
+			}
+			return nil
 		})
-		return nil, fmt.Errorf("failed to reactivate subscription: %w", err)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	subscriptionWithDetails, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
@@ -185,10 +239,15 @@ func (s *subscriptionService) ReactivateSubscription(ctx context.Context, subscr
 	s.logger.Info("Subscription reactivated successfully", logger.Fields{
 		"subscription_id": subscriptionID,
 		"user_id":         userID,
-		"old_status":      oldStatus,
+		"old_status":      entity.StatusCancelled,
 		"new_status":      subscription.Status,
 	})
 
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionReactivated, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+
 	return subscriptionWithDetails, nil
 }
 
@@ -222,39 +281,53 @@ func (s *subscriptionService) GetSubscriptionByID(ctx context.Context, subscript
 	return subscription, nil
 }
 
-func (s *subscriptionService) PauseSubscription(ctx context.Context, subscriptionID, userID string, startDate, endDate time.Time) error {
-
-	if startDate.After(endDate) || startDate.Before(time.Now()) {
-		return subscriptions.ErrInvalidPauseDates
-	}
-
-	subscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
+// GetAllowedActions lists the changes the customer can make to the
+// subscription now, so clients need not repeat the state machine's rules.
+func (s *subscriptionService) GetAllowedActions(ctx context.Context, subscriptionID, userID string) (*subscriptions.AllowedActionsResponse, error) {
+	subscription, err := s.GetSubscriptionByID(ctx, subscriptionID)
 	if err != nil {
-		return fmt.Errorf("failed to get subscription: %w", err)
+		return nil, err
 	}
 
-	if subscription == nil {
-		return subscriptions.ErrSubscriptionNotFound
+	if err := checkOwnership(&subscription.Subscription, userID); err != nil {
+		return nil, err
 	}
 
-	if subscription.UserID != userID {
-		return subscriptions.ErrUnauthorizedAccess
-	}
+	return &subscriptions.AllowedActionsResponse{
+		SubscriptionID: subscription.ID,
+		Status:         subscription.Status,
+		Version:        subscription.Version,
+		Actions:        subscriptions.AllowedActions(&subscription.Subscription, time.Now()),
+	}, nil
+}
 
-	if subscription.Status != entity.StatusActive {
-		return subscriptions.ErrSubscriptionCancelled
+func (s *subscriptionService) PauseSubscription(ctx context.Context, subscriptionID, userID string, version int, startDate, endDate time.Time) error {
+	return s.pause(ctx, subscriptionID, userID, version, startDate, endDate, nil)
+}
+
+// pause records feedback on the audit entry when the pause was taken in
+// place of a cancellation.
+func (s *subscriptionService) pause(ctx context.Context, subscriptionID, userID string, version int, startDate, endDate time.Time, feedback *entity.CancellationFeedback) error {
+	if startDate.After(endDate) || startDate.Before(time.Now()) {
+		return subscriptions.ErrInvalidPauseDates
 	}
 
-	subscription.Status = entity.StatusPaused
-	subscription.PauseStartDate = &startDate
-	subscription.PauseEndDate = &endDate
+	audit := subscriptionRepo.AuditEntry{Feedback: feedback}
+	_, err := s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionPause, audit, func(subscription *entity.Subscription) error {
+		if err := checkOwnership(subscription, userID); err != nil {
+			return err
+		}
 
-	if err := s.subscriptionRepo.Update(ctx, &subscription.Subscription); err != nil {
-		s.logger.Error("Failed to pause subscription", logger.Fields{
-			"error":        err.Error(),
-			"subscription": subscriptionID,
-		})
-		return fmt.Errorf("failed to pause subscription: %w", err)
+		if err := checkVersion(subscription, version); err != nil {
+			return err
+		}
+
+		subscription.PauseStartDate = &startDate
+		subscription.PauseEndDate = &endDate
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("Subscription paused successfully", logger.Fields{
@@ -264,86 +337,187 @@ func (s *subscriptionService) PauseSubscription(ctx context.Context, subscriptio
 		"end_date":     endDate,
 	})
 
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionPaused, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+		PauseStartDate: &startDate,
+		PauseEndDate:   &endDate,
+	})
+
 	return nil
 }
 
-func (s *subscriptionService) ResumeSubscription(ctx context.Context, subscriptionID, userID string) error {
-	subscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
+func (s *subscriptionService) ResumeSubscription(ctx context.Context, subscriptionID, userID string, version int) error {
+	_, err := s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionResume, subscriptionRepo.AuditEntry{}, func(subscription *entity.Subscription) error {
+		if err := checkOwnership(subscription, userID); err != nil {
+			return err
+		}
+		return checkVersion(subscription, version)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get subscription: %w", err)
+		return err
 	}
 
-	if subscription == nil {
-		return subscriptions.ErrSubscriptionNotFound
-	}
+	s.logger.Info("Subscription resumed successfully", logger.Fields{
+		"subscription": subscriptionID,
+		"user_id":      userID,
+	})
 
-	if subscription.UserID != userID {
-		return subscriptions.ErrUnauthorizedAccess
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionResumed, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+
+	return nil
+}
+
+func (s *subscriptionService) CancelSubscription(ctx context.Context, subscriptionID, userID string, version int, req subscriptions.CancelSubscriptionRequest) (*subscriptions.CancelSubscriptionResponse, error) {
+	feedback := &entity.CancellationFeedback{
+		Reason:     req.Reason,
+		Feedback:   strings.TrimSpace(req.Feedback),
+		PauseOffer: req.PauseOffer,
 	}
 
-	if subscription.Status != entity.StatusPaused {
-		return fmt.Errorf("subscription is not paused")
+	if feedback.Reason == entity.CancellationOther && feedback.Feedback == "" {
+		return nil, subscriptions.ErrFeedbackRequired
 	}
 
-	subscription.Status = entity.StatusActive
-	subscription.PauseStartDate = nil
-	subscription.PauseEndDate = nil
+	if req.PauseOffer != nil && *req.PauseOffer == entity.PauseOfferAccepted {
+		if req.PauseStartDate == nil || req.PauseEndDate == nil {
+			return nil, subscriptions.ErrInvalidPauseDates
+		}
 
-	if err := s.subscriptionRepo.Update(ctx, &subscription.Subscription); err != nil {
-		s.logger.Error("Failed to resume subscription", logger.Fields{
-			"error":        err.Error(),
-			"subscription": subscriptionID,
-		})
-		return fmt.Errorf("failed to resume subscription: %w", err)
+		if err := s.pause(ctx, subscriptionID, userID, version, *req.PauseStartDate, *req.PauseEndDate, feedback); err != nil {
+			return nil, err
+		}
+
+		return &subscriptions.CancelSubscriptionResponse{
+			Success: true,
+			Message: "Subscription paused instead of cancelled",
+			Note:    fmt.Sprintf("Deliveries resume on %s", req.PauseEndDate.Format("2006-01-02")),
+			Status:  entity.StatusPaused,
+		}, nil
 	}
 
-	s.logger.Info("Subscription resumed successfully", logger.Fields{
+	audit := subscriptionRepo.AuditEntry{Feedback: feedback}
+	_, err := s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionCancel, audit, func(subscription *entity.Subscription) error {
+		if err := checkOwnership(subscription, userID); err != nil {
+			return err
+		}
+		return checkVersion(subscription, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Subscription cancelled successfully", logger.Fields{
 		"subscription": subscriptionID,
 		"user_id":      userID,
+		"reason":       feedback.Reason,
 	})
 
-	return nil
+	s.eventBus.Publish(ctx, subscriptions.EventSubscriptionCancelled, subscriptions.SubscriptionEvent{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+
+	return &subscriptions.CancelSubscriptionResponse{
+		Success: true,
+		Message: "Subscription cancelled successfully",
+		Note:    "You can reactivate it at any time",
+		Status:  entity.StatusCancelled,
+	}, nil
 }
 
-func (s *subscriptionService) CancelSubscription(ctx context.Context, subscriptionID, userID string) error {
-	subscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
+func (s *subscriptionService) GetCancellationReport(ctx context.Context, req subscriptions.CancellationReportRequest) (*subscriptions.CancellationReportResponse, error) {
+	now := time.Now()
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if req.EndDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, subscriptions.ErrInvalidDateRange
+		}
+		endDate = parsed
+	}
+
+	startDate := endDate.AddDate(0, 0, -29)
+	if req.StartDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, subscriptions.ErrInvalidDateRange
+		}
+		startDate = parsed
+	}
+
+	if startDate.After(endDate) {
+		return nil, subscriptions.ErrInvalidDateRange
+	}
+
+	counts, err := s.subscriptionRepo.CountCancellationReasons(ctx, startDate, endDate.AddDate(0, 0, 1), req.MealPlanID)
 	if err != nil {
-		return fmt.Errorf("failed to get subscription: %w", err)
+		s.logger.Error("Failed to count cancellation reasons", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
 	}
 
-	if subscription == nil {
-		return subscriptions.ErrSubscriptionNotFound
+	entries, err := s.subscriptionRepo.ListCancellationFeedback(ctx, startDate, endDate.AddDate(0, 0, 1), req.MealPlanID, recentFeedbackLimit)
+	if err != nil {
+		s.logger.Error("Failed to list cancellation feedback", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
 	}
 
-	if subscription.UserID != userID {
-		return subscriptions.ErrUnauthorizedAccess
+	report := &subscriptions.CancellationReportResponse{
+		StartDate:      startDate.Format("2006-01-02"),
+		EndDate:        endDate.Format("2006-01-02"),
+		Reasons:        make([]subscriptions.CancellationReasonStats, 0, len(counts)),
+		RecentFeedback: make([]subscriptions.CancellationFeedback, 0, len(entries)),
+	}
+
+	for _, count := range counts {
+		report.Cancellations += count.Cancelled
+		report.PausedInstead += count.PausedInstead
+		report.OfferDeclined += count.OfferDeclined
 	}
 
-	if subscription.Status == entity.StatusCancelled {
-		return fmt.Errorf("subscription is already cancelled")
+	for _, count := range counts {
+		stats := subscriptions.CancellationReasonStats{
+			Reason:        count.Reason,
+			Cancellations: count.Cancelled,
+			PausedInstead: count.PausedInstead,
+			OfferDeclined: count.OfferDeclined,
+		}
+		if report.Cancellations > 0 {
+			stats.Percentage = roundPercentage(count.Cancelled, report.Cancellations)
+		}
+		report.Reasons = append(report.Reasons, stats)
 	}
 
-	subscription.Status = entity.StatusCancelled
-	subscription.PauseStartDate = nil
-	subscription.PauseEndDate = nil
+	if offered := report.PausedInstead + report.OfferDeclined; offered > 0 {
+		report.SaveRate = roundPercentage(report.PausedInstead, offered)
+	}
 
-	if err := s.subscriptionRepo.Update(ctx, &subscription.Subscription); err != nil {
-		s.logger.Error("Failed to cancel subscription", logger.Fields{
-			"error":        err.Error(),
-			"subscription": subscriptionID,
+	for _, entry := range entries {
+		report.RecentFeedback = append(report.RecentFeedback, subscriptions.CancellationFeedback{
+			SubscriptionID: entry.SubscriptionID,
+			MealPlanName:   entry.MealPlanName,
+			Reason:         entry.Reason,
+			Feedback:       entry.Feedback,
+			PausedInstead:  entry.Action == "paused",
+			CreatedAt:      entry.CreatedAt,
 		})
-		return fmt.Errorf("failed to cancel subscription: %w", err)
 	}
 
-	s.logger.Info("Subscription cancelled successfully", logger.Fields{
-		"subscription": subscriptionID,
-		"user_id":      userID,
-	})
+	return report, nil
+}
 
-	return nil
+func roundPercentage(part, whole int) float64 {
+	return float64(int(float64(part)/float64(whole)*10000)) / 100
 }
 
-func (s *subscriptionService) UpdateSubscription(ctx context.Context, subscriptionID, userID string, req subscriptions.CreateSubscriptionRequest) (*entity.SubscriptionWithDetails, error) {
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, subscriptionID, userID string, version int, req subscriptions.CreateSubscriptionRequest) (*entity.SubscriptionWithDetails, error) {
 
 	if err := s.utils.ValidateMealTypes(convertMealTypesToStrings(req.MealTypes)); err != nil {
 		return nil, subscriptions.ErrInvalidMealTypes
@@ -353,21 +527,8 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, subscripti
 		return nil, subscriptions.ErrInvalidDeliveryDays
 	}
 
-	subscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
-	}
-
-	if subscription == nil {
-		return nil, subscriptions.ErrSubscriptionNotFound
-	}
-
-	if subscription.UserID != userID {
-		return nil, subscriptions.ErrUnauthorizedAccess
-	}
-
-	if subscription.Status == entity.StatusCancelled {
-		return nil, fmt.Errorf("cannot update cancelled subscription")
+	if (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return nil, subscriptions.ErrIncompleteCoordinates
 	}
 
 	mealPlan, err := s.mealPlanRepo.GetByID(ctx, req.MealPlanID)
@@ -385,18 +546,54 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, subscripti
 		convertDeliveryDaysToStrings(req.DeliveryDays),
 	)
 
-	subscription.MealPlanID = req.MealPlanID
-	subscription.MealTypes = req.MealTypes
-	subscription.DeliveryDays = req.DeliveryDays
-	subscription.Allergies = req.Allergies
-	subscription.TotalPrice = totalPrice
+	// The allowance is checked in the same transaction as the change, which
+	// holds the member's lock until it commits.
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.subscriptionRepo.Modify(ctx, subscriptionID, subscriptions.ActionUpdate, subscriptionRepo.AuditEntry{}, func(subscription *entity.Subscription) error {
+			if err := checkOwnership(subscription, userID); err != nil {
+				return err
+			}
 
-	if err := s.subscriptionRepo.Update(ctx, &subscription.Subscription); err != nil {
-		s.logger.Error("Failed to update subscription", logger.Fields{
-			"error":        err.Error(),
-			"subscription": subscriptionID,
+			if err := checkVersion(subscription, version); err != nil {
+				return err
+			}
+
+			if err := subscriptions.Check(subscription, subscriptions.ActionUpdate, time.Now()); err != nil {
+				return err
+			}
+
+			if subscription.IsGift() && giftTermsChanged(subscription, req) {
+				return subscriptions.ErrGiftedSubscriptionLocked
+			}
+
+			if subscription.IsOrganizationBilled() {
+				err := s.checkOrganizationAllowance(ctx, *subscription.OrganizationID, userID, totalPrice, subscription.TotalPrice)
+				if err != nil {
+					return err
+				}
+			}
+
+			subscription.MealPlanID = req.MealPlanID
+			subscription.MealTypes = req.MealTypes
+			subscription.DeliveryDays = req.DeliveryDays
+			subscription.Allergies = req.Allergies
+			subscription.TotalPrice = totalPrice
+			if req.DeliveryAddress != "" {
+				subscription.DeliveryAddress = req.DeliveryAddress
+			}
+			if req.DeliveryLatitude != nil {
+				subscription.DeliveryLatitude = req.DeliveryLatitude
+				subscription.DeliveryLongitude = req.DeliveryLongitude
+			}
+			if req.DeliveryZone != "" {
+				subscription.DeliveryZone = strings.TrimSpace(req.DeliveryZone)
+			}
+			return nil
 		})
-		return nil, fmt.Errorf("failed to update subscription: %w", err)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	updatedSubscription, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
@@ -446,6 +643,9 @@ func (s *subscriptionService) GetSubscriptionStats(ctx context.Context, startDat
 	return response, nil
 }
 
+// ProcessExpiredPauses resumes every subscription whose pause has ended.
+// Each is resumed in its own transaction, so one that fails is logged and
+// left for the next run without holding up the rest.
 func (s *subscriptionService) ProcessExpiredPauses(ctx context.Context) error {
 
 	expiredSubscriptions, err := s.subscriptionRepo.GetExpiredSubscriptions(ctx)
@@ -460,26 +660,162 @@ func (s *subscriptionService) ProcessExpiredPauses(ctx context.Context) error {
 		return nil
 	}
 
-	var ids []string
+	audit := subscriptionRepo.AuditEntry{Reason: "Pause ended"}
+	resumed := 0
 	for _, sub := range expiredSubscriptions {
-		ids = append(ids, sub.ID)
-	}
+		_, err := s.subscriptionRepo.Modify(ctx, sub.ID, subscriptions.ActionResume, audit, func(subscription *entity.Subscription) error {
+			// The customer may have changed the pause since it was listed.
+			if subscription.PauseEndDate == nil || !subscription.PauseEndDate.Before(time.Now()) {
+				return errPauseNotOver
+			}
+			return nil
+		})
+		if err != nil {
+			// Gifts that ended during the pause are expired by the gift job.
+			if err != errPauseNotOver && err != subscriptions.ErrSubscriptionEnded {
+				s.logger.Error("Failed to resume expired pause", logger.Fields{
+					"error":           err.Error(),
+					"subscription_id": sub.ID,
+				})
+			}
+			continue
+		}
 
-	if err := s.subscriptionRepo.BulkUpdateStatus(ctx, ids, entity.StatusActive); err != nil {
-		s.logger.Error("Failed to bulk resume expired subscriptions", logger.Fields{
-			"error": err.Error(),
-			"count": len(ids),
+		resumed++
+		s.eventBus.Publish(ctx, subscriptions.EventSubscriptionResumed, subscriptions.SubscriptionEvent{
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			AutoResumed:    true,
 		})
-		return fmt.Errorf("failed to bulk resume subscriptions: %w", err)
 	}
 
 	s.logger.Info("Processed expired paused subscriptions", logger.Fields{
-		"count": len(ids),
+		"count":   len(expiredSubscriptions),
+		"resumed": resumed,
 	})
 
 	return nil
 }
 
+// ProcessPauseEndingReminders announces every pause that ends tomorrow. Each
+// pause is only announced once, so running it more than once a day is safe.
+func (s *subscriptionService) ProcessPauseEndingReminders(ctx context.Context) (int, error) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+
+	endingSubscriptions, err := s.subscriptionRepo.ClaimPauseEndingReminders(ctx, tomorrow)
+	if err != nil {
+		s.logger.Error("Failed to get pauses ending tomorrow", logger.Fields{
+			"error": err.Error(),
+		})
+		return 0, fmt.Errorf("failed to get pauses ending tomorrow: %w", err)
+	}
+
+	for _, sub := range endingSubscriptions {
+		s.eventBus.Publish(ctx, subscriptions.EventSubscriptionPauseEnding, subscriptions.SubscriptionEvent{
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			PauseStartDate: sub.PauseStartDate,
+			PauseEndDate:   sub.PauseEndDate,
+		})
+	}
+
+	if len(endingSubscriptions) > 0 {
+		s.logger.Info("Processed pause ending reminders", logger.Fields{
+			"count": len(endingSubscriptions),
+		})
+	}
+
+	return len(endingSubscriptions), nil
+}
+
+// checkOwnership verifies the caller owns the subscription. Redeeming a gift
+// creates the subscription under the recipient's user ID, so the recipient
+// passes and the buyer does not without any gift-specific check.
+func checkOwnership(subscription *entity.Subscription, userID string) error {
+	if subscription.UserID != userID {
+		return subscriptions.ErrUnauthorizedAccess
+	}
+	return nil
+}
+
+func checkVersion(subscription *entity.Subscription, version int) error {
+	if subscription.Version != version {
+		return subscriptions.ErrVersionConflict
+	}
+	return nil
+}
+
+// checkOrganizationAllowance ensures the member may bill the organization and
+// that the new monthly price fits their allowance. currentPrice is the amount
+// the subscription already counts towards their spend, so plan changes are
+// judged on the difference rather than double counted. Call it in the
+// transaction that saves the subscription: it locks the member's row, so
+// concurrent changes cannot each pass against the same spend.
+func (s *subscriptionService) checkOrganizationAllowance(ctx context.Context, orgID, userID string, newPrice, currentPrice float64) error {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if err == organizations.ErrOrganizationNotFound {
+			return subscriptions.ErrNotOrganizationMember
+		}
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	if !org.IsActive {
+		return subscriptions.ErrNotOrganizationMember
+	}
+
+	member, err := s.orgRepo.LockMember(ctx, orgID, userID)
+	if err != nil {
+		if err == organizations.ErrMemberNotFound {
+			return subscriptions.ErrNotOrganizationMember
+		}
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	if member.Status != entity.OrgMemberActive {
+		return subscriptions.ErrNotOrganizationMember
+	}
+
+	allowance := member.EffectiveAllowance(org)
+	if allowance == nil {
+		return nil
+	}
+
+	spend, err := s.orgRepo.GetMemberMonthlySpend(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	if spend-currentPrice+newPrice > *allowance {
+		s.logger.Warn("Organization allowance exceeded", logger.Fields{
+			"organization_id": orgID,
+			"user_id":         userID,
+			"allowance":       *allowance,
+			"current_spend":   spend,
+			"requested_price": newPrice,
+		})
+		return subscriptions.ErrAllowanceExceeded
+	}
+
+	return nil
+}
+
+func giftTermsChanged(subscription *entity.Subscription, req subscriptions.CreateSubscriptionRequest) bool {
+	if subscription.MealPlanID != req.MealPlanID {
+		return true
+	}
+
+	current := convertMealTypesToStrings(subscription.MealTypes)
+	requested := convertMealTypesToStrings(req.MealTypes)
+	if strings.Join(current, ",") != strings.Join(requested, ",") {
+		return true
+	}
+
+	currentDays := convertDeliveryDaysToStrings(subscription.DeliveryDays)
+	requestedDays := convertDeliveryDaysToStrings(req.DeliveryDays)
+	return strings.Join(currentDays, ",") != strings.Join(requestedDays, ",")
+}
+
 func convertMealTypesToStrings(mealTypes []entity.MealType) []string {
 	result := make([]string, len(mealTypes))
 	for i, mt := range mealTypes {
@@ -0,0 +1,198 @@
+package subscriptions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sea-catering-backend/internal/entity"
+)
+
+// Action is something that can happen to a subscription. Every change of
+// status goes through Apply, which looks the action up in the transition
+// table.
+type Action string
+
+const (
+	ActionUpdate     Action = "update"
+	ActionPause      Action = "pause"
+	ActionResume     Action = "resume"
+	ActionCancel     Action = "cancel"
+	ActionReactivate Action = "reactivate"
+	ActionExpire     Action = "expire"
+)
+
+// Guard vetoes a transition the table would otherwise allow.
+type Guard func(subscription *entity.Subscription, now time.Time) error
+
+// Effect is a change besides the status that a transition always makes.
+type Effect func(subscription *entity.Subscription)
+
+type Transition struct {
+	From []entity.SubscriptionStatus
+	// To is empty for actions that keep the status.
+	To entity.SubscriptionStatus
+	// Customer marks actions customers take themselves; the others are
+	// taken by background jobs or admins.
+	Customer bool
+	// Audit is the action recorded in subscription_audit.
+	Audit   string
+	Guards  []Guard
+	Effects []Effect
+}
+
+var liveStatuses = []entity.SubscriptionStatus{entity.StatusActive, entity.StatusPaused}
+
+var transitions = map[Action]Transition{
+	ActionUpdate: {
+		From:     liveStatuses,
+		Customer: true,
+		Audit:    "updated",
+	},
+	ActionPause: {
+		From:     []entity.SubscriptionStatus{entity.StatusActive},
+		To:       entity.StatusPaused,
+		Customer: true,
+		Audit:    "paused",
+	},
+	ActionResume: {
+		From:     []entity.SubscriptionStatus{entity.StatusPaused},
+		To:       entity.StatusActive,
+		Customer: true,
+		Audit:    "resumed",
+		Guards:   []Guard{notEnded},
+		Effects:  []Effect{clearPause},
+	},
+	ActionCancel: {
+		From:     liveStatuses,
+		To:       entity.StatusCancelled,
+		Customer: true,
+		Audit:    "cancelled",
+		Effects:  []Effect{clearPause},
+	},
+	ActionReactivate: {
+		From:     []entity.SubscriptionStatus{entity.StatusCancelled},
+		To:       entity.StatusActive,
+		Customer: true,
+		Audit:    "reactivated",
+		Guards:   []Guard{notEnded},
+		Effects:  []Effect{clearPause},
+	},
+	ActionExpire: {
+		From:    liveStatuses,
+		To:      entity.StatusExpired,
+		Audit:   "expired",
+		Guards:  []Guard{ended},
+		Effects: []Effect{clearPause},
+	},
+}
+
+// actionOrder is the order AllowedActions lists actions in.
+var actionOrder = []Action{
+	ActionUpdate, ActionPause, ActionResume, ActionReactivate, ActionCancel, ActionExpire,
+}
+
+// TransitionError is returned when the subscription's status does not allow
+// the action.
+type TransitionError struct {
+	Action Action
+	Status entity.SubscriptionStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %s a subscription that is %s", e.Action, e.Status)
+}
+
+// Message explains the refusal to the customer.
+func (e *TransitionError) Message() string {
+	switch {
+	case e.Action == ActionReactivate && e.Status == entity.StatusActive:
+		return "Subscription is already active"
+	case e.Action == ActionReactivate && e.Status == entity.StatusPaused:
+		return "Cannot reactivate paused subscription, please resume instead"
+	case e.Action == ActionCancel && e.Status == entity.StatusCancelled:
+		return "Subscription is already cancelled"
+	}
+
+	status := strings.ReplaceAll(string(e.Status), "_", " ")
+	action := strings.ReplaceAll(string(e.Action), "_", " ")
+	return fmt.Sprintf("This subscription is %s, so it cannot %s", status, action)
+}
+
+// Check reports whether action may be applied to the subscription now.
+func Check(subscription *entity.Subscription, action Action, now time.Time) error {
+	transition, ok := transitions[action]
+	if !ok {
+		return fmt.Errorf("unknown subscription action %q", action)
+	}
+
+	if !transition.allows(subscription.Status) {
+		return &TransitionError{Action: action, Status: subscription.Status}
+	}
+
+	for _, guard := range transition.Guards {
+		if err := guard(subscription, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Apply checks action and, if it is allowed, moves the subscription to its
+// new status and makes the transition's side effects. It returns the
+// transition so the caller can audit it.
+func Apply(subscription *entity.Subscription, action Action, now time.Time) (Transition, error) {
+	if err := Check(subscription, action, now); err != nil {
+		return Transition{}, err
+	}
+
+	transition := transitions[action]
+	if transition.To != "" {
+		subscription.Status = transition.To
+	}
+	for _, effect := range transition.Effects {
+		effect(subscription)
+	}
+
+	return transition, nil
+}
+
+// AllowedActions lists what the customer can do with the subscription now.
+func AllowedActions(subscription *entity.Subscription, now time.Time) []Action {
+	actions := []Action{}
+	for _, action := range actionOrder {
+		if transitions[action].Customer && Check(subscription, action, now) == nil {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+func (t Transition) allows(status entity.SubscriptionStatus) bool {
+	for _, from := range t.From {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
+
+func notEnded(subscription *entity.Subscription, now time.Time) error {
+	if subscription.EndsAt != nil && subscription.EndsAt.Before(now) {
+		return ErrSubscriptionEnded
+	}
+	return nil
+}
+
+func ended(subscription *entity.Subscription, now time.Time) error {
+	if subscription.EndsAt == nil || !subscription.EndsAt.Before(now) {
+		return ErrSubscriptionNotEnded
+	}
+	return nil
+}
+
+func clearPause(subscription *entity.Subscription) {
+	subscription.PauseStartDate = nil
+	subscription.PauseEndDate = nil
+}
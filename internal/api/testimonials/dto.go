@@ -1,16 +1,78 @@
 package testimonials
 
+import "sea-catering-backend/internal/entity"
+
+// CreateTestimonialRequest is submitted by a signed-in user; the name shown
+// on the review comes from their account.
 type CreateTestimonialRequest struct {
-	CustomerName string `json:"customer_name" validate:"required,min=2,max=100"`
-	Message      string `json:"message" validate:"required,min=10,max=1000"`
-	Rating       int    `json:"rating" validate:"required,min=1,max=5"`
+	MealPlanID *string `json:"meal_plan_id" validate:"omitempty,max=36"`
+	Message    string  `json:"message" validate:"required,min=10,max=1000"`
+	Rating     int     `json:"rating" validate:"required,min=1,max=5"`
+}
+
+type UpdateTestimonialRequest struct {
+	Message *string `json:"message" validate:"omitempty,min=10,max=1000"`
+	Rating  *int    `json:"rating" validate:"omitempty,min=1,max=5"`
+}
+
+type TestimonialListRequest struct {
+	MealPlanID string `query:"meal_plan_id" validate:"omitempty,max=36"`
 }
 
 type TestimonialResponse struct {
-	ID           string `json:"id"`
-	CustomerName string `json:"customer_name"`
-	Message      string `json:"message"`
-	Rating       int    `json:"rating"`
-	IsApproved   bool   `json:"is_approved"`
-	CreatedAt    string `json:"created_at"`
+	ID                 string  `json:"id"`
+	CustomerName       string  `json:"customer_name"`
+	MealPlanID         *string `json:"meal_plan_id,omitempty"`
+	Message            string  `json:"message"`
+	Rating             int     `json:"rating"`
+	VerifiedSubscriber bool    `json:"verified_subscriber"`
+	Status             string  `json:"status"`
+	IsApproved         bool    `json:"is_approved"`
+	// RejectionReason is shown to the author so they know what to fix.
+	RejectionReason *string `json:"rejection_reason,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// ModerationRequest carries a moderator's decision. Rejecting or hiding
+// needs a reason; "other" and flagging also need a note.
+type ModerationRequest struct {
+	Reason string `json:"reason" validate:"omitempty,oneof=spam offensive off_topic personal_info not_genuine other"`
+	Note   string `json:"note" validate:"omitempty,max=500"`
+}
+
+// ModerationQueueRequest filters the moderation queue. Without a status it
+// shows reviews awaiting a decision (pending and flagged), oldest first.
+type ModerationQueueRequest struct {
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status     string `query:"status" validate:"omitempty,oneof=queue all pending approved rejected hidden flagged"`
+	Rating     int    `query:"rating" validate:"omitempty,min=1,max=5"`
+	MinScore   int    `query:"min_score" validate:"omitempty,min=1,max=100"`
+	Verified   string `query:"verified" validate:"omitempty,oneof=true false"`
+	MealPlanID string `query:"meal_plan_id" validate:"omitempty,max=36"`
+	Search     string `query:"search" validate:"omitempty,max=100"`
+	Sort       string `query:"sort" validate:"omitempty,oneof=oldest newest score"`
+}
+
+type ModerationQueueResponse struct {
+	Testimonials []entity.Testimonial `json:"testimonials"`
+	// StatusCounts covers every status regardless of the other filters, so
+	// moderators can see the size of the queue at a glance.
+	StatusCounts map[entity.TestimonialStatus]int `json:"status_counts"`
+	Meta         *PaginationMeta                  `json:"meta"`
+}
+
+type TestimonialDetailResponse struct {
+	Testimonial *entity.Testimonial                 `json:"testimonial"`
+	History     []entity.TestimonialModerationEntry `json:"history"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
 }
@@ -5,6 +5,13 @@ import "errors"
 var (
 	ErrTestimonialNotFound = errors.New("testimonial not found")
 	ErrInvalidRating       = errors.New("rating must be between 1 and 5")
-	ErrTestimonialExists   = errors.New("testimonial already exists")
+	ErrTestimonialExists   = errors.New("you have already reviewed this meal plan")
 	ErrUnauthorizedAccess  = errors.New("unauthorized access to testimonial")
+	ErrTooManySubmissions  = errors.New("too many testimonials submitted from this address")
+
+	ErrInvalidStatusTransition  = errors.New("testimonial cannot move to the requested status")
+	ErrTestimonialStatusChanged = errors.New("testimonial was moderated by someone else")
+	ErrRejectionReasonRequired  = errors.New("a rejection reason is required")
+	ErrModerationNoteRequired   = errors.New("a moderation note is required")
+	ErrTestimonialReplaced      = errors.New("the author has written a newer review of this meal plan")
 )
@@ -0,0 +1,22 @@
+package testimonials
+
+import "sea-catering-backend/internal/entity"
+
+// Moderation events published on the app event bus when a moderator decides
+// on a review that has an author account.
+const (
+	EventTestimonialApproved = "testimonial.approved"
+	EventTestimonialRejected = "testimonial.rejected"
+	EventTestimonialHidden   = "testimonial.hidden"
+)
+
+type TestimonialEvent struct {
+	TestimonialID string
+	UserID        string
+	// MealPlanName is empty for a general review.
+	MealPlanName string
+	Rating       int
+	Message      string
+	Reason       entity.RejectionReason
+	Note         string
+}
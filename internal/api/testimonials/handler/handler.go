@@ -1,17 +1,22 @@
 package handler
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 
+	"sea-catering-backend/internal/api/meal_plans"
 	"sea-catering-backend/internal/api/testimonials"
 	"sea-catering-backend/internal/api/testimonials/service"
+	"sea-catering-backend/internal/entity"
 	"sea-catering-backend/internal/middleware"
 	"sea-catering-backend/pkg/context"
 	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/jwt"
 	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
 )
 
 type TestimonialHandler struct {
@@ -38,14 +43,23 @@ func NewTestimonialHandler(
 func (h *TestimonialHandler) RegisterRoutes(router fiber.Router) {
 	testimonialsGroup := router.Group("/testimonials")
 
-	testimonialsGroup.Post("/", h.CreateTestimonial)
 	testimonialsGroup.Get("/", h.GetApprovedTestimonials)
 
 	admin := testimonialsGroup.Group("/admin", h.middleware.AdminMiddleware())
 	admin.Get("/all", h.GetAllTestimonials)
-	admin.Put("/:id/approve", h.ApproveTestimonial)
-	admin.Put("/:id/reject", h.RejectTestimonial)
+	admin.Get("/queue", h.GetModerationQueue)
+	admin.Get("/:id", h.GetTestimonialDetail)
+	admin.Put("/:id/approve", h.moderate(entity.TestimonialApproved, "approve_testimonial"))
+	admin.Put("/:id/reject", h.moderate(entity.TestimonialRejected, "reject_testimonial"))
+	admin.Put("/:id/hide", h.moderate(entity.TestimonialHidden, "hide_testimonial"))
+	admin.Put("/:id/flag", h.moderate(entity.TestimonialFlagged, "flag_testimonial"))
 	admin.Delete("/:id", h.DeleteTestimonial)
+	admin.Post("/:id/restore", h.RestoreTestimonial)
+
+	protected := testimonialsGroup.Use(h.middleware.AuthMiddleware())
+	protected.Post("/", h.CreateTestimonial)
+	protected.Get("/mine", h.GetMyTestimonials)
+	protected.Put("/:id", h.UpdateTestimonial)
 }
 
 func (h *TestimonialHandler) CreateTestimonial(c *fiber.Ctx) error {
@@ -55,6 +69,11 @@ func (h *TestimonialHandler) CreateTestimonial(c *fiber.Ctx) error {
 	errHandler := handlerutil.New(h.logger)
 	requestID := h.getRequestID(c)
 
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
 	var req testimonials.CreateTestimonialRequest
 	if err := c.BodyParser(&req); err != nil {
 		return errHandler.Handle(c, requestID, err, c.Path(), "parse_request_body")
@@ -64,7 +83,7 @@ func (h *TestimonialHandler) CreateTestimonial(c *fiber.Ctx) error {
 		return errHandler.HandleValidationError(c, requestID, err, c.Path())
 	}
 
-	testimonial, err := h.testimonialService.CreateTestimonial(ctx, req)
+	testimonial, err := h.testimonialService.CreateTestimonial(ctx, userID, c.IP(), req)
 	if err != nil {
 		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "create_testimonial")
 	}
@@ -82,7 +101,16 @@ func (h *TestimonialHandler) GetApprovedTestimonials(c *fiber.Ctx) error {
 	errHandler := handlerutil.New(h.logger)
 	requestID := h.getRequestID(c)
 
-	testimonialsList, err := h.testimonialService.GetApprovedTestimonials(ctx)
+	var req testimonials.TestimonialListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	testimonialsList, err := h.testimonialService.GetApprovedTestimonials(ctx, req)
 	if err != nil {
 		return errHandler.Handle(c, requestID, err, c.Path(), "get_approved_testimonials")
 	}
@@ -90,44 +118,103 @@ func (h *TestimonialHandler) GetApprovedTestimonials(c *fiber.Ctx) error {
 	return errHandler.HandleSuccess(c, fiber.StatusOK, testimonialsList)
 }
 
-func (h *TestimonialHandler) GetAllTestimonials(c *fiber.Ctx) error {
+func (h *TestimonialHandler) GetMyTestimonials(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
 	defer cancel()
 
 	errHandler := handlerutil.New(h.logger)
 	requestID := h.getRequestID(c)
 
-	testimonialsList, err := h.testimonialService.GetAllTestimonials(ctx)
+	userID, err := jwt.GetUserID(c)
 	if err != nil {
-		return errHandler.Handle(c, requestID, err, c.Path(), "get_all_testimonials")
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
+	testimonialsList, err := h.testimonialService.GetMyTestimonials(ctx, userID)
+	if err != nil {
+		return errHandler.Handle(c, requestID, err, c.Path(), "get_my_testimonials")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, testimonialsList)
 }
 
-func (h *TestimonialHandler) ApproveTestimonial(c *fiber.Ctx) error {
+func (h *TestimonialHandler) UpdateTestimonial(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
 	defer cancel()
 
 	errHandler := handlerutil.New(h.logger)
 	requestID := h.getRequestID(c)
 
+	userID, err := jwt.GetUserID(c)
+	if err != nil {
+		return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+	}
+
 	testimonialID := c.Params("id")
 	if testimonialID == "" {
 		return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
 	}
 
-	err := h.testimonialService.ApproveTestimonial(ctx, testimonialID)
+	var req testimonials.UpdateTestimonialRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	testimonial, err := h.testimonialService.UpdateTestimonial(ctx, userID, testimonialID, req)
 	if err != nil {
-		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "approve_testimonial")
+		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "update_testimonial")
 	}
 
 	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
-		"message": "Testimonial approved successfully",
+		"message":     "Testimonial updated and is pending approval",
+		"testimonial": testimonial,
 	})
 }
 
-func (h *TestimonialHandler) RejectTestimonial(c *fiber.Ctx) error {
+func (h *TestimonialHandler) GetAllTestimonials(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	testimonialsList, err := h.testimonialService.GetAllTestimonials(ctx)
+	if err != nil {
+		return errHandler.Handle(c, requestID, err, c.Path(), "get_all_testimonials")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, testimonialsList)
+}
+
+func (h *TestimonialHandler) GetModerationQueue(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req testimonials.ModerationQueueRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	queue, err := h.testimonialService.GetModerationQueue(ctx, req)
+	if err != nil {
+		return errHandler.Handle(c, requestID, err, c.Path(), "get_moderation_queue")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, queue)
+}
+
+func (h *TestimonialHandler) GetTestimonialDetail(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
 	defer cancel()
 
@@ -139,14 +226,55 @@ func (h *TestimonialHandler) RejectTestimonial(c *fiber.Ctx) error {
 		return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
 	}
 
-	err := h.testimonialService.RejectTestimonial(ctx, testimonialID)
+	detail, err := h.testimonialService.GetTestimonialDetail(ctx, testimonialID)
 	if err != nil {
-		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "reject_testimonial")
+		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "get_testimonial_detail")
 	}
 
-	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
-		"message": "Testimonial rejected successfully",
-	})
+	return errHandler.HandleSuccess(c, fiber.StatusOK, detail)
+}
+
+// moderate builds the handler for one moderator decision. The body is
+// optional for decisions that need no reason.
+func (h *TestimonialHandler) moderate(to entity.TestimonialStatus, operation string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+		defer cancel()
+
+		errHandler := handlerutil.New(h.logger)
+		requestID := h.getRequestID(c)
+
+		moderatorID, err := jwt.GetUserID(c)
+		if err != nil {
+			return errHandler.HandleUnauthorized(c, requestID, "Authentication required")
+		}
+
+		testimonialID := c.Params("id")
+		if testimonialID == "" {
+			return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
+		}
+
+		var req testimonials.ModerationRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+			}
+		}
+
+		if err := h.validator.Struct(req); err != nil {
+			return errHandler.HandleValidationError(c, requestID, err, c.Path())
+		}
+
+		testimonial, err := h.testimonialService.Moderate(ctx, moderatorID, testimonialID, to, req)
+		if err != nil {
+			return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), operation)
+		}
+
+		return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+			"message":     fmt.Sprintf("Testimonial %s", to),
+			"testimonial": testimonial,
+		})
+	}
 }
 
 func (h *TestimonialHandler) DeleteTestimonial(c *fiber.Ctx) error {
@@ -171,6 +299,28 @@ func (h *TestimonialHandler) DeleteTestimonial(c *fiber.Ctx) error {
 	})
 }
 
+func (h *TestimonialHandler) RestoreTestimonial(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	testimonialID := c.Params("id")
+	if testimonialID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Testimonial ID is required")
+	}
+
+	err := h.testimonialService.RestoreTestimonial(ctx, testimonialID)
+	if err != nil {
+		return h.handleTestimonialError(c, errHandler, requestID, err, c.Path(), "restore_testimonial")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Testimonial restored successfully",
+	})
+}
+
 func (h *TestimonialHandler) getRequestID(c *fiber.Ctx) string {
 	if requestID := c.Locals("request_id"); requestID != nil {
 		if id, ok := requestID.(string); ok {
@@ -188,6 +338,22 @@ func (h *TestimonialHandler) handleTestimonialError(c *fiber.Ctx, errHandler *ha
 		return errHandler.HandleBadRequest(c, requestID, "Rating must be between 1 and 5")
 	case testimonials.ErrUnauthorizedAccess:
 		return errHandler.HandleForbidden(c, requestID, "Unauthorized access")
+	case testimonials.ErrTestimonialExists:
+		return response.Conflict(c, "You have already reviewed this meal plan. Edit your existing review instead")
+	case testimonials.ErrTooManySubmissions:
+		return response.TooManyRequests(c, "Too many reviews submitted, please try again later")
+	case meal_plans.ErrMealPlanNotFound:
+		return errHandler.HandleNotFound(c, requestID, "Meal plan")
+	case testimonials.ErrInvalidStatusTransition:
+		return response.Conflict(c, "This testimonial has already been reviewed and cannot move to the requested status")
+	case testimonials.ErrTestimonialStatusChanged:
+		return response.Conflict(c, "This testimonial was just moderated by someone else. Reload it and try again")
+	case testimonials.ErrRejectionReasonRequired:
+		return errHandler.HandleBadRequest(c, requestID, "A reason is required: spam, offensive, off_topic, personal_info, not_genuine or other")
+	case testimonials.ErrModerationNoteRequired:
+		return errHandler.HandleBadRequest(c, requestID, "A note is required for this decision")
+	case testimonials.ErrTestimonialReplaced:
+		return response.Conflict(c, "The author has written a newer review of this meal plan, so this one cannot be restored")
 	default:
 		return errHandler.Handle(c, requestID, err, path, operation)
 	}
@@ -3,71 +3,156 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"sea-catering-backend/internal/api/testimonials"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
 )
 
 type TestimonialRepository interface {
-	Create(ctx context.Context, testimonial *entity.Testimonial) error
+	Create(ctx context.Context, testimonial *entity.Testimonial, entry *entity.TestimonialModerationEntry) error
 	GetByID(ctx context.Context, id string) (*entity.Testimonial, error)
 	GetAll(ctx context.Context) ([]entity.Testimonial, error)
-	GetApproved(ctx context.Context) ([]entity.Testimonial, error)
+	GetApproved(ctx context.Context, mealPlanID string) ([]entity.Testimonial, error)
+	GetByUserID(ctx context.Context, userID string) ([]entity.Testimonial, error)
 	GetPending(ctx context.Context) ([]entity.Testimonial, error)
-	Update(ctx context.Context, testimonial *entity.Testimonial) error
+	Transition(ctx context.Context, testimonial *entity.Testimonial, from entity.TestimonialStatus, entry *entity.TestimonialModerationEntry) error
+	// Delete marks the testimonial deleted; reads leave it out until Restore.
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	GetByRating(ctx context.Context, rating int) ([]entity.Testimonial, error)
 	Count(ctx context.Context) (int, error)
 	CountApproved(ctx context.Context) (int, error)
 	CountPending(ctx context.Context) (int, error)
+	ListForModeration(ctx context.Context, req testimonials.ModerationQueueRequest) ([]entity.Testimonial, *testimonials.PaginationMeta, error)
+	// EachForModeration applies the queue filters without paging and passes
+	// rows to fn as they are read.
+	EachForModeration(ctx context.Context, req testimonials.ModerationQueueRequest, fn func(*entity.Testimonial) error) error
+	CountByStatus(ctx context.Context) (map[entity.TestimonialStatus]int, error)
+	GetModerationLog(ctx context.Context, testimonialID string) ([]entity.TestimonialModerationEntry, error)
+	FindByContentHash(ctx context.Context, hash, excludeID string) (string, error)
 }
 
 type testimonialRepository struct {
-	db *sqlx.DB
+	db *database.DB
 }
 
 func NewTestimonialRepository(db *sqlx.DB) TestimonialRepository {
 	return &testimonialRepository{
-		db: db,
+		db: database.New(db),
 	}
 }
 
-func (r *testimonialRepository) Create(ctx context.Context, testimonial *entity.Testimonial) error {
+const testimonialColumns = `id, user_id, meal_plan_id, subscription_id, customer_name, message, rating, is_verified,
+	status, rejection_reason, moderation_note, moderated_by, moderated_at,
+	spam_score, screening_findings, content_hash, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTestimonial(row rowScanner) (*entity.Testimonial, error) {
+	var testimonial entity.Testimonial
+	var findings []byte
+	err := row.Scan(
+		&testimonial.ID, &testimonial.UserID, &testimonial.MealPlanID, &testimonial.SubscriptionID,
+		&testimonial.CustomerName, &testimonial.Message, &testimonial.Rating,
+		&testimonial.IsVerified, &testimonial.Status, &testimonial.RejectionReason,
+		&testimonial.ModerationNote, &testimonial.ModeratedBy, &testimonial.ModeratedAt,
+		&testimonial.SpamScore, &findings, &testimonial.ContentHash,
+		&testimonial.CreatedAt, &testimonial.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(findings, &testimonial.ScreeningFindings); err != nil {
+		return nil, fmt.Errorf("failed to decode screening findings: %w", err)
+	}
+	return &testimonial, nil
+}
+
+func marshalFindings(findings []entity.ScreeningFinding) ([]byte, error) {
+	if findings == nil {
+		findings = []entity.ScreeningFinding{}
+	}
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode screening findings: %w", err)
+	}
+	return data, nil
+}
+
+func (r *testimonialRepository) insertModerationEntry(ctx context.Context, entry *entity.TestimonialModerationEntry) error {
 	query := `
-		INSERT INTO testimonials (id, customer_name, message, rating, is_approved, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO testimonial_moderation_log (id, testimonial_id, from_status, to_status, reason, note, moderator_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
-		testimonial.ID, testimonial.CustomerName, testimonial.Message,
-		testimonial.Rating, testimonial.IsApproved,
-		testimonial.CreatedAt, testimonial.UpdatedAt,
+		entry.ID, entry.TestimonialID, entry.FromStatus, entry.ToStatus,
+		entry.Reason, entry.Note, entry.ModeratorID, entry.CreatedAt,
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to create testimonial: %w", err)
+		return fmt.Errorf("failed to record testimonial moderation: %w", err)
 	}
 
 	return nil
 }
 
+// Create inserts the testimonial. entry, when set, records the status that
+// automatic screening gave it in the same transaction.
+func (r *testimonialRepository) Create(ctx context.Context, testimonial *entity.Testimonial, entry *entity.TestimonialModerationEntry) error {
+	findings, err := marshalFindings(testimonial.ScreeningFindings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO testimonials (
+			id, user_id, meal_plan_id, subscription_id, customer_name, message, rating, is_verified,
+			status, moderation_note, moderated_at, spam_score, screening_findings, content_hash, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query,
+			testimonial.ID, testimonial.UserID, testimonial.MealPlanID, testimonial.SubscriptionID,
+			testimonial.CustomerName, testimonial.Message, testimonial.Rating,
+			testimonial.IsVerified, testimonial.Status, testimonial.ModerationNote, testimonial.ModeratedAt,
+			testimonial.SpamScore, findings, testimonial.ContentHash,
+			testimonial.CreatedAt, testimonial.UpdatedAt,
+		)
+
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return testimonials.ErrTestimonialExists
+			}
+			return fmt.Errorf("failed to create testimonial: %w", err)
+		}
+
+		if entry == nil {
+			return nil
+		}
+		return r.insertModerationEntry(ctx, entry)
+	})
+}
+
 func (r *testimonialRepository) GetByID(ctx context.Context, id string) (*entity.Testimonial, error) {
 	query := `
-		SELECT id, customer_name, message, rating, is_approved, created_at, updated_at
+		SELECT ` + testimonialColumns + `
 		FROM testimonials
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var testimonial entity.Testimonial
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&testimonial.ID, &testimonial.CustomerName, &testimonial.Message,
-		&testimonial.Rating, &testimonial.IsApproved,
-		&testimonial.CreatedAt, &testimonial.UpdatedAt,
-	)
-
+	testimonial, err := scanTestimonial(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, testimonials.ErrTestimonialNotFound
@@ -75,13 +160,14 @@ func (r *testimonialRepository) GetByID(ctx context.Context, id string) (*entity
 		return nil, fmt.Errorf("failed to get testimonial: %w", err)
 	}
 
-	return &testimonial, nil
+	return testimonial, nil
 }
 
 func (r *testimonialRepository) GetAll(ctx context.Context) ([]entity.Testimonial, error) {
 	query := `
-		SELECT id, customer_name, message, rating, is_approved, created_at, updated_at
+		SELECT ` + testimonialColumns + `
 		FROM testimonials
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -93,16 +179,11 @@ func (r *testimonialRepository) GetAll(ctx context.Context) ([]entity.Testimonia
 
 	var testimonialList []entity.Testimonial
 	for rows.Next() {
-		var testimonial entity.Testimonial
-		err := rows.Scan(
-			&testimonial.ID, &testimonial.CustomerName, &testimonial.Message,
-			&testimonial.Rating, &testimonial.IsApproved,
-			&testimonial.CreatedAt, &testimonial.UpdatedAt,
-		)
+		testimonial, err := scanTestimonial(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan testimonial: %w", err)
 		}
-		testimonialList = append(testimonialList, testimonial)
+		testimonialList = append(testimonialList, *testimonial)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -112,15 +193,17 @@ func (r *testimonialRepository) GetAll(ctx context.Context) ([]entity.Testimonia
 	return testimonialList, nil
 }
 
-func (r *testimonialRepository) GetApproved(ctx context.Context) ([]entity.Testimonial, error) {
+// GetApproved lists published testimonials, verified subscribers first. An
+// empty mealPlanID returns reviews for every plan.
+func (r *testimonialRepository) GetApproved(ctx context.Context, mealPlanID string) ([]entity.Testimonial, error) {
 	query := `
-		SELECT id, customer_name, message, rating, is_approved, created_at, updated_at
+		SELECT ` + testimonialColumns + `
 		FROM testimonials
-		WHERE is_approved = true
-		ORDER BY created_at DESC
+		WHERE status = 'approved' AND deleted_at IS NULL AND ($1 = '' OR meal_plan_id = $1)
+		ORDER BY is_verified DESC, created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, mealPlanID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get approved testimonials: %w", err)
 	}
@@ -128,16 +211,11 @@ func (r *testimonialRepository) GetApproved(ctx context.Context) ([]entity.Testi
 
 	var testimonialList []entity.Testimonial
 	for rows.Next() {
-		var testimonial entity.Testimonial
-		err := rows.Scan(
-			&testimonial.ID, &testimonial.CustomerName, &testimonial.Message,
-			&testimonial.Rating, &testimonial.IsApproved,
-			&testimonial.CreatedAt, &testimonial.UpdatedAt,
-		)
+		testimonial, err := scanTestimonial(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan testimonial: %w", err)
 		}
-		testimonialList = append(testimonialList, testimonial)
+		testimonialList = append(testimonialList, *testimonial)
 	}
 
 	return testimonialList, nil
@@ -145,9 +223,9 @@ func (r *testimonialRepository) GetApproved(ctx context.Context) ([]entity.Testi
 
 func (r *testimonialRepository) GetPending(ctx context.Context) ([]entity.Testimonial, error) {
 	query := `
-		SELECT id, customer_name, message, rating, is_approved, created_at, updated_at
+		SELECT ` + testimonialColumns + `
 		FROM testimonials
-		WHERE is_approved = false
+		WHERE status IN ('pending', 'flagged') AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -159,35 +237,99 @@ func (r *testimonialRepository) GetPending(ctx context.Context) ([]entity.Testim
 
 	var testimonialList []entity.Testimonial
 	for rows.Next() {
-		var testimonial entity.Testimonial
-		err := rows.Scan(
-			&testimonial.ID, &testimonial.CustomerName, &testimonial.Message,
-			&testimonial.Rating, &testimonial.IsApproved,
-			&testimonial.CreatedAt, &testimonial.UpdatedAt,
-		)
+		testimonial, err := scanTestimonial(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan testimonial: %w", err)
+		}
+		testimonialList = append(testimonialList, *testimonial)
+	}
+
+	return testimonialList, nil
+}
+
+func (r *testimonialRepository) GetByUserID(ctx context.Context, userID string) ([]entity.Testimonial, error) {
+	query := `
+		SELECT ` + testimonialColumns + `
+		FROM testimonials
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user testimonials: %w", err)
+	}
+	defer rows.Close()
+
+	testimonialList := []entity.Testimonial{}
+	for rows.Next() {
+		testimonial, err := scanTestimonial(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan testimonial: %w", err)
 		}
-		testimonialList = append(testimonialList, testimonial)
+		testimonialList = append(testimonialList, *testimonial)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
 	return testimonialList, nil
 }
 
-func (r *testimonialRepository) Update(ctx context.Context, testimonial *entity.Testimonial) error {
+// Transition saves the testimonial and records the status change in one
+// transaction. The update only applies while the testimonial is still in
+// the from state, so two moderators acting at once cannot both decide.
+func (r *testimonialRepository) Transition(ctx context.Context, testimonial *entity.Testimonial, from entity.TestimonialStatus, entry *entity.TestimonialModerationEntry) error {
+	findings, err := marshalFindings(testimonial.ScreeningFindings)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE testimonials
-		SET customer_name = $2, message = $3, rating = $4, is_approved = $5, updated_at = $6
-		WHERE id = $1
+		SET meal_plan_id = $3, subscription_id = $4, customer_name = $5, message = $6, rating = $7,
+			is_verified = $8, status = $9, rejection_reason = $10, moderation_note = $11,
+			moderated_by = $12, moderated_at = $13, spam_score = $14, screening_findings = $15,
+			content_hash = $16, updated_at = $17
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
-		testimonial.ID, testimonial.CustomerName, testimonial.Message,
-		testimonial.Rating, testimonial.IsApproved, testimonial.UpdatedAt,
-	)
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := r.db.ExecContext(ctx, query,
+			testimonial.ID, from, testimonial.MealPlanID, testimonial.SubscriptionID,
+			testimonial.CustomerName, testimonial.Message, testimonial.Rating,
+			testimonial.IsVerified, testimonial.Status, testimonial.RejectionReason, testimonial.ModerationNote,
+			testimonial.ModeratedBy, testimonial.ModeratedAt, testimonial.SpamScore, findings,
+			testimonial.ContentHash, testimonial.UpdatedAt,
+		)
+
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return testimonials.ErrTestimonialExists
+			}
+			return fmt.Errorf("failed to update testimonial: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return testimonials.ErrTestimonialStatusChanged
+		}
+
+		return r.insertModerationEntry(ctx, entry)
+	})
+}
+
+func (r *testimonialRepository) Delete(ctx context.Context, id string) error {
+	query := `UPDATE testimonials SET deleted_at = $2, updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`
 
+	result, err := r.db.ExecContext(ctx, query, id, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to update testimonial: %w", err)
+		return fmt.Errorf("failed to delete testimonial: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -202,12 +344,17 @@ func (r *testimonialRepository) Update(ctx context.Context, testimonial *entity.
 	return nil
 }
 
-func (r *testimonialRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM testimonials WHERE id = $1`
+// Restore fails with ErrTestimonialReplaced if the author has since written
+// a new review of the same plan.
+func (r *testimonialRepository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE testimonials SET deleted_at = NULL, updated_at = $2 WHERE id = $1 AND deleted_at IS NOT NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to delete testimonial: %w", err)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return testimonials.ErrTestimonialReplaced
+		}
+		return fmt.Errorf("failed to restore testimonial: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -224,9 +371,9 @@ func (r *testimonialRepository) Delete(ctx context.Context, id string) error {
 
 func (r *testimonialRepository) GetByRating(ctx context.Context, rating int) ([]entity.Testimonial, error) {
 	query := `
-		SELECT id, customer_name, message, rating, is_approved, created_at, updated_at
+		SELECT ` + testimonialColumns + `
 		FROM testimonials
-		WHERE rating = $1 AND is_approved = true
+		WHERE rating = $1 AND status = 'approved' AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -238,23 +385,18 @@ func (r *testimonialRepository) GetByRating(ctx context.Context, rating int) ([]
 
 	var testimonialList []entity.Testimonial
 	for rows.Next() {
-		var testimonial entity.Testimonial
-		err := rows.Scan(
-			&testimonial.ID, &testimonial.CustomerName, &testimonial.Message,
-			&testimonial.Rating, &testimonial.IsApproved,
-			&testimonial.CreatedAt, &testimonial.UpdatedAt,
-		)
+		testimonial, err := scanTestimonial(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan testimonial: %w", err)
 		}
-		testimonialList = append(testimonialList, testimonial)
+		testimonialList = append(testimonialList, *testimonial)
 	}
 
 	return testimonialList, nil
 }
 
 func (r *testimonialRepository) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM testimonials`
+	query := `SELECT COUNT(*) FROM testimonials WHERE deleted_at IS NULL`
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
@@ -266,7 +408,7 @@ func (r *testimonialRepository) Count(ctx context.Context) (int, error) {
 }
 
 func (r *testimonialRepository) CountApproved(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM testimonials WHERE is_approved = true`
+	query := `SELECT COUNT(*) FROM testimonials WHERE status = 'approved' AND deleted_at IS NULL`
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
@@ -278,7 +420,7 @@ func (r *testimonialRepository) CountApproved(ctx context.Context) (int, error)
 }
 
 func (r *testimonialRepository) CountPending(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM testimonials WHERE is_approved = false`
+	query := `SELECT COUNT(*) FROM testimonials WHERE status IN ('pending', 'flagged') AND deleted_at IS NULL`
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
@@ -288,3 +430,221 @@ func (r *testimonialRepository) CountPending(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+func (r *testimonialRepository) ListForModeration(ctx context.Context, req testimonials.ModerationQueueRequest) ([]entity.Testimonial, *testimonials.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereClause, args, argIndex := moderationFilter(req)
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM testimonials %s`, whereClause)
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to count testimonials: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM testimonials
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, testimonialColumns, whereClause, moderationOrder(req), argIndex, argIndex+1)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, req.Limit, offset)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list testimonials for moderation: %w", err)
+	}
+	defer rows.Close()
+
+	testimonialList := []entity.Testimonial{}
+	for rows.Next() {
+		testimonial, err := scanTestimonial(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan testimonial: %w", err)
+		}
+		testimonialList = append(testimonialList, *testimonial)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	meta := &testimonials.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return testimonialList, meta, nil
+}
+
+// moderationFilter builds the WHERE clause shared by the moderation queue and
+// export. It returns the next free placeholder index.
+func moderationFilter(req testimonials.ModerationQueueRequest) (string, []interface{}, int) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	argIndex := 1
+
+	switch req.Status {
+	case "", "queue":
+		conditions = append(conditions, "status IN ('pending', 'flagged')")
+	case "all":
+	default:
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, req.Status)
+		argIndex++
+	}
+
+	if req.Rating > 0 {
+		conditions = append(conditions, fmt.Sprintf("rating = $%d", argIndex))
+		args = append(args, req.Rating)
+		argIndex++
+	}
+
+	if req.Verified != "" {
+		conditions = append(conditions, fmt.Sprintf("is_verified = $%d", argIndex))
+		args = append(args, req.Verified == "true")
+		argIndex++
+	}
+
+	if req.MinScore > 0 {
+		conditions = append(conditions, fmt.Sprintf("spam_score >= $%d", argIndex))
+		args = append(args, req.MinScore)
+		argIndex++
+	}
+
+	if req.MealPlanID != "" {
+		conditions = append(conditions, fmt.Sprintf("meal_plan_id = $%d", argIndex))
+		args = append(args, req.MealPlanID)
+		argIndex++
+	}
+
+	if search := strings.TrimSpace(req.Search); search != "" {
+		conditions = append(conditions, fmt.Sprintf("(customer_name ILIKE $%d OR message ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+search+"%")
+		argIndex++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	return whereClause, args, argIndex
+}
+
+func moderationOrder(req testimonials.ModerationQueueRequest) string {
+	switch req.Sort {
+	case "newest":
+		return "created_at DESC, id DESC"
+	case "score":
+		return "spam_score DESC, created_at ASC, id ASC"
+	default:
+		return "created_at ASC, id ASC"
+	}
+}
+
+func (r *testimonialRepository) EachForModeration(ctx context.Context, req testimonials.ModerationQueueRequest, fn func(*entity.Testimonial) error) error {
+	whereClause, args, _ := moderationFilter(req)
+	query := fmt.Sprintf(`SELECT %s FROM testimonials %s ORDER BY %s`, testimonialColumns, whereClause, moderationOrder(req))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list testimonials for moderation: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		testimonial, err := scanTestimonial(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan testimonial: %w", err)
+		}
+		if err := fn(testimonial); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *testimonialRepository) CountByStatus(ctx context.Context) (map[entity.TestimonialStatus]int, error) {
+	query := `SELECT status, COUNT(*) FROM testimonials WHERE deleted_at IS NULL GROUP BY status`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count testimonials by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[entity.TestimonialStatus]int{
+		entity.TestimonialPending:  0,
+		entity.TestimonialFlagged:  0,
+		entity.TestimonialApproved: 0,
+		entity.TestimonialRejected: 0,
+		entity.TestimonialHidden:   0,
+	}
+	for rows.Next() {
+		var status entity.TestimonialStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan testimonial count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *testimonialRepository) GetModerationLog(ctx context.Context, testimonialID string) ([]entity.TestimonialModerationEntry, error) {
+	query := `
+		SELECT id, testimonial_id, from_status, to_status, reason, note, moderator_id, created_at
+		FROM testimonial_moderation_log
+		WHERE testimonial_id = $1
+		ORDER BY created_at, id
+	`
+
+	entries := []entity.TestimonialModerationEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, testimonialID); err != nil {
+		return nil, fmt.Errorf("failed to get testimonial moderation log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindByContentHash returns the ID of another testimonial with the same
+// normalised text, or an empty string.
+func (r *testimonialRepository) FindByContentHash(ctx context.Context, hash, excludeID string) (string, error) {
+	query := `
+		SELECT id
+		FROM testimonials
+		WHERE content_hash = $1 AND id <> $2 AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+	`
+
+	var id string
+	err := r.db.QueryRowContext(ctx, query, hash, excludeID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to find testimonial by content hash: %w", err)
+	}
+
+	return id, nil
+}
@@ -2,78 +2,246 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
+	authRepo "sea-catering-backend/internal/api/auth/repository"
+	mealPlanRepo "sea-catering-backend/internal/api/meal_plans/repository"
+	subscriptionRepo "sea-catering-backend/internal/api/subscriptions/repository"
 	"sea-catering-backend/internal/api/testimonials"
 	"sea-catering-backend/internal/api/testimonials/repository"
 	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/events"
 	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/screening"
 	"sea-catering-backend/pkg/utils"
 )
 
 type TestimonialService interface {
-	CreateTestimonial(ctx context.Context, req testimonials.CreateTestimonialRequest) (*testimonials.TestimonialResponse, error)
-	GetApprovedTestimonials(ctx context.Context) ([]testimonials.TestimonialResponse, error)
+	CreateTestimonial(ctx context.Context, userID, clientIP string, req testimonials.CreateTestimonialRequest) (*testimonials.TestimonialResponse, error)
+	UpdateTestimonial(ctx context.Context, userID, id string, req testimonials.UpdateTestimonialRequest) (*testimonials.TestimonialResponse, error)
+	GetMyTestimonials(ctx context.Context, userID string) ([]testimonials.TestimonialResponse, error)
+	GetApprovedTestimonials(ctx context.Context, req testimonials.TestimonialListRequest) ([]testimonials.TestimonialResponse, error)
 	GetAllTestimonials(ctx context.Context) ([]testimonials.TestimonialResponse, error)
-	ApproveTestimonial(ctx context.Context, id string) error
-	RejectTestimonial(ctx context.Context, id string) error
+	GetModerationQueue(ctx context.Context, req testimonials.ModerationQueueRequest) (*testimonials.ModerationQueueResponse, error)
+	GetTestimonialDetail(ctx context.Context, id string) (*testimonials.TestimonialDetailResponse, error)
+	Moderate(ctx context.Context, moderatorID, id string, to entity.TestimonialStatus, req testimonials.ModerationRequest) (*entity.Testimonial, error)
 	DeleteTestimonial(ctx context.Context, id string) error
+	RestoreTestimonial(ctx context.Context, id string) error
 }
 
 type testimonialService struct {
-	repo         repository.TestimonialRepository
-	utilsService utils.Interface
-	logger       *logger.Logger
+	repo             repository.TestimonialRepository
+	userRepo         authRepo.UserRepository
+	mealPlanRepo     mealPlanRepo.MealPlanRepository
+	subscriptionRepo subscriptionRepo.SubscriptionRepository
+	eventBus         events.Interface
+	screener         screening.Interface
+	utilsService     utils.Interface
+	logger           *logger.Logger
 }
 
 func NewTestimonialService(
 	repo repository.TestimonialRepository,
+	userRepo authRepo.UserRepository,
+	mealPlanRepo mealPlanRepo.MealPlanRepository,
+	subscriptionRepo subscriptionRepo.SubscriptionRepository,
+	eventBus events.Interface,
+	screener screening.Interface,
 	utilsService utils.Interface,
 	logger *logger.Logger,
 ) TestimonialService {
 	return &testimonialService{
-		repo:         repo,
-		utilsService: utilsService,
-		logger:       logger,
+		repo:             repo,
+		userRepo:         userRepo,
+		mealPlanRepo:     mealPlanRepo,
+		subscriptionRepo: subscriptionRepo,
+		eventBus:         eventBus,
+		screener:         screener,
+		utilsService:     utilsService,
+		logger:           logger,
 	}
 }
 
-func (s *testimonialService) CreateTestimonial(ctx context.Context, req testimonials.CreateTestimonialRequest) (*testimonials.TestimonialResponse, error) {
+func (s *testimonialService) CreateTestimonial(ctx context.Context, userID, clientIP string, req testimonials.CreateTestimonialRequest) (*testimonials.TestimonialResponse, error) {
 
 	if req.Rating < 1 || req.Rating > 5 {
 		return nil, testimonials.ErrInvalidRating
 	}
 
+	allowed, err := s.screener.Allow(ctx, clientIP)
+	if err != nil {
+		s.logger.Error("Failed to check testimonial rate limit", logger.Fields{"error": err.Error()})
+		return nil, err
+	}
+	if !allowed {
+		return nil, testimonials.ErrTooManySubmissions
+	}
+
+	author, err := s.loadAuthor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mealPlanID := req.MealPlanID
+	if mealPlanID != nil && strings.TrimSpace(*mealPlanID) == "" {
+		mealPlanID = nil
+	}
+	if mealPlanID != nil {
+		if _, err := s.mealPlanRepo.GetByID(ctx, *mealPlanID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
 	testimonial := &entity.Testimonial{
 		ID:           s.utilsService.GenerateULID(),
-		CustomerName: strings.TrimSpace(req.CustomerName),
+		UserID:       &userID,
+		MealPlanID:   mealPlanID,
+		CustomerName: author.Name,
 		Message:      strings.TrimSpace(req.Message),
 		Rating:       req.Rating,
-		IsApproved:   false,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Status:       entity.TestimonialPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
-	if err := s.repo.Create(ctx, testimonial); err != nil {
-		s.logger.Error("Failed to create testimonial", logger.Fields{
-			"error":         err.Error(),
-			"customer_name": req.CustomerName,
-		})
+	if err := s.verifyAuthor(ctx, testimonial); err != nil {
+		return nil, err
+	}
+
+	var entry *entity.TestimonialModerationEntry
+	if note := s.screen(ctx, testimonial); note != nil {
+		entry = &entity.TestimonialModerationEntry{
+			ID:            s.utilsService.GenerateULID(),
+			TestimonialID: testimonial.ID,
+			FromStatus:    entity.TestimonialPending,
+			ToStatus:      testimonial.Status,
+			Note:          note,
+			CreatedAt:     now,
+		}
+	}
+
+	if err := s.repo.Create(ctx, testimonial, entry); err != nil {
+		if err != testimonials.ErrTestimonialExists {
+			s.logger.Error("Failed to create testimonial", logger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
 		return nil, err
 	}
 
 	s.logger.Info("Testimonial created successfully", logger.Fields{
-		"id":            testimonial.ID,
-		"customer_name": testimonial.CustomerName,
-		"rating":        testimonial.Rating,
+		"id":           testimonial.ID,
+		"user_id":      userID,
+		"meal_plan_id": testimonial.MealPlanID,
+		"rating":       testimonial.Rating,
+		"verified":     testimonial.IsVerified,
+		"status":       testimonial.Status,
+		"spam_score":   testimonial.SpamScore,
+	})
+
+	return s.entityToResponse(testimonial), nil
+}
+
+// UpdateTestimonial lets the author revise their review. Any edit takes the
+// review off the public listing until it is approved again.
+func (s *testimonialService) UpdateTestimonial(ctx context.Context, userID, id string, req testimonials.UpdateTestimonialRequest) (*testimonials.TestimonialResponse, error) {
+	testimonial, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !testimonial.IsOwnedBy(userID) {
+		return nil, testimonials.ErrUnauthorizedAccess
+	}
+
+	if req.Message != nil {
+		testimonial.Message = strings.TrimSpace(*req.Message)
+	}
+	if req.Rating != nil {
+		if *req.Rating < 1 || *req.Rating > 5 {
+			return nil, testimonials.ErrInvalidRating
+		}
+		testimonial.Rating = *req.Rating
+	}
+
+	author, err := s.loadAuthor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	testimonial.CustomerName = author.Name
+
+	if err := s.verifyAuthor(ctx, testimonial); err != nil {
+		return nil, err
+	}
+
+	// The previous decision stays in the moderation log; the reason no
+	// longer describes the edited text.
+	from := testimonial.Status
+	now := time.Now()
+	testimonial.Status = entity.TestimonialPending
+	testimonial.RejectionReason = nil
+	testimonial.ModerationNote = nil
+	testimonial.UpdatedAt = now
+
+	note := "Edited by author"
+	if screened := s.screen(ctx, testimonial); screened != nil {
+		note += ". " + *screened
+	}
+
+	entry := &entity.TestimonialModerationEntry{
+		ID:            s.utilsService.GenerateULID(),
+		TestimonialID: testimonial.ID,
+		FromStatus:    from,
+		ToStatus:      testimonial.Status,
+		Note:          &note,
+		CreatedAt:     now,
+	}
+
+	if err := s.repo.Transition(ctx, testimonial, from, entry); err != nil {
+		s.logger.Error("Failed to update testimonial", logger.Fields{
+			"error":   err.Error(),
+			"id":      id,
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Testimonial updated and returned to moderation", logger.Fields{
+		"id":         id,
+		"user_id":    userID,
+		"status":     testimonial.Status,
+		"spam_score": testimonial.SpamScore,
 	})
 
 	return s.entityToResponse(testimonial), nil
 }
 
-func (s *testimonialService) GetApprovedTestimonials(ctx context.Context) ([]testimonials.TestimonialResponse, error) {
-	testimonialList, err := s.repo.GetApproved(ctx)
+func (s *testimonialService) GetMyTestimonials(ctx context.Context, userID string) ([]testimonials.TestimonialResponse, error) {
+	testimonialList, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user testimonials", logger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, err
+	}
+
+	responses := make([]testimonials.TestimonialResponse, len(testimonialList))
+	for i, testimonial := range testimonialList {
+		responses[i] = *s.entityToResponse(&testimonial)
+	}
+
+	return responses, nil
+}
+
+func (s *testimonialService) GetApprovedTestimonials(ctx context.Context, req testimonials.TestimonialListRequest) ([]testimonials.TestimonialResponse, error) {
+	testimonialList, err := s.repo.GetApproved(ctx, req.MealPlanID)
 	if err != nil {
 		s.logger.Error("Failed to get approved testimonials", logger.Fields{
 			"error": err.Error(),
@@ -106,87 +274,299 @@ func (s *testimonialService) GetAllTestimonials(ctx context.Context) ([]testimon
 	return responses, nil
 }
 
-func (s *testimonialService) ApproveTestimonial(ctx context.Context, id string) error {
-	testimonial, err := s.repo.GetByID(ctx, id)
+func (s *testimonialService) GetModerationQueue(ctx context.Context, req testimonials.ModerationQueueRequest) (*testimonials.ModerationQueueResponse, error) {
+	testimonialList, meta, err := s.repo.ListForModeration(ctx, req)
 	if err != nil {
-		return err
+		s.logger.Error("Failed to get moderation queue", logger.Fields{
+			"error": err.Error(),
+		})
+		return nil, err
 	}
 
-	if testimonial.IsApproved {
-		return nil
+	counts, err := s.repo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	testimonial.IsApproved = true
-	testimonial.UpdatedAt = time.Now()
+	return &testimonials.ModerationQueueResponse{
+		Testimonials: testimonialList,
+		StatusCounts: counts,
+		Meta:         meta,
+	}, nil
+}
 
-	if err := s.repo.Update(ctx, testimonial); err != nil {
-		s.logger.Error("Failed to approve testimonial", logger.Fields{
-			"error": err.Error(),
-			"id":    id,
-		})
-		return err
+// GetTestimonialDetail includes every earlier decision, so a moderator can
+// see whether a review was already looked at and why.
+func (s *testimonialService) GetTestimonialDetail(ctx context.Context, id string) (*testimonials.TestimonialDetailResponse, error) {
+	testimonial, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Testimonial approved successfully", logger.Fields{
-		"id":            id,
-		"customer_name": testimonial.CustomerName,
-	})
+	history, err := s.repo.GetModerationLog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return &testimonials.TestimonialDetailResponse{
+		Testimonial: testimonial,
+		History:     history,
+	}, nil
 }
 
-func (s *testimonialService) RejectTestimonial(ctx context.Context, id string) error {
+func (s *testimonialService) Moderate(ctx context.Context, moderatorID, id string, to entity.TestimonialStatus, req testimonials.ModerationRequest) (*entity.Testimonial, error) {
 	testimonial, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !testimonial.IsApproved {
-		return nil
+	from := testimonial.Status
+	if !from.CanTransitionTo(to) {
+		return nil, testimonials.ErrInvalidStatusTransition
+	}
+
+	reason, note, err := validateDecision(to, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	testimonial.Status = to
+	testimonial.RejectionReason = reason
+	testimonial.ModerationNote = note
+	testimonial.ModeratedBy = &moderatorID
+	testimonial.ModeratedAt = &now
+	testimonial.UpdatedAt = now
+
+	entry := &entity.TestimonialModerationEntry{
+		ID:            s.utilsService.GenerateULID(),
+		TestimonialID: testimonial.ID,
+		FromStatus:    from,
+		ToStatus:      to,
+		Reason:        reason,
+		Note:          note,
+		ModeratorID:   &moderatorID,
+		CreatedAt:     now,
+	}
+
+	if err := s.repo.Transition(ctx, testimonial, from, entry); err != nil {
+		if err != testimonials.ErrTestimonialStatusChanged {
+			s.logger.Error("Failed to moderate testimonial", logger.Fields{
+				"error":  err.Error(),
+				"id":     id,
+				"status": to,
+			})
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Testimonial moderated", logger.Fields{
+		"id":           id,
+		"from":         from,
+		"to":           to,
+		"reason":       reason,
+		"moderator_id": moderatorID,
+	})
+
+	s.publishDecision(ctx, testimonial)
+
+	return testimonial, nil
+}
+
+// validateDecision enforces what each decision must explain. Reasons only
+// apply to rejecting and hiding; the note is optional except for "other"
+// and for flags, which hand the review to another moderator.
+func validateDecision(to entity.TestimonialStatus, req testimonials.ModerationRequest) (*entity.RejectionReason, *string, error) {
+	var reason *entity.RejectionReason
+	var note *string
+
+	if trimmed := strings.TrimSpace(req.Note); trimmed != "" {
+		note = &trimmed
+	}
+
+	switch to {
+	case entity.TestimonialRejected, entity.TestimonialHidden:
+		if req.Reason == "" {
+			return nil, nil, testimonials.ErrRejectionReasonRequired
+		}
+		code := entity.RejectionReason(req.Reason)
+		if code == entity.RejectionOther && note == nil {
+			return nil, nil, testimonials.ErrModerationNoteRequired
+		}
+		reason = &code
+	case entity.TestimonialFlagged:
+		if note == nil {
+			return nil, nil, testimonials.ErrModerationNoteRequired
+		}
+	}
+
+	return reason, note, nil
+}
+
+// publishDecision tells the author about decisions that change what the
+// public sees. Reviews from before accounts were required have no author.
+func (s *testimonialService) publishDecision(ctx context.Context, testimonial *entity.Testimonial) {
+	if testimonial.UserID == nil {
+		return
+	}
+
+	var name string
+	switch testimonial.Status {
+	case entity.TestimonialApproved:
+		name = testimonials.EventTestimonialApproved
+	case entity.TestimonialRejected:
+		name = testimonials.EventTestimonialRejected
+	case entity.TestimonialHidden:
+		name = testimonials.EventTestimonialHidden
+	default:
+		return
+	}
+
+	event := testimonials.TestimonialEvent{
+		TestimonialID: testimonial.ID,
+		UserID:        *testimonial.UserID,
+		Rating:        testimonial.Rating,
+		Message:       testimonial.Message,
+	}
+	if testimonial.RejectionReason != nil {
+		event.Reason = *testimonial.RejectionReason
+	}
+	if testimonial.Status != entity.TestimonialApproved && testimonial.ModerationNote != nil {
+		event.Note = *testimonial.ModerationNote
+	}
+	if testimonial.MealPlanID != nil {
+		if mealPlan, err := s.mealPlanRepo.GetByID(ctx, *testimonial.MealPlanID); err == nil {
+			event.MealPlanName = mealPlan.Name
+		}
 	}
 
-	testimonial.IsApproved = false
-	testimonial.UpdatedAt = time.Now()
+	s.eventBus.Publish(ctx, name, event)
+}
 
-	if err := s.repo.Update(ctx, testimonial); err != nil {
-		s.logger.Error("Failed to reject testimonial", logger.Fields{
+func (s *testimonialService) DeleteTestimonial(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete testimonial", logger.Fields{
 			"error": err.Error(),
 			"id":    id,
 		})
 		return err
 	}
 
-	s.logger.Info("Testimonial rejected successfully", logger.Fields{
-		"id":            id,
-		"customer_name": testimonial.CustomerName,
+	s.logger.Info("Testimonial deleted successfully", logger.Fields{
+		"id": id,
 	})
 
 	return nil
 }
 
-func (s *testimonialService) DeleteTestimonial(ctx context.Context, id string) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete testimonial", logger.Fields{
+func (s *testimonialService) RestoreTestimonial(ctx context.Context, id string) error {
+	if err := s.repo.Restore(ctx, id); err != nil {
+		s.logger.Error("Failed to restore testimonial", logger.Fields{
 			"error": err.Error(),
 			"id":    id,
 		})
 		return err
 	}
 
-	s.logger.Info("Testimonial deleted successfully", logger.Fields{
+	s.logger.Info("Testimonial restored", logger.Fields{
 		"id": id,
 	})
 
 	return nil
 }
 
+// screen scores a pending testimonial and, past the configured thresholds,
+// flags it or hides it straight away. It returns a note for the moderation
+// log when the status changed. Screening errors leave the review pending
+// for a moderator rather than rejecting the customer's submission.
+func (s *testimonialService) screen(ctx context.Context, testimonial *entity.Testimonial) *string {
+	result, err := s.screener.Screen(ctx, screening.Submission{
+		ID:      testimonial.ID,
+		Message: testimonial.Message,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to screen testimonial", logger.Fields{
+			"error": err.Error(),
+			"id":    testimonial.ID,
+		})
+		return nil
+	}
+
+	testimonial.SpamScore = result.Score
+	testimonial.ContentHash = &result.ContentHash
+	testimonial.ScreeningFindings = make([]entity.ScreeningFinding, len(result.Findings))
+	for i, finding := range result.Findings {
+		testimonial.ScreeningFindings[i] = entity.ScreeningFinding(finding)
+	}
+
+	switch result.Action {
+	case screening.ActionFlag:
+		testimonial.Status = entity.TestimonialFlagged
+	case screening.ActionHide:
+		testimonial.Status = entity.TestimonialHidden
+	default:
+		return nil
+	}
+
+	note := result.Summary()
+	testimonial.ModerationNote = &note
+	testimonial.ModeratedBy = nil
+	testimonial.ModeratedAt = &testimonial.UpdatedAt
+
+	return &note
+}
+
+func (s *testimonialService) loadAuthor(ctx context.Context, userID string) (*entity.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id %q: %w", userID, err)
+	}
+
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// verifyAuthor marks the review as from a verified subscriber when the
+// author has or had any subscription, and links the one that proves it,
+// preferring a subscription to the reviewed plan.
+func (s *testimonialService) verifyAuthor(ctx context.Context, testimonial *entity.Testimonial) error {
+	subscriptionList, err := s.subscriptionRepo.GetByUserID(ctx, *testimonial.UserID)
+	if err != nil {
+		s.logger.Error("Failed to check testimonial author subscriptions", logger.Fields{
+			"error":   err.Error(),
+			"user_id": *testimonial.UserID,
+		})
+		return err
+	}
+
+	testimonial.IsVerified = len(subscriptionList) > 0
+	testimonial.SubscriptionID = nil
+
+	for _, subscription := range subscriptionList {
+		if testimonial.MealPlanID != nil && subscription.MealPlanID == *testimonial.MealPlanID {
+			testimonial.SubscriptionID = &subscription.ID
+			return nil
+		}
+	}
+
+	if len(subscriptionList) > 0 {
+		testimonial.SubscriptionID = &subscriptionList[0].ID
+	}
+
+	return nil
+}
+
 func (s *testimonialService) entityToResponse(testimonial *entity.Testimonial) *testimonials.TestimonialResponse {
 	return &testimonials.TestimonialResponse{
-		ID:           testimonial.ID,
-		CustomerName: testimonial.CustomerName,
-		Message:      testimonial.Message,
-		Rating:       testimonial.Rating,
-		IsApproved:   testimonial.IsApproved,
-		CreatedAt:    testimonial.CreatedAt.Format("2006-01-02 15:04:05"),
+		ID:                 testimonial.ID,
+		CustomerName:       testimonial.CustomerName,
+		MealPlanID:         testimonial.MealPlanID,
+		Message:            testimonial.Message,
+		Rating:             testimonial.Rating,
+		VerifiedSubscriber: testimonial.IsVerified,
+		Status:             string(testimonial.Status),
+		IsApproved:         testimonial.IsApproved(),
+		RejectionReason:    (*string)(testimonial.RejectionReason),
+		CreatedAt:          testimonial.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          testimonial.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
@@ -0,0 +1,53 @@
+package webhooks
+
+import "sea-catering-backend/internal/entity"
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,required"`
+}
+
+type UpdateEndpointRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,url,max=2048"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Secret      *string  `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes  []string `json:"event_types" validate:"omitempty,min=1,dive,required"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// CreateEndpointResponse is the only response that includes the signing
+// secret.
+type CreateEndpointResponse struct {
+	Endpoint *entity.WebhookEndpoint `json:"endpoint"`
+	Secret   string                  `json:"secret"`
+}
+
+type EndpointListResponse struct {
+	Endpoints []entity.WebhookEndpoint `json:"endpoints"`
+}
+
+type EventTypesResponse struct {
+	EventTypes []string `json:"event_types"`
+}
+
+type DeliveryListRequest struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status string `query:"status" validate:"omitempty,oneof=pending sending delivered dead"`
+}
+
+type DeliveryListResponse struct {
+	Deliveries []entity.WebhookDelivery `json:"deliveries"`
+	Meta       *PaginationMeta          `json:"meta,omitempty"`
+}
+
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
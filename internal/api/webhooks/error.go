@@ -0,0 +1,11 @@
+package webhooks
+
+import "errors"
+
+var (
+	ErrEndpointNotFound      = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrDeliveryNotReplayable = errors.New("webhook delivery is still queued")
+	ErrUnknownEventType      = errors.New("unknown webhook event type")
+	ErrEndpointInactive      = errors.New("webhook endpoint is inactive")
+)
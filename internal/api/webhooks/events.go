@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"time"
+
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/subscriptions"
+)
+
+// EventTest is sent by the admin "send test" action and is delivered to the
+// chosen endpoint whatever it subscribes to.
+const EventTest = "webhook.test"
+
+// EventTypes are the bus events partners can subscribe to. An endpoint may
+// also subscribe to "*" to receive all of them.
+var EventTypes = []string{
+	subscriptions.EventSubscriptionCreated,
+	subscriptions.EventSubscriptionPaused,
+	subscriptions.EventSubscriptionResumed,
+	subscriptions.EventSubscriptionPauseEnding,
+	subscriptions.EventSubscriptionCancelled,
+	subscriptions.EventSubscriptionReactivated,
+	subscriptions.EventSubscriptionForceCancelled,
+	deliveries.EventDeliveryOutForDelivery,
+	deliveries.EventDeliveryDelivered,
+	deliveries.EventDeliveryFailed,
+}
+
+func IsEventType(eventType string) bool {
+	if eventType == "*" {
+		return true
+	}
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body POSTed to endpoints. ID is the same for every
+// endpoint and every retry or replay, so receivers can de-duplicate on it.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Event data carries identifiers rather than customer details; partners
+// look anything else up through the API.
+
+type SubscriptionEventData struct {
+	SubscriptionID string     `json:"subscription_id"`
+	UserID         string     `json:"user_id"`
+	PauseStartDate *time.Time `json:"pause_start_date,omitempty"`
+	PauseEndDate   *time.Time `json:"pause_end_date,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	AutoResumed    bool       `json:"auto_resumed,omitempty"`
+}
+
+type DeliveryEventData struct {
+	DeliveryID    string `json:"delivery_id"`
+	UserID        string `json:"user_id"`
+	Status        string `json:"status"`
+	MealType      string `json:"meal_type"`
+	DeliveryDate  string `json:"delivery_date"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+type TestEventData struct {
+	EndpointID string `json:"endpoint_id"`
+	Message    string `json:"message"`
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"sea-catering-backend/internal/api/webhooks"
+	"sea-catering-backend/internal/api/webhooks/service"
+	"sea-catering-backend/internal/middleware"
+	"sea-catering-backend/pkg/context"
+	"sea-catering-backend/pkg/handlerutil"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/response"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	validator      *validator.Validate
+	middleware     middleware.Interface
+	logger         *logger.Logger
+}
+
+func NewWebhookHandler(
+	webhookService service.WebhookService,
+	validator *validator.Validate,
+	middleware middleware.Interface,
+	logger *logger.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator,
+		middleware:     middleware,
+		logger:         logger,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router fiber.Router) {
+	webhooksGroup := router.Group("/webhooks")
+
+	admin := webhooksGroup.Group("/admin", h.middleware.AdminMiddleware())
+	admin.Get("/event-types", h.ListEventTypes)
+	admin.Get("/endpoints", h.ListEndpoints)
+	admin.Post("/endpoints", h.CreateEndpoint)
+	admin.Get("/endpoints/:id", h.GetEndpoint)
+	admin.Put("/endpoints/:id", h.UpdateEndpoint)
+	admin.Delete("/endpoints/:id", h.DeleteEndpoint)
+	admin.Post("/endpoints/:id/test", h.SendTest)
+	admin.Get("/endpoints/:id/deliveries", h.ListDeliveries)
+	admin.Get("/deliveries/:id", h.GetDelivery)
+	admin.Post("/deliveries/:id/replay", h.ReplayDelivery)
+}
+
+func (h *WebhookHandler) ListEventTypes(c *fiber.Ctx) error {
+	errHandler := handlerutil.New(h.logger)
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, h.webhookService.ListEventTypes())
+}
+
+func (h *WebhookHandler) ListEndpoints(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	result, err := h.webhookService.ListEndpoints(ctx)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "list_webhook_endpoints")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *WebhookHandler) CreateEndpoint(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	var req webhooks.CreateEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.webhookService.CreateEndpoint(ctx, req)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "create_webhook_endpoint")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusCreated, result)
+}
+
+func (h *WebhookHandler) GetEndpoint(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	endpointID := c.Params("id")
+	if endpointID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Endpoint ID is required")
+	}
+
+	endpoint, err := h.webhookService.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "get_webhook_endpoint")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, endpoint)
+}
+
+func (h *WebhookHandler) UpdateEndpoint(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	endpointID := c.Params("id")
+	if endpointID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Endpoint ID is required")
+	}
+
+	var req webhooks.UpdateEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid request body")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(ctx, endpointID, req)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "update_webhook_endpoint")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, endpoint)
+}
+
+func (h *WebhookHandler) DeleteEndpoint(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	endpointID := c.Params("id")
+	if endpointID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Endpoint ID is required")
+	}
+
+	if err := h.webhookService.DeleteEndpoint(ctx, endpointID); err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "delete_webhook_endpoint")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Webhook endpoint deleted",
+	})
+}
+
+func (h *WebhookHandler) SendTest(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	endpointID := c.Params("id")
+	if endpointID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Endpoint ID is required")
+	}
+
+	delivery, err := h.webhookService.SendTest(ctx, endpointID)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "send_webhook_test")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	endpointID := c.Params("id")
+	if endpointID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Endpoint ID is required")
+	}
+
+	var req webhooks.DeliveryListRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errHandler.HandleBadRequest(c, requestID, "Invalid query parameters")
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		return errHandler.HandleValidationError(c, requestID, err, c.Path())
+	}
+
+	result, err := h.webhookService.ListDeliveries(ctx, endpointID, req)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "list_webhook_deliveries")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, result)
+}
+
+func (h *WebhookHandler) GetDelivery(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	deliveryID := c.Params("id")
+	if deliveryID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Delivery ID is required")
+	}
+
+	delivery, err := h.webhookService.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "get_webhook_delivery")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) ReplayDelivery(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.FromFiberContext(c), 10*time.Second)
+	defer cancel()
+
+	errHandler := handlerutil.New(h.logger)
+	requestID := h.getRequestID(c)
+
+	deliveryID := c.Params("id")
+	if deliveryID == "" {
+		return errHandler.HandleBadRequest(c, requestID, "Delivery ID is required")
+	}
+
+	if err := h.webhookService.ReplayDelivery(ctx, deliveryID); err != nil {
+		return h.handleWebhookError(c, errHandler, requestID, err, c.Path(), "replay_webhook_delivery")
+	}
+
+	return errHandler.HandleSuccess(c, fiber.StatusOK, fiber.Map{
+		"message": "Webhook delivery queued for replay",
+	})
+}
+
+func (h *WebhookHandler) getRequestID(c *fiber.Ctx) string {
+	if requestID := c.Locals("request_id"); requestID != nil {
+		if id, ok := requestID.(string); ok {
+			return id
+		}
+	}
+	return c.Get("X-Request-ID", "unknown")
+}
+
+func (h *WebhookHandler) handleWebhookError(c *fiber.Ctx, errHandler *handlerutil.ErrorHandler, requestID string, err error, path, operation string) error {
+	switch {
+	case errors.Is(err, webhooks.ErrEndpointNotFound):
+		return errHandler.HandleNotFound(c, requestID, "Webhook endpoint")
+	case errors.Is(err, webhooks.ErrDeliveryNotFound):
+		return errHandler.HandleNotFound(c, requestID, "Webhook delivery")
+	case errors.Is(err, webhooks.ErrDeliveryNotReplayable):
+		return response.Conflict(c, "Webhook delivery is already queued")
+	case errors.Is(err, webhooks.ErrEndpointInactive):
+		return response.Conflict(c, "Webhook endpoint is inactive")
+	case errors.Is(err, webhooks.ErrUnknownEventType):
+		return errHandler.HandleBadRequest(c, requestID, err.Error())
+	default:
+		return errHandler.Handle(c, requestID, err, path, operation)
+	}
+}
@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"sea-catering-backend/internal/api/webhooks"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/database"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id string) (*entity.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]entity.WebhookEndpoint, error)
+	ListActiveEndpoints(ctx context.Context, eventType string) ([]entity.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*entity.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, endpointID string, req webhooks.DeliveryListRequest) ([]entity.WebhookDelivery, *webhooks.PaginationMeta, error)
+	ClaimDue(ctx context.Context, limit int, lockFor time.Duration) ([]entity.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id string, responseStatus int, responseBody string) error
+	MarkRetry(ctx context.Context, id, lastError string, responseStatus *int, responseBody *string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id, lastError string, responseStatus *int, responseBody *string) error
+	Requeue(ctx context.Context, id string) error
+	PurgeDelivered(ctx context.Context, before time.Time) (int, error)
+}
+
+type webhookRepository struct {
+	db *database.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{db: database.New(db)}
+}
+
+const endpointColumns = `id, url, description, secret, event_types, is_active, created_at, updated_at`
+
+const deliveryColumns = `
+	id, endpoint_id, event_id, event_type, payload, status, attempts, max_attempts,
+	response_status, response_body, last_error, next_attempt_at, locked_until, delivered_at,
+	created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEndpoint(row rowScanner) (*entity.WebhookEndpoint, error) {
+	var endpoint entity.WebhookEndpoint
+	var eventTypes pq.StringArray
+
+	err := row.Scan(
+		&endpoint.ID, &endpoint.URL, &endpoint.Description, &endpoint.Secret, &eventTypes,
+		&endpoint.IsActive, &endpoint.CreatedAt, &endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.EventTypes = []string(eventTypes)
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (id, url, description, secret, event_types, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		endpoint.ID, endpoint.URL, endpoint.Description, endpoint.Secret, pq.Array(endpoint.EventTypes),
+		endpoint.IsActive, endpoint.CreatedAt, endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id string) (*entity.WebhookEndpoint, error) {
+	query := fmt.Sprintf(`SELECT %s FROM webhook_endpoints WHERE id = $1`, endpointColumns)
+
+	endpoint, err := scanEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, webhooks.ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context) ([]entity.WebhookEndpoint, error) {
+	query := fmt.Sprintf(`SELECT %s FROM webhook_endpoints ORDER BY created_at`, endpointColumns)
+	return r.queryEndpoints(ctx, query)
+}
+
+func (r *webhookRepository) ListActiveEndpoints(ctx context.Context, eventType string) ([]entity.WebhookEndpoint, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM webhook_endpoints
+		WHERE is_active AND ($1 = ANY(event_types) OR '*' = ANY(event_types))
+	`, endpointColumns)
+	return r.queryEndpoints(ctx, query, eventType)
+}
+
+func (r *webhookRepository) queryEndpoints(ctx context.Context, query string, args ...interface{}) ([]entity.WebhookEndpoint, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []entity.WebhookEndpoint{}
+	for rows.Next() {
+		endpoint, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, *endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $2, description = $3, secret = $4, event_types = $5, is_active = $6
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		endpoint.ID, endpoint.URL, endpoint.Description, endpoint.Secret, pq.Array(endpoint.EventTypes), endpoint.IsActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	return expectRow(result, webhooks.ErrEndpointNotFound)
+}
+
+// DeleteEndpoint also removes its delivery log (ON DELETE CASCADE).
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	return expectRow(result, webhooks.ErrEndpointNotFound)
+}
+
+func scanDelivery(row rowScanner) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+
+	err := row.Scan(
+		&delivery.ID, &delivery.EndpointID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.MaxAttempts, &delivery.ResponseStatus,
+		&delivery.ResponseBody, &delivery.LastError, &delivery.NextAttemptAt, &delivery.LockedUntil,
+		&delivery.DeliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, endpoint_id, event_id, event_type, payload, status, attempts, max_attempts,
+			next_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for _, d := range deliveries {
+			_, err := r.db.ExecContext(ctx, query,
+				d.ID, d.EndpointID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts, d.MaxAttempts,
+				d.NextAttemptAt, d.CreatedAt, d.UpdatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create webhook delivery: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM webhook_deliveries WHERE id = $1`, deliveryColumns)
+
+	delivery, err := scanDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, webhooks.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries leaves out payloads; they are returned when a single
+// delivery is fetched.
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID string, req webhooks.DeliveryListRequest) ([]entity.WebhookDelivery, *webhooks.PaginationMeta, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	whereClause := "WHERE endpoint_id = $1"
+	args := []interface{}{endpointID}
+	if req.Status != "" {
+		whereClause += " AND status = $2"
+		args = append(args, req.Status)
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM webhook_deliveries %s`, whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	totalPages := (total + req.Limit - 1) / req.Limit
+
+	query := fmt.Sprintf(`
+		SELECT id, endpoint_id, event_id, event_type, '' as payload, status, attempts, max_attempts,
+		       response_status, response_body, last_error, next_attempt_at, locked_until, delivered_at,
+		       created_at, updated_at
+		FROM webhook_deliveries
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+
+	args = append(args, req.Limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []entity.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	meta := &webhooks.PaginationMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    req.Page < totalPages,
+		HasPrev:    req.Page > 1,
+	}
+
+	return deliveries, meta, nil
+}
+
+// ClaimDue works like the email outbox claim: it locks due deliveries for the
+// calling worker and counts the attempt, and SKIP LOCKED keeps workers on
+// other replicas from taking the same delivery.
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lockFor time.Duration) ([]entity.WebhookDelivery, error) {
+	now := time.Now()
+
+	query := fmt.Sprintf(`
+		UPDATE webhook_deliveries
+		SET status = 'sending', attempts = attempts + 1, locked_until = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = 'pending' AND next_attempt_at <= $1)
+			   OR (status = 'sending' AND locked_until < $1)
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, deliveryColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lockFor), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) MarkDelivered(ctx context.Context, id string, responseStatus int, responseBody string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', delivered_at = $2, response_status = $3, response_body = $4,
+		    locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, time.Now(), responseStatus, responseBody); err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) MarkRetry(ctx context.Context, id, lastError string, responseStatus *int, responseBody *string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', last_error = $2, response_status = $3, response_body = $4,
+		    next_attempt_at = $5, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, responseStatus, responseBody, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to schedule webhook retry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) MarkDead(ctx context.Context, id, lastError string, responseStatus *int, responseBody *string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'dead', last_error = $2, response_status = $3, response_body = $4, locked_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, responseStatus, responseBody); err != nil {
+		return fmt.Errorf("failed to dead-letter webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// Requeue resets a delivered or dead delivery so it is sent again with a
+// fresh attempt budget and the original event ID.
+func (r *webhookRepository) Requeue(ctx context.Context, id string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, locked_until = NULL, delivered_at = NULL
+		WHERE id = $1 AND status IN ('delivered', 'dead')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+
+	return expectRow(result, webhooks.ErrDeliveryNotReplayable)
+}
+
+func (r *webhookRepository) PurgeDelivered(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM webhook_deliveries WHERE status = 'delivered' AND delivered_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+func expectRow(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound
+	}
+
+	return nil
+}
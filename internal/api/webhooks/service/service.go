@@ -0,0 +1,612 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sea-catering-backend/internal/api/deliveries"
+	"sea-catering-backend/internal/api/subscriptions"
+	"sea-catering-backend/internal/api/webhooks"
+	"sea-catering-backend/internal/api/webhooks/repository"
+	"sea-catering-backend/internal/entity"
+	"sea-catering-backend/pkg/events"
+	"sea-catering-backend/pkg/jobs"
+	"sea-catering-backend/pkg/logger"
+	"sea-catering-backend/pkg/utils"
+	"sea-catering-backend/pkg/webhook"
+)
+
+// maxResponseBody is how much of an endpoint's reply is kept in the delivery
+// log for debugging.
+const maxResponseBody = 2048
+
+type WebhookService interface {
+	RegisterHandlers(bus events.Interface)
+	Start(ctx context.Context)
+	Stop()
+
+	ListEventTypes() *webhooks.EventTypesResponse
+	CreateEndpoint(ctx context.Context, req webhooks.CreateEndpointRequest) (*webhooks.CreateEndpointResponse, error)
+	ListEndpoints(ctx context.Context) (*webhooks.EndpointListResponse, error)
+	GetEndpoint(ctx context.Context, id string) (*entity.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, id string, req webhooks.UpdateEndpointRequest) (*entity.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	SendTest(ctx context.Context, id string) (*entity.WebhookDelivery, error)
+
+	ListDeliveries(ctx context.Context, endpointID string, req webhooks.DeliveryListRequest) (*webhooks.DeliveryListResponse, error)
+	GetDelivery(ctx context.Context, id string) (*entity.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, id string) error
+}
+
+type Config struct {
+	Workers      int
+	MaxAttempts  int
+	PollInterval time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Timeout bounds a single request to an endpoint. ClaimTimeout must
+	// exceed it.
+	Timeout      time.Duration
+	ClaimTimeout time.Duration
+	// Retention is how long successful deliveries stay in the log.
+	Retention time.Duration
+}
+
+func LoadConfig() *Config {
+	config := &Config{
+		Workers:      2,
+		MaxAttempts:  8,
+		PollInterval: 2 * time.Second,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   6 * time.Hour,
+		Timeout:      10 * time.Second,
+		ClaimTimeout: time.Minute,
+		Retention:    30 * 24 * time.Hour,
+	}
+
+	if workers, err := strconv.Atoi(os.Getenv("WEBHOOK_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+
+	if attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.MaxAttempts = attempts
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT")); err == nil && timeout > 0 {
+		config.Timeout = timeout
+		if config.ClaimTimeout <= timeout {
+			config.ClaimTimeout = 2 * timeout
+		}
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv("WEBHOOK_RETENTION")); err == nil && retention > 0 {
+		config.Retention = retention
+	}
+
+	return config
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	utils       utils.Interface
+	config      *Config
+	client      *http.Client
+	logger      *logger.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	utils utils.Interface,
+	config *Config,
+	logger *logger.Logger,
+) WebhookService {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		utils:       utils,
+		config:      config,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			// A redirect would re-send the signed payload somewhere the
+			// admin never registered.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+		wake:   make(chan struct{}, config.Workers),
+	}
+}
+
+func (s *webhookService) RegisterHandlers(bus events.Interface) {
+	for _, eventType := range webhooks.EventTypes {
+		bus.Subscribe(eventType, s.onEvent)
+	}
+}
+
+// onEvent records one delivery per subscribed endpoint. The workers send
+// them, so a slow partner never holds up the event bus.
+func (s *webhookService) onEvent(ctx context.Context, event events.Event) error {
+	endpoints, err := s.webhookRepo.ListActiveEndpoints(ctx, event.Name)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	data, err := eventData(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.enqueue(ctx, endpoints, event.Name, event.OccurredAt, data)
+	return err
+}
+
+func eventData(event events.Event) (interface{}, error) {
+	switch payload := event.Payload.(type) {
+	case subscriptions.SubscriptionEvent:
+		return webhooks.SubscriptionEventData{
+			SubscriptionID: payload.SubscriptionID,
+			UserID:         payload.UserID,
+			PauseStartDate: payload.PauseStartDate,
+			PauseEndDate:   payload.PauseEndDate,
+			Reason:         payload.Reason,
+			AutoResumed:    payload.AutoResumed,
+		}, nil
+	case deliveries.DeliveryEvent:
+		return webhooks.DeliveryEventData{
+			DeliveryID:    payload.DeliveryID,
+			UserID:        payload.UserID,
+			Status:        string(payload.Status),
+			MealType:      string(payload.MealType),
+			DeliveryDate:  payload.DeliveryDate.Format("2006-01-02"),
+			FailureReason: payload.FailureReason,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected payload %T for %s", event.Payload, event.Name)
+	}
+}
+
+func (s *webhookService) enqueue(ctx context.Context, endpoints []entity.WebhookEndpoint, eventType string, occurredAt time.Time, data interface{}) ([]entity.WebhookDelivery, error) {
+	event := webhooks.Event{
+		ID:        s.utils.GenerateULID(),
+		Type:      eventType,
+		CreatedAt: occurredAt,
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	now := time.Now()
+	queued := make([]entity.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		queued = append(queued, entity.WebhookDelivery{
+			ID:            s.utils.GenerateULID(),
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        entity.WebhookDeliveryPending,
+			MaxAttempts:   s.config.MaxAttempts,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+
+	if err := s.webhookRepo.CreateDeliveries(ctx, queued); err != nil {
+		s.logger.Error("Failed to enqueue webhook deliveries", logger.Fields{
+			"error":      err.Error(),
+			"event_type": eventType,
+			"endpoints":  len(endpoints),
+		})
+		return nil, err
+	}
+
+	s.nudge()
+	return queued, nil
+}
+
+func (s *webhookService) nudge() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go s.runWorker(ctx, i)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		jobs.RunEvery(ctx, time.Hour, s.purgeDelivered)
+	}()
+
+	s.logger.Info("Webhook workers started", logger.Fields{
+		"workers":      s.config.Workers,
+		"max_attempts": s.config.MaxAttempts,
+	})
+}
+
+func (s *webhookService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Webhook workers stopped")
+}
+
+func (s *webhookService) runWorker(ctx context.Context, worker int) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.processNext(ctx, worker) {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *webhookService) processNext(ctx context.Context, worker int) bool {
+	claimed, err := s.webhookRepo.ClaimDue(ctx, 1, s.config.ClaimTimeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("Failed to claim webhook delivery", logger.Fields{
+				"error":  err.Error(),
+				"worker": worker,
+			})
+		}
+		return false
+	}
+
+	if len(claimed) == 0 {
+		return false
+	}
+
+	s.deliver(claimed[0], worker)
+	return true
+}
+
+// deliver records the outcome with a fresh context so a shutdown mid-request
+// does not leave the delivery marked as in flight.
+func (s *webhookService) deliver(delivery entity.WebhookDelivery, worker int) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout+10*time.Second)
+	defer cancel()
+
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		s.logger.Error("Failed to load webhook endpoint", logger.Fields{
+			"error":       err.Error(),
+			"delivery_id": delivery.ID,
+		})
+		return
+	}
+
+	// Deliveries queued before the endpoint was disabled stay in the log
+	// and can be replayed once it is enabled again.
+	if !endpoint.IsActive {
+		if err := s.webhookRepo.MarkDead(ctx, delivery.ID, webhooks.ErrEndpointInactive.Error(), nil, nil); err != nil {
+			s.logger.Error("Failed to dead-letter webhook delivery", logger.Fields{
+				"error":       err.Error(),
+				"delivery_id": delivery.ID,
+			})
+		}
+		return
+	}
+
+	status, body, sendErr := s.send(ctx, endpoint, delivery)
+	if sendErr == nil {
+		if err := s.webhookRepo.MarkDelivered(ctx, delivery.ID, *status, *body); err != nil {
+			s.logger.Error("Failed to mark webhook delivered", logger.Fields{
+				"error":       err.Error(),
+				"delivery_id": delivery.ID,
+			})
+		}
+		return
+	}
+
+	fields := logger.Fields{
+		"error":       sendErr.Error(),
+		"delivery_id": delivery.ID,
+		"endpoint_id": endpoint.ID,
+		"event_type":  delivery.EventType,
+		"attempt":     delivery.Attempts,
+		"worker":      worker,
+	}
+
+	if delivery.Attempts >= delivery.MaxAttempts {
+		s.logger.Error("Webhook delivery gave up after final attempt", fields)
+		if err := s.webhookRepo.MarkDead(ctx, delivery.ID, sendErr.Error(), status, body); err != nil {
+			s.logger.Error("Failed to dead-letter webhook delivery", logger.Fields{
+				"error":       err.Error(),
+				"delivery_id": delivery.ID,
+			})
+		}
+		return
+	}
+
+	// With the default backoff, eight attempts span roughly two hours.
+	nextAttemptAt := time.Now().Add(jobs.Backoff(s.config.BaseBackoff, s.config.MaxBackoff, delivery.Attempts))
+	fields["next_attempt_at"] = nextAttemptAt
+	s.logger.Warn("Webhook delivery failed, retry scheduled", fields)
+
+	if err := s.webhookRepo.MarkRetry(ctx, delivery.ID, sendErr.Error(), status, body, nextAttemptAt); err != nil {
+		s.logger.Error("Failed to schedule webhook retry", logger.Fields{
+			"error":       err.Error(),
+			"delivery_id": delivery.ID,
+		})
+	}
+}
+
+// send POSTs the payload and treats any 2xx reply as delivered. The status
+// and body are returned whenever the endpoint answered, for the log.
+func (s *webhookService) send(ctx context.Context, endpoint *entity.WebhookEndpoint, delivery entity.WebhookDelivery) (*int, *string, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SEA-Catering-Webhooks/1.0")
+	req.Header.Set(webhook.HeaderEventID, delivery.EventID)
+	req.Header.Set(webhook.HeaderEventType, delivery.EventType)
+	req.Header.Set(webhook.HeaderDelivery, delivery.ID)
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	status, responseBody := resp.StatusCode, strings.ToValidUTF8(string(reply), "")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &status, &responseBody, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return &status, &responseBody, nil
+}
+
+func (s *webhookService) purgeDelivered(ctx context.Context) {
+	purged, err := s.webhookRepo.PurgeDelivered(ctx, time.Now().Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Error("Failed to purge webhook deliveries", logger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged webhook deliveries", logger.Fields{
+			"count": purged,
+		})
+	}
+}
+
+func (s *webhookService) ListEventTypes() *webhooks.EventTypesResponse {
+	return &webhooks.EventTypesResponse{EventTypes: webhooks.EventTypes}
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, req webhooks.CreateEndpointRequest) (*webhooks.CreateEndpointResponse, error) {
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = "whsec_" + s.utils.GenerateSecureToken(32)
+	}
+
+	now := time.Now()
+	endpoint := &entity.WebhookEndpoint{
+		ID:          s.utils.GenerateULID(),
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  req.EventTypes,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		s.logger.Error("Failed to create webhook endpoint", logger.Fields{
+			"error": err.Error(),
+			"url":   req.URL,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Webhook endpoint created", logger.Fields{
+		"endpoint_id": endpoint.ID,
+		"url":         endpoint.URL,
+		"event_types": endpoint.EventTypes,
+	})
+
+	return &webhooks.CreateEndpointResponse{Endpoint: endpoint, Secret: secret}, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context) (*webhooks.EndpointListResponse, error) {
+	endpoints, err := s.webhookRepo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhooks.EndpointListResponse{Endpoints: endpoints}, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, id string) (*entity.WebhookEndpoint, error) {
+	return s.webhookRepo.GetEndpoint(ctx, id)
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, id string, req webhooks.UpdateEndpointRequest) (*entity.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.Secret != nil {
+		endpoint.Secret = *req.Secret
+	}
+	if req.EventTypes != nil {
+		if err := validateEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = req.EventTypes
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := s.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Webhook endpoint updated", logger.Fields{
+		"endpoint_id":    id,
+		"secret_rotated": req.Secret != nil,
+	})
+
+	return s.webhookRepo.GetEndpoint(ctx, id)
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	if err := s.webhookRepo.DeleteEndpoint(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("Webhook endpoint deleted", logger.Fields{
+		"endpoint_id": id,
+	})
+
+	return nil
+}
+
+// SendTest queues a webhook.test event to one endpoint so a partner can
+// check their receiver and signature verification.
+func (s *webhookService) SendTest(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !endpoint.IsActive {
+		return nil, webhooks.ErrEndpointInactive
+	}
+
+	queued, err := s.enqueue(ctx, []entity.WebhookEndpoint{*endpoint}, webhooks.EventTest, time.Now(), webhooks.TestEventData{
+		EndpointID: endpoint.ID,
+		Message:    "Test event from SEA Catering",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &queued[0], nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, endpointID string, req webhooks.DeliveryListRequest) (*webhooks.DeliveryListResponse, error) {
+	if _, err := s.webhookRepo.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+
+	list, meta, err := s.webhookRepo.ListDeliveries(ctx, endpointID, req)
+	if err != nil {
+		s.logger.Error("Failed to list webhook deliveries", logger.Fields{
+			"error":       err.Error(),
+			"endpoint_id": endpointID,
+		})
+		return nil, err
+	}
+
+	return &webhooks.DeliveryListResponse{Deliveries: list, Meta: meta}, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	return s.webhookRepo.GetDelivery(ctx, id)
+}
+
+// ReplayDelivery sends a finished delivery again, with the same event ID so
+// receivers that already processed it can recognise the duplicate.
+func (s *webhookService) ReplayDelivery(ctx context.Context, id string) error {
+	delivery, err := s.webhookRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !delivery.CanReplay() {
+		return webhooks.ErrDeliveryNotReplayable
+	}
+
+	if err := s.webhookRepo.Requeue(ctx, id); err != nil {
+		return err
+	}
+
+	s.nudge()
+
+	s.logger.Info("Webhook delivery replayed", logger.Fields{
+		"delivery_id":     id,
+		"endpoint_id":     delivery.EndpointID,
+		"previous_status": delivery.Status,
+	})
+
+	return nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !webhooks.IsEventType(eventType) {
+			return fmt.Errorf("%w: %s", webhooks.ErrUnknownEventType, eventType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+type DataRequestType string
+
+const (
+	DataRequestExport  DataRequestType = "export"
+	DataRequestErasure DataRequestType = "erasure"
+)
+
+type DataRequestStatus string
+
+const (
+	DataRequestPending   DataRequestStatus = "pending"
+	DataRequestRunning   DataRequestStatus = "running"
+	DataRequestCompleted DataRequestStatus = "completed"
+	DataRequestFailed    DataRequestStatus = "failed"
+	DataRequestCancelled DataRequestStatus = "cancelled"
+)
+
+// DataRequest is a user's request for a copy of their personal data or for
+// their account to be erased. Both run in the background once ScheduledFor
+// has passed.
+type DataRequest struct {
+	ID            string            `db:"id" json:"id"`
+	UserID        *string           `db:"user_id" json:"user_id,omitempty"`
+	Type          DataRequestType   `db:"type" json:"type"`
+	Status        DataRequestStatus `db:"status" json:"status"`
+	Reason        *string           `db:"reason" json:"reason,omitempty"`
+	UserWasActive *bool             `db:"user_was_active" json:"user_was_active,omitempty"`
+	Attempts      int               `db:"attempts" json:"attempts"`
+	FileKey       *string           `db:"file_key" json:"-"`
+	LastError     *string           `db:"last_error" json:"last_error,omitempty"`
+	ScheduledFor  time.Time         `db:"scheduled_for" json:"scheduled_for"`
+	LockedUntil   *time.Time        `db:"locked_until" json:"-"`
+	StartedAt     *time.Time        `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt   *time.Time        `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt     *time.Time        `db:"expires_at" json:"expires_at,omitempty"`
+	CancelledBy   *string           `db:"cancelled_by" json:"cancelled_by,omitempty"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+}
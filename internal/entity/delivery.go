@@ -0,0 +1,79 @@
+package entity
+
+import "time"
+
+type DeliveryStatus string
+
+const (
+	DeliveryScheduled      DeliveryStatus = "scheduled"
+	DeliveryOutForDelivery DeliveryStatus = "out_for_delivery"
+	DeliveryDelivered      DeliveryStatus = "delivered"
+	DeliveryFailed         DeliveryStatus = "failed"
+)
+
+type Delivery struct {
+	ID               string         `db:"id" json:"id"`
+	SubscriptionID   string         `db:"subscription_id" json:"subscription_id"`
+	UserID           string         `db:"user_id" json:"user_id"`
+	DeliveryDate     time.Time      `db:"delivery_date" json:"delivery_date"`
+	MealType         MealType       `db:"meal_type" json:"meal_type"`
+	DeliveryAddress  string         `db:"delivery_address" json:"delivery_address"`
+	Latitude         *float64       `db:"latitude" json:"latitude,omitempty"`
+	Longitude        *float64       `db:"longitude" json:"longitude,omitempty"`
+	Zone             string         `db:"zone" json:"zone,omitempty"`
+	CourierID        *string        `db:"courier_id" json:"courier_id,omitempty"`
+	Status           DeliveryStatus `db:"status" json:"status"`
+	ProofPhotoURL    *string        `db:"proof_photo_url" json:"proof_photo_url,omitempty"`
+	FailureReason    *string        `db:"failure_reason" json:"failure_reason,omitempty"`
+	AssignedAt       *time.Time     `db:"assigned_at" json:"assigned_at,omitempty"`
+	OutForDeliveryAt *time.Time     `db:"out_for_delivery_at" json:"out_for_delivery_at,omitempty"`
+	DeliveredAt      *time.Time     `db:"delivered_at" json:"delivered_at,omitempty"`
+	FailedAt         *time.Time     `db:"failed_at" json:"failed_at,omitempty"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+type DeliveryWithDetails struct {
+	Delivery
+	CustomerName  string  `db:"customer_name" json:"customer_name"`
+	CustomerPhone *string `db:"customer_phone" json:"customer_phone,omitempty"`
+	MealPlanName  string  `db:"meal_plan_name" json:"meal_plan_name"`
+	Allergies     string  `db:"allergies" json:"allergies,omitempty"`
+	CourierName   *string `db:"courier_name" json:"courier_name,omitempty"`
+}
+
+// DeliveryWindow returns the drop-off time window for a meal type.
+func (m MealType) DeliveryWindow() string {
+	switch m {
+	case MealTypeBreakfast:
+		return "06:00-09:00"
+	case MealTypeLunch:
+		return "11:00-13:00"
+	default:
+		return "17:00-19:00"
+	}
+}
+
+func (d *Delivery) HasCoordinates() bool {
+	return d.Latitude != nil && d.Longitude != nil
+}
+
+func (d *Delivery) IsCompleted() bool {
+	return d.Status == DeliveryDelivered || d.Status == DeliveryFailed
+}
+
+// CanTransitionTo reports whether a courier may move the delivery to next.
+// Deliveries are picked up before they are dropped off, but a courier can
+// report a failure before leaving (e.g. the kitchen could not fulfil it).
+func (d *Delivery) CanTransitionTo(next DeliveryStatus) bool {
+	switch next {
+	case DeliveryOutForDelivery:
+		return d.Status == DeliveryScheduled
+	case DeliveryDelivered:
+		return d.Status == DeliveryOutForDelivery
+	case DeliveryFailed:
+		return d.Status == DeliveryScheduled || d.Status == DeliveryOutForDelivery
+	default:
+		return false
+	}
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSending OutboxStatus = "sending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+)
+
+type OutboxEmail struct {
+	ID             string       `db:"id" json:"id"`
+	Recipients     []string     `db:"recipients" json:"recipients"`
+	Subject        string       `db:"subject" json:"subject"`
+	Body           string       `db:"body" json:"body,omitempty"`
+	TextBody       *string      `db:"text_body" json:"text_body,omitempty"`
+	UnsubscribeURL *string      `db:"unsubscribe_url" json:"-"`
+	IsHTML         bool         `db:"is_html" json:"is_html"`
+	Status         OutboxStatus `db:"status" json:"status"`
+	Attempts       int          `db:"attempts" json:"attempts"`
+	MaxAttempts    int          `db:"max_attempts" json:"max_attempts"`
+	LastError      *string      `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time    `db:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil    *time.Time   `db:"locked_until" json:"locked_until,omitempty"`
+	SentAt         *time.Time   `db:"sent_at" json:"sent_at,omitempty"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// CanResend reports whether an admin may put the email back in the queue.
+// Emails still waiting or in flight are already going to be tried.
+func (e *OutboxEmail) CanResend() bool {
+	return e.Status == OutboxStatusDead || e.Status == OutboxStatusSent
+}
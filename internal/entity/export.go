@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ExportDataset string
+
+const (
+	ExportUsers         ExportDataset = "users"
+	ExportSubscriptions ExportDataset = "subscriptions"
+	ExportTestimonials  ExportDataset = "testimonials"
+	ExportRevenue       ExportDataset = "revenue"
+)
+
+type ExportJobStatus string
+
+const (
+	ExportJobPending   ExportJobStatus = "pending"
+	ExportJobRunning   ExportJobStatus = "running"
+	ExportJobCompleted ExportJobStatus = "completed"
+	ExportJobFailed    ExportJobStatus = "failed"
+)
+
+// ExportJob is an export built in the background. The finished file is kept
+// in S3 until ExpiresAt.
+type ExportJob struct {
+	ID          string          `db:"id" json:"id"`
+	Dataset     ExportDataset   `db:"dataset" json:"dataset"`
+	Format      string          `db:"format" json:"format"`
+	Filters     json.RawMessage `db:"filters" json:"filters"`
+	Status      ExportJobStatus `db:"status" json:"status"`
+	RequestedBy *string         `db:"requested_by" json:"requested_by,omitempty"`
+	Attempts    int             `db:"attempts" json:"attempts"`
+	RowCount    *int            `db:"row_count" json:"row_count,omitempty"`
+	FileKey     *string         `db:"file_key" json:"-"`
+	LastError   *string         `db:"last_error" json:"last_error,omitempty"`
+	LockedUntil *time.Time      `db:"locked_until" json:"-"`
+	StartedAt   *time.Time      `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt *time.Time      `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time      `db:"expires_at" json:"expires_at,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+type GiftStatus string
+
+// A gift waits in pending_payment until its payment is confirmed; only then
+// is the code issued and emailed to the recipient.
+const (
+	GiftStatusPendingPayment GiftStatus = "pending_payment"
+	GiftStatusIssued         GiftStatus = "issued"
+	GiftStatusRedeemed       GiftStatus = "redeemed"
+	GiftStatusExpired        GiftStatus = "expired"
+	GiftStatusCancelled      GiftStatus = "cancelled"
+)
+
+type GiftSubscription struct {
+	ID               string        `db:"id" json:"id"`
+	Code             string        `db:"code" json:"code"`
+	BuyerUserID      string        `db:"buyer_user_id" json:"buyer_user_id"`
+	RecipientName    string        `db:"recipient_name" json:"recipient_name"`
+	RecipientEmail   string        `db:"recipient_email" json:"recipient_email"`
+	Message          string        `db:"message" json:"message,omitempty"`
+	MealPlanID       string        `db:"meal_plan_id" json:"meal_plan_id"`
+	MealTypes        []MealType    `db:"meal_types" json:"meal_types"`
+	DeliveryDays     []DeliveryDay `db:"delivery_days" json:"delivery_days"`
+	DurationMonths   int           `db:"duration_months" json:"duration_months"`
+	TotalPrice       float64       `db:"total_price" json:"total_price"`
+	Status           GiftStatus    `db:"status" json:"status"`
+	ExpiresAt        time.Time     `db:"expires_at" json:"expires_at"`
+	RedeemedByUserID *string       `db:"redeemed_by_user_id" json:"redeemed_by_user_id,omitempty"`
+	SubscriptionID   *string       `db:"subscription_id" json:"subscription_id,omitempty"`
+	RedeemedAt       *time.Time    `db:"redeemed_at" json:"redeemed_at,omitempty"`
+	LastSentAt       *time.Time    `db:"last_sent_at" json:"last_sent_at,omitempty"`
+	SendCount        int           `db:"send_count" json:"send_count"`
+	CreatedAt        time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+type GiftSubscriptionWithDetails struct {
+	GiftSubscription
+	MealPlan   MealPlan `json:"meal_plan"`
+	BuyerName  string   `json:"buyer_name"`
+	BuyerEmail string   `json:"buyer_email"`
+}
+
+func (g *GiftSubscription) IsExpired() bool {
+	return g.Status == GiftStatusExpired ||
+		(g.Status == GiftStatusIssued && g.ExpiresAt.Before(time.Now()))
+}
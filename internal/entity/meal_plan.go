@@ -10,6 +10,7 @@ type MealPlan struct {
 	ImageURL    string    `db:"image_url" json:"image_url,omitempty"`
 	Features    []string  `db:"features" json:"features"`
 	IsActive    bool      `db:"is_active" json:"is_active"`
+	Version     int       `db:"version" json:"version,omitempty"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type MetricsSource string
+
+const (
+	// MetricsSnapshot rows were recorded from live data at the end of the
+	// day. MetricsBackfill rows were reconstructed later from the
+	// subscription audit log and are only as complete as that log.
+	MetricsSnapshot MetricsSource = "snapshot"
+	MetricsBackfill MetricsSource = "backfill"
+)
+
+// DailyMetrics holds subscription and revenue figures for one day. Counts
+// of active, paused and cancelled subscriptions and MRR describe the end of
+// the day; new, churned and reactivated count what happened during it.
+type DailyMetrics struct {
+	Date                   time.Time     `db:"metric_date" json:"date"`
+	MRR                    float64       `db:"mrr" json:"mrr"`
+	ActiveSubscriptions    int           `db:"active_subscriptions" json:"active_subscriptions"`
+	PausedSubscriptions    int           `db:"paused_subscriptions" json:"paused_subscriptions"`
+	CancelledSubscriptions int           `db:"cancelled_subscriptions" json:"cancelled_subscriptions"`
+	NewSubscriptions       int           `db:"new_subscriptions" json:"new_subscriptions"`
+	ChurnedSubscriptions   int           `db:"churned_subscriptions" json:"churned_subscriptions"`
+	Reactivations          int           `db:"reactivations" json:"reactivations"`
+	RevenueByPlan          []PlanRevenue `db:"revenue_by_plan" json:"revenue_by_plan"`
+	Source                 MetricsSource `db:"source" json:"source"`
+	ComputedAt             time.Time     `db:"computed_at" json:"computed_at"`
+}
+
+type PlanRevenue struct {
+	MealPlanID          string  `json:"meal_plan_id"`
+	MealPlanName        string  `json:"meal_plan_name"`
+	ActiveSubscriptions int     `json:"active_subscriptions"`
+	MRR                 float64 `json:"mrr"`
+}
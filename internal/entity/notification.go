@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+type NotificationChannel string
+type NotificationCategory string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelInApp NotificationChannel = "in_app"
+)
+
+const (
+	CategoryTransactional   NotificationCategory = "transactional"
+	CategoryDeliveryUpdates NotificationCategory = "delivery_updates"
+	CategoryMarketing       NotificationCategory = "marketing"
+	CategoryMenuOfTheWeek   NotificationCategory = "menu_of_the_week"
+)
+
+var NotificationChannels = []NotificationChannel{ChannelEmail, ChannelSMS, ChannelInApp}
+
+var NotificationCategories = []NotificationCategory{
+	CategoryTransactional, CategoryDeliveryUpdates, CategoryMarketing, CategoryMenuOfTheWeek,
+}
+
+type NotificationPreference struct {
+	UserID    string               `db:"user_id" json:"-"`
+	Channel   NotificationChannel  `db:"channel" json:"channel"`
+	Category  NotificationCategory `db:"category" json:"category"`
+	Enabled   bool                 `db:"enabled" json:"enabled"`
+	UpdatedAt time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+// IsOptional reports whether customers may switch the category off.
+// Transactional messages (receipts, OTPs, account changes) always go out.
+func (c NotificationCategory) IsOptional() bool {
+	return c != CategoryTransactional
+}
+
+// DefaultEnabled is the setting used until the customer chooses. Promotional
+// categories need explicit consent, so they start switched off.
+func (c NotificationCategory) DefaultEnabled() bool {
+	switch c {
+	case CategoryTransactional, CategoryDeliveryUpdates:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification is a message in a user's in-app inbox.
+type Notification struct {
+	ID            string               `db:"id" json:"id"`
+	UserID        string               `db:"user_id" json:"-"`
+	Category      NotificationCategory `db:"category" json:"category"`
+	Type          string               `db:"type" json:"type"`
+	Title         string               `db:"title" json:"title"`
+	Body          string               `db:"body" json:"body"`
+	ReferenceType *string              `db:"reference_type" json:"reference_type,omitempty"`
+	ReferenceID   *string              `db:"reference_id" json:"reference_id,omitempty"`
+	ReadAt        *time.Time           `db:"read_at" json:"read_at,omitempty"`
+	CreatedAt     time.Time            `db:"created_at" json:"created_at"`
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+type OrganizationMemberRole string
+type OrganizationMemberStatus string
+type OrganizationInvoiceStatus string
+
+const (
+	OrgRoleAdmin  OrganizationMemberRole = "org_admin"
+	OrgRoleMember OrganizationMemberRole = "member"
+)
+
+const (
+	OrgMemberInvited OrganizationMemberStatus = "invited"
+	OrgMemberActive  OrganizationMemberStatus = "active"
+	OrgMemberRemoved OrganizationMemberStatus = "removed"
+)
+
+const (
+	InvoiceStatusDraft  OrganizationInvoiceStatus = "draft"
+	InvoiceStatusIssued OrganizationInvoiceStatus = "issued"
+	InvoiceStatusPaid   OrganizationInvoiceStatus = "paid"
+	InvoiceStatusVoid   OrganizationInvoiceStatus = "void"
+)
+
+type Organization struct {
+	ID                      string    `db:"id" json:"id"`
+	Name                    string    `db:"name" json:"name"`
+	BillingEmail            string    `db:"billing_email" json:"billing_email"`
+	OwnerUserID             string    `db:"owner_user_id" json:"owner_user_id"`
+	DefaultMonthlyAllowance *float64  `db:"default_monthly_allowance" json:"default_monthly_allowance,omitempty"`
+	IsActive                bool      `db:"is_active" json:"is_active"`
+	CreatedAt               time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	ID               string                   `db:"id" json:"id"`
+	OrganizationID   string                   `db:"organization_id" json:"organization_id"`
+	UserID           *string                  `db:"user_id" json:"user_id,omitempty"`
+	Email            string                   `db:"email" json:"email"`
+	Role             OrganizationMemberRole   `db:"role" json:"role"`
+	Status           OrganizationMemberStatus `db:"status" json:"status"`
+	MonthlyAllowance *float64                 `db:"monthly_allowance" json:"monthly_allowance,omitempty"`
+	InviteTokenHash  *string                  `db:"invite_token_hash" json:"-"`
+	InviteExpiresAt  *time.Time               `db:"invite_expires_at" json:"invite_expires_at,omitempty"`
+	InvitedBy        *string                  `db:"invited_by" json:"invited_by,omitempty"`
+	JoinedAt         *time.Time               `db:"joined_at" json:"joined_at,omitempty"`
+	CreatedAt        time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time                `db:"updated_at" json:"updated_at"`
+}
+
+// EffectiveAllowance returns the member's monthly budget, falling back to the
+// organization default. A nil result means the member has no spending limit.
+func (m *OrganizationMember) EffectiveAllowance(org *Organization) *float64 {
+	if m.MonthlyAllowance != nil {
+		return m.MonthlyAllowance
+	}
+	return org.DefaultMonthlyAllowance
+}
+
+func (m *OrganizationMember) IsAdmin() bool {
+	return m.Role == OrgRoleAdmin && m.Status == OrgMemberActive
+}
+
+type OrganizationInvoice struct {
+	ID                string                    `db:"id" json:"id"`
+	OrganizationID    string                    `db:"organization_id" json:"organization_id"`
+	PeriodStart       time.Time                 `db:"period_start" json:"period_start"`
+	PeriodEnd         time.Time                 `db:"period_end" json:"period_end"`
+	SubscriptionCount int                       `db:"subscription_count" json:"subscription_count"`
+	TotalAmount       float64                   `db:"total_amount" json:"total_amount"`
+	Status            OrganizationInvoiceStatus `db:"status" json:"status"`
+	LineItems         []InvoiceLineItem         `db:"line_items" json:"line_items"`
+	IssuedAt          *time.Time                `db:"issued_at" json:"issued_at,omitempty"`
+	CreatedAt         time.Time                 `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                 `db:"updated_at" json:"updated_at"`
+}
+
+type InvoiceLineItem struct {
+	SubscriptionID string  `json:"subscription_id"`
+	UserID         string  `json:"user_id"`
+	MemberName     string  `json:"member_name"`
+	MemberEmail    string  `json:"member_email"`
+	MealPlanName   string  `json:"meal_plan_name"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+}
@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type MealType string
 type DeliveryDay string
@@ -22,25 +25,101 @@ const (
 	DaySunday    DeliveryDay = "sunday"
 )
 
+// Transitions between statuses are defined by the state machine in the
+// subscriptions package.
 const (
 	StatusActive    SubscriptionStatus = "active"
 	StatusPaused    SubscriptionStatus = "paused"
 	StatusCancelled SubscriptionStatus = "cancelled"
+	StatusExpired   SubscriptionStatus = "expired"
+)
+
+// IsClosed reports whether the subscription has been left, by cancelling it
+// or by reaching the end of a fixed term.
+func (s SubscriptionStatus) IsClosed() bool {
+	return s == StatusCancelled || s == StatusExpired
+}
+
+type CancellationReason string
+
+const (
+	CancellationPrice    CancellationReason = "price"
+	CancellationTaste    CancellationReason = "taste"
+	CancellationDelivery CancellationReason = "delivery"
+	CancellationMoving   CancellationReason = "moving"
+	CancellationHealth   CancellationReason = "health"
+	CancellationVariety  CancellationReason = "variety"
+	CancellationSchedule CancellationReason = "schedule"
+	CancellationOther    CancellationReason = "other"
+)
+
+// PauseOffer is the customer's answer when offered a pause instead of
+// cancelling.
+type PauseOffer string
+
+const (
+	PauseOfferAccepted PauseOffer = "accepted"
+	PauseOfferDeclined PauseOffer = "declined"
 )
 
+// CancellationFeedback is the exit survey, stored on the audit entry of the
+// cancellation, or of the pause when the customer took the offer.
+type CancellationFeedback struct {
+	Reason     CancellationReason
+	Feedback   string
+	PauseOffer *PauseOffer
+}
+
 type Subscription struct {
-	ID             string             `db:"id" json:"id"`
-	UserID         string             `db:"user_id" json:"user_id"`
-	MealPlanID     string             `db:"meal_plan_id" json:"meal_plan_id"`
-	MealTypes      []MealType         `db:"meal_types" json:"meal_types"`
-	DeliveryDays   []DeliveryDay      `db:"delivery_days" json:"delivery_days"`
-	Allergies      string             `db:"allergies" json:"allergies,omitempty"`
-	TotalPrice     float64            `db:"total_price" json:"total_price"`
-	Status         SubscriptionStatus `db:"status" json:"status"`
-	PauseStartDate *time.Time         `db:"pause_start_date" json:"pause_start_date,omitempty"`
-	PauseEndDate   *time.Time         `db:"pause_end_date" json:"pause_end_date,omitempty"`
-	CreatedAt      time.Time          `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time          `db:"updated_at" json:"updated_at"`
+	ID                string             `db:"id" json:"id"`
+	UserID            string             `db:"user_id" json:"user_id"`
+	MealPlanID        string             `db:"meal_plan_id" json:"meal_plan_id"`
+	MealTypes         []MealType         `db:"meal_types" json:"meal_types"`
+	DeliveryDays      []DeliveryDay      `db:"delivery_days" json:"delivery_days"`
+	Allergies         string             `db:"allergies" json:"allergies,omitempty"`
+	TotalPrice        float64            `db:"total_price" json:"total_price"`
+	Status            SubscriptionStatus `db:"status" json:"status"`
+	PauseStartDate    *time.Time         `db:"pause_start_date" json:"pause_start_date,omitempty"`
+	PauseEndDate      *time.Time         `db:"pause_end_date" json:"pause_end_date,omitempty"`
+	DeliveryAddress   string             `db:"delivery_address" json:"delivery_address,omitempty"`
+	GiftID            *string            `db:"gift_id" json:"gift_id,omitempty"`
+	EndsAt            *time.Time         `db:"ends_at" json:"ends_at,omitempty"`
+	OrganizationID    *string            `db:"organization_id" json:"organization_id,omitempty"`
+	DeliveryLatitude  *float64           `db:"delivery_latitude" json:"delivery_latitude,omitempty"`
+	DeliveryLongitude *float64           `db:"delivery_longitude" json:"delivery_longitude,omitempty"`
+	DeliveryZone      string             `db:"delivery_zone" json:"delivery_zone,omitempty"`
+	Version           int                `db:"version" json:"version"`
+	CreatedAt         time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+func (s *Subscription) IsGift() bool {
+	return s.GiftID != nil
+}
+
+func (s *Subscription) IsOrganizationBilled() bool {
+	return s.OrganizationID != nil
+}
+
+func (s *Subscription) HasEnded() bool {
+	return s.EndsAt != nil && s.EndsAt.Before(time.Now())
+}
+
+// NextDeliveryAfter returns the first scheduled delivery day after from.
+func (s *Subscription) NextDeliveryAfter(from time.Time) time.Time {
+	days := make(map[string]bool, len(s.DeliveryDays))
+	for _, day := range s.DeliveryDays {
+		days[string(day)] = true
+	}
+
+	for i := 1; i <= 7; i++ {
+		candidate := from.AddDate(0, 0, i)
+		if days[strings.ToLower(candidate.Weekday().String())] {
+			return candidate
+		}
+	}
+
+	return from.AddDate(0, 0, 1)
 }
 
 type SubscriptionWithDetails struct {
@@ -2,12 +2,107 @@ package entity
 
 import "time"
 
+type TestimonialStatus string
+
+const (
+	TestimonialPending  TestimonialStatus = "pending"
+	TestimonialApproved TestimonialStatus = "approved"
+	TestimonialRejected TestimonialStatus = "rejected"
+	TestimonialHidden   TestimonialStatus = "hidden"
+	TestimonialFlagged  TestimonialStatus = "flagged"
+)
+
+// testimonialTransitions lists the moderator decisions allowed from each
+// state. Flagged reviews are escalated for a second look; hidden ones were
+// published and later taken down. An author edit sends a review of any
+// state back to pending, outside this table.
+var testimonialTransitions = map[TestimonialStatus][]TestimonialStatus{
+	TestimonialPending:  {TestimonialApproved, TestimonialRejected, TestimonialFlagged},
+	TestimonialFlagged:  {TestimonialApproved, TestimonialRejected, TestimonialHidden},
+	TestimonialApproved: {TestimonialHidden, TestimonialFlagged},
+	TestimonialHidden:   {TestimonialApproved, TestimonialRejected},
+	TestimonialRejected: {},
+}
+
+func (s TestimonialStatus) IsValid() bool {
+	_, ok := testimonialTransitions[s]
+	return ok
+}
+
+func (s TestimonialStatus) CanTransitionTo(next TestimonialStatus) bool {
+	for _, allowed := range testimonialTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AwaitingReview reports whether the status belongs in the moderation queue.
+func (s TestimonialStatus) AwaitingReview() bool {
+	return s == TestimonialPending || s == TestimonialFlagged
+}
+
+type RejectionReason string
+
+const (
+	RejectionSpam         RejectionReason = "spam"
+	RejectionOffensive    RejectionReason = "offensive"
+	RejectionOffTopic     RejectionReason = "off_topic"
+	RejectionPersonalInfo RejectionReason = "personal_info"
+	RejectionNotGenuine   RejectionReason = "not_genuine"
+	RejectionOther        RejectionReason = "other"
+)
+
 type Testimonial struct {
-	ID           string    `db:"id" json:"id"`
-	CustomerName string    `db:"customer_name" json:"customer_name"`
-	Message      string    `db:"message" json:"message"`
-	Rating       int       `db:"rating" json:"rating"`
-	IsApproved   bool      `db:"is_approved" json:"is_approved"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID              string            `db:"id" json:"id"`
+	UserID          *string           `db:"user_id" json:"user_id,omitempty"`
+	MealPlanID      *string           `db:"meal_plan_id" json:"meal_plan_id,omitempty"`
+	SubscriptionID  *string           `db:"subscription_id" json:"subscription_id,omitempty"`
+	CustomerName    string            `db:"customer_name" json:"customer_name"`
+	Message         string            `db:"message" json:"message"`
+	Rating          int               `db:"rating" json:"rating"`
+	IsVerified      bool              `db:"is_verified" json:"is_verified"`
+	Status          TestimonialStatus `db:"status" json:"status"`
+	RejectionReason *RejectionReason  `db:"rejection_reason" json:"rejection_reason,omitempty"`
+	ModerationNote  *string           `db:"moderation_note" json:"moderation_note,omitempty"`
+	ModeratedBy     *string           `db:"moderated_by" json:"moderated_by,omitempty"`
+	ModeratedAt     *time.Time        `db:"moderated_at" json:"moderated_at,omitempty"`
+	// SpamScore and ScreeningFindings come from automatic screening on
+	// submission and on every edit.
+	SpamScore         int                `db:"spam_score" json:"spam_score"`
+	ScreeningFindings []ScreeningFinding `db:"screening_findings" json:"screening_findings"`
+	ContentHash       *string            `db:"content_hash" json:"-"`
+	CreatedAt         time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+// IsOwnedBy reports whether userID wrote the testimonial. Reviews submitted
+// before accounts were required have no owner.
+func (t *Testimonial) IsOwnedBy(userID string) bool {
+	return t.UserID != nil && *t.UserID == userID
+}
+
+func (t *Testimonial) IsApproved() bool {
+	return t.Status == TestimonialApproved
+}
+
+// ScreeningFinding explains part of a testimonial's spam score.
+type ScreeningFinding struct {
+	Check  string `json:"check"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// TestimonialModerationEntry records one status change. ModeratorID is nil
+// when the author's edit or automatic screening changed the status.
+type TestimonialModerationEntry struct {
+	ID            string            `db:"id" json:"id"`
+	TestimonialID string            `db:"testimonial_id" json:"testimonial_id"`
+	FromStatus    TestimonialStatus `db:"from_status" json:"from_status"`
+	ToStatus      TestimonialStatus `db:"to_status" json:"to_status"`
+	Reason        *RejectionReason  `db:"reason" json:"reason,omitempty"`
+	Note          *string           `db:"note" json:"note,omitempty"`
+	ModeratorID   *string           `db:"moderator_id" json:"moderator_id,omitempty"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
 }
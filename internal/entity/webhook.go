@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySending   WebhookDeliveryStatus = "sending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead"
+)
+
+// WebhookEndpoint is a partner URL that receives the event types it is
+// subscribed to. The secret signs every request and is only shown when the
+// endpoint is created.
+type WebhookEndpoint struct {
+	ID          string    `db:"id" json:"id"`
+	URL         string    `db:"url" json:"url"`
+	Description string    `db:"description" json:"description,omitempty"`
+	Secret      string    `db:"secret" json:"-"`
+	EventTypes  []string  `db:"event_types" json:"event_types"`
+	IsActive    bool      `db:"is_active" json:"is_active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	for _, t := range e.EventTypes {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent, or waiting to be sent, to one endpoint.
+type WebhookDelivery struct {
+	ID             string                `db:"id" json:"id"`
+	EndpointID     string                `db:"endpoint_id" json:"endpoint_id"`
+	EventID        string                `db:"event_id" json:"event_id"`
+	EventType      string                `db:"event_type" json:"event_type"`
+	Payload        string                `db:"payload" json:"payload,omitempty"`
+	Status         WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts       int                   `db:"attempts" json:"attempts"`
+	MaxAttempts    int                   `db:"max_attempts" json:"max_attempts"`
+	ResponseStatus *int                  `db:"response_status" json:"response_status,omitempty"`
+	ResponseBody   *string               `db:"response_body" json:"response_body,omitempty"`
+	LastError      *string               `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil    *time.Time            `db:"locked_until" json:"locked_until,omitempty"`
+	DeliveredAt    *time.Time            `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time             `db:"updated_at" json:"updated_at"`
+}
+
+// CanReplay reports whether an admin may send the delivery again. Pending
+// and in-flight deliveries are already going to be tried.
+func (d *WebhookDelivery) CanReplay() bool {
+	return d.Status == WebhookDeliveryDelivered || d.Status == WebhookDeliveryDead
+}
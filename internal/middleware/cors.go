@@ -31,12 +31,12 @@ func LoadCORSConfig() *CORSConfig {
 
 	allowHeaders := os.Getenv("CORS_ALLOW_HEADERS")
 	if allowHeaders == "" {
-		allowHeaders = "Origin,Content-Type,Accept,Authorization,X-Request-ID,X-Requested-With"
+		allowHeaders = "Origin,Content-Type,Accept,Authorization,X-Request-ID,X-Requested-With,If-Match"
 	}
 
 	exposeHeaders := os.Getenv("CORS_EXPOSE_HEADERS")
 	if exposeHeaders == "" {
-		exposeHeaders = "X-Request-ID,X-Total-Count"
+		exposeHeaders = "X-Request-ID,X-Total-Count,ETag"
 	}
 
 	allowCredentials := os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
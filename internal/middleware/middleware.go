@@ -20,6 +20,7 @@ type Interface interface {
 	RateLimit() fiber.Handler
 	AuthMiddleware() fiber.Handler
 	AdminMiddleware() fiber.Handler
+	CourierMiddleware() fiber.Handler
 	OptionalAuth() fiber.Handler
 	GetRequestID(c *fiber.Ctx) string
 }
@@ -85,8 +86,8 @@ func (m *middleware) CORS() fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID",
-		ExposeHeaders:    "X-Request-ID",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID,If-Match",
+		ExposeHeaders:    "X-Request-ID,ETag",
 		AllowCredentials: false,
 		MaxAge:           300,
 	})
@@ -228,6 +229,56 @@ func (m *middleware) AdminMiddleware() fiber.Handler {
 	}
 }
 
+func (m *middleware) CourierMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Authorization header required",
+			})
+		}
+
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid authorization header format",
+			})
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Token required",
+			})
+		}
+
+		claims, err := m.jwtService.ValidateAccessToken(tokenString)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid or expired token",
+			})
+		}
+
+		if claims.Role != "courier" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "Courier access required",
+			})
+		}
+
+		c.Locals("user", claims)
+		c.Locals("user_id", claims.UserID)
+		c.Locals("user_email", claims.Email)
+		c.Locals("user_role", claims.Role)
+
+		return c.Next()
+	}
+}
+
 func (m *middleware) OptionalAuth() fiber.Handler {
 	return OptionalAuthMiddleware()
 }
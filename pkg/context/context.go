@@ -202,6 +202,12 @@ func FromFiberContext(c *fiber.Ctx) context.Context {
 		}
 	}
 
+	if tenantID := c.Locals("tenant_id"); tenantID != nil {
+		if id, ok := tenantID.(string); ok {
+			ctx = WithTenantID(ctx, id)
+		}
+	}
+
 	return ctx
 }
 
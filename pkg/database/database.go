@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxManager runs a unit of work in one transaction.
+type TxManager interface {
+	// WithinTx calls fn with a context carrying a transaction and commits
+	// it if fn returns nil, rolling it back otherwise. Repositories given
+	// that context run their queries on the transaction. Called again with
+	// such a context, WithinTx runs fn under a savepoint instead, so an
+	// inner failure undoes only the inner work and leaves the outer
+	// transaction to decide.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// DB is what repositories hold in place of the pool. Each query runs on the
+// transaction in its context, if WithinTx started one on the same pool, and
+// on the pool otherwise.
+//
+// A transaction is a single connection: its context must not be shared
+// between goroutines, and rows must be closed before the next query.
+type DB struct {
+	db *sqlx.DB
+}
+
+type txKey struct{}
+
+type txState struct {
+	db    *sqlx.DB
+	tx    *sqlx.Tx
+	depth int
+}
+
+func New(db *sqlx.DB) *DB {
+	return &DB{db: db}
+}
+
+func NewTxManager(db *sqlx.DB) TxManager {
+	return New(db)
+}
+
+func (d *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state := d.state(ctx); state != nil {
+		return d.withinSavepoint(ctx, state, fn)
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{db: d.db, tx: tx})); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) withinSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	inner := &txState{db: state.db, tx: state.tx, depth: state.depth + 1}
+	name := fmt.Sprintf("sp_%d", inner.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, inner)); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (and failed to roll back to savepoint: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// state returns the transaction in ctx, ignoring one begun on another pool.
+func (d *DB) state(ctx context.Context) *txState {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == d.db {
+		return state
+	}
+	return nil
+}
+
+func (d *DB) executor(ctx context.Context) sqlx.ExtContext {
+	if state := d.state(ctx); state != nil {
+		return state.tx
+	}
+	return d.db
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if state := d.state(ctx); state != nil {
+		return state.tx.QueryRowContext(ctx, query, args...)
+	}
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+func (d *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return d.executor(ctx).QueryxContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return d.executor(ctx).QueryRowxContext(ctx, query, args...)
+}
+
+func (d *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.GetContext(ctx, d.executor(ctx), dest, query, args...)
+}
+
+func (d *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.SelectContext(ctx, d.executor(ctx), dest, query, args...)
+}
@@ -1,9 +1,8 @@
 package email
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"html/template"
 	"os"
 	"strconv"
 	"strings"
@@ -14,21 +13,86 @@ import (
 
 type Interface interface {
 	SendEmail(to []string, subject, body string, isHTML bool) error
-	SendEmailWithTemplate(to []string, subject, templateName string, data interface{}) error
+	Deliver(message Message) error
+	SetQueue(queue Queue)
+	SetConsentChecker(checker ConsentChecker)
+	SetLocaleResolver(resolver LocaleResolver)
+	SendEmailWithTemplate(to []string, templateName string, data interface{}) error
+	TemplateNames() []string
+	PreviewTemplate(name string, locale Locale) (*RenderedEmail, error)
 	SendWelcomeEmail(to, name string) error
 	SendOTPEmail(to, name, otp string) error
 	SendPasswordResetEmail(to, name, resetLink string) error
 	SendSubscriptionConfirmationEmail(to, name string, subscription *SubscriptionDetails) error
 	SendSubscriptionCancellationEmail(to, name string) error
+	SendSubscriptionReactivatedEmail(to, name string, subscription *SubscriptionDetails) error
+	SendSubscriptionPausedEmail(to, name string, pause *PauseDetails) error
+	SendSubscriptionResumedEmail(to, name, planName string) error
+	SendPauseEndingReminderEmail(to, name string, pause *PauseDetails) error
+	SendSubscriptionForceCancelledEmail(to, name, planName, reason string) error
 	SendOrderConfirmationEmail(to, name string, order *OrderDetails) error
 	SendPaymentConfirmationEmail(to, name string, payment *PaymentDetails) error
+	SendGiftSubscriptionEmail(to, name string, gift *GiftDetails) error
+	SendOrganizationInvitationEmail(to string, invitation *OrganizationInvitationDetails) error
+	SendOrganizationInvoiceEmail(to, organizationName string, invoice *InvoiceDetails) error
+	SendTestimonialApprovedEmail(to, name string, testimonial *TestimonialDetails) error
+	SendTestimonialRejectedEmail(to, name string, testimonial *TestimonialDetails) error
+	SendDataExportReadyEmail(to, name string, export *DataExportDetails) error
 	TestConnection() error
 }
 
+// Queue stores a rendered email for later delivery. When a queue is set,
+// SendEmail hands messages to it instead of dialing SMTP in the caller.
+type Queue interface {
+	Enqueue(message Message) error
+}
+
+// Message is a rendered email. Either body may be empty; when both are set
+// the email is sent as multipart/alternative.
+type Message struct {
+	To       []string
+	Subject  string
+	HTMLBody string
+	TextBody string
+	// UnsubscribeURL is sent as the List-Unsubscribe header, so mail
+	// clients can offer one-click unsubscribe. Empty for transactional mail.
+	UnsubscribeURL string
+}
+
+// Category groups emails for consent. Only transactional email is sent
+// regardless of the recipient's preferences.
+type Category string
+
+const (
+	CategoryTransactional   Category = "transactional"
+	CategoryDeliveryUpdates Category = "delivery_updates"
+	CategoryMarketing       Category = "marketing"
+	CategoryMenuOfTheWeek   Category = "menu_of_the_week"
+)
+
+// ErrRecipientOptedOut is returned when every recipient has turned the
+// email's category off, so nothing was sent.
+var ErrRecipientOptedOut = errors.New("recipient opted out of this email category")
+
+// ConsentChecker decides whether a recipient accepts a category of email and
+// returns the one-click unsubscribe link to put in the footer.
+type ConsentChecker interface {
+	CheckConsent(to string, category Category) (allowed bool, unsubscribeURL string, err error)
+}
+
+// templateCategories lists the templates that are not transactional.
+var templateCategories = map[string]Category{
+	"pause_ending_reminder": CategoryDeliveryUpdates,
+}
+
 type Service struct {
-	config    *Config
-	dialer    *gomail.Dialer
-	templates map[string]*template.Template
+	config        *Config
+	dialer        *gomail.Dialer
+	templates     map[Locale]map[string]*emailTemplate
+	templateNames []string
+	queue         Queue
+	consent       ConsentChecker
+	locales       LocaleResolver
 }
 
 type Config struct {
@@ -41,6 +105,10 @@ type Config struct {
 	UseTLS       bool
 	UseSSL       bool
 	Timeout      time.Duration
+	// TemplateDir overrides the embedded templates file by file, using the
+	// same <locale>/<name>.html|txt layout. Changes apply on restart.
+	TemplateDir   string
+	DefaultLocale Locale
 }
 
 type SubscriptionDetails struct {
@@ -52,6 +120,12 @@ type SubscriptionDetails struct {
 	NextDelivery time.Time
 }
 
+type PauseDetails struct {
+	PlanName   string
+	PauseStart time.Time
+	PauseEnd   time.Time
+}
+
 type OrderDetails struct {
 	OrderID      string
 	Items        []OrderItem
@@ -75,6 +149,61 @@ type PaymentDetails struct {
 	Date          time.Time
 }
 
+type GiftDetails struct {
+	Code           string
+	BuyerName      string
+	Message        string
+	PlanName       string
+	MealTypes      []string
+	DeliveryDays   []string
+	DurationMonths int
+	ExpiresAt      time.Time
+}
+
+type OrganizationInvitationDetails struct {
+	OrganizationName string
+	InviterName      string
+	InviteToken      string
+	MonthlyAllowance float64
+	ExpiresAt        time.Time
+}
+
+type InvoiceDetails struct {
+	InvoiceID         string
+	PeriodStart       time.Time
+	PeriodEnd         time.Time
+	SubscriptionCount int
+	TotalAmount       float64
+	Items             []InvoiceItem
+}
+
+// TestimonialDetails describes a moderation decision on a customer's
+// review. Reason is a rejection reason code; WasPublished is set when a
+// live review was taken down rather than rejected before publication.
+type TestimonialDetails struct {
+	PlanName     string
+	Rating       int
+	Excerpt      string
+	Reason       string
+	Note         string
+	WasPublished bool
+}
+
+// DataExportDetails points to a finished personal data export. The link
+// stops working at LinkExpiresAt; the file itself is deleted at DeletedAt.
+type DataExportDetails struct {
+	DownloadURL   string
+	LinkExpiresAt time.Time
+	DeletedAt     time.Time
+}
+
+type InvoiceItem struct {
+	MemberName   string
+	MemberEmail  string
+	MealPlanName string
+	Amount       float64
+}
+
 func LoadConfig() *Config {
 	host := os.Getenv("SMTP_HOST")
 	if host == "" {
@@ -100,16 +229,23 @@ func LoadConfig() *Config {
 		fromEmail = "noreply@seacatering.com"
 	}
 
+	defaultLocale, ok := ParseLocale(os.Getenv("EMAIL_DEFAULT_LOCALE"))
+	if !ok {
+		defaultLocale = LocaleIndonesian
+	}
+
 	return &Config{
-		SMTPHost:     host,
-		SMTPPort:     port,
-		SMTPUsername: username,
-		SMTPPassword: password,
-		FromName:     fromName,
-		FromEmail:    fromEmail,
-		UseTLS:       true,
-		UseSSL:       false,
-		Timeout:      30 * time.Second,
+		SMTPHost:      host,
+		SMTPPort:      port,
+		SMTPUsername:  username,
+		SMTPPassword:  password,
+		FromName:      fromName,
+		FromEmail:     fromEmail,
+		UseTLS:        true,
+		UseSSL:        false,
+		Timeout:       30 * time.Second,
+		TemplateDir:   os.Getenv("EMAIL_TEMPLATE_DIR"),
+		DefaultLocale: defaultLocale,
 	}
 }
 
@@ -130,7 +266,7 @@ func NewWithConfig(config *Config) Interface {
 	service := &Service{
 		config:    config,
 		dialer:    dialer,
-		templates: make(map[string]*template.Template),
+		templates: make(map[Locale]map[string]*emailTemplate),
 	}
 
 	service.loadTemplates()
@@ -138,37 +274,127 @@ func NewWithConfig(config *Config) Interface {
 	return service
 }
 
+func (s *Service) SetQueue(queue Queue) {
+	s.queue = queue
+}
+
 func (s *Service) SendEmail(to []string, subject, body string, isHTML bool) error {
 	if len(to) == 0 {
 		return fmt.Errorf("no recipients specified")
 	}
 
+	message := Message{To: to, Subject: subject}
+	if isHTML {
+		message.HTMLBody = body
+	} else {
+		message.TextBody = body
+	}
+
+	return s.send(message)
+}
+
+func (s *Service) send(message Message) error {
+	if s.queue != nil {
+		return s.queue.Enqueue(message)
+	}
+
+	return s.Deliver(message)
+}
+
+// Deliver sends the email over SMTP immediately, bypassing any queue.
+func (s *Service) Deliver(message Message) error {
+	if len(message.To) == 0 {
+		return fmt.Errorf("no recipients specified")
+	}
+
 	m := gomail.NewMessage()
 	m.SetHeader("From", fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromEmail))
-	m.SetHeader("To", to...)
-	m.SetHeader("Subject", subject)
+	m.SetHeader("To", message.To...)
+	m.SetHeader("Subject", message.Subject)
+	if message.UnsubscribeURL != "" {
+		m.SetHeader("List-Unsubscribe", "<"+message.UnsubscribeURL+">")
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
 
-	if isHTML {
-		m.SetBody("text/html", body)
-	} else {
-		m.SetBody("text/plain", body)
+	switch {
+	case message.HTMLBody != "" && message.TextBody != "":
+		m.SetBody("text/plain", message.TextBody)
+		m.AddAlternative("text/html", message.HTMLBody)
+	case message.HTMLBody != "":
+		m.SetBody("text/html", message.HTMLBody)
+	default:
+		m.SetBody("text/plain", message.TextBody)
 	}
 
 	return s.dialer.DialAndSend(m)
 }
 
-func (s *Service) SendEmailWithTemplate(to []string, subject, templateName string, data interface{}) error {
-	tmpl, exists := s.templates[templateName]
-	if !exists {
-		return fmt.Errorf("template %s not found", templateName)
+func (s *Service) SetConsentChecker(checker ConsentChecker) {
+	s.consent = checker
+}
+
+func (s *Service) SetLocaleResolver(resolver LocaleResolver) {
+	s.locales = resolver
+}
+
+// SendEmailWithTemplate renders a template separately for each recipient in
+// their preferred language. Non-transactional templates go only to
+// recipients who accept the category, each with their own unsubscribe link.
+func (s *Service) SendEmailWithTemplate(to []string, templateName string, data interface{}) error {
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients specified")
+	}
+
+	category, optional := templateCategories[templateName]
+	checkConsent := optional && s.consent != nil
+
+	sent := 0
+	for _, recipient := range to {
+		unsubscribeURL := ""
+		if checkConsent {
+			allowed, link, err := s.consent.CheckConsent(recipient, category)
+			if err != nil {
+				return fmt.Errorf("failed to check consent: %w", err)
+			}
+			if !allowed {
+				continue
+			}
+			unsubscribeURL = link
+		}
+
+		rendered, err := s.renderTemplate(templateName, s.localeFor(recipient), data, unsubscribeURL)
+		if err != nil {
+			return err
+		}
+
+		err = s.send(Message{
+			To:       []string{recipient},
+			Subject:  rendered.Subject,
+			HTMLBody: rendered.HTML,
+			TextBody: rendered.Text,
+
+			UnsubscribeURL: unsubscribeURL,
+		})
+		if err != nil {
+			return err
+		}
+		sent++
 	}
 
-	var body bytes.Buffer
-	if err := tmpl.Execute(&body, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	if sent == 0 {
+		return ErrRecipientOptedOut
 	}
 
-	return s.SendEmail(to, subject, body.String(), true)
+	return nil
+}
+
+func (s *Service) localeFor(recipient string) Locale {
+	if s.locales != nil {
+		if locale, ok := ParseLocale(string(s.locales.PreferredLocale(recipient))); ok {
+			return locale
+		}
+	}
+	return s.config.DefaultLocale
 }
 
 func (s *Service) SendWelcomeEmail(to, name string) error {
@@ -180,8 +406,7 @@ func (s *Service) SendWelcomeEmail(to, name string) error {
 		Year: time.Now().Year(),
 	}
 
-	subject := "Welcome to SEA Catering!"
-	return s.SendEmailWithTemplate([]string{to}, subject, "welcome", data)
+	return s.SendEmailWithTemplate([]string{to}, "welcome", data)
 }
 
 func (s *Service) SendOTPEmail(to, name, otp string) error {
@@ -195,8 +420,7 @@ func (s *Service) SendOTPEmail(to, name, otp string) error {
 		Year: time.Now().Year(),
 	}
 
-	subject := "Your OTP Verification Code"
-	return s.SendEmailWithTemplate([]string{to}, subject, "otp", data)
+	return s.SendEmailWithTemplate([]string{to}, "otp", data)
 }
 
 func (s *Service) SendPasswordResetEmail(to, name, resetLink string) error {
@@ -210,8 +434,7 @@ func (s *Service) SendPasswordResetEmail(to, name, resetLink string) error {
 		Year:      time.Now().Year(),
 	}
 
-	subject := "Reset Your Password"
-	return s.SendEmailWithTemplate([]string{to}, subject, "password_reset", data)
+	return s.SendEmailWithTemplate([]string{to}, "password_reset", data)
 }
 
 func (s *Service) SendSubscriptionConfirmationEmail(to, name string, subscription *SubscriptionDetails) error {
@@ -225,8 +448,7 @@ func (s *Service) SendSubscriptionConfirmationEmail(to, name string, subscriptio
 		Year:         time.Now().Year(),
 	}
 
-	subject := "Subscription Confirmed - Welcome to SEA Catering!"
-	return s.SendEmailWithTemplate([]string{to}, subject, "subscription_confirmation", data)
+	return s.SendEmailWithTemplate([]string{to}, "subscription_confirmation", data)
 }
 
 func (s *Service) SendSubscriptionCancellationEmail(to, name string) error {
@@ -238,8 +460,79 @@ func (s *Service) SendSubscriptionCancellationEmail(to, name string) error {
 		Year: time.Now().Year(),
 	}
 
-	subject := "Subscription Cancelled"
-	return s.SendEmailWithTemplate([]string{to}, subject, "subscription_cancellation", data)
+	return s.SendEmailWithTemplate([]string{to}, "subscription_cancellation", data)
+}
+
+func (s *Service) SendSubscriptionReactivatedEmail(to, name string, subscription *SubscriptionDetails) error {
+	data := struct {
+		Name         string
+		Subscription *SubscriptionDetails
+		Year         int
+	}{
+		Name:         name,
+		Subscription: subscription,
+		Year:         time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "subscription_reactivated", data)
+}
+
+func (s *Service) SendSubscriptionPausedEmail(to, name string, pause *PauseDetails) error {
+	data := struct {
+		Name  string
+		Pause *PauseDetails
+		Year  int
+	}{
+		Name:  name,
+		Pause: pause,
+		Year:  time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "subscription_paused", data)
+}
+
+func (s *Service) SendSubscriptionResumedEmail(to, name, planName string) error {
+	data := struct {
+		Name     string
+		PlanName string
+		Year     int
+	}{
+		Name:     name,
+		PlanName: planName,
+		Year:     time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "subscription_resumed", data)
+}
+
+func (s *Service) SendPauseEndingReminderEmail(to, name string, pause *PauseDetails) error {
+	data := struct {
+		Name  string
+		Pause *PauseDetails
+		Year  int
+	}{
+		Name:  name,
+		Pause: pause,
+		Year:  time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "pause_ending_reminder", data)
+}
+
+func (s *Service) SendSubscriptionForceCancelledEmail(to, name, planName, reason string) error {
+	data := struct {
+		Name     string
+		PlanName string
+		Reason   string
+		Year     int
+	}{
+		Name:     name,
+		PlanName: planName,
+		Reason:   reason,
+		Year:     time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "subscription_force_cancellation", data)
 }
 
 func (s *Service) SendOrderConfirmationEmail(to, name string, order *OrderDetails) error {
@@ -253,8 +546,7 @@ func (s *Service) SendOrderConfirmationEmail(to, name string, order *OrderDetail
 		Year:  time.Now().Year(),
 	}
 
-	subject := fmt.Sprintf("Order Confirmation - #%s", order.OrderID)
-	return s.SendEmailWithTemplate([]string{to}, subject, "order_confirmation", data)
+	return s.SendEmailWithTemplate([]string{to}, "order_confirmation", data)
 }
 
 func (s *Service) SendPaymentConfirmationEmail(to, name string, payment *PaymentDetails) error {
@@ -268,227 +560,93 @@ func (s *Service) SendPaymentConfirmationEmail(to, name string, payment *Payment
 		Year:    time.Now().Year(),
 	}
 
-	subject := "Payment Confirmation"
-	return s.SendEmailWithTemplate([]string{to}, subject, "payment_confirmation", data)
+	return s.SendEmailWithTemplate([]string{to}, "payment_confirmation", data)
 }
 
-func (s *Service) TestConnection() error {
-	return s.dialer.DialAndSend()
+func (s *Service) SendGiftSubscriptionEmail(to, name string, gift *GiftDetails) error {
+	data := struct {
+		Name string
+		Gift *GiftDetails
+		Year int
+	}{
+		Name: name,
+		Gift: gift,
+		Year: time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "gift_subscription", data)
+}
+
+func (s *Service) SendOrganizationInvitationEmail(to string, invitation *OrganizationInvitationDetails) error {
+	data := struct {
+		Invitation *OrganizationInvitationDetails
+		Year       int
+	}{
+		Invitation: invitation,
+		Year:       time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "organization_invitation", data)
+}
+
+func (s *Service) SendOrganizationInvoiceEmail(to, organizationName string, invoice *InvoiceDetails) error {
+	data := struct {
+		Name    string
+		Invoice *InvoiceDetails
+		Year    int
+	}{
+		Name:    organizationName,
+		Invoice: invoice,
+		Year:    time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "organization_invoice", data)
+}
+
+func (s *Service) SendTestimonialApprovedEmail(to, name string, testimonial *TestimonialDetails) error {
+	data := struct {
+		Name        string
+		Testimonial *TestimonialDetails
+		Year        int
+	}{
+		Name:        name,
+		Testimonial: testimonial,
+		Year:        time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "testimonial_approved", data)
 }
 
-func (s *Service) loadTemplates() {
-
-	s.templates["welcome"] = template.Must(template.New("welcome").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Welcome to SEA Catering</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Welcome to SEA Catering, {{.Name}}!</h1>
-        <p>Thank you for joining SEA Catering! We're excited to help you maintain a healthy lifestyle with our customizable meal plans.</p>
-        <p>Our mission is to provide you with delicious, nutritious meals delivered right to your door, anywhere in Indonesia.</p>
-        <h3>What's Next?</h3>
-        <ul>
-            <li>Browse our meal plans and find the perfect fit for your lifestyle</li>
-            <li>Customize your meals based on your preferences and dietary needs</li>
-            <li>Set up your delivery schedule</li>
-            <li>Enjoy healthy, delicious meals!</li>
-        </ul>
-        <p>If you have any questions, feel free to contact our support team.</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["otp"] = template.Must(template.New("otp").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>OTP Verification</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Verification Code</h1>
-        <p>Hello {{.Name}},</p>
-        <p>Your verification code is:</p>
-        <div style="background: #f4f4f4; padding: 20px; text-align: center; margin: 20px 0;">
-            <h2 style="color: #2c5530; font-size: 32px; margin: 0; letter-spacing: 5px;">{{.OTP}}</h2>
-        </div>
-        <p>This code will expire in 10 minutes. If you didn't request this code, please ignore this email.</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["password_reset"] = template.Must(template.New("password_reset").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Reset Your Password</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Reset Your Password</h1>
-        <p>Hello {{.Name}},</p>
-        <p>We received a request to reset your password. Click the button below to reset it:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.ResetLink}}" style="background: #2c5530; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Reset Password</a>
-        </div>
-        <p>If the button doesn't work, copy and paste this link into your browser:</p>
-        <p style="word-break: break-all;">{{.ResetLink}}</p>
-        <p>This link will expire in 1 hour. If you didn't request a password reset, please ignore this email.</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["subscription_confirmation"] = template.Must(template.New("subscription_confirmation").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Subscription Confirmed</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Subscription Confirmed!</h1>
-        <p>Hello {{.Name}},</p>
-        <p>Great news! Your subscription has been confirmed. Here are the details:</p>
-        <div style="background: #f9f9f9; padding: 20px; border-radius: 5px; margin: 20px 0;">
-            <h3 style="margin-top: 0;">Subscription Details</h3>
-            <p><strong>Plan:</strong> {{.Subscription.PlanName}}</p>
-            <p><strong>Meal Types:</strong> {{range $index, $meal := .Subscription.MealTypes}}{{if $index}}, {{end}}{{$meal}}{{end}}</p>
-            <p><strong>Delivery Days:</strong> {{range $index, $day := .Subscription.DeliveryDays}}{{if $index}}, {{end}}{{$day}}{{end}}</p>
-            <p><strong>Monthly Total:</strong> Rp{{printf "%.2f" .Subscription.TotalPrice}}</p>
-            <p><strong>Start Date:</strong> {{.Subscription.StartDate.Format "January 2, 2006"}}</p>
-            <p><strong>Next Delivery:</strong> {{.Subscription.NextDelivery.Format "January 2, 2006"}}</p>
-        </div>
-        <p>We'll send you a reminder before each delivery. You can manage your subscription anytime through your account dashboard.</p>
-        <p>Thank you for choosing SEA Catering!</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["subscription_cancellation"] = template.Must(template.New("subscription_cancellation").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Subscription Cancelled</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Subscription Cancelled</h1>
-        <p>Hello {{.Name}},</p>
-        <p>We're sorry to see you go! Your subscription has been successfully cancelled.</p>
-        <p>You will continue to receive deliveries until the end of your current billing period. After that, no further charges will be made.</p>
-        <p>We'd love to have you back anytime! If you have any feedback about your experience, please don't hesitate to reach out.</p>
-        <p>Thank you for being part of the SEA Catering family.</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["order_confirmation"] = template.Must(template.New("order_confirmation").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Order Confirmation</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Order Confirmation</h1>
-        <p>Hello {{.Name}},</p>
-        <p>Thank you for your order! Here are the details:</p>
-        <div style="background: #f9f9f9; padding: 20px; border-radius: 5px; margin: 20px 0;">
-            <h3 style="margin-top: 0;">Order #{{.Order.OrderID}}</h3>
-            <table style="width: 100%; border-collapse: collapse;">
-                <thead>
-                    <tr>
-                        <th style="text-align: left; padding: 8px; border-bottom: 1px solid #ddd;">Item</th>
-                        <th style="text-align: center; padding: 8px; border-bottom: 1px solid #ddd;">Qty</th>
-                        <th style="text-align: right; padding: 8px; border-bottom: 1px solid #ddd;">Price</th>
-                    </tr>
-                </thead>
-                <tbody>
-                    {{range .Order.Items}}
-                    <tr>
-                        <td style="padding: 8px; border-bottom: 1px solid #eee;">{{.Name}}</td>
-                        <td style="text-align: center; padding: 8px; border-bottom: 1px solid #eee;">{{.Quantity}}</td>
-                        <td style="text-align: right; padding: 8px; border-bottom: 1px solid #eee;">Rp{{printf "%.2f" .Price}}</td>
-                    </tr>
-                    {{end}}
-                </tbody>
-                <tfoot>
-                    <tr>
-                        <th colspan="2" style="text-align: right; padding: 8px; border-top: 2px solid #333;">Total:</th>
-                        <th style="text-align: right; padding: 8px; border-top: 2px solid #333;">Rp{{printf "%.2f" .Order.TotalAmount}}</th>
-                    </tr>
-                </tfoot>
-            </table>
-            <p><strong>Delivery Date:</strong> {{.Order.DeliveryDate.Format "January 2, 2006"}}</p>
-            <p><strong>Delivery Time:</strong> {{.Order.DeliveryTime}}</p>
-            <p><strong>Delivery Address:</strong> {{.Order.Address}}</p>
-        </div>
-        <p>We'll notify you when your order is on its way!</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
-
-	s.templates["payment_confirmation"] = template.Must(template.New("payment_confirmation").Parse(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Payment Confirmation</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2c5530;">Payment Confirmed</h1>
-        <p>Hello {{.Name}},</p>
-        <p>Your payment has been successfully processed. Here are the details:</p>
-        <div style="background: #f9f9f9; padding: 20px; border-radius: 5px; margin: 20px 0;">
-            <h3 style="margin-top: 0;">Payment Details</h3>
-            <p><strong>Payment ID:</strong> {{.Payment.PaymentID}}</p>
-            <p><strong>Amount:</strong> Rp{{printf "%.2f" .Payment.Amount}}</p>
-            <p><strong>Payment Method:</strong> {{.Payment.Method}}</p>
-            <p><strong>Transaction ID:</strong> {{.Payment.TransactionID}}</p>
-            <p><strong>Date:</strong> {{.Payment.Date.Format "January 2, 2006 at 3:04 PM"}}</p>
-        </div>
-        <p>Thank you for your payment! A receipt has been generated for your records.</p>
-        <p>Best regards,<br>The SEA Catering Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} SEA Catering. All rights reserved.</p>
-    </div>
-</body>
-</html>
-	`))
+func (s *Service) SendTestimonialRejectedEmail(to, name string, testimonial *TestimonialDetails) error {
+	data := struct {
+		Name        string
+		Testimonial *TestimonialDetails
+		Year        int
+	}{
+		Name:        name,
+		Testimonial: testimonial,
+		Year:        time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "testimonial_rejected", data)
+}
+
+func (s *Service) SendDataExportReadyEmail(to, name string, export *DataExportDetails) error {
+	data := struct {
+		Name   string
+		Export *DataExportDetails
+		Year   int
+	}{
+		Name:   name,
+		Export: export,
+		Year:   time.Now().Year(),
+	}
+
+	return s.SendEmailWithTemplate([]string{to}, "data_export_ready", data)
+}
+
+func (s *Service) TestConnection() error {
+	return s.dialer.DialAndSend()
 }
 
 func FormatPrice(amount float64) string {
@@ -0,0 +1,177 @@
+package email
+
+import "time"
+
+// sampleData feeds the admin template preview. Keys mirror the data each
+// Send* method passes to its template.
+var sampleData = map[string]func() interface{}{
+	"welcome": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Year": time.Now().Year()}
+	},
+	"otp": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "OTP": "482913", "Year": time.Now().Year()}
+	},
+	"password_reset": func() interface{} {
+		return map[string]interface{}{
+			"Name":      "Siti Rahma",
+			"ResetLink": "https://example.com/reset-password?token=preview",
+			"Year":      time.Now().Year(),
+		}
+	},
+	"subscription_confirmation": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Subscription": sampleSubscription(), "Year": time.Now().Year()}
+	},
+	"subscription_cancellation": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Year": time.Now().Year()}
+	},
+	"subscription_reactivated": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Subscription": sampleSubscription(), "Year": time.Now().Year()}
+	},
+	"subscription_paused": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Pause": samplePause(), "Year": time.Now().Year()}
+	},
+	"subscription_resumed": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "PlanName": "Protein Plan", "Year": time.Now().Year()}
+	},
+	"pause_ending_reminder": func() interface{} {
+		return map[string]interface{}{"Name": "Siti Rahma", "Pause": samplePause(), "Year": time.Now().Year()}
+	},
+	"subscription_force_cancellation": func() interface{} {
+		return map[string]interface{}{
+			"Name":     "Siti Rahma",
+			"PlanName": "Protein Plan",
+			"Reason":   "Repeated failed payments",
+			"Year":     time.Now().Year(),
+		}
+	},
+	"order_confirmation": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Order": &OrderDetails{
+				OrderID: "ORD-240117",
+				Items: []OrderItem{
+					{Name: "Grilled Chicken Bowl", Quantity: 2, Price: 45000},
+					{Name: "Tempeh Salad", Quantity: 1, Price: 38000},
+				},
+				TotalAmount:  128000,
+				DeliveryDate: time.Now().AddDate(0, 0, 1),
+				DeliveryTime: "11:00 - 13:00",
+				Address:      "Jl. Sudirman No. 10, Jakarta",
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"payment_confirmation": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Payment": &PaymentDetails{
+				PaymentID:     "PAY-240117",
+				Amount:        1290000,
+				Method:        "Bank Transfer",
+				TransactionID: "TRX-9F2A61",
+				Date:          time.Now(),
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"gift_subscription": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Gift": &GiftDetails{
+				Code:           "GIFT-7KQ2-M9XP",
+				BuyerName:      "Budi Santoso",
+				Message:        "Selamat ulang tahun! Happy birthday!",
+				PlanName:       "Diet Plan",
+				MealTypes:      []string{"breakfast", "lunch"},
+				DeliveryDays:   []string{"monday", "wednesday", "friday"},
+				DurationMonths: 3,
+				ExpiresAt:      time.Now().AddDate(0, 6, 0),
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"organization_invitation": func() interface{} {
+		return map[string]interface{}{
+			"Invitation": &OrganizationInvitationDetails{
+				OrganizationName: "PT Nusantara Digital",
+				InviterName:      "Budi Santoso",
+				InviteToken:      "inv_preview_3b6f1c2d9a",
+				MonthlyAllowance: 1500000,
+				ExpiresAt:        time.Now().AddDate(0, 0, 7),
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"organization_invoice": func() interface{} {
+		periodStart := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+		return map[string]interface{}{
+			"Name": "PT Nusantara Digital",
+			"Invoice": &InvoiceDetails{
+				InvoiceID:         "INV-202401-0007",
+				PeriodStart:       periodStart,
+				PeriodEnd:         periodStart.AddDate(0, 1, -1),
+				SubscriptionCount: 2,
+				TotalAmount:       2580000,
+				Items: []InvoiceItem{
+					{MemberName: "Siti Rahma", MemberEmail: "siti@example.com", MealPlanName: "Protein Plan", Amount: 1290000},
+					{MemberName: "Andi Wijaya", MemberEmail: "andi@example.com", MealPlanName: "Royal Plan", Amount: 1290000},
+				},
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"testimonial_approved": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Testimonial": &TestimonialDetails{
+				PlanName: "Protein Plan",
+				Rating:   5,
+				Excerpt:  "The portions are generous and the chicken is always tender.",
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"testimonial_rejected": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Testimonial": &TestimonialDetails{
+				PlanName: "Protein Plan",
+				Rating:   4,
+				Excerpt:  "Great food! Call me at 0812 3456 7890 for a referral discount.",
+				Reason:   "personal_info",
+				Note:     "Please remove your phone number and submit the review again.",
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+	"data_export_ready": func() interface{} {
+		return map[string]interface{}{
+			"Name": "Siti Rahma",
+			"Export": &DataExportDetails{
+				DownloadURL:   "https://example.com/data-exports/preview.zip",
+				LinkExpiresAt: time.Now().Add(72 * time.Hour),
+				DeletedAt:     time.Now().AddDate(0, 0, 7),
+			},
+			"Year": time.Now().Year(),
+		}
+	},
+}
+
+func sampleSubscription() *SubscriptionDetails {
+	return &SubscriptionDetails{
+		PlanName:     "Protein Plan",
+		MealTypes:    []string{"breakfast", "dinner"},
+		DeliveryDays: []string{"monday", "tuesday", "thursday"},
+		TotalPrice:   1290000,
+		StartDate:    time.Now(),
+		NextDelivery: time.Now().AddDate(0, 0, 1),
+	}
+}
+
+func samplePause() *PauseDetails {
+	return &PauseDetails{
+		PlanName:   "Protein Plan",
+		PauseStart: time.Now(),
+		PauseEnd:   time.Now().AddDate(0, 0, 14),
+	}
+}
@@ -0,0 +1,321 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Templates live in templates/<locale>/. Each email has a <name>.html body
+// defining "title" and "content" blocks, and a <name>.txt plain-text
+// alternative defining "subject" and "content" blocks. Both are wrapped by
+// the locale's layout.html / layout.txt, which carry the shared header and
+// footer.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+type Locale string
+
+const (
+	LocaleIndonesian Locale = "id"
+	LocaleEnglish    Locale = "en"
+)
+
+// Locales are the languages templates are shipped in. English is the
+// fallback when a template has no variant in the requested locale.
+var Locales = []Locale{LocaleIndonesian, LocaleEnglish}
+
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// LocaleResolver picks the language to email a recipient in. An empty
+// result falls back to the configured default locale.
+type LocaleResolver interface {
+	PreferredLocale(to string) Locale
+}
+
+func ParseLocale(value string) (Locale, bool) {
+	for _, locale := range Locales {
+		if string(locale) == strings.ToLower(strings.TrimSpace(value)) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// RenderedEmail is a template executed for one recipient.
+type RenderedEmail struct {
+	Template string `json:"template"`
+	Locale   Locale `json:"locale"`
+	Subject  string `json:"subject"`
+	HTML     string `json:"html"`
+	Text     string `json:"text"`
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// placeholderFuncs let templates parse; renderTemplate binds the real,
+// locale and recipient specific implementations before executing.
+var placeholderFuncs = map[string]interface{}{
+	"unsubscribeURL": func() string { return "" },
+	"date":           func(time.Time) string { return "" },
+	"datetime":       func(time.Time) string { return "" },
+	"monthYear":      func(time.Time) string { return "" },
+	"rupiah":         func(float64) string { return "" },
+	"join":           strings.Join,
+}
+
+// overlayFS serves files from the override directory when present and from
+// the embedded templates otherwise, so an override can replace a single
+// file without copying the rest.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) ReadFile(name string) ([]byte, error) {
+	if o.override != nil {
+		if data, err := fs.ReadFile(o.override, name); err == nil {
+			return data, nil
+		}
+	}
+	return fs.ReadFile(o.base, name)
+}
+
+// loadTemplates parses every template the binary ships with, in every
+// locale, applying files from Config.TemplateDir on top. A broken override
+// stops startup rather than failing on the first send.
+func (s *Service) loadTemplates() {
+	base, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		panic(fmt.Sprintf("email: failed to open embedded templates: %v", err))
+	}
+
+	files := overlayFS{base: base}
+	if s.config.TemplateDir != "" {
+		files.override = os.DirFS(s.config.TemplateDir)
+	}
+
+	names, err := templateNames(base)
+	if err != nil {
+		panic(fmt.Sprintf("email: failed to list templates: %v", err))
+	}
+
+	for _, locale := range Locales {
+		s.templates[locale] = make(map[string]*emailTemplate)
+
+		for _, name := range names {
+			tmpl, err := parseTemplate(files, locale, name)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				panic(fmt.Sprintf("email: failed to parse template %s/%s: %v", locale, name, err))
+			}
+			s.templates[locale][name] = tmpl
+		}
+	}
+
+	s.templateNames = names
+}
+
+func templateNames(base fs.FS) ([]string, error) {
+	matches, err := fs.Glob(base, string(LocaleEnglish)+"/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		name := strings.TrimSuffix(path.Base(match), ".html")
+		if name != "layout" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func parseTemplate(files overlayFS, locale Locale, name string) (*emailTemplate, error) {
+	dir := string(locale)
+
+	htmlLayout, err := files.ReadFile(path.Join(dir, "layout.html"))
+	if err != nil {
+		return nil, err
+	}
+	htmlBody, err := files.ReadFile(path.Join(dir, name+".html"))
+	if err != nil {
+		return nil, err
+	}
+	textLayout, err := files.ReadFile(path.Join(dir, "layout.txt"))
+	if err != nil {
+		return nil, err
+	}
+	textBody, err := files.ReadFile(path.Join(dir, name+".txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := htmltemplate.New("layout").Funcs(placeholderFuncs).Parse(string(htmlLayout))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := html.New(name).Parse(string(htmlBody)); err != nil {
+		return nil, err
+	}
+
+	text, err := texttemplate.New("layout").Funcs(placeholderFuncs).Parse(string(textLayout))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.New(name).Parse(string(textBody)); err != nil {
+		return nil, err
+	}
+
+	if text.Lookup("subject") == nil {
+		return nil, fmt.Errorf("%s.txt does not define a subject", name)
+	}
+
+	return &emailTemplate{html: html, text: text}, nil
+}
+
+// lookupTemplate returns the template in the requested locale, or the
+// English one when it has not been translated.
+func (s *Service) lookupTemplate(name string, locale Locale) (*emailTemplate, Locale, error) {
+	if tmpl, ok := s.templates[locale][name]; ok {
+		return tmpl, locale, nil
+	}
+	if tmpl, ok := s.templates[LocaleEnglish][name]; ok {
+		return tmpl, LocaleEnglish, nil
+	}
+	return nil, "", ErrTemplateNotFound
+}
+
+func (s *Service) renderTemplate(name string, locale Locale, data interface{}, unsubscribeURL string) (*RenderedEmail, error) {
+	tmpl, locale, err := s.lookupTemplate(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := localeFuncs(locale, unsubscribeURL)
+
+	html, err := tmpl.html.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	text, err := tmpl.text.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	html.Funcs(funcs)
+	text.Funcs(funcs)
+
+	var subject, htmlBody, textBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to execute subject template: %w", err)
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	if err := text.ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to execute text template: %w", err)
+	}
+
+	return &RenderedEmail{
+		Template: name,
+		Locale:   locale,
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		HTML:     htmlBody.String(),
+		Text:     strings.TrimSpace(textBody.String()) + "\n",
+	}, nil
+}
+
+func (s *Service) TemplateNames() []string {
+	return append([]string(nil), s.templateNames...)
+}
+
+// PreviewTemplate renders a template with made-up data so copy changes can
+// be checked without sending anything.
+func (s *Service) PreviewTemplate(name string, locale Locale) (*RenderedEmail, error) {
+	data, ok := sampleData[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	unsubscribeURL := ""
+	if _, optional := templateCategories[name]; optional {
+		unsubscribeURL = "https://example.com/api/v1/notifications/unsubscribe?token=preview"
+	}
+
+	return s.renderTemplate(name, locale, data(), unsubscribeURL)
+}
+
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+func localeFuncs(locale Locale, unsubscribeURL string) map[string]interface{} {
+	funcs := map[string]interface{}{
+		"unsubscribeURL": func() string { return unsubscribeURL },
+		"join":           strings.Join,
+	}
+
+	if locale == LocaleIndonesian {
+		funcs["date"] = func(t time.Time) string {
+			return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+		}
+		funcs["datetime"] = func(t time.Time) string {
+			return fmt.Sprintf("%d %s %d pukul %s", t.Day(), indonesianMonths[t.Month()-1], t.Year(), t.Format("15.04"))
+		}
+		funcs["monthYear"] = func(t time.Time) string {
+			return fmt.Sprintf("%s %d", indonesianMonths[t.Month()-1], t.Year())
+		}
+		funcs["rupiah"] = func(amount float64) string { return formatRupiah(amount, ".", ",") }
+		return funcs
+	}
+
+	funcs["date"] = func(t time.Time) string { return t.Format("January 2, 2006") }
+	funcs["datetime"] = func(t time.Time) string { return t.Format("January 2, 2006 at 3:04 PM") }
+	funcs["monthYear"] = func(t time.Time) string { return t.Format("January 2006") }
+	funcs["rupiah"] = func(amount float64) string { return formatRupiah(amount, ",", ".") }
+	return funcs
+}
+
+// formatRupiah groups thousands with the locale's separators, e.g.
+// Rp1.250.000,00 in Indonesian and Rp1,250,000.00 in English.
+func formatRupiah(amount float64, thousands, decimal string) string {
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	whole, fraction := digits[:len(digits)-3], digits[len(digits)-2:]
+
+	var b strings.Builder
+	if amount < 0 {
+		b.WriteString("-")
+	}
+	b.WriteString("Rp")
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(decimal)
+	b.WriteString(fraction)
+
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"sea-catering-backend/pkg/logger"
+)
+
+type Event struct {
+	Name       string
+	OccurredAt time.Time
+	Payload    interface{}
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+type Interface interface {
+	Subscribe(name string, handler Handler)
+	Publish(ctx context.Context, name string, payload interface{})
+	Close()
+}
+
+// Bus delivers events to subscribers in the background so a slow or failing
+// handler never holds up, or fails, the request that raised the event.
+// Handlers get a context detached from the publisher's cancellation.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	timeout  time.Duration
+	logger   *logger.Logger
+	wg       sync.WaitGroup
+}
+
+func New(logger *logger.Logger) Interface {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		timeout:  30 * time.Second,
+		logger:   logger,
+	}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, name string, payload interface{}) {
+	b.mu.RLock()
+	handlers := b.handlers[name]
+	b.mu.RUnlock()
+
+	event := Event{
+		Name:       name,
+		OccurredAt: time.Now(),
+		Payload:    payload,
+	}
+
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go b.dispatch(context.WithoutCancel(ctx), handler, event)
+	}
+}
+
+// Close waits for handlers that are still running.
+func (b *Bus) Close() {
+	b.wg.Wait()
+}
+
+func (b *Bus) dispatch(ctx context.Context, handler Handler, event Event) {
+	defer b.wg.Done()
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Event handler panicked", logger.Fields{
+				"event": event.Name,
+				"panic": fmt.Sprint(r),
+			})
+		}
+	}()
+
+	if err := handler(ctx, event); err != nil {
+		b.logger.Error("Event handler failed", logger.Fields{
+			"event": event.Name,
+			"error": err.Error(),
+		})
+	}
+}
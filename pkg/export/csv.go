@@ -0,0 +1,47 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) WriteHeader(columns ...string) error {
+	return w.writer.Write(columns)
+}
+
+func (w *csvWriter) WriteRow(values ...interface{}) error {
+	w.record = w.record[:0]
+	for _, value := range values {
+		c := toCell(value)
+		text := c.text
+		if !c.numeric {
+			text = escapeFormula(text)
+		}
+		w.record = append(w.record, text)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// escapeFormula stops spreadsheet apps from evaluating text that came from
+// customers, such as a name starting with "=".
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("export: unsupported format")
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Writer writes one table. Rows are written straight through to the
+// underlying writer, so memory use does not grow with the row count.
+type Writer interface {
+	WriteHeader(columns ...string) error
+	// WriteRow accepts strings, numbers, bools, times and pointers to them;
+	// nil pointers become empty cells.
+	WriteRow(values ...interface{}) error
+	// Close finishes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a writer for format. sheet names the worksheet in XLSX
+// files and is ignored for CSV.
+func NewWriter(w io.Writer, format Format, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// cell is a value reduced to text, with numeric cells marked so that
+// spreadsheets keep them as numbers.
+type cell struct {
+	text    string
+	numeric bool
+}
+
+func toCell(value interface{}) cell {
+	switch v := value.(type) {
+	case nil:
+		return cell{}
+	case string:
+		return cell{text: v}
+	case *string:
+		if v == nil {
+			return cell{}
+		}
+		return cell{text: *v}
+	case int:
+		return cell{text: strconv.Itoa(v), numeric: true}
+	case int64:
+		return cell{text: strconv.FormatInt(v, 10), numeric: true}
+	case *int:
+		if v == nil {
+			return cell{}
+		}
+		return cell{text: strconv.Itoa(*v), numeric: true}
+	case float64:
+		return cell{text: strconv.FormatFloat(v, 'f', -1, 64), numeric: true}
+	case *float64:
+		if v == nil {
+			return cell{}
+		}
+		return cell{text: strconv.FormatFloat(*v, 'f', -1, 64), numeric: true}
+	case bool:
+		return cell{text: strconv.FormatBool(v)}
+	case time.Time:
+		if v.IsZero() {
+			return cell{}
+		}
+		return cell{text: v.Format(timeLayout)}
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return cell{}
+		}
+		return cell{text: v.Format(timeLayout)}
+	case fmt.Stringer:
+		return cell{text: v.String()}
+	default:
+		return cell{text: fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const spreadsheetNS = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+
+// The fixed parts of a single-sheet workbook. Style 1 is the bold header.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="` + spreadsheetNS + `"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`},
+}
+
+// xlsxWriter streams a workbook with one sheet. The fixed parts are written
+// up front, leaving the sheet as the last zip entry so rows can be appended
+// until Close.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	name  string
+	row   int
+	err   error
+}
+
+func newXLSXWriter(w io.Writer, sheet string) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), name: sheetName(sheet)}
+}
+
+func (w *xlsxWriter) start() error {
+	if w.sheet != nil || w.err != nil {
+		return w.err
+	}
+
+	for _, part := range xlsxParts {
+		if w.err = w.writePart(part.name, part.content); w.err != nil {
+			return w.err
+		}
+	}
+
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(w.name))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="` + spreadsheetNS + `" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if w.err = w.writePart("xl/workbook.xml", workbook); w.err != nil {
+		return w.err
+	}
+
+	part, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.sheet = bufio.NewWriter(part)
+	_, w.err = w.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="` + spreadsheetNS + `"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`)
+	return w.err
+}
+
+func (w *xlsxWriter) writePart(name, content string) error {
+	part, err := w.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+func (w *xlsxWriter) WriteHeader(columns ...string) error {
+	cells := make([]cell, len(columns))
+	for i, column := range columns {
+		cells[i] = cell{text: column}
+	}
+	return w.writeCells(cells, 1)
+}
+
+func (w *xlsxWriter) WriteRow(values ...interface{}) error {
+	cells := make([]cell, len(values))
+	for i, value := range values {
+		cells[i] = toCell(value)
+	}
+	return w.writeCells(cells, 0)
+}
+
+func (w *xlsxWriter) writeCells(cells []cell, style int) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, c := range cells {
+		if c.text == "" {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(w.row)
+		styleAttr := ""
+		if style > 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		if c.numeric {
+			fmt.Fprintf(w.sheet, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, c.text)
+			continue
+		}
+		fmt.Fprintf(w.sheet, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, styleAttr)
+		xml.EscapeText(w.sheet, []byte(c.text))
+		w.sheet.WriteString(`</t></is></c>`)
+	}
+	_, w.err = w.sheet.WriteString(`</row>`)
+	return w.err
+}
+
+func (w *xlsxWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	if _, err := w.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+// columnName converts a zero-based index to a column letter: 0 is A, 26 is AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName drops characters Excel does not allow in sheet names and keeps
+// within its 31-character limit.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
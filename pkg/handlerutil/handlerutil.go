@@ -39,7 +39,7 @@ func (e *ErrorHandler) HandleValidationError(c *fiber.Ctx, requestID string, err
 		for _, validationErr := range validationErrors {
 			errors = append(errors, response.ErrorDetail{
 				Code:    "VALIDATION_ERROR",
-				Message: getValidationMessage(validationErr),
+				Message: ValidationMessage(validationErr),
 				Field:   validationErr.Field(),
 				Value:   validationErr.Value(),
 			})
@@ -105,12 +105,14 @@ func (e *ErrorHandler) HandleForbidden(c *fiber.Ctx, requestID string, message s
 	return response.Forbidden(c, message)
 }
 
-func getValidationMessage(err validator.FieldError) string {
+// ValidationMessage describes a failed validation rule in words an admin or
+// customer can act on.
+func ValidationMessage(err validator.FieldError) string {
 	field := err.Field()
 	tag := err.Tag()
 
 	switch tag {
-	case "required":
+	case "required", "required_if":
 		return fmt.Sprintf("%s is required", field)
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
@@ -120,6 +122,12 @@ func getValidationMessage(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be at most %s characters long", field, err.Param())
 	case "phone_id":
 		return fmt.Sprintf("%s must be a valid Indonesian phone number", field)
+	case "datetime":
+		return fmt.Sprintf("%s must be a date in the format %s", field, err.Param())
+	case "meal_type":
+		return fmt.Sprintf("%s must be breakfast, lunch or dinner", field)
+	case "day_of_week":
+		return fmt.Sprintf("%s must be a day of the week", field)
 	case "strong_password":
 		return fmt.Sprintf("%s must contain at least 8 characters with uppercase, lowercase, number, and special character", field)
 	default:
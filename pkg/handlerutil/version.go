@@ -0,0 +1,60 @@
+package handlerutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"sea-catering-backend/pkg/response"
+)
+
+// VersionRequest is the body of a change that carries nothing but the
+// version the client last read.
+type VersionRequest struct {
+	Version *int `json:"version,omitempty"`
+}
+
+// ExpectedVersion returns the version the client last read, from an
+// If-Match header such as "3" or W/"3", or else from the version field of
+// the body. It reports false when neither holds a valid version.
+func ExpectedVersion(c *fiber.Ctx, body *int) (int, bool) {
+	if header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch)); header != "" {
+		tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+		version, err := strconv.Atoi(tag)
+		return version, err == nil && version > 0
+	}
+
+	if body != nil && *body > 0 {
+		return *body, true
+	}
+	return 0, false
+}
+
+// ReadVersion is ExpectedVersion for requests whose only body, if any, is
+// a VersionRequest.
+func ReadVersion(c *fiber.Ctx) (int, bool) {
+	var req VersionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return 0, false
+		}
+	}
+	return ExpectedVersion(c, req.Version)
+}
+
+// SetETag exposes version so clients can send it back in If-Match.
+func SetETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, fmt.Sprintf(`"%d"`, version))
+}
+
+func (e *ErrorHandler) HandleVersionRequired(c *fiber.Ctx, requestID string) error {
+	e.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"path":       c.Path(),
+	}).Warn("Change without a version rejected")
+
+	return response.Error(c, fiber.StatusPreconditionRequired, "Send the version you last read in an If-Match header or as \"version\" in the body")
+}
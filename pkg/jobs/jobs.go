@@ -0,0 +1,244 @@
+// Package jobs runs background work queued in a database table. Workers
+// lease due rows, run each under a timeout and record the outcome, and a
+// janitor tidies up on a fixed interval.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"sea-catering-backend/pkg/database"
+	"sea-catering-backend/pkg/logger"
+)
+
+// Handler does the work for one kind of job.
+type Handler[T any] interface {
+	// Claim leases the next due job until lockFor has passed. It returns
+	// nil when nothing is due.
+	Claim(ctx context.Context, lockFor time.Duration, maxAttempts int) (*T, error)
+	// Run does the job within the job timeout.
+	Run(ctx context.Context, job *T) error
+	// Record saves the outcome of Run.
+	Record(ctx context.Context, job *T, err error)
+	// Purge is run by the janitor, such as to delete expired files.
+	Purge(ctx context.Context)
+}
+
+type Config struct {
+	// Name labels the runner in logs, such as "Export".
+	Name         string
+	Workers      int
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+	// MaxAttempts is how often a job is retried after its worker stopped
+	// part-way, such as during a deploy.
+	MaxAttempts     int
+	JanitorInterval time.Duration
+}
+
+type Runner[T any] struct {
+	handler Handler[T]
+	config  Config
+	logger  *logger.Logger
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner[T any](handler Handler[T], config Config, logger *logger.Logger) *Runner[T] {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 3
+	}
+	if config.JanitorInterval <= 0 {
+		config.JanitorInterval = time.Hour
+	}
+
+	return &Runner[T]{
+		handler: handler,
+		config:  config,
+		logger:  logger,
+		wake:    make(chan struct{}, config.Workers),
+	}
+}
+
+// Nudge wakes an idle worker, so a job queued just now does not wait for
+// the next poll.
+func (r *Runner[T]) Nudge() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runner[T]) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go r.runWorker(ctx, i)
+	}
+
+	r.wg.Add(1)
+	go r.runJanitor(ctx)
+
+	r.logger.Info(r.config.Name+" workers started", logger.Fields{
+		"workers": r.config.Workers,
+	})
+}
+
+func (r *Runner[T]) Stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	r.wg.Wait()
+	r.logger.Info(r.config.Name + " workers stopped")
+}
+
+func (r *Runner[T]) runWorker(ctx context.Context, worker int) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for r.processNext(ctx, worker) {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner[T]) processNext(ctx context.Context, worker int) bool {
+	// The lease outlasts the job timeout, so a job is only picked up again
+	// once its worker is certainly gone.
+	job, err := r.handler.Claim(ctx, r.config.JobTimeout+time.Minute, r.config.MaxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("Failed to claim job", logger.Fields{
+				"error":  err.Error(),
+				"runner": r.config.Name,
+				"worker": worker,
+			})
+		}
+		return false
+	}
+
+	if job == nil {
+		return false
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, r.config.JobTimeout)
+	defer cancel()
+
+	err = r.handler.Run(jobCtx, job)
+	// A job interrupted by shutdown keeps its lease and is picked up again
+	// once the lease expires.
+	if err != nil && ctx.Err() != nil {
+		return true
+	}
+
+	// A fresh context, so the outcome is saved even when the job timed out.
+	recordCtx, recordCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer recordCancel()
+
+	r.handler.Record(recordCtx, job, err)
+	return true
+}
+
+func (r *Runner[T]) runJanitor(ctx context.Context) {
+	defer r.wg.Done()
+	RunEvery(ctx, r.config.JanitorInterval, r.handler.Purge)
+}
+
+// RunEvery calls fn once per interval until ctx is cancelled. The first
+// call is one interval after the start.
+func RunEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
+
+// Backoff is the wait before retrying after the given failed attempt,
+// counting from 1. It doubles each time, starting at base and capped at
+// max: with a 30s base, 30s, 1m, 2m, 4m...
+func Backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := float64(base) * math.Pow(2, float64(attempt-1))
+	if delay > float64(max) {
+		return max
+	}
+	return time.Duration(delay)
+}
+
+// Table describes a job table for Claim. It needs status, attempts,
+// started_at and locked_until columns.
+type Table struct {
+	Name string
+	// Columns are returned for the claimed row.
+	Columns string
+	// Due narrows the pending rows, such as to those scheduled by $1, the
+	// current time. Empty means every pending row is due.
+	Due   string
+	Order string
+}
+
+// Claim leases the oldest due row of table, or a running one whose worker
+// stopped before finishing, and scans it into a new T. It returns nil when
+// nothing is due.
+func Claim[T any](ctx context.Context, db *database.DB, table Table, lockFor time.Duration, maxAttempts int) (*T, error) {
+	pending := "status = 'pending'"
+	if table.Due != "" {
+		pending = fmt.Sprintf("(status = 'pending' AND %s)", table.Due)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %[1]s
+		SET status = 'running', attempts = attempts + 1, started_at = $1, locked_until = $2
+		WHERE id = (
+			SELECT id FROM %[1]s
+			WHERE (%[2]s OR (status = 'running' AND locked_until < $1))
+			AND attempts < $3
+			ORDER BY %[3]s
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %[4]s
+	`, table.Name, pending, table.Order, table.Columns)
+
+	now := time.Now()
+
+	var job T
+	if err := db.GetContext(ctx, &job, query, now, now.Add(lockFor), maxAttempts); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim from %s: %w", table.Name, err)
+	}
+
+	return &job, nil
+}
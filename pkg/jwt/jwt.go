@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"strings"
@@ -19,6 +20,9 @@ type Interface interface {
 	ExtractTokenFromFiberContext(c *fiber.Ctx) (string, error)
 	RevokeToken(ctx context.Context, tokenString string) error
 	IsTokenRevoked(ctx context.Context, tokenString string) (bool, error)
+	// RevokeUserTokens invalidates every token issued to the user so far.
+	// Tokens issued afterwards are accepted.
+	RevokeUserTokens(ctx context.Context, userID string) error
 	CleanupExpiredTokens(ctx context.Context) error
 }
 
@@ -149,6 +153,13 @@ func (s *Service) ValidateAccessToken(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	if s.redisClient != nil && claims.IssuedAt != nil {
+		revokedAt, err := s.redisClient.Get(context.Background(), userRevocationKey(claims.UserID)).Int64()
+		if err == nil && claims.IssuedAt.Unix() <= revokedAt {
+			return nil, fmt.Errorf("token has been revoked")
+		}
+	}
+
 	return claims, nil
 }
 
@@ -197,8 +208,7 @@ func (s *Service) RevokeToken(ctx context.Context, tokenString string) error {
 		}
 	}
 
-	revokedKey := fmt.Sprintf("revoked_token:%s", generateTokenHash(tokenString))
-	err = s.redisClient.Set(ctx, revokedKey, time.Now().Unix(), ttl).Err()
+	err = s.redisClient.Set(ctx, revokedTokenKey(tokenString), time.Now().Unix(), ttl).Err()
 	if err != nil {
 		return fmt.Errorf("failed to revoke token in Redis: %w", err)
 	}
@@ -211,8 +221,12 @@ func (s *Service) IsTokenRevoked(ctx context.Context, tokenString string) (bool,
 		return false, nil
 	}
 
-	revokedKey := fmt.Sprintf("revoked_token:%s", generateTokenHash(tokenString))
-	exists, err := s.redisClient.Exists(ctx, revokedKey).Result()
+	// Keys written before tokens were hashed with SHA-256 are still checked
+	// until they expire, one access token lifetime after the upgrade.
+	exists, err := s.redisClient.Exists(ctx,
+		revokedTokenKey(tokenString),
+		fmt.Sprintf("revoked_token:%s", legacyTokenHash(tokenString)),
+	).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to check token revocation: %w", err)
 	}
@@ -220,6 +234,25 @@ func (s *Service) IsTokenRevoked(ctx context.Context, tokenString string) (bool,
 	return exists > 0, nil
 }
 
+func (s *Service) RevokeUserTokens(ctx context.Context, userID string) error {
+	if s.redisClient == nil {
+		return fmt.Errorf("Redis client not available for token revocation")
+	}
+
+	// Tokens carry their issue time in whole seconds, so one issued later in
+	// the same second is revoked too.
+	err := s.redisClient.Set(ctx, userRevocationKey(userID), time.Now().Unix(), s.config.AccessTokenExpiry).Err()
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens in Redis: %w", err)
+	}
+
+	return nil
+}
+
+func userRevocationKey(userID string) string {
+	return fmt.Sprintf("revoked_user:%s", userID)
+}
+
 func (s *Service) CleanupExpiredTokens(ctx context.Context) error {
 	if s.redisClient == nil {
 		return nil
@@ -232,8 +265,20 @@ func generateJTI() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
 
+func revokedTokenKey(token string) string {
+	return fmt.Sprintf("revoked_token:%s", generateTokenHash(token))
+}
+
 func generateTokenHash(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
 
+// legacyTokenHash is the key format used before SHA-256. It only depends on
+// the token's length and first byte, so unrelated tokens share a key.
+func legacyTokenHash(token string) string {
+	if token == "" {
+		return ""
+	}
 	return fmt.Sprintf("%x", len(token)*31+int(token[0]))
 }
 
@@ -1,6 +1,7 @@
 package s3
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
@@ -22,6 +23,9 @@ type Interface interface {
 	UploadFile(file *multipart.FileHeader, key string) (*UploadResult, error)
 	UploadFileFromBytes(data []byte, key, contentType string) (*UploadResult, error)
 	UploadFileFromReader(reader io.Reader, key, contentType string, size int64) (*UploadResult, error)
+	// UploadPrivateFileFromReader stores the object without the default
+	// ACL, so it can only be fetched with a presigned URL.
+	UploadPrivateFileFromReader(reader io.Reader, key, contentType string) (*UploadResult, error)
 	DownloadFile(key string) ([]byte, error)
 	DeleteFile(key string) error
 	DeleteFiles(keys []string) error
@@ -192,6 +196,14 @@ func (s *Service) UploadFileFromBytes(data []byte, key, contentType string) (*Up
 }
 
 func (s *Service) UploadFileFromReader(reader io.Reader, key, contentType string, size int64) (*UploadResult, error) {
+	return s.upload(reader, key, contentType, size, s.config.DefaultACL)
+}
+
+func (s *Service) UploadPrivateFileFromReader(reader io.Reader, key, contentType string) (*UploadResult, error) {
+	return s.upload(reader, key, contentType, 0, "private")
+}
+
+func (s *Service) upload(reader io.Reader, key, contentType string, size int64, acl string) (*UploadResult, error) {
 	if key == "" {
 		return nil, fmt.Errorf("key is required")
 	}
@@ -205,7 +217,7 @@ func (s *Service) UploadFileFromReader(reader io.Reader, key, contentType string
 		Key:         aws.String(key),
 		Body:        reader,
 		ContentType: aws.String(contentType),
-		ACL:         aws.String(s.config.DefaultACL),
+		ACL:         aws.String(acl),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file: %w", err)
@@ -514,6 +526,31 @@ func (s *Service) GetContentType(filename string) string {
 	return "application/octet-stream"
 }
 
+// StreamPrivateFile uploads what write produces as it is produced, so a
+// large file is never held in memory. The upload fails if write does.
+func StreamPrivateFile(client Interface, key, contentType string, write func(w io.Writer) error) error {
+	pr, pw := io.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		buffered := bufio.NewWriter(pw)
+		err := write(buffered)
+		if err == nil {
+			err = buffered.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	_, err := client.UploadPrivateFileFromReader(pr, key, contentType)
+	// Unblocks the writer if the upload gave up early.
+	pr.CloseWithError(err)
+	<-done
+
+	return err
+}
+
 func ExtractKeyFromURL(fileURL string) string {
 	parsedURL, err := url.Parse(fileURL)
 	if err != nil {
@@ -0,0 +1,189 @@
+package screening
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed wordlists
+var embeddedWordlists embed.FS
+
+var wordlistFiles = []string{"id.txt", "en.txt"}
+
+const (
+	profanityScorePerWord = 30
+	profanityMaxScore     = 60
+	linkScore             = 40
+	linkScorePerExtra     = 10
+	linkMaxScore          = 60
+	duplicateScore        = 50
+)
+
+// loadWordlists reads the built-in lists, replacing any file that also
+// exists in dir.
+func loadWordlists(dir string) ([]string, error) {
+	var words []string
+	for _, name := range wordlistFiles {
+		data, err := fs.ReadFile(embeddedWordlists, "wordlists/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("screening: failed to read built-in wordlist %s: %w", name, err)
+		}
+
+		if dir != "" {
+			override, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil {
+				data = override
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("screening: failed to read wordlist %s: %w", name, err)
+			}
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			words = append(words, line)
+		}
+	}
+	return words, nil
+}
+
+type profanityCheck struct {
+	words map[string]string
+}
+
+// NewProfanityCheck matches whole words against the list. Both sides are
+// normalised first, so "sh1t" and "shiiit" match "shit".
+func NewProfanityCheck(words []string) Check {
+	normalized := make(map[string]string, len(words))
+	for _, word := range words {
+		normalized[normalizeWord(word)] = strings.ToLower(word)
+	}
+	return &profanityCheck{words: normalized}
+}
+
+func (c *profanityCheck) Name() string {
+	return "profanity"
+}
+
+func (c *profanityCheck) Run(ctx context.Context, submission Submission) (*Finding, error) {
+	seen := map[string]bool{}
+	var matched []string
+
+	for _, token := range tokenize(submission.Message) {
+		word, ok := c.words[normalizeWord(token)]
+		if !ok || seen[word] {
+			continue
+		}
+		seen[word] = true
+		matched = append(matched, fmt.Sprintf("%q", word))
+	}
+
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	return &Finding{
+		Check:  c.Name(),
+		Score:  min(len(matched)*profanityScorePerWord, profanityMaxScore),
+		Detail: "Contains " + strings.Join(matched, ", "),
+	}, nil
+}
+
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+func tokenize(message string) []string {
+	return strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+}
+
+// normalizeWord undoes digit substitutions and collapses repeated letters.
+func normalizeWord(word string) string {
+	word = leetReplacer.Replace(strings.ToLower(word))
+
+	var b strings.Builder
+	var last rune
+	for _, r := range word {
+		if r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+type linkCheck struct{}
+
+// Links are rarely part of an honest review of a meal, and are the main
+// payload of spam. Obfuscated forms such as "example dot com" count too.
+// Only the spelled-out dots may have spaces around them; a bare "." with a
+// space after it ends a sentence, as in "Loved the salmon. Me and my wife".
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9][a-z0-9-]*(?:` + linkDot + `[a-z0-9-]+)*` + linkDot + `(?:com|net|org|id|co|io|me|ly|xyz|info|biz|site|online|shop|link|app)\b(?:/\S*)?`)
+
+const linkDot = `(?:\.|\s*\(dot\)\s*|\s*\[dot\]\s*|\s+dot\s+)`
+
+func NewLinkCheck() Check {
+	return &linkCheck{}
+}
+
+func (c *linkCheck) Name() string {
+	return "links"
+}
+
+func (c *linkCheck) Run(ctx context.Context, submission Submission) (*Finding, error) {
+	links := linkPattern.FindAllString(submission.Message, 5)
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	return &Finding{
+		Check:  c.Name(),
+		Score:  min(linkScore+(len(links)-1)*linkScorePerExtra, linkMaxScore),
+		Detail: fmt.Sprintf("Contains %d link(s): %s", len(links), strings.Join(links, ", ")),
+	}, nil
+}
+
+type duplicateCheck struct {
+	finder DuplicateFinder
+}
+
+func NewDuplicateCheck(finder DuplicateFinder) Check {
+	return &duplicateCheck{finder: finder}
+}
+
+func (c *duplicateCheck) Name() string {
+	return "duplicate"
+}
+
+func (c *duplicateCheck) Run(ctx context.Context, submission Submission) (*Finding, error) {
+	if c.finder == nil || submission.ContentHash == "" {
+		return nil, nil
+	}
+
+	existingID, err := c.finder.FindByContentHash(ctx, submission.ContentHash, submission.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existingID == "" {
+		return nil, nil
+	}
+
+	return &Finding{
+		Check:  c.Name(),
+		Score:  duplicateScore,
+		Detail: "Same text as " + existingID,
+	}, nil
+}
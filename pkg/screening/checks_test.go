@@ -0,0 +1,36 @@
+package screening
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLinkCheck(t *testing.T) {
+	cases := []struct {
+		message string
+		flagged bool
+	}{
+		{"Order at cheapmeals.com today", true},
+		{"Visit https://example.org/deals", true},
+		{"See www.example.net", true},
+		{"cheapmeals dot com", true},
+		{"cheapmeals (dot) com", true},
+		{"cheapmeals[dot]co", true},
+		{"shop.cheapmeals.id/promo", true},
+		{"Loved the salmon. Me and my wife order every week.", false},
+		{"Great value. Co-workers keep asking where it's from.", false},
+		{"Tasty and filling. Info on allergens was clear too.", false},
+		{"Fresh every day, highly recommended!", false},
+	}
+
+	check := NewLinkCheck()
+	for _, tc := range cases {
+		finding, err := check.Run(context.Background(), Submission{Message: tc.message})
+		if err != nil {
+			t.Fatalf("%q: %v", tc.message, err)
+		}
+		if got := finding != nil; got != tc.flagged {
+			t.Errorf("%q: flagged = %v, want %v (finding %+v)", tc.message, got, tc.flagged, finding)
+		}
+	}
+}
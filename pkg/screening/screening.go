@@ -0,0 +1,235 @@
+package screening
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"sea-catering-backend/pkg/logger"
+)
+
+type Interface interface {
+	// Allow counts a submission from ip and reports whether it is within
+	// the per-IP limit.
+	Allow(ctx context.Context, ip string) (bool, error)
+	Screen(ctx context.Context, submission Submission) (*Result, error)
+}
+
+// Check scores one aspect of a submission. It returns nil when there is
+// nothing to report.
+type Check interface {
+	Name() string
+	Run(ctx context.Context, submission Submission) (*Finding, error)
+}
+
+// DuplicateFinder looks up earlier content with the same hash and returns
+// its ID, or an empty string when there is none.
+type DuplicateFinder interface {
+	FindByContentHash(ctx context.Context, hash, excludeID string) (string, error)
+}
+
+// RateLimiter counts events per key within a window.
+type RateLimiter interface {
+	IsRateLimited(ctx context.Context, key string, limit int64, window time.Duration) (bool, error)
+}
+
+type Submission struct {
+	// ID identifies the content being screened so that an edit is not
+	// reported as a duplicate of itself. Empty for new content.
+	ID          string
+	Message     string
+	ContentHash string
+}
+
+type Finding struct {
+	Check  string `json:"check"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+type Action string
+
+const (
+	ActionNone Action = "none"
+	ActionFlag Action = "flag"
+	ActionHide Action = "hide"
+)
+
+type Result struct {
+	Score       int
+	Findings    []Finding
+	ContentHash string
+	Action      Action
+}
+
+// Summary lists the checks that scored, for moderation notes.
+func (r *Result) Summary() string {
+	names := make([]string, len(r.Findings))
+	for i, finding := range r.Findings {
+		names[i] = finding.Check
+	}
+	return fmt.Sprintf("Automatic screening scored %d (%s)", r.Score, strings.Join(names, ", "))
+}
+
+type Config struct {
+	// FlagThreshold and HideThreshold are spam scores from 0 to 100 at or
+	// above which content is sent for a second look or taken out of view.
+	FlagThreshold int
+	HideThreshold int
+	IPLimit       int64
+	IPWindow      time.Duration
+	// WordlistDir, when set, holds id.txt and en.txt files that replace the
+	// built-in profanity lists.
+	WordlistDir string
+}
+
+type Service struct {
+	config  *Config
+	checks  []Check
+	limiter RateLimiter
+	logger  *logger.Logger
+}
+
+func LoadConfig() *Config {
+	flagThreshold := 30
+	if v, err := strconv.Atoi(os.Getenv("SCREENING_FLAG_THRESHOLD")); err == nil && v > 0 {
+		flagThreshold = v
+	}
+
+	hideThreshold := 70
+	if v, err := strconv.Atoi(os.Getenv("SCREENING_HIDE_THRESHOLD")); err == nil && v > 0 {
+		hideThreshold = v
+	}
+
+	ipLimit := int64(5)
+	if v, err := strconv.ParseInt(os.Getenv("SCREENING_IP_LIMIT"), 10, 64); err == nil && v > 0 {
+		ipLimit = v
+	}
+
+	ipWindow := time.Hour
+	if v, err := time.ParseDuration(os.Getenv("SCREENING_IP_WINDOW")); err == nil && v > 0 {
+		ipWindow = v
+	}
+
+	return &Config{
+		FlagThreshold: flagThreshold,
+		HideThreshold: hideThreshold,
+		IPLimit:       ipLimit,
+		IPWindow:      ipWindow,
+		WordlistDir:   os.Getenv("SCREENING_WORDLIST_DIR"),
+	}
+}
+
+// New builds the default pipeline of profanity, link and duplicate checks.
+// Extra checks run after them and add to the same score.
+func New(config *Config, finder DuplicateFinder, limiter RateLimiter, logger *logger.Logger, extra ...Check) (Interface, error) {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	words, err := loadWordlists(config.WordlistDir)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []Check{
+		NewProfanityCheck(words),
+		NewLinkCheck(),
+		NewDuplicateCheck(finder),
+	}
+
+	return &Service{
+		config:  config,
+		checks:  append(checks, extra...),
+		limiter: limiter,
+		logger:  logger,
+	}, nil
+}
+
+func (s *Service) Allow(ctx context.Context, ip string) (bool, error) {
+	if ip == "" {
+		return true, nil
+	}
+
+	limited, err := s.limiter.IsRateLimited(ctx, fmt.Sprintf("screening:ip:%s", ip), s.config.IPLimit, s.config.IPWindow)
+	if err != nil {
+		return false, err
+	}
+
+	return !limited, nil
+}
+
+// Screen runs every check. A check that fails is logged and skipped, so a
+// database hiccup never blocks a submission; the content still goes to
+// manual review.
+func (s *Service) Screen(ctx context.Context, submission Submission) (*Result, error) {
+	submission.ContentHash = ContentHash(submission.Message)
+
+	result := &Result{
+		Findings:    []Finding{},
+		ContentHash: submission.ContentHash,
+		Action:      ActionNone,
+	}
+
+	for _, check := range s.checks {
+		finding, err := check.Run(ctx, submission)
+		if err != nil {
+			s.logger.Warn("Content check failed", logger.Fields{
+				"check": check.Name(),
+				"error": err.Error(),
+			})
+			continue
+		}
+		if finding == nil || finding.Score <= 0 {
+			continue
+		}
+		result.Findings = append(result.Findings, *finding)
+		result.Score += finding.Score
+	}
+
+	if result.Score > 100 {
+		result.Score = 100
+	}
+
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return result.Findings[i].Score > result.Findings[j].Score
+	})
+
+	switch {
+	case result.Score >= s.config.HideThreshold:
+		result.Action = ActionHide
+	case result.Score >= s.config.FlagThreshold:
+		result.Action = ActionFlag
+	}
+
+	return result, nil
+}
+
+// ContentHash identifies a message regardless of case, punctuation and
+// spacing, so trivially altered copies still match.
+func ContentHash(message string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(message) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
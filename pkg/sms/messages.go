@@ -0,0 +1,57 @@
+package sms
+
+import "fmt"
+
+// Texts are kept short enough to fit a single 160-character SMS segment.
+
+var indonesianMonths = [...]string{
+	"Jan", "Feb", "Mar", "Apr", "Mei", "Jun",
+	"Jul", "Agu", "Sep", "Okt", "Nov", "Des",
+}
+
+var indonesianMealTypes = map[string]string{
+	"breakfast": "sarapan",
+	"lunch":     "makan siang",
+	"dinner":    "makan malam",
+}
+
+func otpText(locale Locale, otp string) string {
+	if locale == LocaleEnglish {
+		return fmt.Sprintf("SEA Catering: your verification code is %s. It expires in 10 minutes. Do not share it with anyone.", otp)
+	}
+	return fmt.Sprintf("SEA Catering: kode verifikasi Anda %s. Berlaku 10 menit. Jangan berikan kode ini kepada siapa pun.", otp)
+}
+
+func deliveryText(locale Locale, update *DeliveryUpdate) (string, error) {
+	if locale == LocaleEnglish {
+		date := update.Date.Format("Jan 2")
+		switch update.Status {
+		case DeliveryOutForDelivery:
+			return fmt.Sprintf("SEA Catering: your %s for %s is on its way, arriving %s.", update.MealType, date, update.Window), nil
+		case DeliveryDelivered:
+			return fmt.Sprintf("SEA Catering: your %s for %s has been delivered. Enjoy your meal!", update.MealType, date), nil
+		case DeliveryFailed:
+			return fmt.Sprintf("SEA Catering: we could not deliver your %s for %s (%s). Our team will contact you.", update.MealType, date, update.FailureReason), nil
+		}
+	} else {
+		date := fmt.Sprintf("%d %s", update.Date.Day(), indonesianMonths[update.Date.Month()-1])
+		mealType := indonesianMealType(update.MealType)
+		switch update.Status {
+		case DeliveryOutForDelivery:
+			return fmt.Sprintf("SEA Catering: %s Anda untuk %s sedang diantar, tiba pukul %s.", mealType, date, update.Window), nil
+		case DeliveryDelivered:
+			return fmt.Sprintf("SEA Catering: %s Anda untuk %s telah diterima. Selamat menikmati!", mealType, date), nil
+		case DeliveryFailed:
+			return fmt.Sprintf("SEA Catering: %s Anda untuk %s gagal diantar (%s). Tim kami akan menghubungi Anda.", mealType, date, update.FailureReason), nil
+		}
+	}
+
+	return "", fmt.Errorf("sms: no delivery text for status %q", update.Status)
+}
+
+func indonesianMealType(mealType string) string {
+	if translated, ok := indonesianMealTypes[mealType]; ok {
+		return translated
+	}
+	return mealType
+}
@@ -0,0 +1,137 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sea-catering-backend/pkg/logger"
+)
+
+// LogProvider writes messages to the application log instead of sending
+// them. It is the default so local development never texts real numbers.
+type LogProvider struct {
+	logger *logger.Logger
+}
+
+func NewLogProvider(logger *logger.Logger) *LogProvider {
+	return &LogProvider{logger: logger}
+}
+
+func (p *LogProvider) Name() string {
+	return "log"
+}
+
+func (p *LogProvider) Send(ctx context.Context, message Message) error {
+	p.logger.Info("SMS not sent (log provider)", logger.Fields{
+		"to":   message.To,
+		"body": message.Body,
+	})
+	return nil
+}
+
+// FileProvider appends each message as a JSON line, so OTPs can be read back
+// by scripts and end-to-end tests.
+type FileProvider struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+func (p *FileProvider) Send(ctx context.Context, message Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sent_at"`
+	}{message, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// TwilioProvider sends through Twilio's Messages API. With the WhatsApp
+// channel both numbers get the "whatsapp:" prefix Twilio expects.
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	channel    Channel
+	client     *http.Client
+}
+
+func NewTwilioProvider(config *Config) *TwilioProvider {
+	return &TwilioProvider{
+		accountSID: config.TwilioAccountSID,
+		authToken:  config.TwilioAuthToken,
+		from:       config.TwilioFrom,
+		channel:    config.Channel,
+		client:     &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+func (p *TwilioProvider) Send(ctx context.Context, message Message) error {
+	from, to := p.from, message.To
+	if p.channel == ChannelWhatsApp {
+		from, to = "whatsapp:"+from, "whatsapp:"+to
+	}
+
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", message.Body)
+
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", p.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
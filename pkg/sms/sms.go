@@ -0,0 +1,201 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sea-catering-backend/pkg/logger"
+)
+
+type Interface interface {
+	Send(ctx context.Context, message Message) error
+	SendOTP(ctx context.Context, to, otp string, locale Locale) error
+	SendDeliveryUpdate(ctx context.Context, to string, locale Locale, update *DeliveryUpdate) error
+	ProviderName() string
+}
+
+// Provider delivers a single short message. Implementations must be safe for
+// concurrent use.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, message Message) error
+}
+
+// Message is a short text to one phone number in E.164 form.
+type Message struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+type Channel string
+
+const (
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+type Locale string
+
+const (
+	LocaleIndonesian Locale = "id"
+	LocaleEnglish    Locale = "en"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+type DeliveryStatus string
+
+const (
+	DeliveryOutForDelivery DeliveryStatus = "out_for_delivery"
+	DeliveryDelivered      DeliveryStatus = "delivered"
+	DeliveryFailed         DeliveryStatus = "failed"
+)
+
+type DeliveryUpdate struct {
+	Status        DeliveryStatus
+	MealType      string
+	Window        string
+	Date          time.Time
+	FailureReason string
+}
+
+type Config struct {
+	// Provider is one of "log", "file" or "twilio".
+	Provider string
+	// Channel selects plain SMS or WhatsApp on providers that support both.
+	Channel  Channel
+	FilePath string
+
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFrom       string
+	Timeout          time.Duration
+}
+
+type Service struct {
+	provider Provider
+}
+
+func LoadConfig() *Config {
+	provider := strings.ToLower(os.Getenv("SMS_PROVIDER"))
+	if provider == "" {
+		provider = "log"
+	}
+
+	channel := ChannelSMS
+	if strings.EqualFold(os.Getenv("SMS_CHANNEL"), string(ChannelWhatsApp)) {
+		channel = ChannelWhatsApp
+	}
+
+	filePath := os.Getenv("SMS_FILE_PATH")
+	if filePath == "" {
+		filePath = "./logs/sms.jsonl"
+	}
+
+	return &Config{
+		Provider:         provider,
+		Channel:          channel,
+		FilePath:         filePath,
+		TwilioAccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+		TwilioFrom:       os.Getenv("TWILIO_FROM"),
+		Timeout:          10 * time.Second,
+	}
+}
+
+func New(logger *logger.Logger) (Interface, error) {
+	return NewWithConfig(LoadConfig(), logger)
+}
+
+func NewWithConfig(config *Config, logger *logger.Logger) (Interface, error) {
+	if config == nil {
+		config = LoadConfig()
+	}
+
+	provider, err := newProvider(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithProvider(provider), nil
+}
+
+// NewWithProvider wraps a ready-made provider, e.g. a fake in tests.
+func NewWithProvider(provider Provider) Interface {
+	return &Service{provider: provider}
+}
+
+func newProvider(config *Config, logger *logger.Logger) (Provider, error) {
+	switch config.Provider {
+	case "log":
+		return NewLogProvider(logger), nil
+	case "file":
+		return NewFileProvider(config.FilePath), nil
+	case "twilio":
+		if config.TwilioAccountSID == "" || config.TwilioAuthToken == "" || config.TwilioFrom == "" {
+			return nil, fmt.Errorf("sms: twilio provider needs TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM")
+		}
+		return NewTwilioProvider(config), nil
+	default:
+		return nil, fmt.Errorf("sms: unknown provider %q", config.Provider)
+	}
+}
+
+func (s *Service) ProviderName() string {
+	return s.provider.Name()
+}
+
+func (s *Service) Send(ctx context.Context, message Message) error {
+	to, err := NormalizePhone(message.To)
+	if err != nil {
+		return err
+	}
+	message.To = to
+
+	if err := s.provider.Send(ctx, message); err != nil {
+		return fmt.Errorf("sms: %s provider: %w", s.provider.Name(), err)
+	}
+
+	return nil
+}
+
+func (s *Service) SendOTP(ctx context.Context, to, otp string, locale Locale) error {
+	return s.Send(ctx, Message{To: to, Body: otpText(locale, otp)})
+}
+
+func (s *Service) SendDeliveryUpdate(ctx context.Context, to string, locale Locale, update *DeliveryUpdate) error {
+	body, err := deliveryText(locale, update)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(ctx, Message{To: to, Body: body})
+}
+
+// NormalizePhone turns the Indonesian formats accepted at registration
+// (08…, 628…, +628…) into E.164.
+func NormalizePhone(phone string) (string, error) {
+	digits := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "0"):
+		digits = "62" + digits[1:]
+	}
+
+	if len(digits) < 9 || len(digits) > 15 {
+		return "", ErrInvalidPhone
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidPhone
+		}
+	}
+
+	return "+" + digits, nil
+}
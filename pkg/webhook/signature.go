@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers set on every webhook call.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrMalformedHeader  = errors.New("malformed webhook signature header")
+	ErrTimestampExpired = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign returns the signature header value "t=<unix>,v1=<hex>", where v1 is
+// the HMAC-SHA256 of "<unix>.<body>" under the endpoint secret. Including the
+// timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, computeSignature(secret, unix, body))
+}
+
+// Verify checks a signature header produced by Sign. A zero tolerance skips
+// the timestamp check.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return ErrMalformedHeader
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	if unix == "" || signature == "" {
+		return ErrMalformedHeader
+	}
+
+	if tolerance > 0 {
+		seconds, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			return ErrMalformedHeader
+		}
+		if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(computeSignature(secret, unix, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func computeSignature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"sea-catering-backend/pkg/webhook"
+)
+
+// A local endpoint for checking webhook delivery end to end. Register
+// http://localhost:9000/webhook as an endpoint, then run:
+//
+//	go run ./tools/webhook-receiver -secret whsec_...
+func main() {
+	var (
+		addr      = flag.String("addr", ":9000", "Listen address")
+		path      = flag.String("path", "/webhook", "Path to receive webhooks on")
+		secret    = flag.String("secret", "", "Endpoint signing secret")
+		tolerance = flag.Duration("tolerance", 5*time.Minute, "Maximum signature age, 0 to disable")
+		fail      = flag.Int("fail", 0, "Respond 500 to the first N requests to exercise retries")
+	)
+	flag.Parse()
+
+	if *secret == "" {
+		*secret = os.Getenv("WEBHOOK_SECRET")
+		if *secret == "" {
+			log.Fatal("Signing secret is required. Set WEBHOOK_SECRET environment variable or use -secret flag")
+		}
+	}
+
+	received := 0
+	http.HandleFunc(*path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		received++
+		log.Printf("#%d %s event=%s id=%s delivery=%s",
+			received, r.Method,
+			r.Header.Get(webhook.HeaderEventType),
+			r.Header.Get(webhook.HeaderEventID),
+			r.Header.Get(webhook.HeaderDelivery),
+		)
+
+		if err := webhook.Verify(*secret, r.Header.Get(webhook.HeaderSignature), body, *tolerance); err != nil {
+			log.Printf("   rejected: %v", err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if received <= *fail {
+			log.Printf("   signature ok, failing on purpose (%d/%d)", received, *fail)
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "   ", "  "); err != nil {
+			pretty.Write(body)
+		}
+		log.Printf("   signature ok\n   %s", pretty.String())
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"received":true}`)
+	})
+
+	log.Printf("Listening for webhooks on http://localhost%s%s", *addr, *path)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}